@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/agent"
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/config"
+	"github.com/notexe/cli-chat/internal/mcp"
+)
+
+// maxAutoPreview limits how much of a tool result is printed as progress.
+const maxAutoPreview = 300
+
+// runAuto runs task in autonomous mode and prints progress to stdout.
+// It returns an error if the run failed or stopped before the model
+// signalled completion.
+func runAuto(ctx context.Context, provider api.Provider, mcpManager *mcp.Manager, cfg *config.Config, task string, limits agent.Limits) error {
+	system := agent.SystemPrompt
+	if cfg.Model.SystemPrompt != "" {
+		system = cfg.Model.SystemPrompt + "\n\n" + agent.SystemPrompt
+	}
+
+	runner := &agent.Runner{
+		Provider:    provider,
+		MCP:         mcpManager,
+		System:      system,
+		Model:       cfg.Model.Name,
+		MaxTokens:   cfg.Model.MaxTokens,
+		Temperature: cfg.Model.Temperature,
+		Limits:      limits,
+		OnEvent:     printAutoEvent(limits),
+	}
+
+	fmt.Printf("Autonomous mode: up to %d iterations, %d tokens, %v\n",
+		limits.MaxIterations, limits.TokenBudget, limits.Timeout)
+
+	result, err := runner.Run(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nStopped: %s after %d iteration(s), %d tokens, %v\n",
+		result.StopReason, result.Iterations,
+		result.Usage.InputTokens+result.Usage.OutputTokens,
+		result.Duration.Round(100*time.Millisecond))
+	if result.Summary != "" {
+		fmt.Printf("\n%s\n", result.Summary)
+	}
+
+	if result.StopReason != agent.StopDone {
+		return fmt.Errorf("task not completed (stopped: %s)", result.StopReason)
+	}
+	return nil
+}
+
+// printAutoEvent returns a progress printer for autonomous runs.
+func printAutoEvent(limits agent.Limits) func(agent.Event) {
+	return func(e agent.Event) {
+		switch e.Kind {
+		case agent.EventIteration:
+			fmt.Printf("\n[%d/%d] thinking... (%d tokens used)\n",
+				e.Iteration, limits.MaxIterations, e.Usage.InputTokens+e.Usage.OutputTokens)
+		case agent.EventMessage:
+			fmt.Printf("  %s\n", strings.TrimSpace(e.Text))
+		case agent.EventToolCall:
+			fmt.Printf("  Tool: %s %s\n", e.ToolName, e.Arguments)
+		case agent.EventToolResult:
+			preview := strings.TrimSpace(e.Text)
+			if len(preview) > maxAutoPreview {
+				preview = preview[:maxAutoPreview] + "..."
+			}
+			fmt.Printf("  Result: %s\n", preview)
+		}
+	}
+}
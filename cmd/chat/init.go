@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/config"
+	"golang.org/x/term"
+)
+
+// defaultOllamaModel is suggested when Ollama is chosen during --init.
+const defaultOllamaModel = "llama3.2"
+
+// runInit writes a commented config file to configPath and an example
+// mcp.json next to the default config, asking for the provider and API key
+// first. Existing files are left alone unless force is set.
+func runInit(configPath string, force bool) error {
+	configPath = config.ExpandPath(configPath)
+	mcpPath := config.ExpandPath(config.GetDefaultMCPConfigPath())
+
+	writeConfig := force || !fileExists(configPath)
+	writeMCP := force || !fileExists(mcpPath)
+	if !writeConfig {
+		fmt.Printf("%s already exists, left unchanged (use --force to overwrite)\n", configPath)
+	}
+	if !writeMCP {
+		fmt.Printf("%s already exists, left unchanged (use --force to overwrite)\n", mcpPath)
+	}
+
+	if writeConfig {
+		opts, err := promptInitOptions(bufio.NewReader(os.Stdin))
+		if err != nil {
+			return err
+		}
+		content, err := config.InitConfig(opts)
+		if err != nil {
+			return err
+		}
+		// Private: the file may hold the API key
+		if err := writeInitFile(configPath, content, 0o600); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", configPath)
+	}
+
+	if writeMCP {
+		content, err := config.InitMCPConfig()
+		if err != nil {
+			return err
+		}
+		if err := writeInitFile(mcpPath, content, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s (see mcp.example.json for more servers)\n", mcpPath)
+	}
+
+	return nil
+}
+
+// promptInitOptions asks for the provider, then the API key for DeepSeek
+// or the model for Ollama.
+func promptInitOptions(in *bufio.Reader) (config.InitOptions, error) {
+	var opts config.InitOptions
+
+	for {
+		answer, err := prompt(in, fmt.Sprintf("Provider (%s/%s) [%s]: ", config.ProviderDeepSeek, config.ProviderOllama, config.ProviderDeepSeek))
+		if err != nil {
+			return opts, err
+		}
+		switch strings.ToLower(answer) {
+		case "", config.ProviderDeepSeek:
+			opts.Provider = config.ProviderDeepSeek
+		case config.ProviderOllama:
+			opts.Provider = config.ProviderOllama
+		default:
+			fmt.Printf("Unknown provider %q\n", answer)
+			continue
+		}
+		break
+	}
+
+	if opts.Provider == config.ProviderOllama {
+		model, err := prompt(in, fmt.Sprintf("Ollama model [%s]: ", defaultOllamaModel))
+		if err != nil {
+			return opts, err
+		}
+		if model == "" {
+			model = defaultOllamaModel
+		}
+		opts.Model = model
+		return opts, nil
+	}
+
+	key, err := promptSecret(in, "DeepSeek API key (empty to use DEEPSEEK_API_KEY): ")
+	if err != nil {
+		return opts, err
+	}
+	opts.APIKey = key
+	return opts, nil
+}
+
+// prompt prints label and returns the trimmed line typed in answer.
+func prompt(in *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// promptSecret is prompt without echoing the answer when reading from a
+// terminal.
+func promptSecret(in *bufio.Reader, label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(in, label)
+	}
+	fmt.Print(label)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// writeInitFile writes content to path, creating its directory. A file
+// overwritten under --force gets perm too, before the content goes in, as
+// os.WriteFile keeps the mode of an existing file.
+func writeInitFile(path, content string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.Chmod(path, perm); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -7,25 +7,104 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/notexe/cli-chat/internal/agent"
 	"github.com/notexe/cli-chat/internal/api"
 	"github.com/notexe/cli-chat/internal/chat"
 	"github.com/notexe/cli-chat/internal/config"
 	"github.com/notexe/cli-chat/internal/mcp"
+	"github.com/notexe/cli-chat/internal/ollama"
 	"github.com/notexe/cli-chat/internal/repl"
 	"github.com/notexe/cli-chat/internal/scheduler"
 )
 
+// stringList is a flag.Value that collects every occurrence of a flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// printPullProgress returns a callback that prints Ollama pull progress,
+// rewriting the current line while a layer downloads.
+func printPullProgress(model string) func(ollama.PullProgress) {
+	lastStatus := ""
+	return func(p ollama.PullProgress) {
+		if p.Total > 0 {
+			fmt.Printf("\rPulling %s: %s %d%%", model, p.Status, p.Completed*100/p.Total)
+			lastStatus = p.Status
+			return
+		}
+		if p.Status != lastStatus {
+			fmt.Printf("\rPulling %s: %s\033[K\n", model, p.Status)
+			lastStatus = p.Status
+		}
+	}
+}
+
+// projectLine is the startup line naming the detected project.
+func projectLine(p *chat.ProjectContext) string {
+	name := p.Name
+	if p.Git.RepoOwner != "" && p.Git.RepoName != "" {
+		name = p.Git.RepoOwner + "/" + p.Git.RepoName
+	}
+
+	var details []string
+	if p.Git.Branch != "" {
+		details = append(details, "branch: "+p.Git.Branch)
+	}
+	if len(p.Languages) > 0 {
+		details = append(details, strings.Join(p.Languages, ", "))
+	}
+	if len(details) == 0 {
+		return "Project: " + name
+	}
+	return fmt.Sprintf("Project: %s (%s)", name, strings.Join(details, "; "))
+}
+
 func main() {
-	configPath := flag.String("config", config.GetDefaultConfigPath(), "Path to configuration file")
+	var configPaths stringList
+	flag.Var(&configPaths, "config", "Config file layered over /etc/cli-chat and ~/.cli-chat configs (repeatable)")
 	provider := flag.String("provider", "", "Provider to use (deepseek, ollama)")
+	profile := flag.String("profile", "", "Config profile to use (overrides CLI_CHAT_PROFILE)")
 	modelName := flag.String("model", "", "Model name (overrides config)")
 	systemPrompt := flag.String("system-prompt", "", "System prompt (overrides config)")
 	noColor := flag.Bool("no-color", false, "Disable colored output")
+	defaults := agent.DefaultLimits()
+	auto := flag.Bool("auto", false, "Run the task given as arguments autonomously until the model signals completion")
+	autoIterations := flag.Int("auto-max-iterations", defaults.MaxIterations, "Autonomous mode: maximum model requests")
+	autoTokens := flag.Int("auto-token-budget", defaults.TokenBudget, "Autonomous mode: maximum total tokens")
+	autoTimeout := flag.Duration("auto-timeout", defaults.Timeout, "Autonomous mode: maximum run time")
+	initConfig := flag.Bool("init", false, "Write a commented config.yaml and an example mcp.json, then exit")
+	force := flag.Bool("force", false, "With --init: overwrite existing files")
 	flag.Parse()
 
-	cfg, err := config.Load(*configPath)
+	if *initConfig {
+		// Write to the last --config path if given, else the user config
+		path := config.GetDefaultConfigPath()
+		if len(configPaths) > 0 {
+			path = configPaths[len(configPaths)-1]
+		}
+		if err := runInit(path, *force); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	autoTask := strings.TrimSpace(strings.Join(flag.Args(), " "))
+	if *auto && autoTask == "" {
+		fmt.Fprintln(os.Stderr, "Usage: chat --auto [flags] \"task description\"")
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadProfile(*profile, config.ConfigPaths(configPaths)...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
 		os.Exit(1)
@@ -47,11 +126,18 @@ func main() {
 
 	if err := cfg.Validate(); err != nil {
 		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
-		if cfg.Provider == config.ProviderDeepSeek {
+		var verr *config.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			fmt.Fprintf(os.Stderr, "Tip: See config.example.yaml and mcp.example.json for every option, or run chat --init\n")
+		case cfg.Provider == config.ProviderDeepSeek && cfg.DeepSeek.APIKey == "":
 			fmt.Fprintf(os.Stderr, "Tip: Set DEEPSEEK_API_KEY environment variable or add it to config file\n")
 		}
 		os.Exit(1)
 	}
+	for _, issue := range cfg.MCPWarnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", issue)
+	}
 
 	providerInstance, err := api.NewProvider(cfg.GetProviderConfig())
 	if err != nil {
@@ -60,15 +146,25 @@ func main() {
 	}
 	defer providerInstance.Close()
 
+	if ollama, ok := providerInstance.(*api.OllamaProvider); ok && cfg.Ollama.AutoPull {
+		if err := ollama.EnsureModel(context.Background(), cfg.Model.Name, printPullProgress(cfg.Model.Name)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to pull model %s: %v\n", cfg.Model.Name, err)
+		}
+	}
+
 	session := chat.NewSessionWithContext(&cfg.Model, cfg.Session.MaxHistory, &cfg.Context)
+	session.SetNativeJSON(api.SupportsJSONMode(providerInstance))
+	session.SetBudget(chat.Budget{
+		MaxCostUSD:     cfg.Session.MaxCostUSD,
+		MaxTokensTotal: cfg.Session.MaxTokensTotal,
+	})
 
-	// Auto-detect git/project context
-	if gitCtx := chat.DetectGitContext(); gitCtx.IsRepo {
-		if prompt := chat.BuildGitContextPrompt(gitCtx); prompt != "" {
-			session.SetProjectPrompt(prompt)
-			if gitCtx.RepoOwner != "" && gitCtx.RepoName != "" {
-				fmt.Printf("Project: %s/%s (branch: %s)\n", gitCtx.RepoOwner, gitCtx.RepoName, gitCtx.Branch)
-			}
+	// Auto-detect the project in the working directory
+	if cfg.Context.ProjectContext {
+		if wd, err := os.Getwd(); err == nil {
+			project := chat.DetectProjectContext(wd)
+			session.SetProjectPrompt(chat.BuildProjectContextPrompt(project))
+			fmt.Println(projectLine(project))
 		}
 	}
 
@@ -84,6 +180,13 @@ func main() {
 		}
 	}
 
+	// Load checkpoints saved next to the history file
+	if path := chat.CheckpointFile(cfg.Session.HistoryFile); path != "" {
+		if err := session.LoadCheckpoints(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to load checkpoints: %v\n", err)
+		}
+	}
+
 	replInstance, err := repl.NewREPL(session, providerInstance, cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating REPL: %v\n", err)
@@ -94,15 +197,24 @@ func main() {
 	var mcpManager *mcp.Manager
 	if cfg.MCP.Enabled && len(cfg.MCP.Servers) > 0 {
 		mcpManager = mcp.NewManager()
+		mcpManager.SetCallTimeout(time.Duration(cfg.MCP.CallTimeout) * time.Second)
+		if err := mcpManager.SetAllowedRoots(cfg.MCP.AllowedRoots); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid mcp.allowed_roots: %v\n", err)
+			os.Exit(1)
+		}
 		initCtx, initCancel := context.WithTimeout(context.Background(), 60*1e9) // 60 seconds
 
 		for _, srv := range cfg.MCP.Servers {
 			fmt.Printf("Connecting to MCP server: %s...\n", srv.Name)
 			err := mcpManager.AddServer(initCtx, mcp.ServerConfig{
-				Name:    srv.Name,
-				Command: srv.Command,
-				Args:    srv.Args,
-				Env:     srv.Env,
+				Name:          srv.Name,
+				Command:       srv.Command,
+				Args:          srv.Args,
+				Env:           srv.Env,
+				URL:           srv.URL,
+				Headers:       srv.Headers,
+				DisabledTools: srv.DisabledTools,
+				CallTimeout:   time.Duration(srv.CallTimeout) * time.Second,
 			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: Failed to connect to MCP server %s: %v\n", srv.Name, err)
@@ -113,9 +225,26 @@ func main() {
 		}
 		initCancel()
 
-		if len(mcpManager.ListServers()) > 0 {
-			replInstance.SetMCPManager(mcpManager)
+		// Set even if no server connected, so /mcp reload can add them
+		replInstance.SetMCPManager(mcpManager)
+	}
+
+	if *auto {
+		autoCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := runAuto(autoCtx, providerInstance, mcpManager, cfg, autoTask, agent.Limits{
+			MaxIterations: *autoIterations,
+			TokenBudget:   *autoTokens,
+			Timeout:       *autoTimeout,
+		})
+		stop()
+		if mcpManager != nil {
+			mcpManager.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		return
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -137,7 +266,14 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
+		// Ctrl+C aborts the request in flight; at the idle prompt, or on
+		// SIGTERM, the session is saved and the program exits.
+		for sig := range sigChan {
+			if sig == os.Interrupt && replInstance.Interrupt() {
+				continue
+			}
+			break
+		}
 		fmt.Println("\nInterrupted. Saving session...")
 		cancel()
 
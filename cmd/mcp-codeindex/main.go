@@ -1,7 +1,8 @@
 // Command mcp-codeindex provides an MCP server for code indexing and search.
 //
 // This server provides tools for indexing code repositories using local Ollama
-// embeddings and searching through the indexed code semantically.
+// or hosted OpenAI-compatible embeddings and searching through the indexed
+// code semantically.
 //
 // Usage:
 //
@@ -10,8 +11,15 @@
 //
 // Environment:
 //
+//	EMBEDDING_PROVIDER ollama or openai (default: ollama)
+//	EMBEDDING_MODEL    Embedding model name (default: nomic-embed-text for
+//	                   Ollama, text-embedding-3-small for openai)
+//	EMBEDDING_URL      OpenAI-compatible API URL (default: https://api.openai.com/v1)
+//	EMBEDDING_API_KEY  API key for openai (falls back to OPENAI_API_KEY)
 //	OLLAMA_URL         Ollama API URL (default: http://localhost:11434)
-//	OLLAMA_MODEL       Embedding model name (default: nomic-embed-text)
+//	OLLAMA_MODEL       Ollama embedding model, used when EMBEDDING_MODEL is unset
+//	OLLAMA_AUTO_PULL   Pull the model on first use if it is missing (default: false)
+//	OLLAMA_TIMEOUT     Timeout of each Ollama request, e.g. 60s or 5m (default: 2m)
 //
 // Index storage:
 //
@@ -28,6 +36,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/notexe/cli-chat/internal/codeindex"
@@ -47,16 +57,48 @@ func main() {
 		ollamaURL = "http://localhost:11434"
 	}
 
-	ollamaModel := os.Getenv("OLLAMA_MODEL")
-	if ollamaModel == "" {
-		ollamaModel = "nomic-embed-text"
+	provider := os.Getenv("EMBEDDING_PROVIDER")
+	if provider == "" {
+		provider = codeindex.EmbeddingProviderOllama
+	}
+
+	// Empty model names get the provider's default
+	model := os.Getenv("EMBEDDING_MODEL")
+	if model == "" && provider == codeindex.EmbeddingProviderOllama {
+		model = os.Getenv("OLLAMA_MODEL")
+		if model == "" {
+			model = "nomic-embed-text"
+		}
+	}
+
+	apiKey := os.Getenv("EMBEDDING_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+
+	autoPull, _ := strconv.ParseBool(os.Getenv("OLLAMA_AUTO_PULL"))
+
+	var ollamaTimeout time.Duration
+	if v := os.Getenv("OLLAMA_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid OLLAMA_TIMEOUT %q: use a positive duration such as 60s or 5m\n", v)
+			os.Exit(1)
+		}
+		ollamaTimeout = d
 	}
 
 	// Create indexer
 	indexer, err := codeindex.NewIndexer(codeindex.IndexerConfig{
-		OllamaURL:   ollamaURL,
-		ModelName:   ollamaModel,
-		ChunkConfig: codeindex.DefaultChunkConfig(),
+		EmbeddingProvider: provider,
+		OllamaURL:         ollamaURL,
+		ModelName:         model,
+		EmbeddingURL:      os.Getenv("EMBEDDING_URL"),
+		EmbeddingAPIKey:   apiKey,
+		ChunkConfig:       codeindex.DefaultChunkConfig(),
+		OllamaTimeout:     ollamaTimeout,
+		AutoPull:          autoPull,
+		OnPull:            pullProgressPrinter(model),
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create indexer: %v\n", err)
@@ -73,12 +115,36 @@ func main() {
 	}
 }
 
+// pullProgressPrinter reports model pull progress on stderr (stdout carries
+// the MCP protocol). Download progress is printed in 10% steps.
+func pullProgressPrinter(model string) func(codeindex.PullProgress) {
+	lastStatus := ""
+	lastPercent := -1
+
+	return func(p codeindex.PullProgress) {
+		if p.Total > 0 {
+			percent := int(p.Completed * 100 / p.Total / 10 * 10)
+			if p.Status == lastStatus && percent == lastPercent {
+				return
+			}
+			lastStatus, lastPercent = p.Status, percent
+			fmt.Fprintf(os.Stderr, "Pulling %s: %s %d%%\n", model, p.Status, percent)
+			return
+		}
+
+		if p.Status != lastStatus {
+			lastStatus, lastPercent = p.Status, -1
+			fmt.Fprintf(os.Stderr, "Pulling %s: %s\n", model, p.Status)
+		}
+	}
+}
+
 func printHelp() {
 	fmt.Println(`MCP Code Index Server - Semantic code search via MCP protocol
 
 DESCRIPTION:
-    Index code repositories using local Ollama embeddings and search through
-    them semantically. The indexer splits code into chunks, generates embeddings,
+    Index code repositories using local Ollama embeddings (or a hosted
+    OpenAI-compatible embeddings API) and search through them semantically. The indexer splits code into chunks, generates embeddings,
     and stores them in a JSON index for fast similarity search.
 
     Each project stores its index in PROJECT_ROOT/.codeindex/index.json
@@ -89,15 +155,40 @@ USAGE:
     mcp-codeindex --help   Show this help
 
 ENVIRONMENT:
+    EMBEDDING_PROVIDER  Embedding backend: ollama or openai
+                     Default: ollama
+                     openai works with any OpenAI-compatible /embeddings
+                     endpoint (OpenAI, Cohere's compatibility API, ...)
+
+    EMBEDDING_MODEL  Embedding model to use
+                     Default: nomic-embed-text (ollama),
+                              text-embedding-3-small (openai)
+
+    EMBEDDING_URL    Base URL of the OpenAI-compatible API
+                     Default: https://api.openai.com/v1
+
+    EMBEDDING_API_KEY
+                     API key for openai (falls back to OPENAI_API_KEY)
+
     OLLAMA_URL       Ollama API endpoint
                      Default: http://localhost:11434
 
-    OLLAMA_MODEL     Embedding model to use
+    OLLAMA_MODEL     Ollama embedding model, used when EMBEDDING_MODEL is unset
                      Default: nomic-embed-text
                      Other options: all-minilm, mxbai-embed-large
 
+    OLLAMA_AUTO_PULL Pull the model on first use if it is not installed
+                     (downloads can be large; progress goes to stderr)
+                     Default: false
+
+    OLLAMA_TIMEOUT   Timeout of each Ollama request (e.g. 60s, 5m).
+                     Raise it if large models time out while loading
+                     Default: 2m
+
 INDEX STORAGE:
     Index is stored in .codeindex/index.json inside the indexed directory.
+    It records the embedding provider and model; searching with a different
+    one fails until the directory is indexed again.
     When searching, the server looks for .codeindex/ starting from current
     directory and going up (similar to how git finds .git/).
 
@@ -108,7 +199,7 @@ PREREQUISITES:
     1. Install Ollama:
        Visit https://ollama.ai and follow installation instructions
 
-    2. Pull an embedding model:
+    2. Pull an embedding model (or set OLLAMA_AUTO_PULL=true):
        ollama pull nomic-embed-text
 
     3. Start Ollama (if not running):
@@ -121,14 +212,21 @@ TOOLS:
 
     semantic_search  Search indexed code by semantic similarity.
                      Automatically finds .codeindex/ from current directory.
-                     Parameters: query (required), top_k (optional, default: 3)
+                     Parameters: query (required), top_k (optional, default: 3),
+                     index_paths (optional, several indexes merged),
+                     path_glob (optional, e.g. "*_test.go", "internal/api/"),
+                     language (optional, e.g. "go", "ts", ".tsx")
 
     index_stats      Get statistics about the current index
                      (number of chunks, files, model used, index path)
 
-    check_health     Verify Ollama connectivity and model availability
+    check_health     Verify embedding provider connectivity and model availability
+
+    reload_index     Reload the index from disk, dropping cached indexes
 
-    reload_index     Reload the index from disk
+    compact_index    Remove chunks of deleted files and duplicate chunks,
+                     then rewrite the index. No embeddings are generated.
+                     Parameters: index_path (optional, default: auto-detect)
 
 SUPPORTED FILE TYPES:
     .go, .js, .ts, .jsx, .tsx, .py, .java, .c, .cpp, .h, .hpp, .rs,
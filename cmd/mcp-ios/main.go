@@ -15,6 +15,10 @@
 // Add it to your MCP client configuration in ~/.cli-chat/mcp.json
 //
 // For UI automation tools, WebDriverAgent is auto-started if Appium is installed.
+//
+// Environment:
+//
+//	IOS_TARGET_APP  Bundle ID to auto-launch before UI actions (opt-in)
 package main
 
 import (
@@ -43,6 +47,9 @@ func main() {
 
 	// Start MCP server
 	s := ios.NewServer()
+	if bundleID := os.Getenv("IOS_TARGET_APP"); bundleID != "" {
+		s.SetTargetApp(bundleID, 0)
+	}
 
 	if err := server.ServeStdio(s.MCPServer()); err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
@@ -81,10 +88,17 @@ CONFIGURATION:
       }
     }
 
+ENVIRONMENT:
+    IOS_TARGET_APP   Bundle ID to launch before UI actions when it is not
+                     in the foreground (off by default, see set_target_app)
+
 TOOLS:
-    Simulator: list_simulators, boot_simulator, screenshot, record_video_*
-    Apps:      build_app, install_app, launch_app, terminate_app
-    UI:        get_ui_tree, get_elements_with_coords, tap, swipe, input_text
+    Simulator: list_simulators, boot_simulator, screenshot, screenshot_base64, record_video_*,
+               set_appearance, set_permission, list_device_types, create_simulator, delete_simulator
+    Apps:      build_app, install_app, launch_app, terminate_app, get_app_logs
+    UI:        set_target_app, get_device_state, get_ui_tree, get_elements_with_coords, get_screen_text, tap, swipe, input_text,
+               perform_actions, find_elements, get_element_text, wait_for_element,
+               wait_for_element_gone, set_pasteboard, paste
 
 For more info see: cmd/mcp-ios/README.md`)
 }
@@ -10,13 +10,24 @@
 //
 // Environment:
 //
-//	REMINDER_DB_PATH  Path to SQLite database (default: ~/.cli-chat/reminders.db)
+//	REMINDER_DB_PATH   Path to SQLite database (default: ~/.cli-chat/reminders.db)
+//	REMINDER_TIMEZONE  IANA timezone for relative due dates (default: TZ or system local)
+//
+// When TELEGRAM_BOT_TOKEN and a chat ID are set, due reminders are also
+// pushed to Telegram by a background notifier:
+//
+//	TELEGRAM_BOT_TOKEN         Bot token from @BotFather
+//	REMINDER_TELEGRAM_CHAT_ID  Chat to notify (default: TELEGRAM_CHAT_ID)
+//	REMINDER_NOTIFY_INTERVAL   Poll interval, e.g. 30s or 5m (default: 1m)
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/notexe/cli-chat/internal/reminder"
@@ -53,7 +64,27 @@ func main() {
 	}
 	defer store.Close()
 
-	s := reminder.NewServer(store)
+	loc := time.Local
+	if tz := os.Getenv("REMINDER_TIMEZONE"); tz != "" {
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid REMINDER_TIMEZONE %q: %v\n", tz, err)
+			os.Exit(1)
+		}
+	}
+
+	s := reminder.NewServer(store, loc)
+
+	notifier, err := newNotifier(store, loc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if notifier != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go notifier.Run(ctx)
+	}
 
 	if err := server.ServeStdio(s.MCPServer()); err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
@@ -61,6 +92,32 @@ func main() {
 	}
 }
 
+// newNotifier builds the Telegram notifier from the environment. It returns
+// nil when no bot token or chat ID is configured.
+func newNotifier(store *reminder.Store, loc *time.Location) (*reminder.Notifier, error) {
+	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
+	chatID := os.Getenv("REMINDER_TELEGRAM_CHAT_ID")
+	if chatID == "" {
+		chatID = os.Getenv("TELEGRAM_CHAT_ID")
+	}
+	if botToken == "" || chatID == "" {
+		return nil, nil
+	}
+
+	interval := reminder.DefaultNotifyInterval
+	if v := os.Getenv("REMINDER_NOTIFY_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid REMINDER_NOTIFY_INTERVAL %q (use e.g. 30s, 5m; minimum 1s)", v)
+		}
+		interval = d
+	}
+
+	// stdout carries the MCP protocol, so log to stderr
+	logger := log.New(os.Stderr, "mcp-reminder: ", log.LstdFlags)
+	return reminder.NewNotifier(store, botToken, chatID, interval, loc, logger), nil
+}
+
 func printHelp() {
 	fmt.Println(`MCP Reminder Server - Reminder management via MCP protocol
 
@@ -71,14 +128,34 @@ USAGE:
 ENVIRONMENT:
     REMINDER_DB_PATH  Path to SQLite database file
                       Default: ~/.cli-chat/reminders.db
+    REMINDER_TIMEZONE IANA timezone used to resolve relative due dates
+                      such as "tomorrow 9am" (e.g. Europe/Moscow)
+                      Default: TZ or the system timezone
+
+TELEGRAM NOTIFICATIONS (optional):
+    When a bot token and chat ID are set, a background notifier sends
+    a Telegram message once for each reminder that becomes due.
+
+    TELEGRAM_BOT_TOKEN        Bot token from @BotFather
+    REMINDER_TELEGRAM_CHAT_ID Chat to notify
+                              Default: TELEGRAM_CHAT_ID
+    REMINDER_NOTIFY_INTERVAL  How often to check for due reminders
+                              Default: 1m
 
 TOOLS:
-    add_reminder       Add a new reminder (title, due_date, description, priority)
-    list_reminders     List all reminders (optional status filter)
+    add_reminder       Add a new reminder (title, due_date, description, priority, recurrence, tags)
+    list_reminders     List all reminders (optional status and tag filters)
+    search_reminders   Search titles and descriptions (case-insensitive)
     get_due_reminders  Get pending reminders that are due or overdue
-    complete_reminder  Mark a reminder as completed
+    get_upcoming_reminders
+                       Get pending reminders due within a horizon (default 24h)
+    complete_reminder  Mark a reminder as completed (recurring ones are rescheduled)
+    complete_reminders Complete several reminders at once (ids array)
+    snooze_reminder    Push a reminder out by a duration (30m, 1h, 1d)
     delete_reminder    Delete a reminder permanently
-    update_reminder    Update reminder fields (title, description, due_date, priority)
+    delete_reminders   Delete several reminders at once (ids array)
+    delete_completed   Delete all completed reminders
+    update_reminder    Update reminder fields (title, description, due_date, priority, recurrence, tags)
 
 CONFIGURATION:
     Add to ~/.cli-chat/mcp.json:
@@ -45,16 +45,24 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("ENVIRONMENT VARIABLES:")
 	fmt.Println("  TELEGRAM_BOT_TOKEN  (required)  Telegram bot token from @BotFather")
-	fmt.Println("  TELEGRAM_CHAT_ID    (required)  Chat ID to send messages to")
+	fmt.Println("  TELEGRAM_CHAT_ID    (optional)  Default chat ID; tools accept a chat_id")
+	fmt.Println("                                  parameter to target other chats")
 	fmt.Println()
 	fmt.Println("TOOLS:")
 	fmt.Println("  send_message              Send a text message")
 	fmt.Println("  send_message_with_keyboard Send a message with inline keyboard buttons")
+	fmt.Println("  edit_message_reply_markup Replace or remove a message's inline keyboard")
+	fmt.Println("  answer_callback_query     Answer an inline keyboard button press")
 	fmt.Println("  send_photo                Send a photo")
+	fmt.Println("  send_document             Send a file as a document (up to 50 MB)")
+	fmt.Println("  send_audio                Send an audio file (up to 50 MB)")
 	fmt.Println("  get_chat                  Get chat information")
 	fmt.Println("  edit_message              Edit a previously sent message")
 	fmt.Println("  delete_message            Delete a message")
 	fmt.Println("  get_me                    Get bot information")
+	fmt.Println("  get_updates               Get new messages and button presses")
+	fmt.Println("  send_and_wait_reply       Send a message and wait for a reply")
+	fmt.Println("  listen_for_messages       Collect all user messages for a while")
 	fmt.Println()
 	fmt.Println("EXAMPLES:")
 	fmt.Println("  # Set environment variables")
@@ -110,7 +118,7 @@ func checkEnvironment() {
 
 	// Check chat ID
 	if chatID == "" {
-		fmt.Println("❌ TELEGRAM_CHAT_ID: NOT SET")
+		fmt.Println("⚠ TELEGRAM_CHAT_ID: NOT SET (every call must pass chat_id)")
 		fmt.Println("   Set with: export TELEGRAM_CHAT_ID=\"your-chat-id\"")
 	} else {
 		fmt.Printf("✓ TELEGRAM_CHAT_ID: %s\n", chatID)
@@ -118,7 +126,7 @@ func checkEnvironment() {
 
 	fmt.Println()
 
-	if botToken == "" || chatID == "" {
+	if botToken == "" {
 		fmt.Println("Configuration incomplete. Please set the required environment variables.")
 		os.Exit(1)
 	}
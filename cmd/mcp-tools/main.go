@@ -1,9 +1,11 @@
 // Command mcp-tools demonstrates MCP client functionality.
-// It connects to an MCP server and lists all available tools.
+// It connects to an MCP server and lists all available tools, or calls one
+// tool for quick smoke tests.
 //
 // Usage:
 //
 //	./mcp-tools <server-command> [args...]
+//	./mcp-tools call [--timeout 60s] <tool> <json-args> <server-command> [args...]
 //
 // Example with GitHub MCP:
 //
@@ -11,7 +13,10 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -19,12 +24,26 @@ import (
 	"github.com/notexe/cli-chat/internal/mcp"
 )
 
+// Timeouts for the call subcommand.
+const (
+	connectTimeout     = 30 * time.Second
+	defaultCallTimeout = 60 * time.Second
+)
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
+	if os.Args[1] == "call" {
+		if err := runCall(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	command := os.Args[1]
 	args := os.Args[2:]
 
@@ -79,13 +98,89 @@ func main() {
 	fmt.Println("Done!")
 }
 
+// runCall connects to a server, calls one tool and prints its result. A
+// result the tool marks as an error goes to stderr and fails the call.
+// args are: [--timeout <duration>] <tool> <json-args> <server-command> [args...]
+func runCall(args []string) error {
+	callTimeout := defaultCallTimeout
+	if len(args) >= 2 && args[0] == "--timeout" {
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid --timeout %q (e.g. 30s, 2m)", args[1])
+		}
+		callTimeout = d
+		args = args[2:]
+	}
+
+	if len(args) < 3 {
+		printUsage()
+		return fmt.Errorf("call requires <tool> <json-args> <server-command>")
+	}
+
+	toolName, argsJSON, command, serverArgs := args[0], args[1], args[2], args[3:]
+
+	var toolArgs map[string]interface{}
+	if err := json.Unmarshal([]byte(argsJSON), &toolArgs); err != nil {
+		return fmt.Errorf("invalid JSON arguments (expected an object like '{\"key\": \"value\"}'): %w", err)
+	}
+
+	client, err := mcp.NewClient(command, serverArgs...)
+	if err != nil {
+		return fmt.Errorf("creating MCP client: %w", err)
+	}
+	defer client.Close()
+
+	connectCtx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	if err := client.Connect(connectCtx); err != nil {
+		if errors.Is(connectCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %v connecting to %s", connectTimeout, command)
+		}
+		return fmt.Errorf("connecting to MCP server: %w", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := client.CallTool(callCtx, toolName, toolArgs)
+	if err != nil {
+		var toolErr *mcp.ToolError
+		if errors.As(err, &toolErr) {
+			fmt.Fprintln(os.Stderr, prettyJSON(toolErr.Output))
+			return err
+		}
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("tool %s did not finish within %v (use --timeout)", toolName, callTimeout)
+		}
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s finished in %v\n", toolName, time.Since(start).Round(time.Millisecond))
+	fmt.Println(prettyJSON(result))
+	return nil
+}
+
+// prettyJSON indents s if it is a JSON document and returns it unchanged
+// otherwise.
+func prettyJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
 func printUsage() {
 	fmt.Println("MCP Tools Lister")
 	fmt.Println("================")
 	fmt.Println()
-	fmt.Println("Lists all tools available from an MCP server.")
+	fmt.Println("Lists all tools available from an MCP server, or calls one tool.")
 	fmt.Println()
-	fmt.Println("Usage: mcp-tools <server-command> [args...]")
+	fmt.Println("Usage:")
+	fmt.Println("  mcp-tools <server-command> [args...]")
+	fmt.Println("  mcp-tools call [--timeout 60s] <tool> <json-args> <server-command> [args...]")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println()
@@ -98,6 +193,9 @@ func printUsage() {
 	fmt.Println("  # Brave Search MCP Server")
 	fmt.Println("  BRAVE_API_KEY=xxx ./mcp-tools npx -y @modelcontextprotocol/server-brave-search")
 	fmt.Println()
+	fmt.Println("  # Call a tool and print its result")
+	fmt.Println("  ./mcp-tools call list_reminders '{\"status\": \"pending\"}' ./mcp-reminder")
+	fmt.Println()
 	fmt.Println("Available MCP servers: https://github.com/modelcontextprotocol/servers")
 }
 
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/chat"
+	"github.com/notexe/cli-chat/internal/mcp"
+)
+
+// defaultBatchTokens is the estimated diff size reviewed in one agent run.
+const defaultBatchTokens = 20000
+
+// maxSharedContext caps the earlier search results repeated in each batch.
+const maxSharedContext = 16000
+
+// maxSharedResult caps a single search result repeated in later batches.
+const maxSharedResult = 2000
+
+// fileDiff is the part of a unified diff that touches one file.
+type fileDiff struct {
+	Path string
+	Diff string
+}
+
+// splitDiffByFile splits a git diff at its "diff --git" headers. Text
+// before the first header is dropped.
+func splitDiffByFile(diff string) []fileDiff {
+	var files []fileDiff
+	var current *fileDiff
+	var sb strings.Builder
+
+	flush := func() {
+		if current != nil {
+			current.Diff = sb.String()
+			files = append(files, *current)
+		}
+		sb.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			current = &fileDiff{Path: diffPath(line)}
+		}
+		if current != nil {
+			sb.WriteString(line)
+		}
+	}
+	flush()
+
+	return files
+}
+
+// diffPath extracts the new path from a "diff --git a/x b/x" header.
+func diffPath(header string) string {
+	header = strings.TrimSpace(strings.TrimPrefix(header, "diff --git "))
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+3:]
+	}
+	return header
+}
+
+// batchFileDiffs groups files in diff order so each batch stays under
+// budget estimated tokens. A file larger than the budget gets a batch of
+// its own.
+func batchFileDiffs(files []fileDiff, budget int) [][]fileDiff {
+	var batches [][]fileDiff
+	var batch []fileDiff
+	size := 0
+
+	for _, f := range files {
+		tokens := chat.EstimateTokens(f.Diff)
+		if len(batch) > 0 && size+tokens > budget {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, f)
+		size += tokens
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// toolCache shares tool results between batches, so the project context
+// found while reviewing one batch is neither searched for again nor lost.
+type toolCache struct {
+	results map[string]string // tool name + arguments -> result
+	order   []string
+}
+
+func newToolCache() *toolCache {
+	return &toolCache{results: make(map[string]string)}
+}
+
+func (c *toolCache) get(name, args string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	result, ok := c.results[name+" "+args]
+	return result, ok
+}
+
+func (c *toolCache) put(name, args, result string) {
+	if c == nil {
+		return
+	}
+	key := name + " " + args
+	if _, ok := c.results[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.results[key] = result
+}
+
+// summary renders earlier search results for the next batch's prompt.
+func (c *toolCache) summary() string {
+	var sb strings.Builder
+	for _, key := range c.order {
+		if !strings.HasPrefix(key, "semantic_search ") {
+			continue
+		}
+		entry := fmt.Sprintf("### %s\n%s\n\n", key, truncate(c.results[key], maxSharedResult))
+		if sb.Len()+len(entry) > maxSharedContext {
+			break
+		}
+		sb.WriteString(entry)
+	}
+	return sb.String()
+}
+
+// buildBatchMessage builds the user message for one batch of a split PR.
+func buildBatchMessage(title, body string, batch []fileDiff, index, total int, shared string) string {
+	var diff strings.Builder
+	paths := make([]string, len(batch))
+	for i, f := range batch {
+		paths[i] = f.Path
+		diff.WriteString(f.Diff)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(buildUserMessage(title, body, diff.String()))
+	fmt.Fprintf(&sb, "\n\n## Scope\nThis PR is too large for one review and is split into %d parts. "+
+		"This is part %d; review only these files: %s.\n", total, index, strings.Join(paths, ", "))
+
+	if shared != "" {
+		sb.WriteString("\n## Project context from earlier searches\n")
+		sb.WriteString("These semantic_search results were gathered while reviewing other parts. " +
+			"Reuse them instead of repeating the same searches.\n\n")
+		sb.WriteString(shared)
+	}
+
+	return sb.String()
+}
+
+// reviewInBatches runs the agent once per batch and merges the structured
+// results. Responses that are not valid JSON are kept as suggestions so no
+// finding is lost, and their part numbers are returned: those parts have
+// no severities, so the merged review can't gate a merge.
+func reviewInBatches(
+	ctx context.Context,
+	provider api.Provider,
+	mcpManager *mcp.Manager,
+	audit *chat.ToolAuditLogger,
+	system string,
+	model string,
+	maxTokens int,
+	temperature float64,
+	timeout time.Duration,
+	title, body string,
+	batches [][]fileDiff,
+) (*chat.ReviewResponse, []int) {
+	cache := newToolCache()
+	var reviews []*chat.ReviewResponse
+	var invalid []int
+
+	for i, batch := range batches {
+		log("Reviewing part %d/%d (%d files)", i+1, len(batches), len(batch))
+
+		message := buildBatchMessage(title, body, batch, i+1, len(batches), cache.summary())
+		review := runAgentLoop(ctx, provider, mcpManager, audit, cache, system, model, maxTokens, temperature, timeout, message)
+
+		if strings.HasPrefix(review, "ERROR:") {
+			fatal("%s", strings.TrimSpace(review))
+		}
+
+		parsed, err := chat.ParseReviewResponse(review)
+		if err != nil {
+			log("Warning: part %d is not valid JSON (%v), keeping raw text", i+1, err)
+			parsed = &chat.ReviewResponse{Suggestions: []string{strings.TrimSpace(review)}}
+			invalid = append(invalid, i+1)
+		}
+		reviews = append(reviews, parsed)
+	}
+
+	return mergeReviews(reviews), invalid
+}
+
+// invalidPartsError describes the batches whose response is not valid JSON.
+func invalidPartsError(parts []int) string {
+	numbers := make([]string, len(parts))
+	for i, n := range parts {
+		numbers[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("part(s) %s of the review are not valid JSON", strings.Join(numbers, ", "))
+}
+
+// mergeReviews combines batch reviews. Issues with the same message (such
+// as a project convention broken in several files) are reported once,
+// listing the other files; repeated suggestions are dropped.
+func mergeReviews(reviews []*chat.ReviewResponse) *chat.ReviewResponse {
+	merged := &chat.ReviewResponse{
+		Issues:      []chat.ReviewIssue{},
+		Suggestions: []string{},
+	}
+
+	var summaries []string
+	issueIndex := make(map[string]int) // normalized message -> index in merged.Issues
+	alsoIn := make(map[int][]string)   // index in merged.Issues -> other files
+	seenSuggestions := make(map[string]bool)
+
+	for _, r := range reviews {
+		if r.Summary != "" {
+			summaries = append(summaries, r.Summary)
+		}
+
+		for _, issue := range r.Issues {
+			key := normalizeFinding(issue.Message)
+			if i, ok := issueIndex[key]; ok {
+				if issue.File != "" && issue.File != merged.Issues[i].File {
+					alsoIn[i] = append(alsoIn[i], issue.File)
+				}
+				if chat.SeverityRank(issue.Severity) > chat.SeverityRank(merged.Issues[i].Severity) {
+					merged.Issues[i].Severity = issue.Severity
+				}
+				continue
+			}
+			issueIndex[key] = len(merged.Issues)
+			merged.Issues = append(merged.Issues, issue)
+		}
+
+		for _, s := range r.Suggestions {
+			key := normalizeFinding(s)
+			if seenSuggestions[key] {
+				continue
+			}
+			seenSuggestions[key] = true
+			merged.Suggestions = append(merged.Suggestions, s)
+		}
+	}
+
+	for i, files := range alsoIn {
+		merged.Issues[i].Message += fmt.Sprintf(" (also in %s)", strings.Join(files, ", "))
+	}
+
+	merged.Summary = strings.Join(summaries, "\n\n")
+	if merged.Summary == "" {
+		merged.Summary = fmt.Sprintf("Reviewed in %d parts; no part returned a summary.", len(reviews))
+	}
+	return merged
+}
+
+// normalizeFinding returns a comparison key for a finding's text.
+func normalizeFinding(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// renderReviewMarkdown renders a merged review for the Markdown output.
+func renderReviewMarkdown(review *chat.ReviewResponse) string {
+	var sb strings.Builder
+
+	sb.WriteString("### Summary\n\n")
+	sb.WriteString(review.Summary)
+	sb.WriteString("\n\n### Issues\n\n")
+	if len(review.Issues) == 0 {
+		sb.WriteString("No issues found.\n")
+	}
+	for _, issue := range review.Issues {
+		location := issue.File
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+		}
+		fmt.Fprintf(&sb, "- **[%s]** `%s` — %s\n", issue.Severity, location, issue.Message)
+	}
+
+	if len(review.Suggestions) > 0 {
+		sb.WriteString("\n### Suggestions\n\n")
+		for _, s := range review.Suggestions {
+			fmt.Fprintf(&sb, "- %s\n", s)
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
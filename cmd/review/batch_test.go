@@ -0,0 +1,179 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/notexe/cli-chat/internal/chat"
+)
+
+func TestSplitDiffByFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		diff      string
+		wantPaths []string
+	}{
+		{
+			name:      "two files",
+			diff:      testDiff,
+			wantPaths: []string{"main.go", "docs/new file.md"},
+		},
+		{
+			name:      "text before the first header is dropped",
+			diff:      "From 1234 Mon Sep 17 00:00:00 2001\nSubject: fix\n\n" + testDiff,
+			wantPaths: []string{"main.go", "docs/new file.md"},
+		},
+		{
+			name:      "rename uses the new path",
+			diff:      "diff --git a/old.go b/new.go\nsimilarity index 100%\nrename from old.go\nrename to new.go\n",
+			wantPaths: []string{"new.go"},
+		},
+		{
+			name: "no headers",
+			diff: "not a diff\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := splitDiffByFile(tt.diff)
+
+			var paths []string
+			var joined strings.Builder
+			for _, f := range files {
+				paths = append(paths, f.Path)
+				if !strings.HasPrefix(f.Diff, "diff --git ") {
+					t.Errorf("diff of %s starts with %q, want its header", f.Path, f.Diff[:min(len(f.Diff), 20)])
+				}
+				joined.WriteString(f.Diff)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("paths = %q, want %q", paths, tt.wantPaths)
+			}
+			if len(files) > 0 && !strings.HasSuffix(tt.diff, joined.String()) {
+				t.Errorf("file diffs do not add up to the input")
+			}
+		})
+	}
+}
+
+func TestBatchFileDiffs(t *testing.T) {
+	// file returns a diff of the given estimated tokens.
+	file := func(path string, tokens int) fileDiff {
+		return fileDiff{Path: path, Diff: strings.Repeat("x", tokens*4)}
+	}
+
+	tests := []struct {
+		name   string
+		files  []fileDiff
+		budget int
+		want   [][]string
+	}{
+		{
+			name:   "all in one batch",
+			files:  []fileDiff{file("a", 10), file("b", 10), file("c", 10)},
+			budget: 30,
+			want:   [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:   "split in diff order",
+			files:  []fileDiff{file("a", 10), file("b", 15), file("c", 10), file("d", 5)},
+			budget: 25,
+			want:   [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:   "file over the budget gets its own batch",
+			files:  []fileDiff{file("a", 5), file("big", 100), file("c", 5)},
+			budget: 20,
+			want:   [][]string{{"a"}, {"big"}, {"c"}},
+		},
+		{
+			name:   "no files",
+			budget: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, batch := range batchFileDiffs(tt.files, tt.budget) {
+				var paths []string
+				for _, f := range batch {
+					paths = append(paths, f.Path)
+				}
+				got = append(got, paths)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeReviews(t *testing.T) {
+	tests := []struct {
+		name    string
+		reviews []*chat.ReviewResponse
+		want    *chat.ReviewResponse
+	}{
+		{
+			name: "same issue in several files is reported once at its highest severity",
+			reviews: []*chat.ReviewResponse{
+				{
+					Summary: "Part one.",
+					Issues: []chat.ReviewIssue{
+						{File: "a.go", Line: 3, Severity: "low", Message: "Error is ignored."},
+						{File: "a.go", Line: 9, Severity: "high", Message: "Nil map write."},
+					},
+					Suggestions: []string{"Add tests."},
+				},
+				{
+					Summary: "Part two.",
+					Issues: []chat.ReviewIssue{
+						{File: "b.go", Line: 7, Severity: "major", Message: "  error IS   ignored. "},
+						{File: "c.go", Line: 1, Severity: "low", Message: "error is ignored."},
+					},
+					Suggestions: []string{"add  TESTS.", "Document the flag."},
+				},
+			},
+			want: &chat.ReviewResponse{
+				Summary: "Part one.\n\nPart two.",
+				Issues: []chat.ReviewIssue{
+					{File: "a.go", Line: 3, Severity: "major", Message: "Error is ignored. (also in b.go, c.go)"},
+					{File: "a.go", Line: 9, Severity: "high", Message: "Nil map write."},
+				},
+				Suggestions: []string{"Add tests.", "Document the flag."},
+			},
+		},
+		{
+			name: "repeat in the same file lists no other file",
+			reviews: []*chat.ReviewResponse{
+				{Summary: "One.", Issues: []chat.ReviewIssue{{File: "a.go", Line: 1, Severity: "medium", Message: "Typo."}}},
+				{Issues: []chat.ReviewIssue{{File: "a.go", Line: 5, Severity: "low", Message: "typo."}}},
+			},
+			want: &chat.ReviewResponse{
+				Summary:     "One.",
+				Issues:      []chat.ReviewIssue{{File: "a.go", Line: 1, Severity: "medium", Message: "Typo."}},
+				Suggestions: []string{},
+			},
+		},
+		{
+			name:    "no summaries",
+			reviews: []*chat.ReviewResponse{{}, {}},
+			want: &chat.ReviewResponse{
+				Summary:     "Reviewed in 2 parts; no part returned a summary.",
+				Issues:      []chat.ReviewIssue{},
+				Suggestions: []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeReviews(tt.reviews); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeReviews() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
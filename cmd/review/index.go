@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/mcp"
+)
+
+// ensureIndexes creates the code index of root, and of root/docs if that
+// directory exists, when they are missing. Failures are logged and the
+// review continues without RAG context for that index.
+func ensureIndexes(ctx context.Context, mcpManager *mcp.Manager, root string) {
+	ensureIndex(ctx, mcpManager, root, "code")
+
+	docsDir := filepath.Join(root, "docs")
+	if info, err := os.Stat(docsDir); err == nil && info.IsDir() {
+		ensureIndex(ctx, mcpManager, docsDir, "docs")
+	}
+}
+
+// ensureIndex indexes dir unless dir/.codeindex already exists.
+func ensureIndex(ctx context.Context, mcpManager *mcp.Manager, dir, label string) {
+	if _, err := os.Stat(filepath.Join(dir, ".codeindex")); err == nil {
+		log("Using existing %s index: %s", label, dir)
+		return
+	}
+
+	log("No %s index found, indexing %s (this can take a few minutes)...", label, dir)
+	start := time.Now()
+
+	args, _ := json.Marshal(map[string]interface{}{"path": dir})
+	result, err := mcpManager.CallTool(ctx, "index_directory", string(args))
+	if err != nil {
+		log("Warning: failed to index %s: %v", dir, err)
+		return
+	}
+
+	log("Indexed %s in %s: %s", dir, time.Since(start).Round(time.Second), truncate(result, 200))
+}
+
+// projectRoot returns the git top-level directory, or the working
+// directory outside a git repository.
+func projectRoot() string {
+	if out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		return cwd
+	}
+	return "."
+}
@@ -9,6 +9,37 @@
 //	./review --pr 42
 //	./review --pr 42 --codeindex ./mcp-codeindex --model deepseek-chat
 //	./review --diff-file /tmp/pr.diff   # skip gh, use local diff file
+//	./review --pr 42 --audit-log review-tools.jsonl
+//	./review --pr 42 --format json      # {summary, issues, suggestions} for CI
+//	./review --pr 42 --format json --fail-on high --min-severity medium
+//	./review --pr 42 --post             # post the review as a PR comment
+//	./review --pr 42 --format json --post-inline
+//	./review --pr 42 --no-auto-index    # never create missing indexes
+//	./review --pr 42 --language English
+//	./review --pr 42 --prompt-file review-prompt.md
+//
+// The review is written in Russian unless --language names another
+// language. --prompt-file replaces the whole built-in system prompt; the
+// replacement should keep the index check and semantic_search guidance.
+//
+// Severities are low, medium, high and critical. --min-severity drops
+// issues below it from the output; --fail-on sets the merge gate. Both
+// require --format json.
+//
+// Before the review, the project (--codeindex-path, default: the git root)
+// and its docs/ directory are indexed if they have no .codeindex yet.
+//
+// Nothing is posted to the PR unless --post or --post-inline is given.
+// --post adds the review as a PR comment; --post-inline instead creates a PR
+// review with a line comment for each issue inside the diff.
+//
+// Exit codes:
+//
+//	0  Review completed, no issue at or above --fail-on
+//	1  Error (including a response, or a part of a batched review, that is
+//	   not valid JSON while --fail-on or --post-inline is set, and a failure
+//	   to post)
+//	2  Review completed and found an issue at or above --fail-on
 //
 // Environment:
 //
@@ -19,6 +50,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -29,11 +61,17 @@ import (
 	"time"
 
 	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/chat"
 	"github.com/notexe/cli-chat/internal/config"
 	"github.com/notexe/cli-chat/internal/mcp"
 )
 
-const reviewSystemPrompt = `You are an expert code reviewer. You have access to code index tools (semantic_search, index_stats).
+// reviewSystemPrompt is the default system prompt: reviewPromptIntro,
+// reviewOutputRussian and reviewPromptRules.
+const reviewSystemPrompt = reviewPromptIntro + reviewOutputRussian + reviewPromptRules
+
+// reviewPromptIntro covers the index check and search strategy.
+const reviewPromptIntro = `You are an expert code reviewer. You have access to code index tools (semantic_search, index_stats).
 
 BEFORE ANYTHING ELSE:
 1. Call index_stats to check if a code index exists.
@@ -47,14 +85,31 @@ SEARCH STRATEGY (after confirming index exists):
 3. Make multiple targeted searches based on what you see in the diff — search for function names, module names, patterns you see.
 4. Do NOT search for generic terms. Be specific: use class names, function names, module paths from the diff.
 
-REVIEW OUTPUT (in Russian):
+`
+
+// reviewOutputRussian is the default review structure, in Russian.
+const reviewOutputRussian = `REVIEW OUTPUT (in Russian):
 1. **Краткое резюме** — что делает этот PR (1-2 предложения)
 2. **Найденные проблемы** — конкретные баги, логические ошибки, уязвимости. Укажи файл и строку из diff.
 3. **Потенциальные баги** — edge cases, race conditions, ошибки обработки nil/null
 4. **Стиль и качество кода** — нарушения конвенций проекта (используй docs для проверки), дублирование, неоптимальные решения
 5. **Советы по улучшению** — конкретные предложения с примерами кода
 
-RULES:
+`
+
+// reviewOutputTemplate is the review structure for other languages; %[1]s
+// is the language name.
+const reviewOutputTemplate = `REVIEW OUTPUT (in %[1]s, including the section headings):
+1. **Summary** — what this PR does (1-2 sentences)
+2. **Issues found** — concrete bugs, logic errors, vulnerabilities. Cite the file and line from the diff.
+3. **Potential bugs** — edge cases, race conditions, nil/null handling mistakes
+4. **Style and code quality** — violations of project conventions (check them in docs), duplication, suboptimal solutions
+5. **Suggestions** — concrete improvements with code examples
+
+`
+
+// reviewPromptRules are the general rules of every built-in prompt.
+const reviewPromptRules = `RULES:
 - Be specific: reference file names and line numbers from the diff
 - If you found relevant project conventions in docs — cite them
 - If no issues found, say so explicitly — don't invent problems
@@ -65,20 +120,58 @@ RULES:
 // defaultTimeout is the default time limit for the agent loop.
 const defaultTimeout = 5 * time.Minute
 
-// maxToolResultSize limits individual tool result size to prevent context overflow.
-const maxToolResultSize = 32000
+// Output formats for --format.
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+// exitIssuesFound is the exit code when --fail-on matched an issue.
+const exitIssuesFound = 2
 
 func main() {
 	prNumber := flag.String("pr", "", "PR number (uses gh CLI to get diff)")
 	diffFile := flag.String("diff-file", "", "Path to diff file (alternative to --pr)")
 	codeindexBin := flag.String("codeindex", "./mcp-codeindex", "Path to mcp-codeindex binary")
+	codeindexPath := flag.String("codeindex-path", "", "Project directory checked for .codeindex and indexed if missing (default: git root)")
+	noAutoIndex := flag.Bool("no-auto-index", false, "Do not index the project when .codeindex is missing")
 	model := flag.String("model", "deepseek-chat", "DeepSeek model name")
 	maxTokens := flag.Int("max-tokens", 4096, "Max tokens for response")
 	temperature := flag.Float64("temperature", 0.3, "Temperature for generation")
 	outputFile := flag.String("output", "", "Write review to file (default: stdout only)")
-	timeout := flag.Duration("timeout", defaultTimeout, "Time limit for agent loop (e.g. 5m, 2m30s)")
+	language := flag.String("language", "", "Review language, e.g. English (default: Russian)")
+	promptFile := flag.String("prompt-file", "", "Read the whole system prompt from this file")
+	timeout := flag.Duration("timeout", defaultTimeout, "Time limit for agent loop, per part for split PRs (e.g. 5m, 2m30s)")
+	batchTokens := flag.Int("batch-tokens", defaultBatchTokens, "Split diffs larger than this many estimated tokens into per-file parts")
+	var format string
+	flag.StringVar(&format, "format", formatMarkdown, "Output format: markdown or json")
+	flag.StringVar(&format, "output-format", formatMarkdown, "Alias for --format")
+	failOn := flag.String("fail-on", "", "Exit with code 2 if an issue has this severity or higher (low, medium, high, critical)")
+	minSeverity := flag.String("min-severity", "", "Omit issues below this severity from the output")
+	post := flag.Bool("post", false, "Post the review as a comment on the PR (requires --pr)")
+	postInline := flag.Bool("post-inline", false, "Post a PR review with line comments (requires --pr and --format json)")
+	auditLog := flag.String("audit-log", "", "Append tool calls to this JSONL file")
+	auditRedact := flag.String("audit-redact", strings.Join(chat.DefaultAuditRedactKeys, ","), "Comma-separated parts of argument keys redacted in the audit log")
 	flag.Parse()
 
+	if format != formatMarkdown && format != formatJSON {
+		fatal("Unknown --format %q (use %s or %s)", format, formatMarkdown, formatJSON)
+	}
+	for _, sev := range []string{*failOn, *minSeverity} {
+		if sev != "" && !chat.ValidSeverity(sev) {
+			fatal("Unknown severity %q (use low, medium, high or critical)", sev)
+		}
+	}
+	if (*failOn != "" || *minSeverity != "") && format != formatJSON {
+		fatal("--fail-on and --min-severity require --format json")
+	}
+	if (*post || *postInline) && *prNumber == "" {
+		fatal("--post and --post-inline require --pr")
+	}
+	if *postInline && format != formatJSON {
+		fatal("--post-inline requires --format json")
+	}
+
 	apiKey := os.Getenv("DEEPSEEK_API_KEY")
 	if apiKey == "" {
 		fatal("DEEPSEEK_API_KEY environment variable is required")
@@ -151,11 +244,52 @@ func main() {
 	counts := mcpManager.ServerToolCount()
 	log("mcp-codeindex connected: %d tools available", counts["codeindex"])
 
-	// Build user message
-	userMessage := buildUserMessage(prTitle, prBody, diff)
+	if !*noAutoIndex {
+		root := *codeindexPath
+		if root == "" {
+			root = projectRoot()
+		}
+		ensureIndexes(ctx, mcpManager, root)
+	}
+
+	audit, err := chat.NewToolAuditLogger(*auditLog, strings.Split(*auditRedact, ","))
+	if err != nil {
+		fatal("Failed to open audit log: %v", err)
+	}
+	defer audit.Close()
+
+	// Large diffs are reviewed per file batch; the batches always use the
+	// structured template so their findings can be merged
+	batches := batchFileDiffs(splitDiffByFile(diff), *batchTokens)
+
+	system, err := buildSystemPrompt(*language, *promptFile)
+	if err != nil {
+		fatal("%v", err)
+	}
+	if format == formatJSON || len(batches) > 1 {
+		template, err := chat.GetFormatTemplate("review")
+		if err != nil {
+			fatal("%v", err)
+		}
+		system += "\n\n" + template.Prompt
+	}
 
 	// Run agent loop
-	review := runAgentLoop(ctx, provider, mcpManager, *model, *maxTokens, *temperature, *timeout, userMessage)
+	var review string
+	var invalidParts []int // Batches whose response is not valid JSON
+	if len(batches) > 1 {
+		log("Diff is ~%d tokens, reviewing in %d parts", chat.EstimateTokens(diff), len(batches))
+		var merged *chat.ReviewResponse
+		merged, invalidParts = reviewInBatches(ctx, provider, mcpManager, audit, system, *model, *maxTokens, *temperature, *timeout, prTitle, prBody, batches)
+		if format == formatJSON {
+			review = formatReviewJSON(merged)
+		} else {
+			review = renderReviewMarkdown(merged)
+		}
+	} else {
+		userMessage := buildUserMessage(prTitle, prBody, diff)
+		review = runAgentLoop(ctx, provider, mcpManager, audit, nil, system, *model, *maxTokens, *temperature, *timeout, userMessage)
+	}
 
 	if review == "" {
 		fatal("Agent returned empty review")
@@ -167,7 +301,19 @@ func main() {
 	}
 
 	// Output
-	result := formatReviewOutput(review)
+	var result string
+	var parsed *chat.ReviewResponse
+	if format == formatJSON {
+		parsed, err = chat.ParseReviewResponse(review)
+		if err != nil {
+			log("Warning: review is not valid JSON (%v), printing raw text", err)
+			result = review
+		} else {
+			result = formatReviewJSON(filterIssues(parsed, *minSeverity))
+		}
+	} else {
+		result = formatReviewOutput(review)
+	}
 	fmt.Println(result)
 
 	if *outputFile != "" {
@@ -177,6 +323,43 @@ func main() {
 			log("Review written to %s", *outputFile)
 		}
 	}
+
+	switch {
+	case *postInline:
+		if parsed == nil {
+			fatal("Cannot post inline comments: review is not valid JSON")
+		}
+		if len(invalidParts) > 0 {
+			fatal("Cannot post inline comments: %s", invalidPartsError(invalidParts))
+		}
+		n, err := postInlineReview(*prNumber, filterIssues(parsed, *minSeverity), diff)
+		if err != nil {
+			fatal("Failed to post review: %v", err)
+		}
+		log("Posted review to PR #%s with %d inline comment(s)", *prNumber, n)
+	case *post:
+		body := result
+		if parsed != nil {
+			body = formatReviewOutput(renderReviewMarkdown(filterIssues(parsed, *minSeverity)))
+		}
+		if err := postComment(*prNumber, body); err != nil {
+			fatal("Failed to post review: %v", err)
+		}
+		log("Posted review comment to PR #%s", *prNumber)
+	}
+
+	if *failOn != "" {
+		if parsed == nil {
+			fatal("Cannot apply --fail-on %s: review is not valid JSON", *failOn)
+		}
+		if len(invalidParts) > 0 {
+			fatal("Cannot apply --fail-on %s: %s", *failOn, invalidPartsError(invalidParts))
+		}
+		if n := countAtOrAbove(parsed.Issues, *failOn); n > 0 {
+			log("Found %d issue(s) at or above %s severity", n, *failOn)
+			os.Exit(exitIssuesFound)
+		}
+	}
 }
 
 func getDiff(prNumber, diffFilePath string) string {
@@ -223,6 +406,9 @@ func runAgentLoop(
 	ctx context.Context,
 	provider api.Provider,
 	mcpManager *mcp.Manager,
+	audit *chat.ToolAuditLogger,
+	cache *toolCache,
+	system string,
 	model string,
 	maxTokens int,
 	temperature float64,
@@ -241,7 +427,7 @@ func runAgentLoop(
 		round++
 		req := api.MessageRequest{
 			Messages:    messages,
-			System:      reviewSystemPrompt,
+			System:      system,
 			Model:       model,
 			MaxTokens:   maxTokens,
 			Temperature: temperature,
@@ -275,22 +461,26 @@ func runAgentLoop(
 		for _, tc := range resp.ToolCalls {
 			log("  Tool: %s(%s)", tc.Name, truncate(tc.Arguments, 100))
 
-			result, err := mcpManager.CallTool(ctx, tc.Name, tc.Arguments)
-			if err != nil {
-				result = fmt.Sprintf("Error: %v", err)
-				log("  Error: %v", err)
+			result, cached := cache.get(tc.Name, tc.Arguments)
+			if cached {
+				log("  Result: %d chars (cached)", len(result))
 			} else {
-				log("  Result: %d chars", len(result))
-			}
-
-			// Truncate large results
-			if len(result) > maxToolResultSize {
-				result = result[:maxToolResultSize] + "\n\n[... truncated — result too large]"
+				start := time.Now()
+				var err error
+				result, err = mcpManager.CallTool(ctx, tc.Name, tc.Arguments)
+				audit.Log(tc.Name, tc.Arguments, result, time.Since(start), err)
+				if err != nil {
+					result = fmt.Sprintf("Error: %v", err)
+					log("  Error: %v", err)
+				} else {
+					log("  Result: %d chars", len(result))
+					cache.put(tc.Name, tc.Arguments, result)
+				}
 			}
 
 			messages = append(messages, api.Message{
 				Role:       "tool",
-				Content:    result,
+				Content:    mcp.TruncateResult(result),
 				ToolCallID: tc.ID,
 			})
 		}
@@ -306,7 +496,7 @@ func runAgentLoop(
 
 	finalReq := api.MessageRequest{
 		Messages:    messages,
-		System:      reviewSystemPrompt,
+		System:      system,
 		Model:       model,
 		MaxTokens:   maxTokens,
 		Temperature: temperature,
@@ -328,6 +518,66 @@ func formatReviewOutput(review string) string {
 	return "## AI Code Review\n\n" + review + "\n\n---\n*Reviewed by DeepSeek AI with RAG context from project indexes*"
 }
 
+// buildSystemPrompt returns the review system prompt. A prompt file
+// replaces the built-in prompt; with a language set, an instruction to
+// answer in it is appended. Without either, the Russian default is used.
+func buildSystemPrompt(language, promptFile string) (string, error) {
+	if promptFile != "" {
+		data, err := os.ReadFile(promptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		prompt := strings.TrimSpace(string(data))
+		if prompt == "" {
+			return "", fmt.Errorf("prompt file %s is empty", promptFile)
+		}
+		if language != "" {
+			prompt += fmt.Sprintf("\n\nWrite the review in %s.", language)
+		}
+		return prompt, nil
+	}
+
+	switch strings.ToLower(language) {
+	case "", "russian", "ru", "русский":
+		return reviewSystemPrompt, nil
+	}
+	return reviewPromptIntro + fmt.Sprintf(reviewOutputTemplate, language) + reviewPromptRules, nil
+}
+
+// formatReviewJSON encodes a parsed review for CI consumers.
+func formatReviewJSON(review *chat.ReviewResponse) string {
+	output, _ := json.MarshalIndent(review, "", "  ")
+	return string(output)
+}
+
+// filterIssues drops issues below minSeverity. An empty minSeverity keeps
+// all issues.
+func filterIssues(review *chat.ReviewResponse, minSeverity string) *chat.ReviewResponse {
+	if minSeverity == "" {
+		return review
+	}
+
+	filtered := *review
+	filtered.Issues = []chat.ReviewIssue{}
+	for _, issue := range review.Issues {
+		if chat.SeverityRank(issue.Severity) >= chat.SeverityRank(minSeverity) {
+			filtered.Issues = append(filtered.Issues, issue)
+		}
+	}
+	return &filtered
+}
+
+// countAtOrAbove counts issues with severity at or above threshold.
+func countAtOrAbove(issues []chat.ReviewIssue, threshold string) int {
+	n := 0
+	for _, issue := range issues {
+		if chat.SeverityRank(issue.Severity) >= chat.SeverityRank(threshold) {
+			n++
+		}
+	}
+	return n
+}
+
 // ghExec runs a gh CLI command and returns stdout.
 func ghExec(args ...string) string {
 	cmd := exec.Command("gh", args...)
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/notexe/cli-chat/internal/chat"
+)
+
+func TestSeverityRank(t *testing.T) {
+	tests := []struct {
+		severity string
+		want     int
+	}{
+		{"low", 0},
+		{"nit", 0},
+		{"info", 0},
+		{"medium", 1},
+		{"warning", 1},
+		{"high", 2},
+		{"Major", 2},
+		{" error ", 2},
+		{"critical", 3},
+		{"BLOCKER", 3},
+		{"", 1},
+		{"severe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := chat.SeverityRank(tt.severity); got != tt.want {
+			t.Errorf("SeverityRank(%q) = %d, want %d", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestFilterIssues(t *testing.T) {
+	review := &chat.ReviewResponse{
+		Summary: "Looks fine.",
+		Issues: []chat.ReviewIssue{
+			{File: "a.go", Severity: "nit", Message: "Naming."},
+			{File: "b.go", Severity: "medium", Message: "Missing check."},
+			{File: "c.go", Severity: "error", Message: "Wrong result."},
+			{File: "d.go", Severity: "critical", Message: "Data loss."},
+			{File: "e.go", Severity: "unusual", Message: "Unknown severity."},
+		},
+		Suggestions: []string{"Add tests."},
+	}
+
+	tests := []struct {
+		minSeverity string
+		wantFiles   []string
+	}{
+		{"", []string{"a.go", "b.go", "c.go", "d.go", "e.go"}},
+		{"low", []string{"a.go", "b.go", "c.go", "d.go", "e.go"}},
+		{"medium", []string{"b.go", "c.go", "d.go", "e.go"}},
+		{"high", []string{"c.go", "d.go"}},
+		{"critical", []string{"d.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("min "+tt.minSeverity, func(t *testing.T) {
+			got := filterIssues(review, tt.minSeverity)
+
+			var files []string
+			for _, issue := range got.Issues {
+				files = append(files, issue.File)
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("kept %v, want %v", files, tt.wantFiles)
+			}
+			if got.Summary != review.Summary || !reflect.DeepEqual(got.Suggestions, review.Suggestions) {
+				t.Errorf("summary or suggestions changed: %+v", got)
+			}
+			if len(review.Issues) != 5 {
+				t.Fatalf("filterIssues changed its input to %d issues", len(review.Issues))
+			}
+		})
+	}
+}
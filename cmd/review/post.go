@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/chat"
+)
+
+// reviewComment is an inline comment of a GitHub pull request review.
+type reviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// pullRequestReview is the request body of the create-review API.
+type pullRequestReview struct {
+	Event    string          `json:"event"`
+	Body     string          `json:"body"`
+	Comments []reviewComment `json:"comments,omitempty"`
+}
+
+// postComment posts body as a regular PR comment.
+func postComment(prNumber, body string) error {
+	f, err := os.CreateTemp("", "review-*.md")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(body); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	f.Close()
+
+	_, err = ghRun(nil, "pr", "comment", prNumber, "--body-file", f.Name())
+	return err
+}
+
+// postInlineReview posts a PR review with one line comment per issue that
+// points into the diff. GitHub rejects comments outside the diff, so other
+// issues are listed in the review body instead.
+func postInlineReview(prNumber string, review *chat.ReviewResponse, diff string) (int, error) {
+	lines := diffLines(diff)
+
+	var comments []reviewComment
+	var rest []chat.ReviewIssue
+	for _, issue := range review.Issues {
+		if issue.Line > 0 && lines[issue.File][issue.Line] {
+			comments = append(comments, reviewComment{
+				Path: issue.File,
+				Line: issue.Line,
+				Side: "RIGHT",
+				Body: fmt.Sprintf("**[%s]** %s", issue.Severity, issue.Message),
+			})
+			continue
+		}
+		rest = append(rest, issue)
+	}
+
+	body := *review
+	body.Issues = rest
+	payload, _ := json.Marshal(pullRequestReview{
+		Event:    "COMMENT",
+		Body:     formatReviewOutput(renderReviewMarkdown(&body)),
+		Comments: comments,
+	})
+
+	_, err := ghRun(payload, "api", "--method", "POST",
+		"repos/{owner}/{repo}/pulls/"+prNumber+"/reviews", "--input", "-")
+	return len(comments), err
+}
+
+// diffLines returns, per file, the new-side line numbers present in the
+// diff (added and context lines), which are the lines GitHub accepts
+// comments on.
+func diffLines(diff string) map[string]map[int]bool {
+	result := make(map[string]map[int]bool)
+
+	for _, f := range splitDiffByFile(diff) {
+		lines := make(map[int]bool)
+		line := 0
+		inHunk := false
+
+		for _, text := range strings.Split(f.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(text, "@@"):
+				line, inHunk = hunkStart(text), true
+			case !inHunk:
+			case strings.HasPrefix(text, "+"), strings.HasPrefix(text, " "):
+				lines[line] = true
+				line++
+			}
+		}
+		result[f.Path] = lines
+	}
+
+	return result
+}
+
+// hunkStart parses the new-side start line of a "@@ -a,b +c,d @@" header.
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// ghRun runs a gh command with optional stdin and returns stdout. Auth
+// failures are reported with a hint instead of gh's raw output.
+func ghRun(stdin []byte, args ...string) (string, error) {
+	cmd := exec.Command("gh", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if isGHAuthError(msg) {
+			return "", fmt.Errorf("gh is not authenticated: run `gh auth login` or set GH_TOKEN (%s)", msg)
+		}
+		return "", fmt.Errorf("gh %s failed: %v\n%s", args[0], err, msg)
+	}
+	return string(out), nil
+}
+
+func isGHAuthError(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	return strings.Contains(lower, "gh auth login") ||
+		strings.Contains(lower, "http 401") ||
+		strings.Contains(lower, "bad credentials") ||
+		strings.Contains(lower, "authentication")
+}
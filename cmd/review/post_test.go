@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testDiff changes two hunks of main.go and adds a file whose name has a
+// space and no final newline.
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,4 +3,5 @@ import
+ import "fmt"
+-var x = 1
++var x = 2
++var y = 3
+ 
+ func main() {
+@@ -20,3 +21,2 @@ func main() {
+ 	a()
+-	b()
+ }
+diff --git a/docs/new file.md b/docs/new file.md
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/docs/new file.md
+@@ -0,0 +1,2 @@
++# Title
++text
+\ No newline at end of file
+`
+
+func TestHunkStart(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"@@ -1,3 +1,4 @@", 1},
+		{"@@ -20,3 +21,2 @@ func main() {", 21},
+		{"@@ -5 +7 @@", 7},
+		{"@@ -0,0 +1,2 @@", 1},
+		{"@@ -1,2 @@", 0},
+		{"@@", 0},
+	}
+
+	for _, tt := range tests {
+		if got := hunkStart(tt.header); got != tt.want {
+			t.Errorf("hunkStart(%q) = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want map[string]map[int]bool
+	}{
+		{
+			name: "added and context lines on the new side",
+			diff: testDiff,
+			want: map[string]map[int]bool{
+				"main.go":          {3: true, 4: true, 5: true, 6: true, 7: true, 21: true, 22: true},
+				"docs/new file.md": {1: true, 2: true},
+			},
+		},
+		{
+			name: "deleted file",
+			diff: "diff --git a/old.go b/old.go\ndeleted file mode 100644\n--- a/old.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-package old\n-\n",
+			want: map[string]map[int]bool{"old.go": {}},
+		},
+		{
+			name: "no diff",
+			diff: "",
+			want: map[string]map[int]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.diff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
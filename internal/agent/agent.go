@@ -0,0 +1,272 @@
+// Package agent runs tool-calling loops against a provider: the shared tool
+// execution used by scheduled prompts and the autonomous "continue until
+// done" mode.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-deepseek/deepseek/request"
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/mcp"
+)
+
+// DoneToolName is the tool the model must call to finish an autonomous run.
+const DoneToolName = "task_done"
+
+// Stop reasons reported in Result.
+const (
+	StopDone          = "done"
+	StopMaxIterations = "max_iterations"
+	StopTokenBudget   = "token_budget"
+	StopTimeout       = "timeout"
+	StopCancelled     = "cancelled"
+)
+
+// continuePrompt is sent when the model replies without calling a tool.
+const continuePrompt = "Continue working on the task. When it is fully complete, call " +
+	DoneToolName + " with a summary of what you did."
+
+// SystemPrompt is appended to the system prompt of autonomous runs.
+const SystemPrompt = "You are running autonomously without a user to answer questions. " +
+	"Work step by step using the available tools until the task is complete. " +
+	"Make reasonable assumptions instead of asking. " +
+	"When the task is complete, you MUST call the " + DoneToolName + " tool with a summary; " +
+	"the run does not end until you do."
+
+// CallTool executes a tool call via MCP and returns the (possibly truncated)
+// result. Errors are returned as result text so the model can react to them.
+func CallTool(ctx context.Context, mgr *mcp.Manager, tc api.ToolCall) string {
+	if mgr == nil {
+		return fmt.Sprintf("Error: tool %s is not available", tc.Name)
+	}
+
+	result, err := mgr.CallTool(ctx, tc.Name, tc.Arguments)
+	if err != nil {
+		result = fmt.Sprintf("Error: %v", err)
+	}
+
+	return mcp.TruncateResult(result)
+}
+
+// Limits are the hard stop conditions of an autonomous run. A zero field
+// uses the matching DefaultLimits value.
+type Limits struct {
+	MaxIterations int           // Model requests
+	TokenBudget   int           // Input plus output tokens over all requests
+	Timeout       time.Duration // Wall-clock time
+}
+
+// DefaultLimits returns conservative limits for autonomous runs.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxIterations: 20,
+		TokenBudget:   200000,
+		Timeout:       10 * time.Minute,
+	}
+}
+
+// Event kinds reported to Runner.OnEvent.
+const (
+	EventIteration  = "iteration"   // A model request is about to be sent
+	EventMessage    = "message"     // The model replied with text
+	EventToolCall   = "tool_call"   // A tool is about to be executed
+	EventToolResult = "tool_result" // A tool returned
+)
+
+// Event describes progress of an autonomous run.
+type Event struct {
+	Kind      string
+	Iteration int
+	Text      string // Model text or tool result
+	ToolName  string
+	Arguments string
+	Usage     api.Usage // Cumulative usage so far
+}
+
+// Result is the outcome of an autonomous run.
+type Result struct {
+	StopReason string
+	Summary    string // task_done summary, or the last model text
+	Iterations int
+	Usage      api.Usage
+	Duration   time.Duration
+}
+
+// Runner drives an autonomous run: it keeps sending the conversation,
+// executing tool calls and prompting the model to continue until the model
+// calls task_done or a limit is hit.
+type Runner struct {
+	Provider    api.Provider
+	MCP         *mcp.Manager // Optional
+	System      string
+	Model       string
+	MaxTokens   int
+	Temperature float64
+	Limits      Limits
+	OnEvent     func(Event) // Optional progress callback
+}
+
+// Run executes task autonomously. It returns an error only if a model
+// request fails; limits are reported through Result.StopReason.
+func (r *Runner) Run(ctx context.Context, task string) (*Result, error) {
+	limits := r.limits()
+
+	ctx, cancel := context.WithTimeout(ctx, limits.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := &Result{}
+	messages := []api.Message{{Role: "user", Content: task}}
+
+	finish := func(reason string) (*Result, error) {
+		result.StopReason = reason
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	for {
+		if reason := r.checkLimits(ctx, result, limits); reason != "" {
+			return finish(reason)
+		}
+
+		result.Iterations++
+		r.emit(Event{Kind: EventIteration, Iteration: result.Iterations, Usage: result.Usage})
+
+		resp, err := r.Provider.SendMessage(ctx, api.MessageRequest{
+			Messages:    messages,
+			System:      r.System,
+			Model:       r.Model,
+			MaxTokens:   r.MaxTokens,
+			Temperature: r.Temperature,
+			Tools:       r.tools(),
+		})
+		if err != nil {
+			if reason := r.checkLimits(ctx, result, limits); reason == StopTimeout || reason == StopCancelled {
+				return finish(reason)
+			}
+			return nil, fmt.Errorf("API request failed (iteration %d): %w", result.Iterations, err)
+		}
+
+		result.Usage.Add(resp.Usage)
+
+		if resp.Content != "" {
+			result.Summary = resp.Content
+			r.emit(Event{Kind: EventMessage, Iteration: result.Iterations, Text: resp.Content, Usage: result.Usage})
+		}
+
+		if len(resp.ToolCalls) == 0 {
+			// Plain text is not a completion signal; ask the model to go on
+			messages = append(messages,
+				api.Message{Role: "assistant", Content: resp.Content},
+				api.Message{Role: "user", Content: continuePrompt},
+			)
+			continue
+		}
+
+		messages = append(messages, api.Message{
+			Role:      "assistant",
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+		})
+
+		for _, tc := range resp.ToolCalls {
+			if tc.Name == DoneToolName {
+				result.Summary = doneSummary(tc.Arguments, result.Summary)
+				return finish(StopDone)
+			}
+
+			r.emit(Event{Kind: EventToolCall, Iteration: result.Iterations, ToolName: tc.Name, Arguments: tc.Arguments, Usage: result.Usage})
+			output := CallTool(ctx, r.MCP, tc)
+			r.emit(Event{Kind: EventToolResult, Iteration: result.Iterations, ToolName: tc.Name, Text: output, Usage: result.Usage})
+
+			messages = append(messages, api.Message{
+				Role:       "tool",
+				Content:    output,
+				ToolCallID: tc.ID,
+			})
+		}
+	}
+}
+
+// limits fills zero fields of r.Limits with defaults.
+func (r *Runner) limits() Limits {
+	limits := r.Limits
+	defaults := DefaultLimits()
+	if limits.MaxIterations <= 0 {
+		limits.MaxIterations = defaults.MaxIterations
+	}
+	if limits.TokenBudget <= 0 {
+		limits.TokenBudget = defaults.TokenBudget
+	}
+	if limits.Timeout <= 0 {
+		limits.Timeout = defaults.Timeout
+	}
+	return limits
+}
+
+// checkLimits returns the stop reason if a limit has been reached.
+func (r *Runner) checkLimits(ctx context.Context, result *Result, limits Limits) string {
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return StopTimeout
+	case ctx.Err() != nil:
+		return StopCancelled
+	case result.Iterations >= limits.MaxIterations:
+		return StopMaxIterations
+	case result.Usage.InputTokens+result.Usage.OutputTokens >= limits.TokenBudget:
+		return StopTokenBudget
+	}
+	return ""
+}
+
+// tools returns the MCP tools plus the mandatory task_done tool.
+func (r *Runner) tools() []request.Tool {
+	var tools []request.Tool
+	if r.MCP != nil {
+		tools = r.MCP.GetDeepSeekTools()
+	}
+	return append(tools, DoneTool())
+}
+
+func (r *Runner) emit(e Event) {
+	if r.OnEvent != nil {
+		r.OnEvent(e)
+	}
+}
+
+// DoneTool returns the definition of the task_done tool.
+func DoneTool() request.Tool {
+	return request.Tool{
+		Type: "function",
+		Function: &request.ToolFunction{
+			Name:        DoneToolName,
+			Description: "Signal that the task is fully complete. Call this exactly once, at the end, with a summary of the outcome.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "What was done and the final result",
+					},
+				},
+				"required": []string{"summary"},
+			},
+		},
+	}
+}
+
+// doneSummary extracts the summary argument of a task_done call, falling
+// back to the last model text.
+func doneSummary(argsJSON, fallback string) string {
+	var args struct {
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(argsJSON), &args); err == nil && args.Summary != "" {
+		return args.Summary
+	}
+	return fallback
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-deepseek/deepseek/request"
+	"github.com/notexe/cli-chat/internal/config"
+)
+
+// applyCompat rewrites messages for a server that does not accept the full
+// OpenAI chat schema, as described by compat:
+//   - system_role "developer" renames system messages; "user" folds them
+//     into the next user message.
+//   - tool_role "function" sends tool results as named function messages;
+//     "user" (or tool_schema "none") sends tool calls and results as text.
+//   - tool_schema "functions" moves assistant tool calls to function_call.
+func applyCompat(messages []deepseekMessage, compat config.CompatConfig) []deepseekMessage {
+	toolNames := make(map[string]string)
+	textTools := compat.ToolRole == config.RoleUser || compat.ToolSchema == config.ToolSchemaNone
+
+	out := make([]deepseekMessage, 0, len(messages))
+	var pendingSystem []string
+
+	for _, m := range messages {
+		switch m.Role {
+		case "system":
+			switch compat.SystemRole {
+			case config.RoleUser:
+				pendingSystem = append(pendingSystem, m.Content)
+				continue
+			case config.RoleDeveloper:
+				m.Role = config.RoleDeveloper
+			}
+
+		case "assistant":
+			for _, tc := range m.ToolCalls {
+				toolNames[tc.Id] = tc.Function.Name
+			}
+			switch {
+			case textTools && len(m.ToolCalls) > 0:
+				m.Content = strings.TrimSpace(m.Content + "\n" + describeToolCalls(m.ToolCalls))
+				m.ToolCalls = nil
+			case compat.ToolSchema == config.ToolSchemaFunctions && len(m.ToolCalls) > 0:
+				// Legacy function calling allows a single call per message
+				fn := m.ToolCalls[0].Function
+				m.FunctionCall = &fn
+				m.ToolCalls = nil
+			}
+
+		case "tool":
+			name := toolNames[m.ToolCallId]
+			switch {
+			case textTools:
+				m = deepseekMessage{
+					Role:    "user",
+					Content: fmt.Sprintf("[Result of tool %s]\n%s", name, m.Content),
+				}
+			case compat.ToolRole == config.RoleFunction || compat.ToolSchema == config.ToolSchemaFunctions:
+				m.Role = config.RoleFunction
+				m.Name = name
+				if compat.ToolSchema == config.ToolSchemaFunctions {
+					m.ToolCallId = ""
+				}
+			}
+		}
+
+		if m.Role == "user" {
+			if len(pendingSystem) > 0 {
+				m.Content = strings.Join(append(pendingSystem, m.Content), "\n\n")
+				pendingSystem = nil
+			}
+			// Text tool results arrive as consecutive user messages
+			if n := len(out); n > 0 && out[n-1].Role == "user" && textTools {
+				out[n-1].Content += "\n\n" + m.Content
+				out[n-1].Images = append(out[n-1].Images, m.Images...)
+				continue
+			}
+		}
+
+		out = append(out, m)
+	}
+
+	if len(pendingSystem) > 0 {
+		out = append(out, deepseekMessage{Role: "user", Content: strings.Join(pendingSystem, "\n\n")})
+	}
+
+	return out
+}
+
+// describeToolCalls renders tool calls as text for servers without tool
+// support in the message history.
+func describeToolCalls(calls []deepseekToolCall) string {
+	lines := make([]string, len(calls))
+	for i, tc := range calls {
+		lines[i] = fmt.Sprintf("[Called tool %s with %s]", tc.Function.Name, tc.Function.Arguments)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compatTools returns the tool definitions to send in the format the server
+// expects: as tools, as legacy functions, or not at all.
+func compatTools(tools []request.Tool, compat config.CompatConfig) (*[]request.Tool, []*request.ToolFunction) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+
+	switch compat.ToolSchema {
+	case config.ToolSchemaNone:
+		return nil, nil
+	case config.ToolSchemaFunctions:
+		functions := make([]*request.ToolFunction, 0, len(tools))
+		for _, t := range tools {
+			if t.Function != nil {
+				functions = append(functions, t.Function)
+			}
+		}
+		return nil, functions
+	default:
+		return &tools, nil
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-deepseek/deepseek"
@@ -22,6 +24,46 @@ type deepseekMessage struct {
 	Name       string             `json:"name,omitempty"`
 	ToolCallId string             `json:"tool_call_id,omitempty"`
 	ToolCalls  []deepseekToolCall `json:"tool_calls,omitempty"`
+
+	FunctionCall *deepseekToolFunction `json:"function_call,omitempty"` // Legacy function calling
+
+	Images []Image `json:"-"` // Sent as content parts, see MarshalJSON
+}
+
+// deepseekContentPart is one element of a multimodal content array.
+type deepseekContentPart struct {
+	Type     string                `json:"type"`
+	Text     string                `json:"text,omitempty"`
+	ImageURL *deepseekImageURLPart `json:"image_url,omitempty"`
+}
+
+type deepseekImageURLPart struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON sends messages with images in the content array format of
+// vision models; other messages keep plain string content.
+func (m deepseekMessage) MarshalJSON() ([]byte, error) {
+	type plain deepseekMessage
+	if len(m.Images) == 0 {
+		return json.Marshal(plain(m))
+	}
+
+	parts := make([]deepseekContentPart, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, deepseekContentPart{Type: "text", Text: m.Content})
+	}
+	for _, img := range m.Images {
+		parts = append(parts, deepseekContentPart{
+			Type:     "image_url",
+			ImageURL: &deepseekImageURLPart{URL: img.DataURL()},
+		})
+	}
+
+	return json.Marshal(struct {
+		plain
+		Content []deepseekContentPart `json:"content"`
+	}{plain(m), parts})
 }
 
 type deepseekToolCall struct {
@@ -43,6 +85,10 @@ type deepseekChatRequest struct {
 	Temperature *float32          `json:"temperature,omitempty"`
 	Stream      bool              `json:"stream"`
 	Tools       *[]request.Tool   `json:"tools,omitempty"`
+
+	Functions []*request.ToolFunction `json:"functions,omitempty"` // Legacy function calling
+
+	ResponseFormat *request.ResponseFormat `json:"response_format,omitempty"`
 }
 
 // deepseekChatResponse mirrors the API response structure
@@ -52,14 +98,18 @@ type deepseekChatResponse struct {
 		FinishReason string `json:"finish_reason"`
 		Index        int    `json:"index"`
 		Message      struct {
-			Role      string             `json:"role"`
-			Content   string             `json:"content"`
-			ToolCalls []deepseekToolCall `json:"tool_calls"`
+			Role             string                `json:"role"`
+			Content          string                `json:"content"`
+			ReasoningContent string                `json:"reasoning_content"`
+			ToolCalls        []deepseekToolCall    `json:"tool_calls"`
+			FunctionCall     *deepseekToolFunction `json:"function_call"`
 		} `json:"message"`
 	} `json:"choices"`
 	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
+		PromptTokens          int `json:"prompt_tokens"`
+		CompletionTokens      int `json:"completion_tokens"`
+		PromptCacheHitTokens  int `json:"prompt_cache_hit_tokens"`
+		PromptCacheMissTokens int `json:"prompt_cache_miss_tokens"`
 	} `json:"usage"`
 }
 
@@ -96,6 +146,10 @@ func NewDeepSeekProvider(cfg config.DeepSeekConfig) (*DeepSeekProvider, error) {
 
 // SendMessage sends a message to DeepSeek API and returns the response.
 func (p *DeepSeekProvider) SendMessage(ctx context.Context, req MessageRequest) (*MessageResponse, error) {
+	if err := validateResponseFormat(req); err != nil {
+		return nil, err
+	}
+
 	// Check if any message has tool calls - if so, we need direct HTTP call
 	hasToolCalls := false
 	for _, msg := range req.Messages {
@@ -105,7 +159,14 @@ func (p *DeepSeekProvider) SendMessage(ctx context.Context, req MessageRequest)
 		}
 	}
 
-	if hasToolCalls {
+	hasImages := HasImages(req.Messages)
+	if hasImages && !p.isVisionModel(req.Model) {
+		return nil, errNoVision(req.Model)
+	}
+
+	// Strict servers need the request rewritten, which the SDK can't do.
+	// Neither can it send images.
+	if hasToolCalls || hasImages || !p.config.Compat.IsDefault() {
 		return p.sendMessageWithToolCalls(ctx, req)
 	}
 
@@ -151,16 +212,21 @@ func (p *DeepSeekProvider) sendMessageSDK(ctx context.Context, req MessageReques
 		chatReq.Tools = &req.Tools
 	}
 
+	if req.ResponseFormat != "" {
+		chatReq.ResponseFormat = &request.ResponseFormat{Type: req.ResponseFormat}
+	}
+
 	resp, err := p.client.CallChatCompletionsChat(ctx, chatReq)
 	if err != nil {
 		return nil, fmt.Errorf("DeepSeek API request failed: %w", err)
 	}
 
-	var content string
+	var content, reasoning string
 	var toolCalls []ToolCall
 
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
+		reasoning = resp.Choices[0].Message.ReasoningContent
 
 		// Extract tool calls from response
 		for _, tc := range resp.Choices[0].Message.ToolCalls {
@@ -176,17 +242,21 @@ func (p *DeepSeekProvider) sendMessageSDK(ctx context.Context, req MessageReques
 		Content:    content,
 		StopReason: resp.Choices[0].FinishReason,
 		Usage: Usage{
-			InputTokens:  resp.Usage.PromptTokens,
-			OutputTokens: resp.Usage.CompletionTokens,
+			InputTokens:     resp.Usage.PromptTokens,
+			OutputTokens:    resp.Usage.CompletionTokens,
+			CacheHitTokens:  resp.Usage.PromptCacheHitTokens,
+			CacheMissTokens: resp.Usage.PromptCacheMissTokens,
 		},
-		ToolCalls: toolCalls,
+		ToolCalls:        toolCalls,
+		ReasoningContent: reasoning,
 	}
 
 	return response, nil
 }
 
 // sendMessageWithToolCalls uses direct HTTP for messages containing tool calls
-// This is needed because the SDK's request.Message doesn't support tool_calls field
+// This is needed because the SDK's request.Message doesn't support tool_calls field.
+// It is also used for servers that need the compat message rewriting.
 func (p *DeepSeekProvider) sendMessageWithToolCalls(ctx context.Context, req MessageRequest) (*MessageResponse, error) {
 	messages := make([]deepseekMessage, 0, len(req.Messages)+1)
 
@@ -202,6 +272,7 @@ func (p *DeepSeekProvider) sendMessageWithToolCalls(ctx context.Context, req Mes
 			Role:       msg.Role,
 			Content:    msg.Content,
 			ToolCallId: msg.ToolCallID,
+			Images:     msg.Images,
 		}
 
 		// Convert tool calls if present
@@ -222,6 +293,10 @@ func (p *DeepSeekProvider) sendMessageWithToolCalls(ctx context.Context, req Mes
 		messages = append(messages, m)
 	}
 
+	if !p.config.Compat.IsDefault() {
+		messages = applyCompat(messages, p.config.Compat)
+	}
+
 	var temp *float32
 	if req.Temperature > 0 {
 		t := float32(req.Temperature)
@@ -236,8 +311,10 @@ func (p *DeepSeekProvider) sendMessageWithToolCalls(ctx context.Context, req Mes
 		Stream:      false,
 	}
 
-	if len(req.Tools) > 0 {
-		chatReq.Tools = &req.Tools
+	chatReq.Tools, chatReq.Functions = compatTools(req.Tools, p.config.Compat)
+
+	if req.ResponseFormat != "" {
+		chatReq.ResponseFormat = &request.ResponseFormat{Type: req.ResponseFormat}
 	}
 
 	// Make direct HTTP request
@@ -246,11 +323,12 @@ func (p *DeepSeekProvider) sendMessageWithToolCalls(ctx context.Context, req Mes
 		return nil, fmt.Errorf("DeepSeek API request failed: %w", err)
 	}
 
-	var content string
+	var content, reasoning string
 	var toolCalls []ToolCall
 
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
+		reasoning = resp.Choices[0].Message.ReasoningContent
 
 		for _, tc := range resp.Choices[0].Message.ToolCalls {
 			toolCalls = append(toolCalls, ToolCall{
@@ -259,16 +337,29 @@ func (p *DeepSeekProvider) sendMessageWithToolCalls(ctx context.Context, req Mes
 				Arguments: tc.Function.Arguments,
 			})
 		}
+
+		// Legacy function calls carry no ID; make one so the result can be
+		// matched to the call in the history
+		if fc := resp.Choices[0].Message.FunctionCall; fc != nil && len(toolCalls) == 0 {
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        fmt.Sprintf("call_%d", time.Now().UnixNano()),
+				Name:      fc.Name,
+				Arguments: fc.Arguments,
+			})
+		}
 	}
 
 	response := &MessageResponse{
 		Content:    content,
 		StopReason: resp.Choices[0].FinishReason,
 		Usage: Usage{
-			InputTokens:  resp.Usage.PromptTokens,
-			OutputTokens: resp.Usage.CompletionTokens,
+			InputTokens:     resp.Usage.PromptTokens,
+			OutputTokens:    resp.Usage.CompletionTokens,
+			CacheHitTokens:  resp.Usage.PromptCacheHitTokens,
+			CacheMissTokens: resp.Usage.PromptCacheMissTokens,
 		},
-		ToolCalls: toolCalls,
+		ToolCalls:        toolCalls,
+		ReasoningContent: reasoning,
 	}
 
 	return response, nil
@@ -327,6 +418,101 @@ func (p *DeepSeekProvider) doHTTPRequest(ctx context.Context, chatReq deepseekCh
 	return &chatResp, nil
 }
 
+// deepseekModelsResponse mirrors the /models response.
+type deepseekModelsResponse struct {
+	Data []struct {
+		ID      string `json:"id"`
+		OwnedBy string `json:"owned_by"`
+	} `json:"data"`
+}
+
+// ListModels returns the models from the /models endpoint. The API does
+// not report context window sizes.
+func (p *DeepSeekProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	baseURL := p.config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.deepseek.com"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.config.APIKey))
+	httpReq.Header.Set("Accept", "application/json")
+
+	client := &http.Client{
+		Timeout: time.Duration(p.config.Timeout) * time.Second,
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DeepSeek models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp deepseekErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("failed to list DeepSeek models: %s", errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("API error: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+
+	var modelsResp deepseekModelsResponse
+	if err := json.Unmarshal(respBody, &modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	models := make([]ModelInfo, 0, len(modelsResp.Data))
+	for _, m := range modelsResp.Data {
+		models = append(models, ModelInfo{ID: m.ID, OwnedBy: m.OwnedBy})
+	}
+	return models, nil
+}
+
+// validateResponseFormat checks the request against DeepSeek's JSON mode rules:
+// the API rejects json_object requests whose prompt never mentions "json".
+func validateResponseFormat(req MessageRequest) error {
+	switch req.ResponseFormat {
+	case "", request.ResponseFormatText:
+		return nil
+	case ResponseFormatJSON:
+		if strings.Contains(strings.ToLower(req.System), "json") {
+			return nil
+		}
+		for _, msg := range req.Messages {
+			if strings.Contains(strings.ToLower(msg.Content), "json") {
+				return nil
+			}
+		}
+		return fmt.Errorf("JSON response format requires the word \"json\" in the system prompt or messages")
+	default:
+		return fmt.Errorf("unsupported response format: %s (supported: %s, %s)",
+			req.ResponseFormat, request.ResponseFormatText, ResponseFormatJSON)
+	}
+}
+
+// SupportsVision reports whether model is listed in deepseek.vision_models.
+// DeepSeek's own models are text-only.
+func (p *DeepSeekProvider) SupportsVision(ctx context.Context, model string) (bool, error) {
+	return p.isVisionModel(model), nil
+}
+
+func (p *DeepSeekProvider) isVisionModel(model string) bool {
+	return slices.Contains(p.config.VisionModels, model)
+}
+
+// SupportsJSONMode reports that DeepSeek enforces JSON via response_format.
+func (p *DeepSeekProvider) SupportsJSONMode() bool {
+	return true
+}
+
 // Name returns the provider name.
 func (p *DeepSeekProvider) Name() string {
 	return "deepseek"
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/notexe/cli-chat/internal/config"
+	"github.com/notexe/cli-chat/internal/ollama"
 )
 
 const defaultOllamaURL = "http://localhost:11434"
@@ -18,6 +22,11 @@ const defaultOllamaURL = "http://localhost:11434"
 type OllamaProvider struct {
 	client  *http.Client
 	baseURL string
+
+	autoPull bool
+	mu       sync.Mutex
+	ready    map[string]bool // models known to be present locally
+	vision   map[string]bool // cached vision capability per model
 }
 
 // NewOllamaProvider creates a new Ollama provider.
@@ -36,10 +45,42 @@ func NewOllamaProvider(cfg config.OllamaConfig) (*OllamaProvider, error) {
 		client: &http.Client{
 			Timeout: time.Duration(timeout) * time.Second,
 		},
-		baseURL: baseURL,
+		baseURL:  baseURL,
+		autoPull: cfg.AutoPull,
+		ready:    make(map[string]bool),
+		vision:   make(map[string]bool),
 	}, nil
 }
 
+// EnsureModel pulls model if auto_pull is enabled and the model is not
+// present locally. progress, if non-nil, receives pull updates. It is a
+// no-op when auto_pull is disabled or the model was already checked.
+func (p *OllamaProvider) EnsureModel(ctx context.Context, model string, progress func(ollama.PullProgress)) error {
+	if !p.autoPull || model == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ready[model] {
+		return nil
+	}
+
+	present, err := ollama.HasModel(ctx, p.client, p.baseURL, model)
+	if err != nil {
+		return fmt.Errorf("failed to check Ollama model %s: %w", model, err)
+	}
+	if !present {
+		if err := ollama.PullModel(ctx, p.client, p.baseURL, model, progress); err != nil {
+			return err
+		}
+	}
+
+	p.ready[model] = true
+	return nil
+}
+
 // ollamaChatRequest represents the Ollama API chat request.
 type ollamaChatRequest struct {
 	Model    string          `json:"model"`
@@ -49,8 +90,9 @@ type ollamaChatRequest struct {
 }
 
 type ollamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // base64, for vision models
 }
 
 type ollamaOptions struct {
@@ -70,6 +112,12 @@ type ollamaChatResponse struct {
 
 // SendMessage sends a message to Ollama API and returns the response.
 func (p *OllamaProvider) SendMessage(ctx context.Context, req MessageRequest) (*MessageResponse, error) {
+	// Covers models switched to after startup; the initial model is
+	// normally pulled up front with progress output.
+	if err := p.EnsureModel(ctx, req.Model, nil); err != nil {
+		return nil, err
+	}
+
 	messages := make([]ollamaMessage, 0, len(req.Messages)+1)
 
 	if req.System != "" {
@@ -80,10 +128,27 @@ func (p *OllamaProvider) SendMessage(ctx context.Context, req MessageRequest) (*
 	}
 
 	for _, msg := range req.Messages {
-		messages = append(messages, ollamaMessage{
+		m := ollamaMessage{
 			Role:    msg.Role,
 			Content: msg.Content,
-		})
+		}
+		for _, img := range msg.Images {
+			if img.Data == "" {
+				return nil, fmt.Errorf("Ollama accepts only local images, not URLs: %s", img.URL)
+			}
+			m.Images = append(m.Images, img.Data)
+		}
+		messages = append(messages, m)
+	}
+
+	if HasImages(req.Messages) {
+		ok, err := p.SupportsVision(ctx, req.Model)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errNoVision(req.Model)
+		}
 	}
 
 	ollamaReq := ollamaChatRequest{
@@ -133,6 +198,124 @@ func (p *OllamaProvider) SendMessage(ctx context.Context, req MessageRequest) (*
 	}, nil
 }
 
+// ollamaShowResponse is the part of /api/show used to detect vision
+// models and context window sizes.
+type ollamaShowResponse struct {
+	Capabilities []string       `json:"capabilities"`
+	ModelInfo    map[string]any `json:"model_info"`
+}
+
+// contextLength returns the trained context window from model_info, which
+// is keyed by architecture, e.g. "llama.context_length".
+func (r ollamaShowResponse) contextLength() int {
+	for key, val := range r.ModelInfo {
+		if n, ok := val.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+// ollamaTagsResponse mirrors the /api/tags response.
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+		Size int64  `json:"size"`
+	} `json:"models"`
+}
+
+// ListModels returns the locally installed models from /api/tags, with
+// context window sizes from /api/show where available.
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+
+	models := make([]ModelInfo, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		info := ModelInfo{ID: m.Name, Size: m.Size}
+		// The size is informational; a failed lookup leaves it unknown
+		if show, err := p.show(ctx, m.Name); err == nil {
+			info.ContextWindow = show.contextLength()
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+// show fetches model details from /api/show.
+func (p *OllamaProvider) show(ctx context.Context, model string) (*ollamaShowResponse, error) {
+	body, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Ollama request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/show", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var show ollamaShowResponse
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+	return &show, nil
+}
+
+// SupportsVision asks Ollama whether model lists the "vision" capability.
+// Servers too old to report capabilities are assumed to support it and
+// left to reject the request themselves.
+func (p *OllamaProvider) SupportsVision(ctx context.Context, model string) (bool, error) {
+	p.mu.Lock()
+	supported, ok := p.vision[model]
+	p.mu.Unlock()
+	if ok {
+		return supported, nil
+	}
+
+	show, err := p.show(ctx, model)
+	if err != nil {
+		return false, err
+	}
+
+	supported = show.Capabilities == nil || slices.Contains(show.Capabilities, "vision")
+
+	p.mu.Lock()
+	p.vision[model] = supported
+	p.mu.Unlock()
+
+	return supported, nil
+}
+
 // Name returns the provider name.
 func (p *OllamaProvider) Name() string {
 	return "ollama"
@@ -1,6 +1,9 @@
 package api
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // Provider defines the interface for AI chat providers.
 // Implementations include DeepSeek API and Ollama local models.
@@ -11,6 +14,42 @@ type Provider interface {
 	// Name returns the provider name (e.g., "deepseek", "ollama").
 	Name() string
 
+	// ListModels returns the models the provider offers.
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+
 	// Close releases any resources held by the provider.
 	Close() error
 }
+
+// JSONModeProvider is implemented by providers that can enforce JSON output
+// natively via MessageRequest.ResponseFormat.
+type JSONModeProvider interface {
+	SupportsJSONMode() bool
+}
+
+// SupportsJSONMode reports whether the provider can enforce JSON output.
+func SupportsJSONMode(p Provider) bool {
+	jm, ok := p.(JSONModeProvider)
+	return ok && jm.SupportsJSONMode()
+}
+
+// VisionProvider is implemented by providers that can send images
+// (Message.Images) to models able to read them.
+type VisionProvider interface {
+	SupportsVision(ctx context.Context, model string) (bool, error)
+}
+
+// SupportsVision reports whether model accepts images through provider p.
+func SupportsVision(ctx context.Context, p Provider, model string) (bool, error) {
+	vp, ok := p.(VisionProvider)
+	if !ok {
+		return false, nil
+	}
+	return vp.SupportsVision(ctx, model)
+}
+
+// errNoVision is the error for a request with images to a model that
+// cannot read them.
+func errNoVision(model string) error {
+	return fmt.Errorf("model %s does not support image input", model)
+}
@@ -1,6 +1,12 @@
 package api
 
-import "github.com/go-deepseek/deepseek/request"
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/go-deepseek/deepseek/request"
+)
 
 type Message struct {
 	Role       string     `json:"role"`
@@ -8,6 +14,43 @@ type Message struct {
 	TokenCount int        `json:"token_count,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"` // For tool responses
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // For assistant tool requests
+	Images     []Image    `json:"images,omitempty"`       // For user messages to vision models
+}
+
+// Image is a picture attached to a message. Either URL or Data (base64
+// with its MediaType) is set.
+type Image struct {
+	URL       string `json:"url,omitempty"`
+	Data      string `json:"data,omitempty"`
+	MediaType string `json:"media_type,omitempty"` // e.g. "image/png"
+}
+
+// DataURL returns the image as a URL, inlining Data as a data: URL.
+func (img Image) DataURL() string {
+	if img.URL != "" {
+		return img.URL
+	}
+	return "data:" + img.MediaType + ";base64," + img.Data
+}
+
+// Label returns a short description of the image: its URL, or its format
+// and size.
+func (img Image) Label() string {
+	if img.URL != "" {
+		return img.URL
+	}
+	size := base64.StdEncoding.DecodedLen(len(img.Data))
+	return fmt.Sprintf("%s image (%d KB)", strings.TrimPrefix(img.MediaType, "image/"), size>>10)
+}
+
+// HasImages reports whether any message carries images.
+func HasImages(messages []Message) bool {
+	for _, msg := range messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 type ToolCall struct {
@@ -23,16 +66,50 @@ type MessageRequest struct {
 	MaxTokens   int            `json:"max_tokens"`
 	Temperature float64        `json:"temperature"`
 	Tools       []request.Tool `json:"tools,omitempty"` // MCP tools converted to DeepSeek format
+
+	// ResponseFormat forces the output format when the provider supports it
+	// (e.g. ResponseFormatJSON). Empty means plain text.
+	ResponseFormat string `json:"response_format,omitempty"`
+}
+
+// ModelInfo describes a model offered by a provider. Zero fields are
+// unknown.
+type ModelInfo struct {
+	ID            string
+	OwnedBy       string
+	Size          int64 // Download size in bytes (local models)
+	ContextWindow int   // Context window in tokens
 }
 
+// ResponseFormatJSON asks the provider to return a valid JSON object.
+const ResponseFormatJSON = request.ResponseFormatJsonObject
+
 type MessageResponse struct {
 	Content    string     `json:"content"`
 	StopReason string     `json:"stop_reason"`
 	Usage      Usage      `json:"usage"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"` // Tools the model wants to call
+
+	// Chain of thought from reasoning models (deepseek-reasoner). It is only
+	// for display: Message has no field for it, so it never goes back to the
+	// API, which rejects requests that include it.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
 }
 
 type Usage struct {
 	InputTokens  int `json:"input_tokens"`
 	OutputTokens int `json:"output_tokens"`
+
+	// Part of InputTokens served from / missing DeepSeek's context cache.
+	// Zero for providers that do not report it.
+	CacheHitTokens  int `json:"cache_hit_tokens,omitempty"`
+	CacheMissTokens int `json:"cache_miss_tokens,omitempty"`
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheHitTokens += other.CacheHitTokens
+	u.CacheMissTokens += other.CacheMissTokens
 }
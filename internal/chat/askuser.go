@@ -12,6 +12,26 @@ type AskUserQuestion struct {
 	Header      string   `json:"header,omitempty"`      // Short label for the question
 	Options     []Option `json:"options"`
 	MultiSelect bool     `json:"multiSelect,omitempty"` // Allow multiple selections
+	Default     *int     `json:"default,omitempty"`     // 0-based index of the option Enter selects
+	Optional    bool     `json:"optional,omitempty"`    // The user may skip the question
+}
+
+// DefaultIndex returns the index of the default option, or -1 if the
+// question has none or it is out of range.
+func (q AskUserQuestion) DefaultIndex() int {
+	if q.Default == nil || *q.Default < 0 || *q.Default >= len(q.Options) {
+		return -1
+	}
+	return *q.Default
+}
+
+// Title returns the short label of the question, or the question itself
+// when it has none.
+func (q AskUserQuestion) Title() string {
+	if q.Header != "" {
+		return q.Header
+	}
+	return q.Question
 }
 
 // Option represents a single option in a question
@@ -25,6 +45,19 @@ type AskUserRequest struct {
 	Questions []AskUserQuestion `json:"questions"`
 }
 
+// AskUserAnswer is the user's answer to one question: the chosen options
+// and a free-text answer typed instead of or besides them. Both are empty
+// when the user skipped the question.
+type AskUserAnswer struct {
+	Selected []string
+	Custom   string
+}
+
+// Skipped reports whether the user gave no answer.
+func (a AskUserAnswer) Skipped() bool {
+	return len(a.Selected) == 0 && a.Custom == ""
+}
+
 // AskUserResponse contains the user's answers
 type AskUserResponse struct {
 	Answers map[string]string `json:"answers"` // question -> selected option(s)
@@ -75,10 +108,13 @@ func ParseAskUserRequest(content string) (*AskUserRequest, string, error) {
 		return nil, content, nil
 	}
 
-	for i := range req.Questions {
-		if len(req.Questions[i].Options) < 2 {
+	for i, q := range req.Questions {
+		if len(q.Options) < 2 {
 			return nil, content, fmt.Errorf("question %d needs at least 2 options", i+1)
 		}
+		if q.Default != nil && q.DefaultIndex() < 0 {
+			return nil, content, fmt.Errorf("question %d: default %d is out of range (0-%d)", i+1, *q.Default, len(q.Options)-1)
+		}
 	}
 
 	// Extract text before the ask_user block (if any)
@@ -87,15 +123,36 @@ func ParseAskUserRequest(content string) (*AskUserRequest, string, error) {
 	return &req, textBefore, nil
 }
 
-// FormatAskUserAnswers formats the user's answers for the AI
-func FormatAskUserAnswers(questions []AskUserQuestion, answers [][]string) string {
+// FormatAskUserAnswers formats the user's answers for the AI. Skipped
+// questions, answers that are the question's default option and answers
+// the user typed instead of choosing an option are marked as such.
+func FormatAskUserAnswers(questions []AskUserQuestion, answers []AskUserAnswer) string {
 	var sb strings.Builder
 	sb.WriteString("User's selections:\n")
 
 	for i, q := range questions {
-		if i < len(answers) {
-			sb.WriteString(fmt.Sprintf("- %s: %s\n", q.Header, strings.Join(answers[i], ", ")))
+		if i >= len(answers) {
+			continue
+		}
+		a := answers[i]
+
+		var parts []string
+		if len(a.Selected) > 0 {
+			selected := strings.Join(a.Selected, ", ")
+			if def := q.DefaultIndex(); def >= 0 && len(a.Selected) == 1 && a.Selected[0] == q.Options[def].Label {
+				selected += " (the default)"
+			}
+			parts = append(parts, selected)
+		}
+		if a.Custom != "" {
+			parts = append(parts, fmt.Sprintf("typed answer: %q", a.Custom))
+		}
+
+		answer := strings.Join(parts, "; ")
+		if a.Skipped() {
+			answer = "(skipped, no answer)"
 		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", q.Title(), answer))
 	}
 
 	return sb.String()
@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedValue replaces the value of redacted argument keys.
+const redactedValue = "[REDACTED]"
+
+// DefaultAuditRedactKeys are the parts of argument keys redacted when none
+// are configured. "key" covers api_key and apiKey, "token" covers
+// bot_token and access_token.
+var DefaultAuditRedactKeys = []string{"token", "secret", "password", "key", "authorization"}
+
+// ToolAuditEntry is one line of the tool audit log.
+type ToolAuditEntry struct {
+	Time       time.Time       `json:"time"`
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	ResultSize int             `json:"result_size"`
+	DurationMS int64           `json:"duration_ms"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// ToolAuditLogger appends one JSON line per tool call to a file. A nil
+// logger is valid and records nothing, so callers need no enabled checks.
+type ToolAuditLogger struct {
+	mu     sync.Mutex
+	file   *os.File
+	redact []string // Lowercase key parts
+}
+
+// NewToolAuditLogger opens path for appending. Arguments whose key contains
+// one of redactKeys (case-insensitive, at any depth) are replaced before
+// writing. An empty path returns a nil logger.
+func NewToolAuditLogger(path string, redactKeys []string) (*ToolAuditLogger, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	redact := make([]string, 0, len(redactKeys))
+	for _, key := range redactKeys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			redact = append(redact, key)
+		}
+	}
+
+	return &ToolAuditLogger{file: f, redact: redact}, nil
+}
+
+// Log records a finished tool call. Write failures are ignored so auditing
+// never breaks a conversation.
+func (l *ToolAuditLogger) Log(tool, argsJSON string, result string, duration time.Duration, callErr error) {
+	if l == nil {
+		return
+	}
+
+	entry := ToolAuditEntry{
+		Time:       time.Now(),
+		Tool:       tool,
+		Arguments:  l.redactArgs(argsJSON),
+		ResultSize: len(result),
+		DurationMS: duration.Milliseconds(),
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file.Write(append(line, '\n'))
+}
+
+// Close closes the audit file.
+func (l *ToolAuditLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// redactArgs returns the arguments with redacted keys replaced. Arguments
+// that are not valid JSON are logged as a JSON string.
+func (l *ToolAuditLogger) redactArgs(argsJSON string) json.RawMessage {
+	if argsJSON == "" {
+		return nil
+	}
+
+	var args any
+	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+		raw, _ := json.Marshal(argsJSON)
+		return raw
+	}
+
+	raw, err := json.Marshal(l.redactValue(args))
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+// redactValue replaces, in place, the values of redacted keys in v.
+func (l *ToolAuditLogger) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if l.redacts(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = l.redactValue(val)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = l.redactValue(val)
+		}
+	}
+	return v
+}
+
+// redacts reports whether the value of key must be redacted: whether key
+// contains one of the configured parts, so "X-Api-Key" and "botToken" are
+// caught as well as "api_key".
+func (l *ToolAuditLogger) redacts(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range l.redact {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
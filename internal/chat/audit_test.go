@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditRedactsMatchingKeys(t *testing.T) {
+	l, err := NewToolAuditLogger(filepath.Join(t.TempDir(), "audit.jsonl"), DefaultAuditRedactKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	tests := []struct {
+		key      string
+		redacted bool
+	}{
+		{"token", true},
+		{"bot_token", true},
+		{"accessToken", true},
+		{"api_key", true},
+		{"X-Api-Key", true},
+		{"PASSWORD", true},
+		{"db_password", true},
+		{"client_secret", true},
+		{"Authorization", true},
+		{"path", false},
+		{"query", false},
+		{"chat_id", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			args, _ := json.Marshal(map[string]any{
+				tt.key:   "s3cr3t",
+				"nested": []any{map[string]any{tt.key: "s3cr3t"}},
+			})
+
+			var got map[string]any
+			if err := json.Unmarshal(l.redactArgs(string(args)), &got); err != nil {
+				t.Fatal(err)
+			}
+			nested := got["nested"].([]any)[0].(map[string]any)
+
+			want := "s3cr3t"
+			if tt.redacted {
+				want = redactedValue
+			}
+			if got[tt.key] != want {
+				t.Errorf("%s = %v, want %q", tt.key, got[tt.key], want)
+			}
+			if nested[tt.key] != want {
+				t.Errorf("nested %s = %v, want %q", tt.key, nested[tt.key], want)
+			}
+		})
+	}
+}
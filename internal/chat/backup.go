@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupPrefix     = "history-"
+	backupSuffix     = ".json"
+	backupTimeFormat = "20060102-150405"
+)
+
+// HistoryBackup describes a single timestamped history backup.
+type HistoryBackup struct {
+	Path      string
+	Timestamp time.Time
+	Size      int64
+}
+
+// RotateHistoryFile copies the current history file into backupDir as a
+// timestamped backup and prunes old backups so at most keep remain.
+// It is a no-op when keep <= 0 or the history file does not exist.
+func RotateHistoryFile(historyFile, backupDir string, keep int) error {
+	if keep <= 0 || historyFile == "" || backupDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(historyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := backupPrefix + time.Now().Format(backupTimeFormat) + backupSuffix
+	if err := os.WriteFile(filepath.Join(backupDir, name), data, 0600); err != nil {
+		return fmt.Errorf("failed to write history backup: %w", err)
+	}
+
+	return pruneHistoryBackups(backupDir, keep)
+}
+
+// ListHistoryBackups returns available backups, newest first.
+func ListHistoryBackups(backupDir string) ([]HistoryBackup, error) {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []HistoryBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+		ts, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		var size int64
+		if info, err := entry.Info(); err == nil {
+			size = info.Size()
+		}
+
+		backups = append(backups, HistoryBackup{
+			Path:      filepath.Join(backupDir, name),
+			Timestamp: ts,
+			Size:      size,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+
+	return backups, nil
+}
+
+// pruneHistoryBackups removes the oldest backups beyond keep.
+func pruneHistoryBackups(backupDir string, keep int) error {
+	backups, err := ListHistoryBackups(backupDir)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(b.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+
+	return nil
+}
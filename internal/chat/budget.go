@@ -0,0 +1,131 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/notexe/cli-chat/internal/api"
+)
+
+// Operations an API call is recorded under.
+const (
+	OpChat          = "chat"
+	OpToolFollowUp  = "tool follow-up"
+	OpSummarization = "summarization"
+	OpHelp          = "help"
+)
+
+// operationOrder is the display order of operations in the cost summary.
+var operationOrder = []string{OpChat, OpToolFollowUp, OpSummarization, OpHelp}
+
+// OperationUsage is the spend of one kind of operation.
+type OperationUsage struct {
+	Operation string
+	Calls     int
+	Usage     api.Usage
+	Cost      float64
+}
+
+// Budget limits what a session may spend. Zero limits are disabled.
+type Budget struct {
+	MaxCostUSD     float64
+	MaxTokensTotal int
+}
+
+// Enabled reports whether any limit is set.
+func (b Budget) Enabled() bool {
+	return b.MaxCostUSD > 0 || b.MaxTokensTotal > 0
+}
+
+// SetBudget sets the session budget. The configured limits are also the
+// step by which ExtendBudget raises them.
+func (s *Session) SetBudget(budget Budget) {
+	s.budget = budget
+	s.budgetStep = budget
+}
+
+// GetBudget returns the current session budget.
+func (s *Session) GetBudget() Budget {
+	return s.budget
+}
+
+// RecordUsage adds the tokens and cost of one API call to the session
+// totals and to its operation. Every call counts, including summarization.
+func (s *Session) RecordUsage(op string, usage api.Usage, cost float64) {
+	s.totalUsage.Add(usage)
+	s.totalCost += cost
+
+	if s.usageByOp == nil {
+		s.usageByOp = make(map[string]*OperationUsage)
+	}
+	entry, ok := s.usageByOp[op]
+	if !ok {
+		entry = &OperationUsage{Operation: op}
+		s.usageByOp[op] = entry
+	}
+	entry.Calls++
+	entry.Usage.Add(usage)
+	entry.Cost += cost
+}
+
+// UsageByOperation returns the spend per operation, known operations first
+// and the rest in name order. Operations without calls are left out.
+func (s *Session) UsageByOperation() []OperationUsage {
+	var result []OperationUsage
+	seen := make(map[string]bool)
+	for _, op := range operationOrder {
+		if entry, ok := s.usageByOp[op]; ok {
+			result = append(result, *entry)
+			seen[op] = true
+		}
+	}
+
+	var rest []string
+	for op := range s.usageByOp {
+		if !seen[op] {
+			rest = append(rest, op)
+		}
+	}
+	sort.Strings(rest)
+	for _, op := range rest {
+		result = append(result, *s.usageByOp[op])
+	}
+	return result
+}
+
+// TotalCost returns the estimated cost of all API calls in USD.
+func (s *Session) TotalCost() float64 {
+	return s.totalCost
+}
+
+// BudgetExceeded returns a description of the exceeded limit, or an empty
+// string while the session is within budget.
+func (s *Session) BudgetExceeded() string {
+	if s.budget.MaxCostUSD > 0 && s.totalCost >= s.budget.MaxCostUSD {
+		return fmt.Sprintf("cost $%.4f reached the $%.4f limit", s.totalCost, s.budget.MaxCostUSD)
+	}
+
+	tokens := s.totalUsage.InputTokens + s.totalUsage.OutputTokens
+	if s.budget.MaxTokensTotal > 0 && tokens >= s.budget.MaxTokensTotal {
+		return fmt.Sprintf("%d tokens reached the %d token limit", tokens, s.budget.MaxTokensTotal)
+	}
+
+	return ""
+}
+
+// ExtendBudget allows another round of spending: every enabled limit is
+// set to the current total plus its configured amount, or plus usd for the
+// cost limit when usd > 0.
+func (s *Session) ExtendBudget(usd float64) {
+	if usd <= 0 {
+		usd = s.budgetStep.MaxCostUSD
+	}
+	if usd > 0 {
+		s.budget.MaxCostUSD = max(s.budget.MaxCostUSD, s.totalCost) + usd
+	}
+
+	if step := s.budgetStep.MaxTokensTotal; step > 0 {
+		tokens := s.totalUsage.InputTokens + s.totalUsage.OutputTokens
+		s.budget.MaxTokensTotal = max(s.budget.MaxTokensTotal, tokens) + step
+	}
+}
@@ -0,0 +1,128 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/api"
+)
+
+// Checkpoint is a named snapshot of the conversation that can be restored
+// later to branch off from that point.
+type Checkpoint struct {
+	Name         string        `json:"name"`
+	Messages     []api.Message `json:"messages"`
+	SystemPrompt string        `json:"system_prompt"`
+	FormatPrompt string        `json:"format_prompt"`
+	FormatName   string        `json:"format_name,omitempty"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// checkpointSuffix ends the name of the checkpoint file.
+const checkpointSuffix = ".checkpoints.json"
+
+// CheckpointFile returns the checkpoint file kept next to historyFile,
+// e.g. history.json -> history.checkpoints.json. Any other extension is
+// kept, so history.txt -> history.txt.checkpoints.json and two history
+// files never share checkpoints.
+func CheckpointFile(historyFile string) string {
+	if historyFile == "" {
+		return ""
+	}
+	return strings.TrimSuffix(historyFile, ".json") + checkpointSuffix
+}
+
+// Checkpoint saves the current history under name, replacing an existing
+// checkpoint with the same name.
+func (s *Session) Checkpoint(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("checkpoint name cannot be empty")
+	}
+
+	if s.checkpoints == nil {
+		s.checkpoints = make(map[string]Checkpoint)
+	}
+	s.checkpoints[name] = Checkpoint{
+		Name:         name,
+		Messages:     withoutAllImages(s.history.GetAll()),
+		SystemPrompt: s.systemPrompt,
+		FormatPrompt: s.formatPrompt,
+		FormatName:   s.formatName,
+		Timestamp:    time.Now(),
+	}
+	return nil
+}
+
+// RestoreCheckpoint replaces the history with the named snapshot. The
+// checkpoint itself is kept, so it can be restored again.
+func (s *Session) RestoreCheckpoint(name string) error {
+	cp, ok := s.checkpoints[name]
+	if !ok {
+		return fmt.Errorf("checkpoint not found: %s", name)
+	}
+
+	s.history.messages = append([]api.Message(nil), cp.Messages...)
+	s.systemPrompt = cp.SystemPrompt
+	s.formatPrompt = cp.FormatPrompt
+	s.formatName = cp.FormatName
+	s.responseSchema = nil
+	s.lastInputTokens = 0
+	return nil
+}
+
+// Checkpoints returns all checkpoints, oldest first.
+func (s *Session) Checkpoints() []Checkpoint {
+	list := make([]Checkpoint, 0, len(s.checkpoints))
+	for _, cp := range s.checkpoints {
+		list = append(list, cp)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Timestamp.Before(list[j].Timestamp)
+	})
+	return list
+}
+
+// SaveCheckpoints writes all checkpoints to path.
+func (s *Session) SaveCheckpoints(path string) error {
+	jsonData, err := json.MarshalIndent(s.Checkpoints(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoints: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCheckpoints reads checkpoints saved by SaveCheckpoints, replacing the
+// ones in memory.
+func (s *Session) LoadCheckpoints(path string) error {
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	var list []Checkpoint
+	if err := json.Unmarshal(jsonData, &list); err != nil {
+		return fmt.Errorf("failed to unmarshal checkpoints: %w", err)
+	}
+
+	s.checkpoints = make(map[string]Checkpoint, len(list))
+	for _, cp := range list {
+		s.checkpoints[cp.Name] = cp
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package chat
+
+import "testing"
+
+func TestCheckpointFile(t *testing.T) {
+	tests := []struct {
+		history string
+		want    string
+	}{
+		{"", ""},
+		{"/home/me/.cli-chat/history.json", "/home/me/.cli-chat/history.checkpoints.json"},
+		{"history.txt", "history.txt.checkpoints.json"},
+		{"history", "history.checkpoints.json"},
+	}
+
+	for _, tt := range tests {
+		if got := CheckpointFile(tt.history); got != tt.want {
+			t.Errorf("CheckpointFile(%q) = %q, want %q", tt.history, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package chat
 
+import "github.com/notexe/cli-chat/internal/api"
+
 // ContextManager handles context window tracking and summarization decisions.
 type ContextManager struct {
 	modelLimits map[string]int // model name -> context window size in tokens
@@ -87,3 +89,28 @@ func (cm *ContextManager) GetSummarizeAt() float64 {
 func (cm *ContextManager) GetTargetAfter() float64 {
 	return cm.targetAfter
 }
+
+// charsPerToken is the rough text-to-token ratio used by EstimateTokens.
+const charsPerToken = 4
+
+// EstimateTokens roughly estimates how many tokens text uses. It is meant
+// for budgeting before a request, not for exact accounting.
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateChars is the inverse of EstimateTokens: roughly how much text
+// fits in tokens.
+func EstimateChars(tokens int) int {
+	return tokens * charsPerToken
+}
+
+// EstimateMessageTokens estimates the tokens a message adds to a request:
+// its content and the names and arguments of its tool calls.
+func EstimateMessageTokens(msg api.Message) int {
+	tokens := EstimateTokens(msg.Content)
+	for _, tc := range msg.ToolCalls {
+		tokens += EstimateTokens(tc.Name) + EstimateTokens(tc.Arguments)
+	}
+	return tokens
+}
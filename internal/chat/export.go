@@ -0,0 +1,412 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// ExportFormat identifies the output format of a conversation export.
+type ExportFormat string
+
+const (
+	ExportMarkdown ExportFormat = "markdown"
+	ExportHTML     ExportFormat = "html"
+)
+
+// DetectExportFormat picks the export format from the file extension.
+// Unknown extensions fall back to Markdown.
+func DetectExportFormat(path string) ExportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return ExportHTML
+	default:
+		return ExportMarkdown
+	}
+}
+
+// ExportMeta is the metadata written at the top of an export.
+type ExportMeta struct {
+	Model        string
+	SystemPrompt string
+	Usage        api.Usage // Tokens used by the session so far
+	Exported     time.Time
+}
+
+// Export writes the conversation to path, choosing the format by extension.
+func (s *Session) Export(path string) error {
+	meta := ExportMeta{
+		Model:        s.config.Name,
+		SystemPrompt: s.systemPrompt,
+		Usage:        s.totalUsage,
+		Exported:     time.Now(),
+	}
+
+	var content string
+	var err error
+
+	switch DetectExportFormat(path) {
+	case ExportHTML:
+		content, err = RenderHTML(s.history.GetAll(), meta)
+	default:
+		content = RenderMarkdown(s.history.GetAll(), meta)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	return nil
+}
+
+// RenderMarkdown renders the conversation as a Markdown document with a
+// YAML front-matter block holding the metadata.
+func RenderMarkdown(messages []api.Message, meta ExportMeta) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	b.WriteString(fmt.Sprintf("model: %s\n", meta.Model))
+	b.WriteString(fmt.Sprintf("exported: %s\n", meta.Exported.Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("messages: %d\n", len(messages)))
+	b.WriteString(fmt.Sprintf("input_tokens: %d\n", meta.Usage.InputTokens))
+	b.WriteString(fmt.Sprintf("output_tokens: %d\n", meta.Usage.OutputTokens))
+	b.WriteString("---\n\n")
+
+	b.WriteString("# Conversation\n\n")
+
+	if meta.SystemPrompt != "" {
+		b.WriteString("## System\n\n")
+		b.WriteString(strings.TrimSpace(meta.SystemPrompt))
+		b.WriteString("\n\n")
+	}
+
+	toolNames := exportToolNames(messages)
+	for _, msg := range messages {
+		b.WriteString("## " + exportMessageTitle(msg, toolNames) + "\n\n")
+		b.WriteString(markdownMessageBody(msg))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// markdownMessageBody renders a single message body, including tool calls.
+func markdownMessageBody(msg api.Message) string {
+	var b strings.Builder
+
+	if content := strings.TrimSpace(msg.Content); content != "" {
+		if msg.Role == "tool" {
+			b.WriteString(fenceCode(content, ""))
+		} else {
+			b.WriteString(content + "\n")
+		}
+	}
+
+	for _, tc := range msg.ToolCalls {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("**Tool call:** `%s`\n\n", tc.Name))
+		b.WriteString(fenceCode(indentJSON(tc.Arguments), "json"))
+	}
+
+	return b.String()
+}
+
+// fenceCode wraps content in a code fence longer than any backtick run
+// inside it, so code blocks in tool results stay intact.
+func fenceCode(content, language string) string {
+	longest, run := 0, 0
+	for _, c := range content {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + language + "\n" + content + "\n" + fence + "\n"
+}
+
+// indentJSON pretty-prints tool arguments, returning them unchanged when
+// they are not valid JSON.
+func indentJSON(raw string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(raw), "", "  "); err != nil {
+		return raw
+	}
+	return buf.String()
+}
+
+// exportToolNames maps tool call IDs to tool names, so tool results can be
+// labelled with the tool that produced them.
+func exportToolNames(messages []api.Message) map[string]string {
+	names := make(map[string]string)
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			names[tc.ID] = tc.Name
+		}
+	}
+	return names
+}
+
+// exportMessageTitle returns the heading for a message, naming the tool for
+// tool results.
+func exportMessageTitle(msg api.Message, toolNames map[string]string) string {
+	if name := toolNames[msg.ToolCallID]; msg.Role == "tool" && name != "" {
+		return "Tool result: " + name
+	}
+	return exportRoleTitle(msg.Role)
+}
+
+// exportRoleTitle returns a human-readable heading for a message role.
+func exportRoleTitle(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "tool":
+		return "Tool result"
+	case "system":
+		return "System"
+	default:
+		return role
+	}
+}
+
+// htmlMessage is the view model for a single message in the HTML template.
+type htmlMessage struct {
+	Role      string
+	Title     string
+	Body      template.HTML
+	ToolCalls []htmlToolCall
+	Collapsed bool // Tool results are collapsed by default
+}
+
+type htmlToolCall struct {
+	Name      string
+	Arguments template.HTML
+}
+
+// RenderHTML renders the conversation as a self-contained HTML page.
+// Message bodies are rendered from Markdown, code blocks are highlighted
+// with inline styles and tool calls/results are collapsible.
+func RenderHTML(messages []api.Message, meta ExportMeta) (string, error) {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(&highlightRenderer{}, 100)),
+		),
+	)
+
+	render := func(source string) (template.HTML, error) {
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(source), &buf); err != nil {
+			return "", fmt.Errorf("failed to render markdown: %w", err)
+		}
+		return template.HTML(buf.String()), nil
+	}
+
+	view := struct {
+		Model        string
+		Exported     string
+		Usage        api.Usage
+		SystemPrompt template.HTML
+		Messages     []htmlMessage
+	}{
+		Model:    meta.Model,
+		Exported: meta.Exported.Format("2006-01-02 15:04:05"),
+		Usage:    meta.Usage,
+	}
+
+	if meta.SystemPrompt != "" {
+		body, err := render(meta.SystemPrompt)
+		if err != nil {
+			return "", err
+		}
+		view.SystemPrompt = body
+	}
+
+	toolNames := exportToolNames(messages)
+	for _, msg := range messages {
+		hm := htmlMessage{
+			Role:      msg.Role,
+			Title:     exportMessageTitle(msg, toolNames),
+			Collapsed: msg.Role == "tool",
+		}
+
+		content := strings.TrimSpace(msg.Content)
+		if msg.Role == "tool" && content != "" {
+			content = fenceCode(content, "")
+		}
+		if content != "" {
+			body, err := render(content)
+			if err != nil {
+				return "", err
+			}
+			hm.Body = body
+		}
+
+		for _, tc := range msg.ToolCalls {
+			args, err := highlightCode(indentJSON(tc.Arguments), "json")
+			if err != nil {
+				return "", err
+			}
+			hm.ToolCalls = append(hm.ToolCalls, htmlToolCall{Name: tc.Name, Arguments: args})
+		}
+
+		view.Messages = append(view.Messages, hm)
+	}
+
+	var out bytes.Buffer
+	if err := exportHTMLTemplate.Execute(&out, view); err != nil {
+		return "", fmt.Errorf("failed to render HTML template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// highlightCode returns syntax-highlighted HTML with inline styles.
+func highlightCode(source, language string) (template.HTML, error) {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Analyse(source)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, source)
+	if err != nil {
+		return "", fmt.Errorf("failed to tokenise code: %w", err)
+	}
+
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.Standalone(false), chromahtml.WithClasses(false))
+	if err := formatter.Format(&buf, styles.Get("github"), iterator); err != nil {
+		return "", fmt.Errorf("failed to highlight code: %w", err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// highlightRenderer renders fenced code blocks through chroma.
+type highlightRenderer struct{}
+
+func (r *highlightRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *highlightRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block := node.(*ast.FencedCodeBlock)
+	language := string(block.Language(source))
+
+	var code bytes.Buffer
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	highlighted, err := highlightCode(code.String(), language)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	_, _ = w.WriteString(string(highlighted))
+	return ast.WalkSkipChildren, nil
+}
+
+var exportHTMLTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Conversation · {{.Model}}</title>
+<meta name="generator" content="cli-chat">
+<meta name="model" content="{{.Model}}">
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; background: #f6f8fa; color: #1f2328; margin: 0; padding: 2rem 1rem; line-height: 1.55; }
+  main { max-width: 860px; margin: 0 auto; }
+  header { margin-bottom: 1.5rem; }
+  header h1 { margin: 0 0 .25rem 0; font-size: 1.5rem; }
+  header p { margin: 0; color: #656d76; font-size: .9rem; }
+  .msg { background: #fff; border: 1px solid #d0d7de; border-radius: 8px; padding: .75rem 1rem; margin-bottom: 1rem; }
+  .msg > .role { font-weight: 600; font-size: .85rem; text-transform: uppercase; letter-spacing: .04em; margin-bottom: .25rem; }
+  .msg.user { border-left: 4px solid #0969da; }
+  .msg.user > .role { color: #0969da; }
+  .msg.assistant { border-left: 4px solid #1a7f37; }
+  .msg.assistant > .role { color: #1a7f37; }
+  .msg.system { border-left: 4px solid #8250df; }
+  .msg.system > .role { color: #8250df; }
+  .msg.tool { border-left: 4px solid #bc4c00; background: #fffaf5; }
+  .msg.tool > summary { color: #bc4c00; font-weight: 600; font-size: .85rem; text-transform: uppercase; letter-spacing: .04em; cursor: pointer; }
+  details.call { margin-top: .5rem; border: 1px dashed #d0d7de; border-radius: 6px; padding: .25rem .75rem; }
+  details.call > summary { cursor: pointer; color: #bc4c00; font-weight: 600; }
+  pre { overflow-x: auto; padding: .75rem; border-radius: 6px; font-size: .85rem; }
+  code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+  :not(pre) > code { background: #eff1f3; padding: .1em .3em; border-radius: 4px; }
+  table { border-collapse: collapse; }
+  th, td { border: 1px solid #d0d7de; padding: .3rem .6rem; }
+</style>
+</head>
+<body>
+<main>
+<header>
+  <h1>Conversation</h1>
+  <p>Model: {{.Model}} · Exported: {{.Exported}} · Messages: {{len .Messages}} · Tokens: {{.Usage.InputTokens}} in / {{.Usage.OutputTokens}} out</p>
+</header>
+{{- if .SystemPrompt}}
+<section class="msg system">
+  <div class="role">System</div>
+  {{.SystemPrompt}}
+</section>
+{{- end}}
+{{- range .Messages}}
+{{- if .Collapsed}}
+<details class="msg {{.Role}}">
+  <summary>{{.Title}}</summary>
+  {{.Body}}
+</details>
+{{- else}}
+<section class="msg {{.Role}}">
+  <div class="role">{{.Title}}</div>
+  {{.Body}}
+  {{- range .ToolCalls}}
+  <details class="call">
+    <summary>Tool call: {{.Name}}</summary>
+    {{.Arguments}}
+  </details>
+  {{- end}}
+</section>
+{{- end}}
+{{- end}}
+</main>
+</body>
+</html>
+`))
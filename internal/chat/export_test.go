@@ -0,0 +1,169 @@
+package chat
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/config"
+)
+
+// exportTestSession returns a session with a tool call, its result, which
+// holds a code fence of its own, and a result whose call is gone.
+func exportTestSession(t *testing.T) (*Session, []api.Message) {
+	t.Helper()
+	messages := []api.Message{
+		{Role: "user", Content: "Show main.go"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1", Name: "read_file", Arguments: `{"path":"main.go"}`}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "main.go:\n```go\npackage main\n```"},
+		{Role: "tool", ToolCallID: "call_gone", Content: "stale result"},
+		{Role: "assistant", Content: "It declares package main."},
+	}
+
+	s := NewSession(&config.ModelConfig{Name: "test-model"}, 50)
+	if err := s.SetSystemPrompt("Be brief."); err != nil {
+		t.Fatal(err)
+	}
+	s.RestoreMessages(messages)
+	s.RecordUsage("chat", api.Usage{InputTokens: 1200, OutputTokens: 340}, 0)
+	return s, messages
+}
+
+// exportSection is a "## " section of a Markdown export.
+type exportSection struct {
+	title string
+	body  string
+}
+
+// parseMarkdownExport splits a Markdown export into its front matter and
+// sections. Headings inside code fences are not expected in the test data.
+func parseMarkdownExport(t *testing.T, doc string) (map[string]string, []exportSection) {
+	t.Helper()
+	rest, ok := strings.CutPrefix(doc, "---\n")
+	if !ok {
+		t.Fatalf("export does not start with front matter:\n%s", doc)
+	}
+	front, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		t.Fatalf("front matter is not closed:\n%s", doc)
+	}
+
+	fields := make(map[string]string)
+	for _, line := range strings.Split(front, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			t.Fatalf("front matter line %q is not a key: value pair", line)
+		}
+		fields[key] = value
+	}
+
+	var sections []exportSection
+	for _, part := range strings.Split(body, "\n## ")[1:] {
+		title, text, _ := strings.Cut(part, "\n")
+		sections = append(sections, exportSection{title: title, body: strings.TrimSpace(text)})
+	}
+	return fields, sections
+}
+
+func TestMarkdownExportRoundTrip(t *testing.T) {
+	s, messages := exportTestSession(t)
+	path := filepath.Join(t.TempDir(), "chat.md")
+	before := time.Now().Truncate(time.Second)
+	if err := s.Export(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields, sections := parseMarkdownExport(t, string(data))
+
+	exported, err := time.Parse(time.RFC3339, fields["exported"])
+	if err != nil || exported.Before(before) {
+		t.Errorf("exported = %q, want an RFC 3339 time from the export", fields["exported"])
+	}
+	delete(fields, "exported")
+	wantFields := map[string]string{
+		"model":         "test-model",
+		"messages":      "5",
+		"input_tokens":  "1200",
+		"output_tokens": "340",
+	}
+	if !reflect.DeepEqual(fields, wantFields) {
+		t.Errorf("front matter = %v, want %v", fields, wantFields)
+	}
+
+	var titles []string
+	for _, section := range sections {
+		titles = append(titles, section.title)
+	}
+	wantTitles := []string{"System", "User", "Assistant", "Tool result: read_file", "Tool result", "Assistant"}
+	if !reflect.DeepEqual(titles, wantTitles) {
+		t.Fatalf("sections = %q, want %q", titles, wantTitles)
+	}
+
+	if sections[0].body != "Be brief." || sections[1].body != messages[0].Content || sections[5].body != messages[4].Content {
+		t.Errorf("prose sections = %q, %q, %q; want the system prompt and messages", sections[0].body, sections[1].body, sections[5].body)
+	}
+
+	// The tool call's arguments come back as the same JSON.
+	call, args, _ := strings.Cut(sections[2].body, "\n\n")
+	if call != "**Tool call:** `read_file`" {
+		t.Errorf("tool call line = %q", call)
+	}
+	args = strings.TrimSuffix(strings.TrimPrefix(args, "```json\n"), "\n```")
+	var got, want map[string]any
+	if err := json.Unmarshal([]byte(args), &got); err != nil {
+		t.Fatalf("tool call arguments %q: %v", args, err)
+	}
+	_ = json.Unmarshal([]byte(messages[1].ToolCalls[0].Arguments), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tool call arguments = %v, want %v", got, want)
+	}
+
+	// Tool results come back unchanged from inside their fences, which are
+	// longer than the fence in the result.
+	for i, msg := range messages[2:4] {
+		body := sections[3+i].body
+		lines := strings.Split(body, "\n")
+		fence := lines[0]
+		if strings.Trim(fence, "`") != "" || lines[len(lines)-1] != fence {
+			t.Fatalf("tool result is not fenced:\n%s", body)
+		}
+		if got := strings.Join(lines[1:len(lines)-1], "\n"); got != msg.Content {
+			t.Errorf("tool result = %q, want %q", got, msg.Content)
+		}
+	}
+	if lines := strings.Split(sections[3].body, "\n"); len(lines[0]) <= 3 {
+		t.Errorf("fence %q around a result holding ``` is too short", lines[0])
+	}
+}
+
+func TestHTMLExportLabelsToolResults(t *testing.T) {
+	s, _ := exportTestSession(t)
+	path := filepath.Join(t.TempDir(), "chat.html")
+	if err := s.Export(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+
+	for _, want := range []string{"test-model", "1200", "340", "Tool result: read_file", "stale result"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("HTML export lacks %q", want)
+		}
+	}
+	// The result's own fence is content; the longer one around it is not.
+	if strings.Contains(doc, "````") {
+		t.Error("HTML export contains the fence around a tool result")
+	}
+}
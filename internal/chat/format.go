@@ -8,6 +8,8 @@ import (
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/notexe/cli-chat/internal/ui"
+	"go.yaml.in/yaml/v3"
 )
 
 type FormatTemplate struct {
@@ -50,6 +52,62 @@ var formatTemplates = map[string]FormatTemplate{
 			"All fields except response and status are optional - only include them if relevant to the question.\n\n" +
 			"Remember: Return raw JSON directly, no markdown code blocks, no backticks.",
 	},
+	"yaml": {
+		Name:        "yaml",
+		Description: "Structured YAML output with the same fields as json",
+		Prompt: "IMPORTANT: Respond with raw YAML only. Do NOT wrap your response in markdown code blocks. Return a single YAML mapping starting with the response key.\n\n" +
+			"Always respond in valid YAML with the following structure:\n" +
+			"response: |\n" +
+			"  main answer/explanation text\n" +
+			"status: success|info|warning|error\n" +
+			"tags:\n" +
+			"  - tag1\n" +
+			"  - tag2\n" +
+			"steps:\n" +
+			"  - action: what was done\n" +
+			"    result: outcome or finding\n" +
+			"urls:\n" +
+			"  - title: reference title\n" +
+			"    url: https://example.com\n" +
+			"code:\n" +
+			"  - language: go\n" +
+			"    snippet: |\n" +
+			"      code example\n" +
+			"references:\n" +
+			"  - additional notes or references\n" +
+			"summary: brief one-line summary\n\n" +
+			"Field descriptions:\n" +
+			"- response: Main detailed answer (required)\n" +
+			"- status: success/info/warning/error (required)\n" +
+			"- tags: Relevant categorization tags (optional)\n" +
+			"- steps: Step-by-step breakdown for processes (optional)\n" +
+			"- urls: Relevant links with titles (optional)\n" +
+			"- code: Code examples with language specification (optional)\n" +
+			"- references: Additional notes, tips, or references (optional)\n" +
+			"- summary: One-line summary of the response (optional)\n\n" +
+			"All fields except response and status are optional - only include them if relevant to the question. " +
+			"Use block scalars (|) for multi-line text and quote values that contain a colon followed by a space.\n\n" +
+			"Remember: Return raw YAML directly, no markdown code blocks, no backticks.",
+	},
+	"review": {
+		Name:        "review",
+		Description: "Code review findings for CI integration",
+		Prompt: "IMPORTANT: Write the final review as raw JSON only, instead of Markdown. Do NOT wrap it in markdown code blocks. Return the raw JSON object directly starting with { and ending with }.\n\n" +
+			"Use exactly this structure:\n" +
+			"{\n" +
+			"  \"summary\": \"what the change does and the overall verdict\",\n" +
+			"  \"issues\": [\n" +
+			"    {\"file\": \"path/in/diff.go\", \"line\": 42, \"severity\": \"critical|high|medium|low\", \"message\": \"the problem and how to fix it\"}\n" +
+			"  ],\n" +
+			"  \"suggestions\": [\"improvement not tied to a single line\"]\n" +
+			"}\n\n" +
+			"Field descriptions:\n" +
+			"- summary: Short summary of the change and the review (required)\n" +
+			"- issues: Bugs, potential bugs and style problems; file and line refer to the new side of the diff, line is 0 if unknown\n" +
+			"- severity: critical = security hole or data loss, high = bug that will hit users, medium = likely bug or edge case, low = style or nitpick\n" +
+			"- suggestions: General improvement ideas\n\n" +
+			"Use empty arrays when there is nothing to report. Tool calls are unaffected; only the final answer must be JSON.",
+	},
 }
 
 func GetFormatTemplate(name string) (*FormatTemplate, error) {
@@ -60,21 +118,25 @@ func GetFormatTemplate(name string) (*FormatTemplate, error) {
 	return &template, nil
 }
 
+// JSONResponse is the structure of the "json" template. The "yaml"
+// template uses the same fields.
 type JSONResponse struct {
-	Response   string              `json:"response"`
-	Status     string              `json:"status"`
-	Tags       []string            `json:"tags,omitempty"`
-	Steps      []map[string]string `json:"steps,omitempty"`
-	URLs       []map[string]string `json:"urls,omitempty"`
-	Code       []map[string]string `json:"code,omitempty"`
-	References []string            `json:"references,omitempty"`
-	Summary    string              `json:"summary,omitempty"`
+	Response   string              `json:"response" yaml:"response"`
+	Status     string              `json:"status" yaml:"status"`
+	Tags       []string            `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Steps      []map[string]string `json:"steps,omitempty" yaml:"steps,omitempty"`
+	URLs       []map[string]string `json:"urls,omitempty" yaml:"urls,omitempty"`
+	Code       []map[string]string `json:"code,omitempty" yaml:"code,omitempty"`
+	References []string            `json:"references,omitempty" yaml:"references,omitempty"`
+	Summary    string              `json:"summary,omitempty" yaml:"summary,omitempty"`
 }
 
 func CleanMarkdownCodeBlocks(content string) string {
 	content = strings.TrimSpace(content)
 
 	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```yaml")
+	content = strings.TrimPrefix(content, "```yml")
 	content = strings.TrimPrefix(content, "```")
 
 	content = strings.TrimSuffix(content, "```")
@@ -216,69 +278,220 @@ func toSuperscript(s string) string {
 
 func ParseJSONResponse(content string) (*JSONResponse, error) {
 	var parsed JSONResponse
+	if err := unmarshalJSONObject(content, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// ParseYAMLResponse parses a response written with the "yaml" template.
+func ParseYAMLResponse(content string) (*JSONResponse, error) {
+	var parsed JSONResponse
+	if err := yaml.Unmarshal([]byte(CleanMarkdownCodeBlocks(content)), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return &parsed, nil
+}
+
+// ReviewIssue is a single finding of the "review" format template.
+type ReviewIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Review severities, lowest first.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// severityRanks orders severities, including common synonyms models use.
+var severityRanks = map[string]int{
+	"info":           0,
+	"nit":            0,
+	"minor":          0,
+	SeverityLow:      0,
+	"warning":        1,
+	SeverityMedium:   1,
+	"major":          2,
+	"error":          2,
+	SeverityHigh:     2,
+	"blocker":        3,
+	SeverityCritical: 3,
+}
+
+// SeverityRank returns the rank of a severity, 0 (low) to 3 (critical).
+// Unknown severities rank as medium so they are not silently ignored.
+func SeverityRank(severity string) int {
+	if rank, ok := severityRanks[strings.ToLower(strings.TrimSpace(severity))]; ok {
+		return rank
+	}
+	return severityRanks[SeverityMedium]
+}
+
+// ValidSeverity reports whether severity is one of the review severities.
+func ValidSeverity(severity string) bool {
+	switch severity {
+	case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		return true
+	}
+	return false
+}
 
+// ReviewResponse is the response structure of the "review" format template.
+type ReviewResponse struct {
+	Summary     string        `json:"summary"`
+	Issues      []ReviewIssue `json:"issues"`
+	Suggestions []string      `json:"suggestions"`
+}
+
+// ParseReviewResponse parses a response written with the "review" format
+// template. Missing lists are returned empty so the result always encodes
+// with all fields.
+func ParseReviewResponse(content string) (*ReviewResponse, error) {
+	var parsed ReviewResponse
+	if err := unmarshalJSONObject(content, &parsed); err != nil {
+		return nil, err
+	}
+	if parsed.Summary == "" {
+		return nil, fmt.Errorf("missing required field: summary")
+	}
+
+	if parsed.Issues == nil {
+		parsed.Issues = []ReviewIssue{}
+	}
+	if parsed.Suggestions == nil {
+		parsed.Suggestions = []string{}
+	}
+	return &parsed, nil
+}
+
+// unmarshalJSONObject decodes the outermost JSON object in content,
+// ignoring markdown code fences and text around it.
+func unmarshalJSONObject(content string, v any) error {
 	cleaned := CleanMarkdownCodeBlocks(content)
 
 	start := strings.Index(cleaned, "{")
 	end := strings.LastIndex(cleaned, "}")
 
 	if start == -1 || end == -1 || start >= end {
-		return nil, fmt.Errorf("no valid JSON object found in response")
+		return fmt.Errorf("no valid JSON object found in response")
 	}
 
 	jsonContent := cleaned[start : end+1]
 
-	if err := json.Unmarshal([]byte(jsonContent), &parsed); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	if err := json.Unmarshal([]byte(jsonContent), v); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	return &parsed, nil
+	return nil
 }
 
-var (
-	FieldNameStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("75")).
-			Bold(true)
-
-	ResponseStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+// ValidateJSONResponse strictly checks content against the JSON format
+// template: it must be a single JSON object (optionally fenced) with the
+// required "response" and "status" fields.
+func ValidateJSONResponse(content string) (*JSONResponse, error) {
+	cleaned := CleanMarkdownCodeBlocks(content)
+	if !strings.HasPrefix(cleaned, "{") || !strings.HasSuffix(cleaned, "}") {
+		return nil, fmt.Errorf("response is not a bare JSON object")
+	}
 
-	StatusSuccessStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("46")).
-			Bold(true)
+	parsed, err := ParseJSONResponse(cleaned)
+	if err != nil {
+		return nil, err
+	}
 
-	StatusInfoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")).
-			Bold(true)
+	if err := checkRequiredFields(parsed); err != nil {
+		return nil, err
+	}
 
-	StatusWarningStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("226")).
-			Bold(true)
+	return parsed, nil
+}
 
-	StatusErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+// ValidateYAMLResponse checks content against the YAML format template:
+// it must be a YAML mapping (optionally fenced) with the required
+// "response" and "status" fields.
+func ValidateYAMLResponse(content string) (*JSONResponse, error) {
+	parsed, err := ParseYAMLResponse(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRequiredFields(parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
 
-	TagStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("141"))
+// checkRequiredFields reports missing required fields of the json and
+// yaml templates.
+func checkRequiredFields(parsed *JSONResponse) error {
+	var missing []string
+	if parsed.Response == "" {
+		missing = append(missing, "response")
+	}
+	if parsed.Status == "" {
+		missing = append(missing, "status")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required field(s): %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
 
-	SummaryStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("117")).
-			Italic(true)
+// YAMLCorrectionPrompt builds the follow-up message asking the model to
+// resend its previous answer as valid YAML.
+func YAMLCorrectionPrompt(validationErr error) string {
+	return fmt.Sprintf("Your previous response was not valid for the required YAML format (%v). "+
+		"Resend the same answer as a single raw YAML mapping with at least the \"response\" and \"status\" keys, "+
+		"no markdown code blocks and no text outside the mapping.", validationErr)
+}
 
-	StepStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("228"))
+// JSONCorrectionPrompt builds the follow-up message asking the model to
+// resend its previous answer as valid JSON.
+func JSONCorrectionPrompt(validationErr error) string {
+	return fmt.Sprintf("Your previous response was not valid for the required JSON format (%v). "+
+		"Resend the same answer as a single raw JSON object with at least the \"response\" and \"status\" fields, "+
+		"no markdown code blocks and no text outside the object.", validationErr)
+}
 
-	URLStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("87")).
-			Underline(true)
+var (
+	FieldNameStyle     lipgloss.Style
+	ResponseStyle      lipgloss.Style
+	StatusSuccessStyle lipgloss.Style
+	StatusInfoStyle    lipgloss.Style
+	StatusWarningStyle lipgloss.Style
+	StatusErrorStyle   lipgloss.Style
+	TagStyle           lipgloss.Style
+	SummaryStyle       lipgloss.Style
+	StepStyle          lipgloss.Style
+	URLStyle           lipgloss.Style
+	CodeStyle          lipgloss.Style
+	ReferenceStyle     lipgloss.Style
+)
 
-	CodeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("120"))
+func init() {
+	ApplyTheme(ui.CurrentTheme())
+}
 
-	ReferenceStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("213"))
-)
+// ApplyTheme rebuilds the JSON table styles from the colors of t.
+func ApplyTheme(t ui.Theme) {
+	FieldNameStyle = lipgloss.NewStyle().Foreground(t.Field).Bold(true)
+	ResponseStyle = lipgloss.NewStyle().Foreground(t.Text)
+	StatusSuccessStyle = lipgloss.NewStyle().Foreground(t.StatusSuccess).Bold(true)
+	StatusInfoStyle = lipgloss.NewStyle().Foreground(t.StatusInfo).Bold(true)
+	StatusWarningStyle = lipgloss.NewStyle().Foreground(t.StatusWarning).Bold(true)
+	StatusErrorStyle = lipgloss.NewStyle().Foreground(t.StatusError).Bold(true)
+	TagStyle = lipgloss.NewStyle().Foreground(t.Tag)
+	SummaryStyle = lipgloss.NewStyle().Foreground(t.Summary).Italic(true)
+	StepStyle = lipgloss.NewStyle().Foreground(t.Step)
+	URLStyle = lipgloss.NewStyle().Foreground(t.URL).Underline(true)
+	CodeStyle = lipgloss.NewStyle().Foreground(t.Code)
+	ReferenceStyle = lipgloss.NewStyle().Foreground(t.Reference)
+}
 
 func FormatJSONTable(parsed *JSONResponse) string {
 	var result strings.Builder
@@ -371,3 +584,8 @@ func FormatJSONTable(parsed *JSONResponse) string {
 	return result.String()
 }
 
+// FormatYAMLTable renders a parsed YAML response. The yaml template shares
+// the json template's fields, so it uses the same layout.
+func FormatYAMLTable(parsed *JSONResponse) string {
+	return FormatJSONTable(parsed)
+}
@@ -19,32 +19,38 @@ type GitContext struct {
 
 // DetectGitContext gathers git info from the current working directory.
 func DetectGitContext() *GitContext {
+	return detectGitContext("")
+}
+
+// detectGitContext gathers git info from dir ("" = the working directory).
+func detectGitContext(dir string) *GitContext {
 	ctx := &GitContext{}
+	git := func(args ...string) *exec.Cmd { return gitCommand(dir, args...) }
 
 	// Check if we're in a git repo
-	if err := exec.Command("git", "rev-parse", "--is-inside-work-tree").Run(); err != nil {
+	if err := git("rev-parse", "--is-inside-work-tree").Run(); err != nil {
 		return ctx
 	}
 	ctx.IsRepo = true
 
 	// Working directory (git root)
-	if out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+	if out, err := git("rev-parse", "--show-toplevel").Output(); err == nil {
 		ctx.WorkDir = strings.TrimSpace(string(out))
 	}
 
 	// Current branch
-	if out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
+	if out, err := git("rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
 		ctx.Branch = strings.TrimSpace(string(out))
 	}
 
 	// Remote URL (origin)
-	if out, err := exec.Command("git", "config", "--get", "remote.origin.url").Output(); err == nil {
+	if out, err := git("config", "--get", "remote.origin.url").Output(); err == nil {
 		ctx.RemoteURL = strings.TrimSpace(string(out))
 		ctx.RepoOwner, ctx.RepoName = parseGitRemote(ctx.RemoteURL)
 	}
 
 	// Recent commits (last 5)
-	if out, err := exec.Command("git", "log", "--oneline", "-5").Output(); err == nil {
+	if out, err := git("log", "--oneline", "-5").Output(); err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		for _, line := range lines {
 			if line != "" {
@@ -56,6 +62,13 @@ func DetectGitContext() *GitContext {
 	return ctx
 }
 
+// gitCommand returns a git command run in dir ("" = the working directory).
+func gitCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd
+}
+
 // BuildGitContextPrompt creates a system prompt section with git/project info.
 func BuildGitContextPrompt(ctx *GitContext) string {
 	if ctx == nil || !ctx.IsRepo {
@@ -1,6 +1,8 @@
 package chat
 
 import (
+	"strings"
+
 	"github.com/notexe/cli-chat/internal/api"
 )
 
@@ -45,6 +47,41 @@ func (h *History) dropOrphanedToolMessages() {
 	}
 }
 
+// DropImages replaces the images of every message with a note naming
+// them, so an image is sent with the turn that attached it rather than
+// with every later request.
+func (h *History) DropImages() {
+	for i, msg := range h.messages {
+		h.messages[i] = withoutImages(msg)
+	}
+}
+
+// withoutImages returns msg with its images replaced by a note in its
+// content.
+func withoutImages(msg api.Message) api.Message {
+	if len(msg.Images) == 0 {
+		return msg
+	}
+	labels := make([]string, len(msg.Images))
+	for i, img := range msg.Images {
+		labels[i] = img.Label()
+	}
+	note := "[Image sent earlier, no longer attached: " + strings.Join(labels, ", ") + "]"
+	msg.Content = strings.TrimSpace(msg.Content + "\n\n" + note)
+	msg.Images = nil
+	return msg
+}
+
+// withoutAllImages returns a copy of messages without images, for
+// writing to disk.
+func withoutAllImages(messages []api.Message) []api.Message {
+	out := make([]api.Message, len(messages))
+	for i, msg := range messages {
+		out[i] = withoutImages(msg)
+	}
+	return out
+}
+
 func (h *History) GetAll() []api.Message {
 	return h.messages
 }
@@ -76,3 +113,48 @@ func (h *History) ReplaceWithSummary(summary api.Message, keepLast int) {
 	h.messages = append([]api.Message{summary}, kept...)
 	h.dropOrphanedToolMessages()
 }
+
+// TrimBefore drops the messages before index n, plus any tool messages
+// left without their tool call, and returns how many were removed.
+func (h *History) TrimBefore(n int) int {
+	n = min(max(n, 0), len(h.messages))
+	before := len(h.messages)
+	h.messages = append([]api.Message(nil), h.messages[n:]...)
+	h.dropOrphanedToolMessages()
+	return before - len(h.messages)
+}
+
+// HistoryMatch is a message found by History.Find.
+type HistoryMatch struct {
+	Index   int // Position in the history, starting at 0
+	Message api.Message
+}
+
+// Find returns messages whose content or tool call arguments contain term,
+// ignoring case, in history order.
+func (h *History) Find(term string) []HistoryMatch {
+	term = strings.ToLower(term)
+	if term == "" {
+		return nil
+	}
+
+	var matches []HistoryMatch
+	for i, msg := range h.messages {
+		if messageContains(msg, term) {
+			matches = append(matches, HistoryMatch{Index: i, Message: msg})
+		}
+	}
+	return matches
+}
+
+func messageContains(msg api.Message, lowerTerm string) bool {
+	if strings.Contains(strings.ToLower(msg.Content), lowerTerm) {
+		return true
+	}
+	for _, tc := range msg.ToolCalls {
+		if strings.Contains(strings.ToLower(tc.Name+" "+tc.Arguments), lowerTerm) {
+			return true
+		}
+	}
+	return false
+}
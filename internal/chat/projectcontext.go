@@ -0,0 +1,255 @@
+package chat
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	maxProjectLanguages = 3    // Languages named in the prompt
+	maxLanguageScan     = 5000 // Files counted when guessing languages
+	maxLanguageDepth    = 4    // Directory levels walked outside a git repo
+	maxReadmeSummary    = 500  // Characters of the README kept
+)
+
+// languageExtensions maps file extensions to the language they are
+// written in. Only source files count; docs and data are left out.
+var languageExtensions = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".swift":  "Swift",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".scala":  "Scala",
+	".sh":     "Shell",
+	".lua":    "Lua",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".hs":     "Haskell",
+	".zig":    "Zig",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+}
+
+// skippedDirs hold dependencies or build output rather than the project's
+// own code.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+	"venv":         true,
+}
+
+// ProjectContext describes the project the chat was started in.
+type ProjectContext struct {
+	Dir           string      // Project root: the git top level, or the directory itself
+	Name          string      // Repository name, or the directory name
+	Git           *GitContext // IsRepo is false outside a git repository
+	Languages     []string    // Main languages, most files first
+	ReadmeSummary string      // Opening paragraph of the README, trimmed
+}
+
+// DetectProjectContext gathers the repository name, branch, main languages
+// and a README summary for dir. Whatever can't be found is left empty.
+func DetectProjectContext(dir string) *ProjectContext {
+	pc := &ProjectContext{Dir: dir, Git: detectGitContext(dir)}
+	if pc.Git.WorkDir != "" {
+		pc.Dir = pc.Git.WorkDir
+	}
+
+	pc.Name = pc.Git.RepoName
+	if pc.Name == "" {
+		if abs, err := filepath.Abs(pc.Dir); err == nil {
+			pc.Name = filepath.Base(abs)
+		}
+	}
+
+	pc.Languages = detectLanguages(pc.Dir, pc.Git.IsRepo)
+	pc.ReadmeSummary = readmeSummary(pc.Dir)
+	return pc
+}
+
+// BuildProjectContextPrompt creates a system prompt section describing the
+// project, followed by the git section of BuildGitContextPrompt.
+func BuildProjectContextPrompt(pc *ProjectContext) string {
+	if pc == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("PROJECT CONTEXT (the directory the user started the chat in):\n")
+	if pc.Name != "" {
+		b.WriteString(fmt.Sprintf("- Project: %s\n", pc.Name))
+	}
+	if !pc.Git.IsRepo {
+		b.WriteString(fmt.Sprintf("- Directory: %s\n", pc.Dir))
+	}
+	if len(pc.Languages) > 0 {
+		b.WriteString(fmt.Sprintf("- Languages: %s\n", strings.Join(pc.Languages, ", ")))
+	}
+	if pc.ReadmeSummary != "" {
+		b.WriteString(fmt.Sprintf("- README: %s\n", pc.ReadmeSummary))
+	}
+
+	if git := BuildGitContextPrompt(pc.Git); git != "" {
+		b.WriteString("\n" + git)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// detectLanguages returns the languages with the most source files in dir.
+// In a git repository only tracked files count, so ignored build output
+// doesn't skew the result.
+func detectLanguages(dir string, isRepo bool) []string {
+	counts := make(map[string]int)
+	count := func(name string) {
+		if lang, ok := languageExtensions[strings.ToLower(filepath.Ext(name))]; ok {
+			counts[lang]++
+		}
+	}
+
+	if files, ok := gitTrackedFiles(dir, isRepo); ok {
+		for _, f := range files {
+			count(f)
+		}
+	} else {
+		seen := 0
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // Unreadable entries are skipped
+			}
+			if d.IsDir() {
+				rel, _ := filepath.Rel(dir, path)
+				if rel != "." && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] ||
+					strings.Count(rel, string(filepath.Separator)) >= maxLanguageDepth) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if seen++; seen > maxLanguageScan {
+				return filepath.SkipAll
+			}
+			count(d.Name())
+			return nil
+		})
+	}
+
+	languages := make([]string, 0, len(counts))
+	for lang := range counts {
+		languages = append(languages, lang)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if counts[languages[i]] != counts[languages[j]] {
+			return counts[languages[i]] > counts[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	if len(languages) > maxProjectLanguages {
+		languages = languages[:maxProjectLanguages]
+	}
+	return languages
+}
+
+// gitTrackedFiles lists up to maxLanguageScan files tracked in the
+// repository at dir.
+func gitTrackedFiles(dir string, isRepo bool) ([]string, bool) {
+	if !isRepo {
+		return nil, false
+	}
+	out, err := gitCommand(dir, "ls-files").Output()
+	if err != nil {
+		return nil, false
+	}
+
+	var files []string
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() && len(files) < maxLanguageScan {
+		if top, _, _ := strings.Cut(scanner.Text(), "/"); !skippedDirs[top] {
+			files = append(files, scanner.Text())
+		}
+	}
+	return files, true
+}
+
+// readmeSummary returns the first paragraph of prose in the README at the
+// top of dir, skipping headings, badges and HTML, cut to maxReadmeSummary
+// characters.
+func readmeSummary(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var data []byte
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(strings.ToLower(e.Name()), "readme") {
+			if data, err = os.ReadFile(filepath.Join(dir, e.Name())); err == nil {
+				break
+			}
+		}
+	}
+
+	var paragraph []string
+	inCode := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		switch {
+		case inCode:
+			continue
+		case line == "":
+			if len(paragraph) > 0 {
+				return trimSummary(strings.Join(paragraph, " "))
+			}
+		case strings.Trim(line, "=-") == "":
+			paragraph = nil // The lines above were a heading, or this is a rule
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "!["), strings.HasPrefix(line, "[!["),
+			strings.HasPrefix(line, "<"), strings.HasPrefix(line, "|"):
+			if len(paragraph) > 0 {
+				return trimSummary(strings.Join(paragraph, " "))
+			}
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	return trimSummary(strings.Join(paragraph, " "))
+}
+
+// trimSummary cuts s at a word boundary to at most maxReadmeSummary
+// characters.
+func trimSummary(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxReadmeSummary {
+		return s
+	}
+	cut := string(runes[:maxReadmeSummary])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:") + "…"
+}
@@ -0,0 +1,151 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadmeSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		want   string
+	}{
+		{
+			name:   "heading and badges are skipped",
+			readme: "# cli-chat\n\n[![CI](https://ci/badge.svg)](https://ci)\n![logo](logo.png)\n\nA terminal chat\nfor several providers.\n\nSecond paragraph.\n",
+			want:   "A terminal chat for several providers.",
+		},
+		{
+			name:   "setext heading",
+			readme: "cli-chat\n========\n\nChat from the terminal.\n",
+			want:   "Chat from the terminal.",
+		},
+		{
+			name:   "setext subheading directly above the text",
+			readme: "Overview\n--------\nChat from the terminal.\n",
+			want:   "Chat from the terminal.",
+		},
+		{
+			name:   "code fence before the prose",
+			readme: "```sh\ngo install ./cmd/chat\n\nchat --init\n```\n\nInstalls the chat command.\n",
+			want:   "Installs the chat command.",
+		},
+		{
+			name:   "paragraph ends at a heading or table",
+			readme: "Chat from the terminal.\n## Install\nRun make.\n",
+			want:   "Chat from the terminal.",
+		},
+		{
+			name:   "rule between HTML and prose",
+			readme: "<p align=\"center\"><img src=\"logo.png\"></p>\n\n---\n\nChat from the terminal.\n",
+			want:   "Chat from the terminal.",
+		},
+		{
+			name:   "only headings",
+			readme: "# cli-chat\n\n## Install\n",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(tt.readme), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := readmeSummary(dir); got != tt.want {
+				t.Errorf("readmeSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := readmeSummary(t.TempDir()); got != "" {
+		t.Errorf("readmeSummary() without a README = %q, want empty", got)
+	}
+}
+
+func TestTrimSummary(t *testing.T) {
+	long := strings.Repeat("word ", maxReadmeSummary/5) + "tail"
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"short text is kept", "A terminal chat.", "A terminal chat."},
+		{"exactly the limit is kept", strings.Repeat("é", maxReadmeSummary), strings.Repeat("é", maxReadmeSummary)},
+		{"cut at a word boundary", long, strings.TrimSpace(strings.Repeat("word ", maxReadmeSummary/5)) + "…"},
+		{"trailing punctuation is dropped", strings.Repeat("a", maxReadmeSummary-10) + "; " + strings.Repeat("b", 20), strings.Repeat("a", maxReadmeSummary-10) + "…"},
+		{"no space to cut at", strings.Repeat("a", maxReadmeSummary+10), strings.Repeat("a", maxReadmeSummary) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimSummary(tt.in); got != tt.want {
+				t.Errorf("trimSummary() = %q, want %q", got, tt.want)
+			}
+			if n := len([]rune(trimSummary(tt.in))); n > maxReadmeSummary+1 {
+				t.Errorf("trimSummary() has %d characters, want at most %d and an ellipsis", n, maxReadmeSummary)
+			}
+		})
+	}
+}
+
+func TestDetectLanguagesWalk(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "most files first, ties by name, at most three",
+			files: []string{"main.go", "a.go", "b.go", "x.py", "y.py", "app.ts", "lib.rs", "c.h", "README.md"},
+			want:  []string{"Go", "Python", "C"},
+		},
+		{
+			name:  "extensions are matched case-insensitively",
+			files: []string{"Main.GO", "util.go", "script.py"},
+			want:  []string{"Go", "Python"},
+		},
+		{
+			name: "dependencies, hidden and deep directories are skipped",
+			files: []string{
+				"main.py",
+				"node_modules/x/a.js", "node_modules/x/b.js",
+				"vendor/y/a.go", "vendor/y/b.go",
+				".cache/a.rs", ".cache/b.rs",
+				"a/b/c/d/ok.py",
+				"a/b/c/d/e/deep.rb", "a/b/c/d/e/deep2.rb",
+			},
+			want: []string{"Python"},
+		},
+		{
+			name:  "no source files",
+			files: []string{"README.md", "docs/guide.txt"},
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(f))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := detectLanguages(dir, false); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectLanguages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaKeywords mark a file as a JSON Schema rather than an example object.
+var schemaKeywords = []string{"$schema", "$defs", "properties", "items", "oneOf", "anyOf", "allOf"}
+
+// ResponseSchema is a user-defined response shape loaded by /format schema.
+type ResponseSchema struct {
+	Path   string
+	Schema string // Indented JSON Schema the responses are validated against
+
+	compiled *jsonschema.Schema
+	object   bool // The schema requires a JSON object at the top level
+}
+
+// LoadResponseSchema reads a JSON Schema from path. A file that is not a
+// schema is taken as an example object, and a schema requiring the same
+// keys and value types is derived from it.
+func LoadResponseSchema(path string) (*ResponseSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("schema file is not valid JSON: %w", err)
+	}
+
+	if !isJSONSchema(doc) {
+		doc = schemaFromExample(doc)
+	}
+
+	indented, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	// Compile from the marshalled form so numbers are json.Number, as the
+	// validator expects.
+	compiledDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(indented))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema path: %w", err)
+	}
+	url := "file://" + filepath.ToSlash(abs)
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, compiledDoc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	root, _ := doc.(map[string]any)
+	return &ResponseSchema{
+		Path:     path,
+		Schema:   string(indented),
+		compiled: compiled,
+		object:   root["type"] == "object",
+	}, nil
+}
+
+// RequiresObject reports whether responses must be a JSON object, which
+// allows the provider's native JSON mode.
+func (s *ResponseSchema) RequiresObject() bool {
+	return s.object
+}
+
+// isJSONSchema reports whether doc looks like a JSON Schema: an object with
+// schema keywords, or a "type" naming a JSON type.
+func isJSONSchema(doc any) bool {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return false
+	}
+	for _, key := range schemaKeywords {
+		if _, ok := obj[key]; ok {
+			return true
+		}
+	}
+	switch obj["type"] {
+	case "object", "array", "string", "number", "integer", "boolean", "null":
+		return true
+	}
+	return false
+}
+
+// schemaFromExample derives a schema from an example value. Object keys are
+// required, arrays take the shape of their first element.
+func schemaFromExample(v any) map[string]any {
+	switch v := v.(type) {
+	case map[string]any:
+		properties := make(map[string]any, len(v))
+		required := make([]string, 0, len(v))
+		for key, val := range v {
+			properties[key] = schemaFromExample(val)
+			required = append(required, key)
+		}
+		sort.Strings(required)
+		return map[string]any{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	case []any:
+		schema := map[string]any{"type": "array"}
+		if len(v) > 0 {
+			schema["items"] = schemaFromExample(v[0])
+		}
+		return schema
+	case string:
+		return map[string]any{"type": "string"}
+	case float64:
+		return map[string]any{"type": "number"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// Prompt returns the format prompt asking the model to follow the schema.
+func (s *ResponseSchema) Prompt() string {
+	return "IMPORTANT: Respond with raw JSON only. Do NOT wrap your response in markdown code blocks. " +
+		"Return a single JSON value directly, with no text before or after it.\n\n" +
+		"The response must be valid against this JSON Schema:\n" +
+		s.Schema + "\n\n" +
+		"Include every required field, use exactly the property names and types given, " +
+		"and do not add fields the schema does not allow."
+}
+
+// Validate checks content against the schema and returns the decoded value.
+func (s *ResponseSchema) Validate(content string) (any, error) {
+	cleaned := CleanMarkdownCodeBlocks(content)
+
+	value, err := jsonschema.UnmarshalJSON(strings.NewReader(cleaned))
+	if err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	if err := s.compiled.Validate(value); err != nil {
+		return nil, fmt.Errorf("response does not match the schema: %w", err)
+	}
+	return value, nil
+}
+
+// SchemaCorrectionPrompt builds the follow-up message asking the model to
+// resend its previous answer so that it matches the schema.
+func SchemaCorrectionPrompt(validationErr error) string {
+	return fmt.Sprintf("Your previous response did not match the required JSON Schema:\n%v\n\n"+
+		"Resend the same answer as a single raw JSON value that is valid against the schema, "+
+		"no markdown code blocks and no text outside the JSON.", validationErr)
+}
@@ -14,6 +14,10 @@ type Session struct {
 	history         *History
 	systemPrompt    string
 	formatPrompt    string
+	formatName      string // Template behind formatPrompt, e.g. "json" or "yaml"
+	responseSchema  *ResponseSchema
+	nativeJSON      bool   // Provider can enforce JSON output (response_format)
+	strictFormat    bool   // Validate formatted responses and offer a corrected re-request
 	toolsPrompt     string // Additional prompt for available tools guidance
 	projectPrompt   string // Auto-detected project/git context
 	askUserEnabled  bool   // Enable ask_user tool for interactive questions
@@ -22,12 +26,20 @@ type Session struct {
 	contextMgr      *ContextManager
 	lastInputTokens int  // Tokens from last API request (for tracking)
 	autoSummarize   bool // Whether to auto-summarize when threshold reached
+	summary         SummaryOptions
+	checkpoints     map[string]Checkpoint
+	totalUsage      api.Usage // Tokens used by all requests in this session
+	totalCost       float64   // Estimated USD cost of all requests
+	usageByOp       map[string]*OperationUsage
+	budget          Budget
+	budgetStep      Budget // Configured limits, added by ExtendBudget
 }
 
 type SessionData struct {
 	Messages     []api.Message `json:"messages"`
 	SystemPrompt string        `json:"system_prompt"`
 	FormatPrompt string        `json:"format_prompt"`
+	FormatName   string        `json:"format_name,omitempty"`
 	Timestamp    time.Time     `json:"timestamp"`
 }
 
@@ -37,6 +49,7 @@ func NewSession(cfg *config.ModelConfig, maxHistory int) *Session {
 		systemPrompt:   cfg.SystemPrompt,
 		config:         cfg,
 		contextMgr:     NewContextManager(0.70, 0.40), // Default thresholds
+		summary:        summaryOptionsFromConfig(nil),
 		autoSummarize:  true,
 		askUserEnabled: true, // Enable ask_user tool by default
 	}
@@ -50,6 +63,7 @@ func NewSessionWithContext(cfg *config.ModelConfig, maxHistory int, contextCfg *
 		config:         cfg,
 		autoSummarize:  true,
 		askUserEnabled: true, // Enable ask_user tool by default
+		summary:        summaryOptionsFromConfig(contextCfg),
 	}
 
 	if contextCfg != nil {
@@ -74,6 +88,28 @@ func (s *Session) AddUserMessage(content string) {
 	})
 }
 
+// AddUserMessageWithImages adds a user message carrying images for a
+// vision model.
+func (s *Session) AddUserMessageWithImages(content string, images []api.Image) {
+	s.history.Add(api.Message{
+		Role:    "user",
+		Content: content,
+		Images:  images,
+	})
+}
+
+// DropImages replaces the images in the history with notes naming them.
+// Call it once the turn that attached them is answered.
+func (s *Session) DropImages() {
+	s.history.DropImages()
+}
+
+// RestoreMessages replaces the history with messages, e.g. a copy of
+// GetMessages taken before a request that was aborted.
+func (s *Session) RestoreMessages(messages []api.Message) {
+	s.history.messages = messages
+}
+
 func (s *Session) AddAssistantMessage(content string) {
 	s.history.Add(api.Message{
 		Role:    "assistant",
@@ -109,8 +145,84 @@ func (s *Session) GetFormatPrompt() string {
 	return s.formatPrompt
 }
 
+// SetFormatName records which template the format prompt came from, so
+// responses are parsed with the matching parser.
+func (s *Session) SetFormatName(name string) {
+	s.formatName = name
+	if name != "schema" {
+		s.responseSchema = nil
+	}
+}
+
+// GetFormatName returns the active format template. Sessions saved before
+// templates were named default to "json" when a format prompt is set.
+func (s *Session) GetFormatName() string {
+	if s.formatName == "" && s.formatPrompt != "" {
+		return "json"
+	}
+	return s.formatName
+}
+
+// SetResponseSchema makes responses follow a user-defined schema.
+func (s *Session) SetResponseSchema(schema *ResponseSchema) error {
+	if err := s.SetFormatPrompt(schema.Prompt()); err != nil {
+		return err
+	}
+	s.formatName = "schema"
+	s.responseSchema = schema
+	return nil
+}
+
+// GetResponseSchema returns the active response schema, or nil.
+func (s *Session) GetResponseSchema() *ResponseSchema {
+	return s.responseSchema
+}
+
 func (s *Session) ClearFormatPrompt() {
 	s.formatPrompt = ""
+	s.formatName = ""
+	s.responseSchema = nil
+	s.strictFormat = false
+}
+
+// SetStrictFormat enables or disables strict validation of formatted responses.
+func (s *Session) SetStrictFormat(enabled bool) {
+	s.strictFormat = enabled
+}
+
+// IsStrictFormat returns whether formatted responses are strictly validated.
+func (s *Session) IsStrictFormat() bool {
+	return s.strictFormat
+}
+
+// SetNativeJSON records whether the provider can enforce JSON output, so
+// JSON templates are sent with the provider's native JSON mode.
+func (s *Session) SetNativeJSON(supported bool) {
+	s.nativeJSON = supported
+}
+
+// IsJSONMode returns whether requests use the provider's native JSON mode.
+func (s *Session) IsJSONMode() bool {
+	return s.responseFormat() == api.ResponseFormatJSON
+}
+
+// responseFormat returns the response format to request from the provider:
+// JSON mode while the JSON template, or a schema requiring an object, is
+// active. It follows the template, so restored sessions and checkpoints get
+// it back too. CleanMarkdownCodeBlocks stays the fallback elsewhere.
+func (s *Session) responseFormat() string {
+	if !s.nativeJSON || s.formatPrompt == "" {
+		return ""
+	}
+	switch s.GetFormatName() {
+	case "json":
+		return api.ResponseFormatJSON
+	case "schema":
+		if s.responseSchema != nil && s.responseSchema.RequiresObject() {
+			return api.ResponseFormatJSON
+		}
+	}
+	return ""
 }
 
 // SetToolsPrompt sets additional guidance for available tools.
@@ -171,6 +283,17 @@ func (s *Session) MessageCount() int {
 	return s.history.Size()
 }
 
+// TrimMessagesBefore drops the messages before index n and returns how many
+// were removed.
+func (s *Session) TrimMessagesBefore(n int) int {
+	return s.history.TrimBefore(n)
+}
+
+// FindMessages searches the history for term, ignoring case.
+func (s *Session) FindMessages(term string) []HistoryMatch {
+	return s.history.Find(term)
+}
+
 func (s *Session) BuildAPIRequest() api.MessageRequest {
 	return s.buildAPIRequest(true)
 }
@@ -194,19 +317,21 @@ func (s *Session) buildAPIRequest(includeClarify bool) api.MessageRequest {
 	systemPrompt := BuildSystemPrompt(s.systemPrompt, s.projectPrompt, s.toolsPrompt, s.formatPrompt, clarifyPrompt, askUserPrompt)
 
 	return api.MessageRequest{
-		Messages:    s.history.GetAll(),
-		System:      systemPrompt,
-		Model:       s.config.Name,
-		MaxTokens:   s.config.MaxTokens,
-		Temperature: s.config.Temperature,
+		Messages:       s.history.GetAll(),
+		System:         systemPrompt,
+		Model:          s.config.Name,
+		MaxTokens:      s.config.MaxTokens,
+		Temperature:    s.config.Temperature,
+		ResponseFormat: s.responseFormat(),
 	}
 }
 
 func (s *Session) Save(filepath string) error {
 	data := SessionData{
-		Messages:     s.history.GetAll(),
+		Messages:     withoutAllImages(s.history.GetAll()), // Images are sent once, not kept
 		SystemPrompt: s.systemPrompt,
 		FormatPrompt: s.formatPrompt,
+		FormatName:   s.formatName,
 		Timestamp:    time.Now(),
 	}
 
@@ -235,10 +360,12 @@ func (s *Session) Load(filepath string) error {
 
 	s.history.Clear()
 	for _, msg := range data.Messages {
-		s.history.Add(msg)
+		s.history.Add(withoutImages(msg))
 	}
 	s.systemPrompt = data.SystemPrompt
 	s.formatPrompt = data.FormatPrompt
+	s.formatName = data.FormatName
+	s.responseSchema = nil // Not persisted; only the schema prompt is kept
 
 	return nil
 }
@@ -248,6 +375,11 @@ func (s *Session) UpdateTokensFromResponse(usage api.Usage) {
 	s.lastInputTokens = usage.InputTokens
 }
 
+// TotalUsage returns the tokens used by all requests in this session.
+func (s *Session) TotalUsage() api.Usage {
+	return s.totalUsage
+}
+
 // ResetInputTokens resets the token counter (used after summarization).
 func (s *Session) ResetInputTokens() {
 	s.lastInputTokens = 0
@@ -283,6 +415,21 @@ func (s *Session) ApplySummary(summary api.Message, keptMessages int) {
 	s.history.ReplaceWithSummary(summary, keptMessages)
 }
 
+// GetSummaryOptions returns how the history is summarized.
+func (s *Session) GetSummaryOptions() SummaryOptions {
+	return s.summary
+}
+
+// SetKeepLastPairs sets how many recent user/assistant pairs are kept
+// verbatim when the history is summarized.
+func (s *Session) SetKeepLastPairs(pairs int) error {
+	if pairs < 1 {
+		return fmt.Errorf("keep must be at least 1 pair")
+	}
+	s.summary.KeepLastPairs = pairs
+	return nil
+}
+
 // SetAutoSummarize enables or disables automatic summarization.
 func (s *Session) SetAutoSummarize(enabled bool) {
 	s.autoSummarize = enabled
@@ -332,10 +479,11 @@ func (s *Session) BuildAPIRequestWithToolResults() api.MessageRequest {
 	systemPrompt := BuildSystemPrompt(s.systemPrompt, s.projectPrompt, s.toolsPrompt, s.formatPrompt, "")
 
 	return api.MessageRequest{
-		Messages:    s.history.GetAll(),
-		System:      systemPrompt,
-		Model:       s.config.Name,
-		MaxTokens:   s.config.MaxTokens,
-		Temperature: s.config.Temperature,
+		Messages:       s.history.GetAll(),
+		System:         systemPrompt,
+		Model:          s.config.Name,
+		MaxTokens:      s.config.MaxTokens,
+		Temperature:    s.config.Temperature,
+		ResponseFormat: s.responseFormat(),
 	}
 }
@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/config"
+)
+
+func TestImagesAreSentOnce(t *testing.T) {
+	s := NewSession(&config.ModelConfig{}, 50)
+	s.AddUserMessageWithImages("what is this?", []api.Image{{Data: "aGVsbG8=", MediaType: "image/png"}})
+
+	if !api.HasImages(s.BuildAPIRequest().Messages) {
+		t.Fatal("the turn that attached the image must send it")
+	}
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "aGVsbG8=") {
+		t.Error("saved history contains the image data")
+	}
+
+	s.AddAssistantMessage("a picture")
+	s.DropImages()
+	messages := s.BuildAPIRequest().Messages
+	if api.HasImages(messages) {
+		t.Error("later requests still carry the image")
+	}
+	for _, msg := range messages {
+		if msg.Role == "user" && !strings.Contains(msg.Content, "png image") {
+			t.Errorf("user message %q has no note about the image", msg.Content)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/config"
 )
 
 const summarizationPrompt = `Create a concise summary of the following conversation, preserving:
@@ -14,13 +15,111 @@ const summarizationPrompt = `Create a concise summary of the following conversat
 4. Unfinished tasks or pending items
 
 Format: Write as a coherent paragraph, not a list. Aim for ~25% of the original length.
-Focus on information that would be needed to continue this conversation naturally.
+Focus on information that would be needed to continue this conversation naturally.`
 
-Conversation to summarize:`
+// mergeSummariesPrompt asks for one summary from the partial summaries of
+// the map-reduce strategy.
+const mergeSummariesPrompt = `The following are summaries of consecutive parts of one conversation, oldest first.
+Merge them into a single summary following the same instructions. Keep later
+information when parts contradict each other.
 
-// BuildSummarizationRequest creates an API request for summarizing messages.
-func BuildSummarizationRequest(messages []api.Message, modelName string, maxTokens int, temperature float64) api.MessageRequest {
-	// Build conversation text from messages
+Instructions:
+%s
+
+Partial summaries:`
+
+// Defaults for SummaryOptions fields left at zero.
+const (
+	defaultKeepLastPairs = 4
+	defaultChunkTokens   = 8000
+)
+
+// SummaryOptions controls how the history is summarized.
+type SummaryOptions struct {
+	KeepLastPairs int    // Recent user/assistant pairs kept verbatim
+	Prompt        string // Summary instructions; empty uses the built-in prompt
+	Strategy      string // config.SummaryStrategySingle or config.SummaryStrategyMapReduce
+	ChunkTokens   int    // Map-reduce chunk size in estimated tokens
+}
+
+// summaryOptionsFromConfig returns the options in cfg with defaults for
+// unset fields. A nil cfg gives the defaults.
+func summaryOptionsFromConfig(cfg *config.ContextConfig) SummaryOptions {
+	opts := SummaryOptions{
+		KeepLastPairs: defaultKeepLastPairs,
+		Strategy:      config.SummaryStrategySingle,
+		ChunkTokens:   defaultChunkTokens,
+	}
+	if cfg == nil {
+		return opts
+	}
+	if cfg.KeepLastPairs > 0 {
+		opts.KeepLastPairs = cfg.KeepLastPairs
+	}
+	if cfg.Strategy != "" {
+		opts.Strategy = cfg.Strategy
+	}
+	if cfg.ChunkTokens > 0 {
+		opts.ChunkTokens = cfg.ChunkTokens
+	}
+	opts.Prompt = cfg.SummaryPrompt
+	return opts
+}
+
+// promptOrDefault returns the configured summary instructions.
+func (o SummaryOptions) promptOrDefault() string {
+	if strings.TrimSpace(o.Prompt) != "" {
+		return o.Prompt
+	}
+	return summarizationPrompt
+}
+
+// BuildSummarizationRequest creates an API request for summarizing messages
+// with the given instructions (empty uses the built-in prompt).
+func BuildSummarizationRequest(messages []api.Message, prompt, modelName string, maxTokens int, temperature float64) api.MessageRequest {
+	opts := SummaryOptions{Prompt: prompt}
+	userMessage := fmt.Sprintf("%s\n\nConversation to summarize:\n\n%s", opts.promptOrDefault(), conversationText(messages))
+	return summaryRequest(userMessage, modelName, maxTokens, temperature)
+}
+
+// BuildMergeSummariesRequest creates an API request that merges the partial
+// summaries of the map-reduce strategy into one.
+func BuildMergeSummariesRequest(summaries []string, prompt, modelName string, maxTokens int, temperature float64) api.MessageRequest {
+	opts := SummaryOptions{Prompt: prompt}
+
+	var parts strings.Builder
+	for i, summary := range summaries {
+		fmt.Fprintf(&parts, "Part %d:\n%s\n\n", i+1, strings.TrimSpace(summary))
+	}
+
+	userMessage := fmt.Sprintf(mergeSummariesPrompt, opts.promptOrDefault()) + "\n\n" + parts.String()
+	return summaryRequest(userMessage, modelName, maxTokens, temperature)
+}
+
+// ChunkMessages splits messages into consecutive chunks of at most
+// chunkTokens estimated tokens. A single larger message forms its own chunk.
+func ChunkMessages(messages []api.Message, chunkTokens int) [][]api.Message {
+	var chunks [][]api.Message
+	var current []api.Message
+	tokens := 0
+
+	for _, msg := range messages {
+		msgTokens := EstimateTokens(msg.Content)
+		if len(current) > 0 && tokens+msgTokens > chunkTokens {
+			chunks = append(chunks, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, msg)
+		tokens += msgTokens
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// conversationText renders messages as a transcript for summarization.
+func conversationText(messages []api.Message) string {
 	var conversationBuilder strings.Builder
 	for _, msg := range messages {
 		var role string
@@ -34,10 +133,10 @@ func BuildSummarizationRequest(messages []api.Message, modelName string, maxToke
 		}
 		conversationBuilder.WriteString(fmt.Sprintf("%s: %s\n\n", role, msg.Content))
 	}
+	return conversationBuilder.String()
+}
 
-	// Create the summarization request
-	userMessage := fmt.Sprintf("%s\n\n%s", summarizationPrompt, conversationBuilder.String())
-
+func summaryRequest(userMessage, modelName string, maxTokens int, temperature float64) api.MessageRequest {
 	return api.MessageRequest{
 		Messages: []api.Message{
 			{Role: "user", Content: userMessage},
@@ -0,0 +1,78 @@
+package codeindex
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// indexCache keeps loaded indexes in memory, keyed by index file path, so
+// repeated searches don't re-read and unmarshal index.json. An entry is
+// reused while the file's modification time and size are unchanged. The
+// zero value is ready to use.
+type indexCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedIndex
+}
+
+// cachedIndex is an index together with the file version it was read from.
+type cachedIndex struct {
+	index   *CodeIndex
+	modTime time.Time
+	size    int64
+}
+
+// load returns the index at path, reading it from disk only if it is not
+// cached or the file changed since it was read.
+func (c *indexCache) load(path string) (*CodeIndex, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read index file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[path]; ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.index, nil
+	}
+
+	// Stat before reading: if the file is replaced in between, the entry
+	// looks stale and is read again next time rather than the reverse.
+	index, err := LoadIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	c.set(path, index, info)
+	return index, nil
+}
+
+// put caches an index that was just saved to path.
+func (c *indexCache) put(path string, index *CodeIndex) {
+	info, err := os.Stat(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		delete(c.entries, path)
+		return
+	}
+	c.set(path, index, info)
+}
+
+// set stores an entry; c.mu must be held.
+func (c *indexCache) set(path string, index *CodeIndex, info os.FileInfo) {
+	if c.entries == nil {
+		c.entries = make(map[string]cachedIndex)
+	}
+	c.entries[path] = cachedIndex{index: index, modTime: info.ModTime(), size: info.Size()}
+}
+
+// clear drops all entries so the next load reads from disk.
+func (c *indexCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
@@ -30,6 +30,7 @@ type CodeChunk struct {
 
 // ChunkCode splits code into overlapping chunks.
 // It tries to split on natural boundaries (newlines, function boundaries).
+// Start and End are the file's numbers of a chunk's first and last lines.
 func ChunkCode(filePath string, content string, cfg ChunkConfig) []CodeChunk {
 	lines := strings.Split(content, "\n")
 	chunks := []CodeChunk{}
@@ -48,23 +49,15 @@ func ChunkCode(filePath string, content string, cfg ChunkConfig) []CodeChunk {
 
 		// If adding this line exceeds max size, save current chunk
 		if len(testChunk) > cfg.MaxChunkSize && currentChunk != "" {
-			chunks = append(chunks, CodeChunk{
-				FilePath: filePath,
-				Content:  strings.TrimSpace(currentChunk),
-				Start:    currentStart,
-				End:      lineNum - 1,
-				Index:    chunkIndex,
-			})
-			chunkIndex++
+			if chunk, ok := makeChunk(filePath, lines[currentStart-1:i], currentStart, chunkIndex); ok {
+				chunks = append(chunks, chunk)
+				chunkIndex++
+			}
 
 			// Start new chunk with overlap
 			overlapLines := getOverlapLines(lines, i, cfg.Overlap)
 			currentChunk = strings.Join(overlapLines, "\n")
-			if len(overlapLines) > 0 {
-				currentStart = lineNum - len(overlapLines) + 1
-			} else {
-				currentStart = lineNum
-			}
+			currentStart = lineNum - len(overlapLines)
 		}
 
 		// Add line to current chunk
@@ -75,19 +68,38 @@ func ChunkCode(filePath string, content string, cfg ChunkConfig) []CodeChunk {
 	}
 
 	// Add final chunk
-	if strings.TrimSpace(currentChunk) != "" {
-		chunks = append(chunks, CodeChunk{
-			FilePath: filePath,
-			Content:  strings.TrimSpace(currentChunk),
-			Start:    currentStart,
-			End:      len(lines),
-			Index:    chunkIndex,
-		})
+	if chunk, ok := makeChunk(filePath, lines[currentStart-1:], currentStart, chunkIndex); ok {
+		chunks = append(chunks, chunk)
 	}
 
 	return chunks
 }
 
+// makeChunk returns the chunk holding lines, the first of which is line
+// start of the file. Blank lines at either end are left out and Start and
+// End moved past them, so the numbers still match the file. It reports
+// false if all the lines are blank.
+func makeChunk(filePath string, lines []string, start, index int) (CodeChunk, bool) {
+	first, last := 0, len(lines)-1
+	for first <= last && strings.TrimSpace(lines[first]) == "" {
+		first++
+	}
+	for last >= first && strings.TrimSpace(lines[last]) == "" {
+		last--
+	}
+	if first > last {
+		return CodeChunk{}, false
+	}
+
+	return CodeChunk{
+		FilePath: filePath,
+		Content:  strings.Join(lines[first:last+1], "\n"),
+		Start:    start + first,
+		End:      start + last,
+		Index:    index,
+	}, true
+}
+
 // getOverlapLines returns the last N characters worth of lines for overlap.
 func getOverlapLines(lines []string, currentIndex int, overlapSize int) []string {
 	if currentIndex <= 0 || overlapSize <= 0 {
@@ -153,31 +165,14 @@ func ShouldIndexFile(filename string) bool {
 	return false
 }
 
-// CleanCode removes excessive whitespace while preserving code structure.
+// CleanCode removes trailing whitespace while preserving code structure.
+// Every line is kept, even in long runs of blank lines, so line numbers in
+// the cleaned code are those of the file.
 func CleanCode(code string) string {
 	lines := strings.Split(code, "\n")
-	cleaned := []string{}
-
-	for _, line := range lines {
+	for i, line := range lines {
 		// Trim trailing whitespace but preserve indentation
-		trimmed := strings.TrimRightFunc(line, unicode.IsSpace)
-		cleaned = append(cleaned, trimmed)
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
 	}
-
-	// Remove excessive blank lines (more than 2 consecutive)
-	result := []string{}
-	blankCount := 0
-	for _, line := range cleaned {
-		if strings.TrimSpace(line) == "" {
-			blankCount++
-			if blankCount <= 2 {
-				result = append(result, line)
-			}
-		} else {
-			blankCount = 0
-			result = append(result, line)
-		}
-	}
-
-	return strings.Join(result, "\n")
+	return strings.Join(lines, "\n")
 }
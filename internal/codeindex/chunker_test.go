@@ -0,0 +1,50 @@
+package codeindex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestChunkLineNumbers checks that the line numbers shown for each chunk are
+// those of the file, across leading blank lines, long blank runs and
+// overlapping chunks.
+func TestChunkLineNumbers(t *testing.T) {
+	source := "\n\n\npackage main   \n\nimport \"fmt\"\n\n\n\n\n" +
+		"func main() {\n\tfmt.Println(\"hello\")\n}\n\n\n\n\n\n" +
+		"func helper() int {\n\treturn 42\n}\n\n\n"
+	fileLines := strings.Split(source, "\n")
+
+	cfg := ChunkConfig{MaxChunkSize: 40, Overlap: 15}
+	chunks := ChunkCode("main.go", CleanCode(source), cfg)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want several", len(chunks))
+	}
+
+	for _, chunk := range chunks {
+		if chunk.Start < 1 || chunk.End > len(fileLines) || chunk.Start > chunk.End {
+			t.Fatalf("chunk %d spans lines %d-%d of %d", chunk.Index, chunk.Start, chunk.End, len(fileLines))
+		}
+		lines := strings.Split(chunk.Content, "\n")
+		if got := chunk.Start + len(lines) - 1; got != chunk.End {
+			t.Errorf("chunk %d has %d lines from %d, but ends at %d", chunk.Index, len(lines), chunk.Start, chunk.End)
+		}
+		for i, line := range lines {
+			if want := strings.TrimRight(fileLines[chunk.Start-1+i], " \t"); line != want {
+				t.Errorf("chunk %d line %d = %q, file has %q", chunk.Index, chunk.Start+i, line, want)
+			}
+		}
+
+		snippet := strings.Split(highlightSnippet(chunk.Content, chunk.Start, nil), "\n")
+		for _, row := range snippet {
+			var n int
+			if _, err := fmt.Sscanf(strings.TrimSpace(row), "%d |", &n); err != nil {
+				t.Fatalf("snippet row %q: %v", row, err)
+			}
+			_, text, _ := strings.Cut(row, " | ")
+			if want := strings.TrimRight(fileLines[n-1], " \t"); text != want {
+				t.Errorf("snippet line %d = %q, file has %q", n, text, want)
+			}
+		}
+	}
+}
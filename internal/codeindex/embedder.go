@@ -0,0 +1,141 @@
+package codeindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Embedding providers for IndexerConfig.EmbeddingProvider.
+const (
+	EmbeddingProviderOllama = "ollama" // Local Ollama (default)
+	EmbeddingProviderOpenAI = "openai" // OpenAI-compatible /embeddings endpoint
+)
+
+// Embedder generates embedding vectors for code chunks and queries.
+type Embedder interface {
+	GenerateEmbedding(ctx context.Context, text string) ([]float64, error)
+	CheckHealth(ctx context.Context) error
+
+	// Provider and Model identify the embedding space; they are stored in
+	// the index so searches can detect a mismatch.
+	Provider() string
+	Model() string
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible embeddings endpoint, which
+// covers OpenAI itself and hosted services with a compatible API (e.g.
+// Cohere's compatibility API).
+type OpenAIEmbedder struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder creates a client for the embeddings endpoint at
+// baseURL (e.g. https://api.openai.com/v1).
+func NewOpenAIEmbedder(baseURL, apiKey, model string) *OpenAIEmbedder {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+
+	return &OpenAIEmbedder{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// openAIEmbeddingRequest represents the OpenAI embeddings request.
+type openAIEmbeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+// openAIEmbeddingResponse represents the OpenAI embeddings response.
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GenerateEmbedding generates an embedding vector for the given text.
+func (c *OpenAIEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	body, err := json.Marshal(openAIEmbeddingRequest{
+		Model: c.model,
+		Input: text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var embedResp openAIEmbeddingResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(respBody, &embedResp) == nil && embedResp.Error != nil {
+			return nil, fmt.Errorf("embeddings API error (status %d): %s", resp.StatusCode, embedResp.Error.Message)
+		}
+		return nil, fmt.Errorf("embeddings API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.Unmarshal(respBody, &embedResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	if len(embedResp.Data) == 0 || len(embedResp.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("empty embedding returned")
+	}
+
+	return embedResp.Data[0].Embedding, nil
+}
+
+// CheckHealth checks that the endpoint accepts the key and model.
+func (c *OpenAIEmbedder) CheckHealth(ctx context.Context) error {
+	if _, err := c.GenerateEmbedding(ctx, "test"); err != nil {
+		return fmt.Errorf("embeddings health check failed: %w (check EMBEDDING_URL, EMBEDDING_API_KEY and model '%s')", err, c.model)
+	}
+	return nil
+}
+
+// Provider returns EmbeddingProviderOpenAI.
+func (c *OpenAIEmbedder) Provider() string {
+	return EmbeddingProviderOpenAI
+}
+
+// Model returns the embedding model name.
+func (c *OpenAIEmbedder) Model() string {
+	return c.model
+}
@@ -0,0 +1,176 @@
+package codeindex
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// languageExtensions maps the language names accepted by semantic_search to
+// the file extensions ShouldIndexFile indexes for them.
+var languageExtensions = map[string][]string{
+	"go":         {".go"},
+	"javascript": {".js", ".jsx"},
+	"typescript": {".ts", ".tsx"},
+	"python":     {".py"},
+	"java":       {".java"},
+	"c":          {".c", ".h"},
+	"cpp":        {".cpp", ".hpp", ".h"},
+	"rust":       {".rs"},
+	"ruby":       {".rb"},
+	"php":        {".php"},
+	"csharp":     {".cs"},
+	"swift":      {".swift"},
+	"kotlin":     {".kt"},
+	"scala":      {".scala"},
+	"shell":      {".sh", ".bash"},
+	"sql":        {".sql"},
+	"proto":      {".proto"},
+	"thrift":     {".thrift"},
+	"graphql":    {".graphql"},
+	"yaml":       {".yaml", ".yml"},
+	"json":       {".json"},
+	"xml":        {".xml"},
+	"markdown":   {".md"},
+}
+
+// languageAliases are alternative names for languageExtensions keys.
+var languageAliases = map[string]string{
+	"js":   "javascript",
+	"ts":   "typescript",
+	"py":   "python",
+	"c++":  "cpp",
+	"rs":   "rust",
+	"rb":   "ruby",
+	"c#":   "csharp",
+	"cs":   "csharp",
+	"kt":   "kotlin",
+	"sh":   "shell",
+	"bash": "shell",
+	"yml":  "yaml",
+	"md":   "markdown",
+}
+
+// ResultFilter restricts search results to files matching a path glob
+// and/or a language. The zero value and nil match everything.
+type ResultFilter struct {
+	PathGlob   string
+	Language   string
+	extensions map[string]bool
+}
+
+// NewResultFilter validates pathGlob and resolves language, which may be a
+// language name ("go", "typescript"), an alias ("ts") or an extension
+// (".tsx").
+//
+// Glob semantics: a pattern without "/" matches the file name at any depth
+// ("*_test.go"). A pattern with "/" matches trailing path segments, so
+// relative patterns work against the absolute paths in the index
+// ("internal/api/*.go"); a leading "/" anchors it at the filesystem root.
+// "*", "?" and "[...]" match within one segment as in path.Match, "**"
+// matches any number of segments, and a trailing "/" matches everything
+// below a directory ("internal/codeindex/").
+func NewResultFilter(pathGlob, language string) (*ResultFilter, error) {
+	f := &ResultFilter{PathGlob: filepath.ToSlash(strings.TrimSpace(pathGlob))}
+
+	if f.PathGlob != "" {
+		if strings.HasSuffix(f.PathGlob, "/") {
+			f.PathGlob += "**"
+		}
+		for _, segment := range strings.Split(f.PathGlob, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid path_glob %q: %w", pathGlob, err)
+			}
+		}
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language != "" {
+		f.Language = language
+		f.extensions = make(map[string]bool)
+		if strings.HasPrefix(language, ".") {
+			f.extensions[language] = true
+			return f, nil
+		}
+		if alias, ok := languageAliases[language]; ok {
+			language = alias
+		}
+		exts, ok := languageExtensions[language]
+		if !ok {
+			return nil, fmt.Errorf("unknown language %q (supported: %s)", f.Language, supportedLanguages())
+		}
+		for _, ext := range exts {
+			f.extensions[ext] = true
+		}
+	}
+
+	return f, nil
+}
+
+// Match reports whether filePath passes the filter.
+func (f *ResultFilter) Match(filePath string) bool {
+	if f == nil {
+		return true
+	}
+	if f.extensions != nil && !f.extensions[strings.ToLower(filepath.Ext(filePath))] {
+		return false
+	}
+	if f.PathGlob != "" && !matchGlob(f.PathGlob, filepath.ToSlash(filePath)) {
+		return false
+	}
+	return true
+}
+
+// matchGlob matches a slash-separated path against pattern; see
+// NewResultFilter for the semantics.
+func matchGlob(pattern, filePath string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(filePath))
+		return ok
+	}
+
+	patternSegments := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.TrimPrefix(filePath, "/"), "/")
+	if strings.HasPrefix(pattern, "/") {
+		return matchSegments(patternSegments, pathSegments)
+	}
+	for i := range pathSegments {
+		if matchSegments(patternSegments, pathSegments[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// matches zero or more segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
+
+// supportedLanguages returns the language names, sorted, for error messages.
+func supportedLanguages() string {
+	names := make([]string, 0, len(languageExtensions))
+	for name := range languageExtensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,108 @@
+package codeindex
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResultFilterMatch(t *testing.T) {
+	tests := []struct {
+		glob, language string
+		path           string
+		want           bool
+	}{
+		// No "/": the file name at any depth
+		{"*_test.go", "", "/repo/internal/api/client_test.go", true},
+		{"*_test.go", "", "/repo/internal/api/client.go", false},
+		{"client.go", "", "/repo/internal/api/client.go", true},
+
+		// With "/": trailing path segments
+		{"internal/api/*.go", "", "/repo/internal/api/client.go", true},
+		{"internal/api/*.go", "", "/repo/internal/api/v2/client.go", false},
+		{"api/*.go", "", "/repo/internal/api/client.go", true},
+		{"internal/api/*.go", "", "/repo/internal/apis/client.go", false},
+
+		// Leading "/": anchored at the root
+		{"/repo/internal/*/*.go", "", "/repo/internal/api/client.go", true},
+		{"/internal/api/*.go", "", "/repo/internal/api/client.go", false},
+
+		// "**" spans segments, including none
+		{"internal/**/*_test.go", "", "/repo/internal/api/v2/client_test.go", true},
+		{"internal/**/*_test.go", "", "/repo/internal/client_test.go", true},
+		{"internal/**", "", "/repo/cmd/main.go", false},
+
+		// Trailing "/": everything below a directory
+		{"internal/codeindex/", "", "/repo/internal/codeindex/index.go", true},
+		{"internal/codeindex/", "", "/repo/internal/codeindex/sub/x.go", true},
+		{"internal/codeindex/", "", "/repo/internal/codeindexer/x.go", false},
+
+		// "?" and "[...]" stay within a segment
+		{"v?/*.go", "", "/repo/api/v2/client.go", true},
+		{"[ab]*.go", "", "/repo/api.go", true},
+		{"[ab]*.go", "", "/repo/client.go", false},
+
+		// Languages, aliases and extensions
+		{"", "go", "/repo/main.go", true},
+		{"", "go", "/repo/main.py", false},
+		{"", "ts", "/repo/app.tsx", true},
+		{"", "TypeScript", "/repo/app.ts", true},
+		{"", ".tsx", "/repo/app.ts", false},
+		{"", "cpp", "/repo/lib.h", true},
+		{"", "go", "/repo/MAIN.GO", true},
+
+		// Both must match
+		{"internal/**", "go", "/repo/internal/api/client.go", true},
+		{"internal/**", "go", "/repo/internal/api/schema.sql", false},
+		{"internal/**", "go", "/repo/cmd/main.go", false},
+
+		// Empty filter
+		{"", "", "/repo/anything.bin", true},
+	}
+
+	for _, tt := range tests {
+		f, err := NewResultFilter(tt.glob, tt.language)
+		if err != nil {
+			t.Fatalf("NewResultFilter(%q, %q): %v", tt.glob, tt.language, err)
+		}
+		if got := f.Match(tt.path); got != tt.want {
+			t.Errorf("glob %q language %q: Match(%q) = %v, want %v", tt.glob, tt.language, tt.path, got, tt.want)
+		}
+	}
+
+	var nilFilter *ResultFilter
+	if !nilFilter.Match("/repo/main.go") {
+		t.Error("nil filter rejected a path")
+	}
+}
+
+func TestNewResultFilterErrors(t *testing.T) {
+	if _, err := NewResultFilter("internal/[", ""); err == nil {
+		t.Error("invalid glob accepted")
+	}
+	if _, err := NewResultFilter("", "cobol"); err == nil {
+		t.Error("unknown language accepted")
+	}
+}
+
+func TestSearchFiltersBeforeRanking(t *testing.T) {
+	index := NewCodeIndex("fake", "fake-model")
+	// Ten Python chunks closer to the query than the one Go chunk
+	for i := range 10 {
+		index.AddChunk(CodeChunk{FilePath: "/repo/script.py", Start: i}, []float64{1, 0.01})
+	}
+	index.AddChunk(CodeChunk{FilePath: "/repo/main.go"}, []float64{1, 1})
+
+	filter, err := NewResultFilter("", "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := index.Search(context.Background(), []float64{1, 0}, 3, filter)
+	if len(results) != 1 || results[0].Chunk.FilePath != "/repo/main.go" {
+		t.Fatalf("results = %+v, want only the Go chunk", results)
+	}
+
+	if results := index.Search(context.Background(), []float64{1, 0}, 3, nil); len(results) != 3 {
+		t.Errorf("unfiltered search returned %d results, want 3", len(results))
+	}
+}
@@ -0,0 +1,90 @@
+package codeindex
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// truncationMarker is the last line of a snippet cut short by the search
+// tool's max_content_length.
+const truncationMarker = "..."
+
+// queryStopWords are query words too common to be worth highlighting.
+var queryStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true,
+	"this": true, "how": true, "what": true, "where": true, "which": true,
+	"does": true, "are": true, "from": true, "into": true, "code": true,
+}
+
+// queryTerms splits a search query into the lowercase words worth
+// highlighting in results, skipping short words and stop words.
+func queryTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	seen := make(map[string]bool)
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if len(w) < 3 || queryStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// matchedTerms returns the terms that occur in content, ignoring case.
+func matchedTerms(content string, terms []string) []string {
+	lower := strings.ToLower(content)
+	var matched []string
+	for _, term := range terms {
+		if strings.Contains(lower, term) {
+			matched = append(matched, term)
+		}
+	}
+	return matched
+}
+
+// highlightSnippet numbers the lines of a chunk starting at start and
+// marks the lines that contain a query term with ">" in the gutter:
+//
+//	  41 | func (idx *Indexer) Search(...)
+//	> 42 |     queryEmbedding, err := ...
+func highlightSnippet(content string, start int, terms []string) string {
+	lines := strings.Split(content, "\n")
+	truncated := len(lines) > 1 && lines[len(lines)-1] == truncationMarker
+	if truncated {
+		lines = lines[:len(lines)-1]
+	}
+
+	width := len(fmt.Sprint(start + len(lines) - 1))
+
+	var builder strings.Builder
+	for i, line := range lines {
+		marker := " "
+		if len(matchedTerms(line, terms)) > 0 {
+			marker = ">"
+		}
+		builder.WriteString(fmt.Sprintf("%s %*d | %s\n", marker, width, start+i, line))
+	}
+	if truncated {
+		builder.WriteString(truncationMarker + "\n")
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// writeSnippet writes a result's location anchor on its own line, the
+// query terms it matched and its highlighted code.
+func writeSnippet(builder *strings.Builder, chunk CodeChunk, terms []string) {
+	builder.WriteString(fmt.Sprintf("%s:%d\n", chunk.FilePath, chunk.Start))
+	if matched := matchedTerms(chunk.Content, terms); len(matched) > 0 {
+		builder.WriteString(fmt.Sprintf("Matched: %s\n", strings.Join(matched, ", ")))
+	}
+	builder.WriteString("```\n")
+	builder.WriteString(highlightSnippet(chunk.Content, chunk.Start, terms))
+	builder.WriteString("\n```\n\n")
+}
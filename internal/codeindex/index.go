@@ -1,16 +1,19 @@
 package codeindex
 
 import (
+	"container/heap"
 	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 )
 
 // IndexedChunk represents a code chunk with its embedding.
+// Embeddings are stored L2-normalized so search is a plain dot product.
 type IndexedChunk struct {
 	Chunk     CodeChunk `json:"chunk"`
 	Embedding []float64 `json:"embedding"`
@@ -19,14 +22,18 @@ type IndexedChunk struct {
 // CodeIndex manages the searchable code index.
 type CodeIndex struct {
 	Chunks    []IndexedChunk `json:"chunks"`
+	Provider  string         `json:"provider,omitempty"` // Embedding provider (empty = ollama)
 	ModelName string         `json:"model_name"`
+	Dimension int            `json:"dimension,omitempty"` // Embedding vector size
 	indexPath string
 }
 
-// NewCodeIndex creates a new empty code index.
-func NewCodeIndex(modelName string) *CodeIndex {
+// NewCodeIndex creates a new empty code index for embeddings from the
+// given provider and model.
+func NewCodeIndex(provider, modelName string) *CodeIndex {
 	return &CodeIndex{
 		Chunks:    []IndexedChunk{},
+		Provider:  provider,
 		ModelName: modelName,
 	}
 }
@@ -40,14 +47,53 @@ func LoadIndex(path string) (*CodeIndex, error) {
 
 	var idx CodeIndex
 	if err := json.Unmarshal(data, &idx); err != nil {
-		return nil, fmt.Errorf("unmarshal index: %w", err)
+		return nil, fmt.Errorf("unmarshal index %s (file may be corrupt, re-run index_directory to rebuild): %w", path, err)
+	}
+
+	if err := idx.validateDimensions(); err != nil {
+		return nil, fmt.Errorf("invalid index %s (re-run index_directory to rebuild): %w", path, err)
+	}
+
+	// Older indexes did not record the dimension; derive it from the data.
+	if idx.Dimension == 0 && len(idx.Chunks) > 0 {
+		idx.Dimension = len(idx.Chunks[0].Embedding)
+	}
+
+	// Older indexes stored raw vectors; normalizing is idempotent for newer ones.
+	for i := range idx.Chunks {
+		normalize(idx.Chunks[i].Embedding)
 	}
 
 	idx.indexPath = path
 	return &idx, nil
 }
 
+// validateDimensions checks that every chunk has a non-empty embedding
+// of the same dimension.
+func (idx *CodeIndex) validateDimensions() error {
+	dim := 0
+	for i, indexed := range idx.Chunks {
+		if len(indexed.Embedding) == 0 {
+			return fmt.Errorf("chunk %d (%s) has no embedding", i, indexed.Chunk.FilePath)
+		}
+		if dim == 0 {
+			dim = len(indexed.Embedding)
+			if idx.Dimension != 0 && idx.Dimension != dim {
+				return fmt.Errorf("recorded dimension %d does not match embedding dimension %d", idx.Dimension, dim)
+			}
+			continue
+		}
+		if len(indexed.Embedding) != dim {
+			return fmt.Errorf("chunk %d (%s) has embedding dimension %d, expected %d",
+				i, indexed.Chunk.FilePath, len(indexed.Embedding), dim)
+		}
+	}
+	return nil
+}
+
 // Save saves the index to disk.
+// The index is written to a temporary file in the same directory and then
+// renamed into place, so an interrupted save never leaves a truncated file.
 func (idx *CodeIndex) Save(path string) error {
 	idx.indexPath = path
 
@@ -62,74 +108,187 @@ func (idx *CodeIndex) Save(path string) error {
 		return fmt.Errorf("marshal index: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp index file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
 		return fmt.Errorf("write index file: %w", err)
 	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("sync index file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close index file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("chmod index file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("replace index file: %w", err)
+	}
 
 	return nil
 }
 
-// AddChunk adds a chunk with its embedding to the index.
+// AddChunk adds a chunk with its embedding to the index. The index keeps a
+// normalized copy; the caller's slice is left unchanged.
 func (idx *CodeIndex) AddChunk(chunk CodeChunk, embedding []float64) {
+	if idx.Dimension == 0 {
+		idx.Dimension = len(embedding)
+	}
+	embedding = slices.Clone(embedding)
+	normalize(embedding)
 	idx.Chunks = append(idx.Chunks, IndexedChunk{
 		Chunk:     chunk,
 		Embedding: embedding,
 	})
 }
 
+// CheckModel returns an error if the index was built with a different
+// embedding provider or model. Indexes without a recorded model pass, and
+// indexes without a recorded provider were built with Ollama.
+func (idx *CodeIndex) CheckModel(provider, modelName string) error {
+	indexProvider := idx.Provider
+	if indexProvider == "" {
+		indexProvider = EmbeddingProviderOllama
+	}
+	if indexProvider != provider && idx.ModelName != "" {
+		return fmt.Errorf("embedding provider mismatch: index was built with %s %q but queries use %s %q; "+
+			"re-run index_directory to rebuild the index or set EMBEDDING_PROVIDER=%s",
+			indexProvider, idx.ModelName, provider, modelName, indexProvider)
+	}
+	if idx.ModelName == "" || idx.ModelName == modelName {
+		return nil
+	}
+	return fmt.Errorf("embedding model mismatch: index was built with %q (dimension %d) but queries use %q; "+
+		"re-run index_directory to rebuild the index or set EMBEDDING_MODEL=%s",
+		idx.ModelName, idx.Dimension, modelName, idx.ModelName)
+}
+
+// CheckDimension returns an error if a query embedding does not match the
+// dimension of the indexed embeddings.
+func (idx *CodeIndex) CheckDimension(modelName string, dim int) error {
+	if idx.Dimension == 0 || idx.Dimension == dim {
+		return nil
+	}
+	return fmt.Errorf("embedding dimension mismatch: index was built with %q (dimension %d) but %q returned dimension %d; "+
+		"re-run index_directory to rebuild the index or switch back to %s",
+		idx.ModelName, idx.Dimension, modelName, dim, idx.ModelName)
+}
+
 // SearchResult represents a search result with similarity score.
 type SearchResult struct {
 	Chunk      CodeChunk `json:"chunk"`
 	Similarity float64   `json:"similarity"`
+	Source     string    `json:"source,omitempty"` // Index the result came from, set by SearchMany
 }
 
-// Search searches the index for chunks similar to the query.
-func (idx *CodeIndex) Search(ctx context.Context, queryEmbedding []float64, topK int) []SearchResult {
-	if len(idx.Chunks) == 0 {
+// Search searches the index for chunks similar to the query, among the
+// chunks of files that pass filter (nil passes all). Filtering comes before
+// ranking, so the topK results all match it.
+// Stored embeddings are unit vectors, so cosine similarity reduces to a dot
+// product; only the best topK results are kept in a min-heap.
+func (idx *CodeIndex) Search(ctx context.Context, queryEmbedding []float64, topK int, filter *ResultFilter) []SearchResult {
+	if len(idx.Chunks) == 0 || topK <= 0 {
 		return nil
 	}
 
-	// Calculate cosine similarity for each chunk
-	similarities := make([]SearchResult, len(idx.Chunks))
-	for i, indexed := range idx.Chunks {
-		sim := cosineSimilarity(queryEmbedding, indexed.Embedding)
-		similarities[i] = SearchResult{
-			Chunk:      indexed.Chunk,
-			Similarity: sim,
+	query := make([]float64, len(queryEmbedding))
+	copy(query, queryEmbedding)
+	normalize(query)
+
+	// Min-heap of the best results seen so far; the root is the weakest
+	best := make(resultHeap, 0, min(topK, len(idx.Chunks)))
+	for i := range idx.Chunks {
+		indexed := &idx.Chunks[i]
+		if !filter.Match(indexed.Chunk.FilePath) {
+			continue
+		}
+
+		var sim float64
+		if len(indexed.Embedding) == len(query) {
+			sim = dot(query, indexed.Embedding)
+		}
+
+		if len(best) < topK {
+			heap.Push(&best, SearchResult{Chunk: indexed.Chunk, Similarity: sim})
+		} else if sim > best[0].Similarity {
+			best[0] = SearchResult{Chunk: indexed.Chunk, Similarity: sim}
+			heap.Fix(&best, 0)
 		}
 	}
 
 	// Sort by similarity (descending)
-	sort.Slice(similarities, func(i, j int) bool {
-		return similarities[i].Similarity > similarities[j].Similarity
+	results := []SearchResult(best)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
 	})
 
-	// Return top K results
-	if topK > len(similarities) {
-		topK = len(similarities)
-	}
+	return results
+}
 
-	return similarities[:topK]
+// resultHeap is a min-heap of search results ordered by similarity.
+type resultHeap []SearchResult
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return h[i].Similarity < h[j].Similarity }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x any)        { *h = append(*h, x.(SearchResult)) }
+func (h *resultHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
 }
 
-// cosineSimilarity calculates the cosine similarity between two vectors.
-func cosineSimilarity(a, b []float64) float64 {
-	if len(a) != len(b) {
-		return 0
+// normalize scales v to unit length in place. Zero vectors are left as is,
+// which gives them a similarity of 0 to everything.
+func normalize(v []float64) {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm == 0 {
+		return
 	}
 
-	var dotProduct, normA, normB float64
-	for i := range a {
-		dotProduct += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
+	inv := 1 / math.Sqrt(norm)
+	for i := range v {
+		v[i] *= inv
 	}
+}
 
-	if normA == 0 || normB == 0 {
-		return 0
+// dot returns the dot product of two vectors of equal length.
+// The loop is unrolled by four, which lets the compiler keep independent
+// accumulators in registers.
+func dot(a, b []float64) float64 {
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
 	}
 
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+	return (s0 + s1) + (s2 + s3)
 }
 
 // Stats returns statistics about the index.
@@ -142,14 +301,62 @@ func (idx *CodeIndex) Stats() map[string]interface{} {
 	return map[string]interface{}{
 		"total_chunks": len(idx.Chunks),
 		"total_files":  len(fileMap),
+		"provider":     idx.Provider,
 		"model":        idx.ModelName,
+		"dimension":    idx.Dimension,
 		"index_path":   idx.indexPath,
 	}
 }
 
+// removeOrphanedAndDuplicates drops chunks whose file no longer exists and
+// repeated copies of the same chunk, keeping the first. Relative file paths
+// are resolved against root. It returns the number of chunks of each kind
+// removed.
+func (idx *CodeIndex) removeOrphanedAndDuplicates(root string) (orphaned, duplicates int) {
+	type chunkKey struct {
+		path       string
+		start, end int
+		content    string
+	}
+
+	exists := make(map[string]bool)
+	seen := make(map[chunkKey]bool)
+	kept := idx.Chunks[:0]
+
+	for _, indexed := range idx.Chunks {
+		path := indexed.Chunk.FilePath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		present, checked := exists[path]
+		if !checked {
+			_, err := os.Stat(path)
+			present = err == nil
+			exists[path] = present
+		}
+		if !present {
+			orphaned++
+			continue
+		}
+
+		key := chunkKey{path, indexed.Chunk.Start, indexed.Chunk.End, indexed.Chunk.Content}
+		if seen[key] {
+			duplicates++
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, indexed)
+	}
+
+	clear(idx.Chunks[len(kept):])
+	idx.Chunks = kept
+	return orphaned, duplicates
+}
+
 // Clear removes all chunks from the index.
 func (idx *CodeIndex) Clear() {
 	idx.Chunks = []IndexedChunk{}
+	idx.Dimension = 0
 }
 
 // IsEmpty returns true if the index has no chunks.
@@ -0,0 +1,169 @@
+package codeindex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoadIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), IndexDirName, IndexFileName)
+
+	index := NewCodeIndex("fake", "fake-model")
+	index.AddChunk(CodeChunk{FilePath: "/repo/main.go", Content: "package main", Start: 1, End: 1}, []float64{3, 4})
+	if err := index.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Chunks) != 1 || loaded.Chunks[0].Chunk.Content != "package main" {
+		t.Errorf("loaded chunks = %+v", loaded.Chunks)
+	}
+	if loaded.Dimension != 2 || loaded.ModelName != "fake-model" || loaded.Provider != "fake" {
+		t.Errorf("loaded index = dimension %d, model %q, provider %q", loaded.Dimension, loaded.ModelName, loaded.Provider)
+	}
+
+	// The write went through a temporary file that is gone now
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("index directory holds %d files, want only %s", len(entries), IndexFileName)
+	}
+}
+
+func TestAddChunkKeepsCallerEmbedding(t *testing.T) {
+	index := NewCodeIndex("fake", "fake-model")
+	embedding := []float64{3, 4}
+	index.AddChunk(CodeChunk{FilePath: "/repo/main.go", Content: "package main"}, embedding)
+
+	if embedding[0] != 3 || embedding[1] != 4 {
+		t.Errorf("caller's embedding = %v, want it unchanged", embedding)
+	}
+	if got := index.Chunks[0].Embedding; math.Abs(got[0]-0.6) > 1e-9 || math.Abs(got[1]-0.8) > 1e-9 {
+		t.Errorf("stored embedding = %v, want [0.6 0.8]", got)
+	}
+}
+
+func TestLoadCorruptIndex(t *testing.T) {
+	valid := `{"model_name": "m", "chunks": [{"chunk": {"file_path": "a.go"}, "embedding": [1, 0]}]}`
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"truncated", valid[:len(valid)/2], "corrupt"},
+		{"not JSON", "\x00\x01garbage", "corrupt"},
+		{"empty", "", "corrupt"},
+		{"wrong type", `{"chunks": "none"}`, "corrupt"},
+		{"chunk without embedding", `{"chunks": [{"chunk": {"file_path": "a.go"}, "embedding": []}]}`, "no embedding"},
+		{"mixed dimensions", `{"chunks": [{"chunk": {"file_path": "a.go"}, "embedding": [1, 0]}, {"chunk": {"file_path": "b.go"}, "embedding": [1, 0, 0]}]}`, "dimension"},
+		{"recorded dimension differs", `{"dimension": 3, "chunks": [{"chunk": {"file_path": "a.go"}, "embedding": [1, 0]}]}`, "dimension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), IndexFileName)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadIndex(path)
+			if err == nil {
+				t.Fatal("LoadIndex accepted a corrupt index")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "index_directory") {
+				t.Errorf("error %q should mention %q and how to rebuild", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveReplacesCorruptIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), IndexFileName)
+	if err := os.WriteFile(path, []byte(`{"chunks": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	index := NewCodeIndex("fake", "fake-model")
+	index.AddChunk(CodeChunk{FilePath: "a.go"}, []float64{1, 0})
+	if err := index.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadIndex(path); err != nil {
+		t.Errorf("LoadIndex after Save: %v", err)
+	}
+}
+
+func TestSaveFailureKeepsPreviousIndex(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, IndexFileName)
+	old := NewCodeIndex("fake", "fake-model")
+	old.AddChunk(CodeChunk{FilePath: "a.go"}, []float64{1, 0})
+	if err := old.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The temporary file can't be created in a read-only directory
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0o755) })
+
+	replacement := NewCodeIndex("fake", "fake-model")
+	if err := replacement.Save(path); err == nil {
+		t.Fatal("Save succeeded in a read-only directory")
+	}
+
+	loaded, err := LoadIndex(path)
+	if err != nil {
+		t.Fatalf("previous index is unreadable after a failed save: %v", err)
+	}
+	if len(loaded.Chunks) != 1 {
+		t.Errorf("previous index has %d chunks, want 1", len(loaded.Chunks))
+	}
+}
+
+// benchmarkIndex returns an index of n chunks with random dim-dimensional
+// embeddings, the same for every run.
+func benchmarkIndex(n, dim int) (*CodeIndex, []float64) {
+	rng := rand.New(rand.NewSource(1))
+	vector := func() []float64 {
+		v := make([]float64, dim)
+		for i := range v {
+			v[i] = rng.NormFloat64()
+		}
+		return v
+	}
+
+	index := NewCodeIndex("fake", "fake-model")
+	for i := 0; i < n; i++ {
+		index.AddChunk(CodeChunk{FilePath: fmt.Sprintf("/repo/file%d.go", i%1000), Start: i}, vector())
+	}
+	return index, vector()
+}
+
+// BenchmarkSearch searches a 50k-chunk index of 768-dimensional
+// embeddings, the size of nomic-embed-text vectors.
+func BenchmarkSearch(b *testing.B) {
+	index, query := benchmarkIndex(50_000, 768)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if results := index.Search(ctx, query, 10, nil); len(results) != 10 {
+			b.Fatalf("%d results, want 10", len(results))
+		}
+	}
+}
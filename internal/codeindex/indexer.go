@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -15,33 +18,64 @@ const (
 	IndexFileName = "index.json"
 )
 
-// Indexer orchestrates the indexing process.
+// Indexer orchestrates the indexing process. Its methods may be called
+// concurrently; the loaded index is replaced, never modified in place.
 type Indexer struct {
-	ollama      *OllamaClient
-	chunkCfg    ChunkConfig
-	modelName   string
+	embedder Embedder
+	ollama   *OllamaClient // LLM reranking; nil when embeddings are not from Ollama
+	chunkCfg ChunkConfig
+	cache    indexCache // Indexes loaded from disk, by path
+
+	mu          sync.Mutex // Guards the fields below
 	index       *CodeIndex
 	projectRoot string // Root directory of the indexed project
+	queryDim    int    // Dimension of the last query embedding (0 = unknown)
 }
 
 // IndexerConfig defines indexer configuration.
 type IndexerConfig struct {
-	OllamaURL   string
-	ModelName   string
-	IndexPath   string // Deprecated: index is now stored in project's .codeindex/
-	ChunkConfig ChunkConfig
+	EmbeddingProvider string // EmbeddingProviderOllama (default) or EmbeddingProviderOpenAI
+	OllamaURL         string
+	ModelName         string
+	EmbeddingURL      string // Base URL of the OpenAI-compatible API
+	EmbeddingAPIKey   string // API key for the OpenAI-compatible API
+	IndexPath         string // Deprecated: index is now stored in project's .codeindex/
+	ChunkConfig       ChunkConfig
+	OllamaTimeout     time.Duration      // Per-request Ollama timeout (0 = DefaultOllamaTimeout)
+	AutoPull          bool               // Pull the Ollama model before first use if it is missing
+	OnPull            func(PullProgress) // Optional pull progress callback
 }
 
 // NewIndexer creates a new code indexer.
 func NewIndexer(cfg IndexerConfig) (*Indexer, error) {
-	ollama := NewOllamaClient(cfg.OllamaURL, cfg.ModelName)
+	idx := &Indexer{
+		chunkCfg: cfg.ChunkConfig,
+	}
 
-	return &Indexer{
-		ollama:    ollama,
-		chunkCfg:  cfg.ChunkConfig,
-		modelName: cfg.ModelName,
-		index:     NewCodeIndex(cfg.ModelName),
-	}, nil
+	switch cfg.EmbeddingProvider {
+	case "", EmbeddingProviderOllama:
+		ollama := NewOllamaClient(cfg.OllamaURL, cfg.ModelName)
+		ollama.SetAutoPull(cfg.AutoPull, cfg.OnPull)
+		ollama.SetTimeout(cfg.OllamaTimeout)
+		idx.embedder = ollama
+		idx.ollama = ollama
+	case EmbeddingProviderOpenAI:
+		if cfg.EmbeddingAPIKey == "" {
+			return nil, fmt.Errorf("an API key is required for the %s embedding provider", EmbeddingProviderOpenAI)
+		}
+		idx.embedder = NewOpenAIEmbedder(cfg.EmbeddingURL, cfg.EmbeddingAPIKey, cfg.ModelName)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s (supported: %s, %s)",
+			cfg.EmbeddingProvider, EmbeddingProviderOllama, EmbeddingProviderOpenAI)
+	}
+
+	idx.index = idx.newIndex()
+	return idx, nil
+}
+
+// newIndex returns an empty index for the configured embedder.
+func (idx *Indexer) newIndex() *CodeIndex {
+	return NewCodeIndex(idx.embedder.Provider(), idx.embedder.Model())
 }
 
 // getIndexPath returns the path to the index file for a given project root.
@@ -49,6 +83,43 @@ func getIndexPath(projectRoot string) string {
 	return filepath.Join(projectRoot, IndexDirName, IndexFileName)
 }
 
+// currentIndex returns the loaded index, reloading it if its file changed
+// on disk. When none is loaded it loads the nearest index above the
+// working directory.
+func (idx *Indexer) currentIndex() (*CodeIndex, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	indexPath := idx.index.indexPath
+	if indexPath == "" {
+		if !idx.index.IsEmpty() {
+			return idx.index, nil
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("get working directory: %w", err)
+		}
+		if indexPath, err = findProjectIndex(cwd); err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := idx.cache.load(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("load index: %w", err)
+	}
+	idx.index = index
+	return index, nil
+}
+
+// setQueryDim records the dimension of a query embedding for Stats.
+func (idx *Indexer) setQueryDim(dim int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.queryDim = dim
+}
+
 // findProjectIndex searches for .codeindex directory starting from dir and going up.
 func findProjectIndex(startDir string) (string, error) {
 	dir, err := filepath.Abs(startDir)
@@ -71,17 +142,17 @@ func findProjectIndex(startDir string) (string, error) {
 	}
 }
 
-// IndexDirectory indexes all code files in a directory recursively.
+// IndexDirectory indexes all code files in a directory recursively. The
+// new index replaces the loaded one once it is saved; searches until then
+// use the previous index.
 func (idx *Indexer) IndexDirectory(ctx context.Context, dirPath string, progress func(string)) error {
 	// Get absolute path for the project root
 	absPath, err := filepath.Abs(dirPath)
 	if err != nil {
 		return fmt.Errorf("get absolute path: %w", err)
 	}
-	idx.projectRoot = absPath
 
-	// Clear existing index
-	idx.index = NewCodeIndex(idx.modelName)
+	index := idx.newIndex()
 
 	var filesToIndex []string
 
@@ -116,13 +187,17 @@ func (idx *Indexer) IndexDirectory(ctx context.Context, dirPath string, progress
 	}
 
 	// Index each file
-	for _, filePath := range filesToIndex {
+	for i, filePath := range filesToIndex {
 		if progress != nil {
 			relPath, _ := filepath.Rel(absPath, filePath)
-			progress(fmt.Sprintf("Indexing: %s", relPath))
+			progress(fmt.Sprintf("Indexing %d/%d: %s", i+1, len(filesToIndex), relPath))
 		}
 
-		if err := idx.IndexFile(ctx, filePath); err != nil {
+		if err := idx.indexFile(ctx, index, filePath); err != nil {
+			// Not specific to the file; every other file would fail too
+			if IsOllamaSetupError(err) {
+				return err
+			}
 			return fmt.Errorf("index file %s: %w", filePath, err)
 		}
 	}
@@ -135,15 +210,21 @@ func (idx *Indexer) IndexDirectory(ctx context.Context, dirPath string, progress
 
 	// Save index
 	indexPath := getIndexPath(absPath)
-	if err := idx.index.Save(indexPath); err != nil {
+	if err := index.Save(indexPath); err != nil {
 		return fmt.Errorf("save index: %w", err)
 	}
+	idx.cache.put(indexPath, index)
+
+	idx.mu.Lock()
+	idx.index = index
+	idx.projectRoot = absPath
+	idx.mu.Unlock()
 
 	return nil
 }
 
-// IndexFile indexes a single file.
-func (idx *Indexer) IndexFile(ctx context.Context, filePath string) error {
+// indexFile adds the chunks of a single file to index.
+func (idx *Indexer) indexFile(ctx context.Context, index *CodeIndex, filePath string) error {
 	// Read file content
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -156,52 +237,58 @@ func (idx *Indexer) IndexFile(ctx context.Context, filePath string) error {
 
 	// Generate embeddings for each chunk
 	for _, chunk := range chunks {
-		embedding, err := idx.ollama.GenerateEmbedding(ctx, chunk.Content)
+		embedding, err := idx.embedder.GenerateEmbedding(ctx, chunk.Content)
 		if err != nil {
 			return fmt.Errorf("generate embedding for chunk %d: %w", chunk.Index, err)
 		}
 
-		idx.index.AddChunk(chunk, embedding)
+		index.AddChunk(chunk, embedding)
 	}
 
 	return nil
 }
 
-// Search searches the index for code similar to the query.
-func (idx *Indexer) Search(ctx context.Context, query string, topK int) ([]SearchResult, error) {
-	// Try to load index from current directory if not already loaded
-	if idx.index.IsEmpty() {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("get working directory: %w", err)
-		}
+// Search searches the index for code similar to the query in the files
+// that pass filter (nil passes all).
+func (idx *Indexer) Search(ctx context.Context, query string, topK int, filter *ResultFilter) ([]SearchResult, error) {
+	index, err := idx.currentIndex()
+	if err != nil {
+		return nil, err
+	}
 
-		indexPath, err := findProjectIndex(cwd)
-		if err != nil {
-			return nil, err
-		}
+	queryEmbedding, err := idx.embedQuery(ctx, index, query)
+	if err != nil {
+		return nil, err
+	}
 
-		loadedIndex, err := LoadIndex(indexPath)
-		if err != nil {
-			return nil, fmt.Errorf("load index: %w", err)
-		}
-		idx.index = loadedIndex
+	// Search index
+	results := index.Search(ctx, queryEmbedding, topK, filter)
+	return results, nil
+}
+
+// embedQuery generates the query embedding after verifying that the index
+// was built with the same provider, model and dimension as the current one.
+func (idx *Indexer) embedQuery(ctx context.Context, index *CodeIndex, query string) ([]float64, error) {
+	if err := index.CheckModel(idx.embedder.Provider(), idx.embedder.Model()); err != nil {
+		return nil, err
 	}
 
-	// Generate embedding for query
-	queryEmbedding, err := idx.ollama.GenerateEmbedding(ctx, query)
+	queryEmbedding, err := idx.embedder.GenerateEmbedding(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("generate query embedding: %w", err)
 	}
+	idx.setQueryDim(len(queryEmbedding))
 
-	// Search index
-	results := idx.index.Search(ctx, queryEmbedding, topK)
-	return results, nil
+	if err := index.CheckDimension(idx.embedder.Model(), len(queryEmbedding)); err != nil {
+		return nil, err
+	}
+
+	return queryEmbedding, nil
 }
 
 // SearchAt searches a specific index at the given directory path.
 // It loads the index from dirPath/.codeindex/index.json without changing the main loaded index.
-func (idx *Indexer) SearchAt(ctx context.Context, dirPath string, query string, topK int) ([]SearchResult, error) {
+func (idx *Indexer) SearchAt(ctx context.Context, dirPath string, query string, topK int, filter *ResultFilter) ([]SearchResult, error) {
 	absPath, err := filepath.Abs(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("get absolute path: %w", err)
@@ -212,51 +299,208 @@ func (idx *Indexer) SearchAt(ctx context.Context, dirPath string, query string,
 		return nil, fmt.Errorf("no index found at %s", indexPath)
 	}
 
-	tempIndex, err := LoadIndex(indexPath)
+	tempIndex, err := idx.cache.load(indexPath)
 	if err != nil {
 		return nil, fmt.Errorf("load index at %s: %w", indexPath, err)
 	}
 
-	queryEmbedding, err := idx.ollama.GenerateEmbedding(ctx, query)
+	queryEmbedding, err := idx.embedQuery(ctx, tempIndex, query)
 	if err != nil {
-		return nil, fmt.Errorf("generate query embedding: %w", err)
+		return nil, err
 	}
 
-	results := tempIndex.Search(ctx, queryEmbedding, topK)
+	results := tempIndex.Search(ctx, queryEmbedding, topK, filter)
 	return results, nil
 }
 
+// SearchMany searches the indexes at several directory paths with one query
+// embedding and merges the results by similarity. Each result's Source is
+// the directory path it came from. Identical chunks found in more than one
+// index (e.g. docs/ indexed on its own and as part of the project) are
+// returned once, from the earliest index in dirPaths. Only files that pass
+// filter (nil passes all) are searched.
+func (idx *Indexer) SearchMany(ctx context.Context, dirPaths []string, query string, topK int, filter *ResultFilter) ([]SearchResult, error) {
+	indexes := make([]*CodeIndex, len(dirPaths))
+	for i, dirPath := range dirPaths {
+		absPath, err := filepath.Abs(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("get absolute path: %w", err)
+		}
+
+		indexPath := getIndexPath(absPath)
+		if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("no index found at %s", indexPath)
+		}
+
+		index, err := idx.cache.load(indexPath)
+		if err != nil {
+			return nil, fmt.Errorf("load index at %s: %w", indexPath, err)
+		}
+		if err := index.CheckModel(idx.embedder.Provider(), idx.embedder.Model()); err != nil {
+			return nil, fmt.Errorf("%s: %w", dirPath, err)
+		}
+		indexes[i] = index
+	}
+
+	queryEmbedding, err := idx.embedder.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("generate query embedding: %w", err)
+	}
+	idx.setQueryDim(len(queryEmbedding))
+
+	type chunkKey struct {
+		path       string
+		start, end int
+		content    string
+	}
+	seen := make(map[chunkKey]bool)
+
+	var merged []SearchResult
+	for i, index := range indexes {
+		if err := index.CheckDimension(idx.embedder.Model(), len(queryEmbedding)); err != nil {
+			return nil, fmt.Errorf("%s: %w", dirPaths[i], err)
+		}
+
+		for _, res := range index.Search(ctx, queryEmbedding, topK, filter) {
+			key := chunkKey{res.Chunk.FilePath, res.Chunk.Start, res.Chunk.End, res.Chunk.Content}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			res.Source = dirPaths[i]
+			merged = append(merged, res)
+		}
+	}
+
+	// Stable, so ties keep the order of dirPaths
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Similarity > merged[j].Similarity
+	})
+	if len(merged) > topK {
+		merged = merged[:topK]
+	}
+	return merged, nil
+}
+
 // Stats returns index statistics.
 func (idx *Indexer) Stats() map[string]interface{} {
-	// Try to load index if empty
-	if idx.index.IsEmpty() {
+	// Load or refresh the index; on failure report the one in memory
+	_, _ = idx.currentIndex()
+
+	idx.mu.Lock()
+	index, queryDim := idx.index, idx.queryDim
+	idx.mu.Unlock()
+
+	stats := index.Stats()
+	stats["query_provider"] = idx.embedder.Provider()
+	stats["query_model"] = idx.embedder.Model()
+	if queryDim > 0 {
+		stats["query_dimension"] = queryDim
+	}
+	stats["model_mismatch"] = index.CheckModel(idx.embedder.Provider(), idx.embedder.Model()) != nil
+	return stats
+}
+
+// CompactResult reports what Compact removed from an index.
+type CompactResult struct {
+	IndexPath       string `json:"index_path"`
+	OrphanedChunks  int    `json:"orphaned_chunks"`  // Chunks of files that no longer exist
+	DuplicateChunks int    `json:"duplicate_chunks"` // Repeated copies of the same chunk
+	RemainingChunks int    `json:"remaining_chunks"`
+	BytesBefore     int64  `json:"bytes_before"`
+	BytesAfter      int64  `json:"bytes_after"`
+	BytesReclaimed  int64  `json:"bytes_reclaimed"`
+}
+
+// Compact removes chunks of deleted or moved files and duplicate chunks
+// from the index at dirPath/.codeindex/, or from the nearest index above
+// the working directory when dirPath is empty, and rewrites it. No
+// embeddings are generated. The loaded index is replaced if it is the one
+// compacted.
+func (idx *Indexer) Compact(dirPath string) (*CompactResult, error) {
+	var indexPath string
+	if dirPath != "" {
+		absPath, err := filepath.Abs(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("get absolute path: %w", err)
+		}
+		indexPath = getIndexPath(absPath)
+	} else {
 		cwd, err := os.Getwd()
-		if err == nil {
-			if indexPath, err := findProjectIndex(cwd); err == nil {
-				if loadedIndex, err := LoadIndex(indexPath); err == nil {
-					idx.index = loadedIndex
-				}
-			}
+		if err != nil {
+			return nil, fmt.Errorf("get working directory: %w", err)
+		}
+		if indexPath, err = findProjectIndex(cwd); err != nil {
+			return nil, err
 		}
 	}
-	return idx.index.Stats()
+
+	before, err := os.Stat(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("no index found at %s", indexPath)
+	}
+
+	index, err := LoadIndex(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("load index: %w", err)
+	}
+
+	// The index lives in PROJECT_ROOT/.codeindex/index.json
+	root := filepath.Dir(filepath.Dir(indexPath))
+	orphaned, duplicates := index.removeOrphanedAndDuplicates(root)
+
+	if err := index.Save(indexPath); err != nil {
+		return nil, fmt.Errorf("save index: %w", err)
+	}
+
+	after, err := os.Stat(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("stat index: %w", err)
+	}
+	idx.cache.put(indexPath, index)
+
+	idx.mu.Lock()
+	if idx.index.indexPath == indexPath {
+		idx.index = index
+	}
+	idx.mu.Unlock()
+
+	return &CompactResult{
+		IndexPath:       indexPath,
+		OrphanedChunks:  orphaned,
+		DuplicateChunks: duplicates,
+		RemainingChunks: len(index.Chunks),
+		BytesBefore:     before.Size(),
+		BytesAfter:      after.Size(),
+		BytesReclaimed:  before.Size() - after.Size(),
+	}, nil
 }
 
-// CheckHealth verifies that Ollama is available.
+// CheckHealth verifies that the embedding provider is available.
 func (idx *Indexer) CheckHealth(ctx context.Context) error {
-	return idx.ollama.CheckHealth(ctx)
+	return idx.embedder.CheckHealth(ctx)
 }
 
 // SaveIndex saves the current index to disk.
 func (idx *Indexer) SaveIndex() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
 	if idx.projectRoot == "" {
 		return fmt.Errorf("no project indexed yet")
 	}
-	return idx.index.Save(getIndexPath(idx.projectRoot))
+	indexPath := getIndexPath(idx.projectRoot)
+	if err := idx.index.Save(indexPath); err != nil {
+		return err
+	}
+	idx.cache.put(indexPath, idx.index)
+	return nil
 }
 
-// LoadIndex reloads the index from disk.
+// LoadIndex reloads the index from disk, dropping all cached indexes.
 func (idx *Indexer) LoadIndex() error {
+	idx.cache.clear()
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("get working directory: %w", err)
@@ -267,30 +511,34 @@ func (idx *Indexer) LoadIndex() error {
 		return err
 	}
 
-	index, err := LoadIndex(indexPath)
+	index, err := idx.cache.load(indexPath)
 	if err != nil {
 		return err
 	}
+
+	idx.mu.Lock()
 	idx.index = index
+	idx.mu.Unlock()
 	return nil
 }
 
-// FormatSearchResults formats search results as a readable string.
-func FormatSearchResults(results []SearchResult) string {
+// FormatSearchResults formats search results as a readable string, with
+// each result's path:line anchor on its own line and the lines matching
+// query marked.
+func FormatSearchResults(query string, results []SearchResult) string {
 	if len(results) == 0 {
 		return "No results found."
 	}
 
+	terms := queryTerms(query)
+
 	var builder strings.Builder
 	builder.WriteString(fmt.Sprintf("Found %d result(s):\n\n", len(results)))
 
 	for i, result := range results {
-		builder.WriteString(fmt.Sprintf("Result %d (similarity: %.3f):\n", i+1, result.Similarity))
-		builder.WriteString(fmt.Sprintf("File: %s (lines %d-%d)\n",
-			result.Chunk.FilePath, result.Chunk.Start, result.Chunk.End))
-		builder.WriteString("```\n")
-		builder.WriteString(result.Chunk.Content)
-		builder.WriteString("\n```\n\n")
+		builder.WriteString(fmt.Sprintf("Result %d (similarity: %.3f, lines %d-%d):\n",
+			i+1, result.Similarity, result.Chunk.Start, result.Chunk.End))
+		writeSnippet(&builder, result.Chunk, terms)
 	}
 
 	return builder.String()
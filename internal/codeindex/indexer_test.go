@@ -0,0 +1,78 @@
+package codeindex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fakeEmbedder derives a small vector from the text length.
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return []float64{float64(len(text)), 1, 2}, nil
+}
+func (fakeEmbedder) CheckHealth(ctx context.Context) error { return nil }
+func (fakeEmbedder) Provider() string                      { return "fake" }
+func (fakeEmbedder) Model() string                         { return "fake-model" }
+
+func newTestIndexer() *Indexer {
+	idx := &Indexer{embedder: fakeEmbedder{}, chunkCfg: DefaultChunkConfig()}
+	idx.index = idx.newIndex()
+	return idx
+}
+
+// indexTestProject indexes a small project in a temporary directory and
+// returns the directory.
+func indexTestProject(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go": "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+		"util.go": "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := newTestIndexer().IndexDirectory(context.Background(), dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestConcurrentSearchesLoadIndexOnce(t *testing.T) {
+	dir := indexTestProject(t)
+	t.Chdir(dir)
+
+	idx := newTestIndexer()
+
+	const searches = 8
+	loaded := make([]*CodeIndex, searches)
+	var wg sync.WaitGroup
+	for i := range searches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := idx.Search(context.Background(), "add", 3, nil); err != nil {
+				t.Error(err)
+			}
+			_ = idx.Stats()
+			loaded[i], _ = idx.currentIndex()
+		}()
+	}
+	wg.Wait()
+
+	// Every read of the file yields a new *CodeIndex.
+	for i, index := range loaded {
+		if index == nil || index != loaded[0] {
+			t.Fatalf("search %d used index %p, want %p: the file was read more than once", i, index, loaded[0])
+		}
+	}
+	if len(loaded[0].Chunks) == 0 {
+		t.Error("loaded index has no chunks")
+	}
+}
@@ -7,14 +7,40 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/notexe/cli-chat/internal/ollama"
+)
+
+// DefaultOllamaTimeout bounds a single Ollama request. Model pulls are not
+// bounded by it.
+const DefaultOllamaTimeout = 120 * time.Second
+
+// Setup errors returned by OllamaClient. They carry the command that fixes
+// them and are returned as is by health checks and indexing.
+var (
+	ErrOllamaNotRunning = ollama.ErrNotRunning
+	ErrModelNotPulled   = ollama.ErrModelNotPulled
 )
 
+// IsOllamaSetupError reports whether err means Ollama is not running or the
+// model is missing, as opposed to a failure of a single request.
+func IsOllamaSetupError(err error) bool {
+	return ollama.IsSetupError(err)
+}
+
 // OllamaClient communicates with local Ollama instance for embeddings.
 type OllamaClient struct {
 	baseURL    string
 	model      string
 	httpClient *http.Client
+
+	// Auto-pull state. The model is checked once before the first embedding.
+	autoPull   bool
+	onProgress func(PullProgress)
+	pullMu     sync.Mutex
+	modelReady bool
 }
 
 // NewOllamaClient creates a new Ollama client.
@@ -30,11 +56,41 @@ func NewOllamaClient(baseURL, model string) *OllamaClient {
 		baseURL: baseURL,
 		model:   model,
 		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
+			Timeout: DefaultOllamaTimeout,
 		},
 	}
 }
 
+// SetTimeout sets the timeout of each request; zero or negative restores
+// DefaultOllamaTimeout.
+func (c *OllamaClient) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultOllamaTimeout
+	}
+	c.httpClient.Timeout = timeout
+}
+
+// requestError turns a failed request into an actionable error when Ollama
+// is unreachable or too slow.
+func (c *OllamaClient) requestError(ctx context.Context, err error) error {
+	return ollama.RequestError(ctx, c.baseURL, c.httpClient.Timeout, err)
+}
+
+// modelNotPulled returns the error for a model Ollama does not have.
+func modelNotPulled(model string) error {
+	return fmt.Errorf("%w: %s (run `ollama pull %s`)", ErrModelNotPulled, model, model)
+}
+
+// SetAutoPull enables pulling the embedding model before first use when it
+// is not present locally. progress, if non-nil, receives pull updates.
+// Pulling can download several gigabytes, so it is off by default.
+func (c *OllamaClient) SetAutoPull(enabled bool, progress func(PullProgress)) {
+	c.pullMu.Lock()
+	defer c.pullMu.Unlock()
+	c.autoPull = enabled
+	c.onProgress = progress
+}
+
 // EmbeddingRequest represents the Ollama API embedding request.
 type EmbeddingRequest struct {
 	Model  string `json:"model"`
@@ -48,6 +104,10 @@ type EmbeddingResponse struct {
 
 // GenerateEmbedding generates an embedding vector for the given text.
 func (c *OllamaClient) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if err := c.ensureModel(ctx); err != nil {
+		return nil, err
+	}
+
 	req := EmbeddingRequest{
 		Model:  c.model,
 		Prompt: text,
@@ -66,10 +126,13 @@ func (c *OllamaClient) GenerateEmbedding(ctx context.Context, text string) ([]fl
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, c.requestError(ctx, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, modelNotPulled(c.model)
+	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(bodyBytes))
@@ -106,12 +169,25 @@ func (c *OllamaClient) GenerateBatchEmbeddings(ctx context.Context, texts []stri
 func (c *OllamaClient) CheckHealth(ctx context.Context) error {
 	// Try to generate a small test embedding
 	_, err := c.GenerateEmbedding(ctx, "test")
+	if IsOllamaSetupError(err) {
+		return fmt.Errorf("ollama health check failed: %w", err)
+	}
 	if err != nil {
 		return fmt.Errorf("ollama health check failed: %w (ensure ollama is running and model '%s' is pulled)", err, c.model)
 	}
 	return nil
 }
 
+// Provider returns EmbeddingProviderOllama.
+func (c *OllamaClient) Provider() string {
+	return EmbeddingProviderOllama
+}
+
+// Model returns the embedding model name.
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
 // GenerateRequest represents the Ollama API generate request.
 type GenerateRequest struct {
 	Model  string `json:"model"`
@@ -151,10 +227,13 @@ func (c *OllamaClient) Generate(ctx context.Context, prompt string) (string, err
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("send request: %w", err)
+		return "", c.requestError(ctx, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return "", modelNotPulled(rerankModel)
+	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		return "", fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(bodyBytes))
@@ -167,3 +246,41 @@ func (c *OllamaClient) Generate(ctx context.Context, prompt string) (string, err
 
 	return genResp.Response, nil
 }
+
+// PullProgress is a single status update streamed by the Ollama pull API.
+type PullProgress = ollama.PullProgress
+
+// ensureModel pulls the model once if auto-pull is enabled and the model
+// is missing. A failed check or pull is retried on the next call.
+func (c *OllamaClient) ensureModel(ctx context.Context) error {
+	c.pullMu.Lock()
+	defer c.pullMu.Unlock()
+
+	if !c.autoPull || c.modelReady {
+		return nil
+	}
+
+	present, err := c.HasModel(ctx)
+	if err != nil {
+		return err
+	}
+	if !present {
+		if err := c.PullModel(ctx, c.model, c.onProgress); err != nil {
+			return err
+		}
+	}
+
+	c.modelReady = true
+	return nil
+}
+
+// HasModel reports whether the configured model is available locally.
+func (c *OllamaClient) HasModel(ctx context.Context) (bool, error) {
+	return ollama.HasModel(ctx, c.httpClient, c.baseURL, c.model)
+}
+
+// PullModel downloads model via the Ollama pull API, streaming progress
+// updates to progress if non-nil. It blocks until the pull completes.
+func (c *OllamaClient) PullModel(ctx context.Context, model string, progress func(PullProgress)) error {
+	return ollama.PullModel(ctx, c.httpClient, c.baseURL, model, progress)
+}
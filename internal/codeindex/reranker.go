@@ -122,6 +122,7 @@ type RerankerStats struct {
 // SourceCitation represents a citation/reference to a source code location.
 type SourceCitation struct {
 	ID         int     `json:"id"`
+	Index      string  `json:"index,omitempty"` // Index the result came from, for multi-index searches
 	FilePath   string  `json:"file_path"`
 	FileName   string  `json:"file_name"`
 	StartLine  int     `json:"start_line"`
@@ -258,7 +259,9 @@ func FilterByThreshold(results []SearchResult, minSimilarity float64) []SearchRe
 }
 
 // FormatRerankedResults formats reranked results with stats and a separate sources block.
-func FormatRerankedResults(results []RerankedResult, stats *RerankerStats) string {
+// Each result starts with a path:line anchor on its own line, and the lines
+// matching query are marked in the snippet.
+func FormatRerankedResults(query string, results []RerankedResult, stats *RerankerStats) string {
 	if len(results) == 0 {
 		msg := fmt.Sprintf("No relevant results found (threshold: %.2f).\n", stats.MinSimilarity)
 		if stats.OriginalCount > 0 {
@@ -281,6 +284,8 @@ func FormatRerankedResults(results []RerankedResult, stats *RerankerStats) strin
 	}
 	builder.WriteString(":\n\n")
 
+	terms := queryTerms(query)
+
 	// Code results with citation IDs
 	for i, result := range results {
 		builder.WriteString(fmt.Sprintf("[%d] Result", i+1))
@@ -290,10 +295,11 @@ func FormatRerankedResults(results []RerankedResult, stats *RerankerStats) strin
 		} else {
 			builder.WriteString(fmt.Sprintf(" (similarity: %.3f)", result.Similarity))
 		}
+		if result.Source != "" {
+			builder.WriteString(fmt.Sprintf(" from index %s", result.Source))
+		}
 		builder.WriteString(":\n")
-		builder.WriteString("```\n")
-		builder.WriteString(result.Chunk.Content)
-		builder.WriteString("\n```\n\n")
+		writeSnippet(&builder, result.Chunk, terms)
 	}
 
 	// Sources block - separate section with file references
@@ -340,6 +346,7 @@ func BuildSearchResponse(query string, results []RerankedResult, stats *Reranker
 
 		source := SourceCitation{
 			ID:         citationID,
+			Index:      r.Source,
 			FilePath:   r.Chunk.FilePath,
 			FileName:   fileName,
 			StartLine:  r.Chunk.Start,
@@ -370,8 +377,12 @@ func FormatCompactResponse(resp *SearchResponse) string {
 	builder.WriteString(fmt.Sprintf("Found %d files for: %q\n\n", len(resp.Sources), resp.Query))
 
 	for _, source := range resp.Sources {
-		builder.WriteString(fmt.Sprintf("[%d] %s:%d-%d (%.0f%% relevant)\n",
+		builder.WriteString(fmt.Sprintf("[%d] %s:%d-%d (%.0f%% relevant)",
 			source.ID, source.FilePath, source.StartLine, source.EndLine, source.Similarity*100))
+		if source.Index != "" {
+			builder.WriteString(fmt.Sprintf(" [index: %s]", source.Index))
+		}
+		builder.WriteString("\n")
 		builder.WriteString(fmt.Sprintf("    %s\n", source.Preview))
 	}
 
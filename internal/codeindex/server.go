@@ -4,11 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	mcpclient "github.com/notexe/cli-chat/internal/mcp"
 )
 
+// indexCallTimeout is the call timeout index_directory declares, since
+// embedding a large tree takes far longer than an ordinary tool call.
+const indexCallTimeout = 30 * time.Minute
+
 const (
 	serverName    = "codeindex"
 	serverVersion = "1.0.0"
@@ -45,8 +51,9 @@ func (s *Server) registerTools() {
 	// index_directory
 	s.mcpServer.AddTool(
 		mcp.NewTool("index_directory",
-			mcp.WithDescription("Index all code files in a directory recursively. Creates embeddings using local Ollama."),
+			mcp.WithDescription("Index all code files in a directory recursively. Creates embeddings with the configured embedding provider."),
 			mcp.WithString("path", mcp.Required(), mcp.Description("Path to directory to index")),
+			mcpclient.WithCallTimeout(indexCallTimeout),
 		),
 		s.handleIndexDirectory,
 	)
@@ -62,6 +69,10 @@ func (s *Server) registerTools() {
 			mcp.WithNumber("max_content_length", mcp.Description("Max snippet length (default: 500)")),
 			mcp.WithBoolean("compact", mcp.Description("Return only file paths, no code")),
 			mcp.WithString("index_path", mcp.Description("Directory path with .codeindex/ to search in (default: auto-detect from CWD)")),
+			mcp.WithArray("index_paths", mcp.WithStringItems(), mcp.Description("Several directory paths with .codeindex/ to search together; results are merged and labeled with their index. Earlier paths win ties")),
+			mcp.WithString("path_glob", mcp.Description("Only files matching this glob: \"*_test.go\" matches file names, \"internal/api/**\" or \"internal/api/\" a subdirectory")),
+			mcp.WithString("language", mcp.Description("Only files in this language or extension, e.g. \"go\", \"typescript\", \".tsx\"")),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleSearchCode,
 	)
@@ -70,6 +81,7 @@ func (s *Server) registerTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("index_stats",
 			mcp.WithDescription("Get statistics about the code index (number of chunks, files, model used)"),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleIndexStats,
 	)
@@ -77,7 +89,8 @@ func (s *Server) registerTools() {
 	// check_health
 	s.mcpServer.AddTool(
 		mcp.NewTool("check_health",
-			mcp.WithDescription("Check if Ollama is running and the embedding model is available"),
+			mcp.WithDescription("Check if the embedding provider is reachable and the embedding model is available"),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleCheckHealth,
 	)
@@ -85,10 +98,19 @@ func (s *Server) registerTools() {
 	// reload_index
 	s.mcpServer.AddTool(
 		mcp.NewTool("reload_index",
-			mcp.WithDescription("Reload the index from disk (useful after manual edits or external updates)"),
+			mcp.WithDescription("Reload the index from disk, dropping all cached indexes (indexes are otherwise cached in memory and reloaded only when their file changes)"),
 		),
 		s.handleReloadIndex,
 	)
+
+	// compact_index
+	s.mcpServer.AddTool(
+		mcp.NewTool("compact_index",
+			mcp.WithDescription("Remove chunks of deleted or moved files and duplicate chunks from the index, then rewrite it"),
+			mcp.WithString("index_path", mcp.Description("Directory path with .codeindex/ to compact (default: auto-detect from CWD)")),
+		),
+		s.handleCompactIndex,
+	)
 }
 
 func (s *Server) handleIndexDirectory(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -97,10 +119,12 @@ func (s *Server) handleIndexDirectory(ctx context.Context, req mcp.CallToolReque
 		return mcp.NewToolResultError("path is required"), nil
 	}
 
-	// Channel for progress messages
+	// Keep the last progress message and report each one to the client
 	progressMsg := ""
+	notify := progressNotifier(ctx, req)
 	progress := func(msg string) {
 		progressMsg = msg
+		notify(msg)
 	}
 
 	err := s.indexer.IndexDirectory(ctx, path, progress)
@@ -153,6 +177,15 @@ func (s *Server) handleSearchCode(ctx context.Context, req mcp.CallToolRequest)
 	compact := req.GetBool("compact", false)
 
 	indexPath := req.GetString("index_path", "")
+	indexPaths := req.GetStringSlice("index_paths", nil)
+	if indexPath != "" && len(indexPaths) > 0 {
+		return mcp.NewToolResultError("use either index_path or index_paths, not both"), nil
+	}
+
+	filter, err := NewResultFilter(req.GetString("path_glob", ""), req.GetString("language", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	// Get more results initially for filtering
 	searchK := topK * 3
@@ -161,11 +194,12 @@ func (s *Server) handleSearchCode(ctx context.Context, req mcp.CallToolRequest)
 	}
 
 	var results []SearchResult
-	var err error
-	if indexPath != "" {
-		results, err = s.indexer.SearchAt(ctx, indexPath, query, searchK)
+	if len(indexPaths) > 0 {
+		results, err = s.indexer.SearchMany(ctx, indexPaths, query, searchK, filter)
+	} else if indexPath != "" {
+		results, err = s.indexer.SearchAt(ctx, indexPath, query, searchK, filter)
 	} else {
-		results, err = s.indexer.Search(ctx, query, searchK)
+		results, err = s.indexer.Search(ctx, query, searchK, filter)
 	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
@@ -199,7 +233,7 @@ func (s *Server) handleSearchCode(ctx context.Context, req mcp.CallToolRequest)
 		return mcp.NewToolResultText(FormatCompactResponse(searchResp)), nil
 	}
 
-	formatted := FormatRerankedResults(reranked, stats)
+	formatted := FormatRerankedResults(query, reranked, stats)
 	return mcp.NewToolResultText(formatted), nil
 }
 
@@ -215,7 +249,7 @@ func (s *Server) handleCheckHealth(ctx context.Context, _ mcp.CallToolRequest) (
 		return mcp.NewToolResultError(fmt.Sprintf("health check failed: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText("Ollama is healthy and embedding model is available"), nil
+	return mcp.NewToolResultText(fmt.Sprintf("%s is healthy and embedding model is available", s.indexer.embedder.Provider())), nil
 }
 
 func (s *Server) handleReloadIndex(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -234,3 +268,40 @@ func (s *Server) handleReloadIndex(_ context.Context, _ mcp.CallToolRequest) (*m
 	output, _ := json.MarshalIndent(result, "", "  ")
 	return mcp.NewToolResultText(string(output)), nil
 }
+
+func (s *Server) handleCompactIndex(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result, err := s.indexer.Compact(req.GetString("index_path", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to compact index: %v", err)), nil
+	}
+
+	output, _ := json.MarshalIndent(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Removed %d orphaned and %d duplicate chunks, reclaimed %d bytes",
+			result.OrphanedChunks, result.DuplicateChunks, result.BytesReclaimed),
+		"result": result,
+	}, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// progressNotifier returns a function that sends each message to the client
+// as a progress notification, if the client asked for progress with a
+// progress token. Otherwise the function does nothing.
+func progressNotifier(ctx context.Context, req mcp.CallToolRequest) func(string) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil || req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return func(string) {}
+	}
+
+	token := req.Params.Meta.ProgressToken
+	step := 0
+	return func(msg string) {
+		step++
+		// Progress is best effort; the call goes on if the client is gone
+		_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      step,
+			"message":       msg,
+		})
+	}
+}
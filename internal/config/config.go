@@ -1,10 +1,11 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/env"
@@ -29,8 +30,16 @@ type Config struct {
 	MCP       MCPConfig       `koanf:"mcp"`
 	Scheduler SchedulerConfig `koanf:"scheduler"`
 
+	MissingEnv string `koanf:"missing_env"` // ${VAR} of an unset variable: "error" or "empty"
+
+	Profile  string            `koanf:"profile"`  // Active profile, merged over the settings above
+	Profiles map[string]Config `koanf:"profiles"` // Named setups overriding any top-level keys
+
 	// Deprecated: Use DeepSeek config instead. Kept for backwards compatibility.
 	API APIConfig `koanf:"api"`
+
+	issues    []Issue // Mistakes found in the files while loading, reported by Validate
+	mcpIssues []Issue // Problems in the MCP config file; the servers they affect are skipped
 }
 
 type SchedulerConfig struct {
@@ -47,9 +56,16 @@ type TelegramConfig struct {
 }
 
 type MCPConfig struct {
-	Enabled    bool              `koanf:"enabled"`
-	ConfigFile string            `koanf:"config_file"` // Path to mcp.json (default: ~/.cli-chat/mcp.json)
-	Servers    []MCPServerConfig // Loaded from mcp.json only
+	Enabled      bool              `koanf:"enabled"`
+	ConfigFile   string            `koanf:"config_file"`   // Path to mcp.json (default: ~/.cli-chat/mcp.json)
+	CallTimeout  int               `koanf:"call_timeout"`  // Seconds a single tool call may run
+	Confirm      bool              `koanf:"confirm"`       // Ask before each tool call runs
+	Plan         bool              `koanf:"plan"`          // Review each batch of tool calls before any runs
+	AutoApprove  []string          `koanf:"auto_approve"`  // Tools run without asking when Confirm is on
+	AuditLog     string            `koanf:"audit_log"`     // JSONL file recording every tool call (empty = off)
+	AuditRedact  []string          `koanf:"audit_redact"`  // Parts of argument keys redacted in the audit log
+	AllowedRoots []string          `koanf:"allowed_roots"` // Directories filesystem tools may use (empty = any)
+	Servers      []MCPServerConfig // Loaded from mcp.json only
 }
 
 type MCPServerConfig struct {
@@ -58,6 +74,13 @@ type MCPServerConfig struct {
 	Args    []string          `json:"args"`
 	Env     []string          `json:"-"`           // Internal format: ["KEY=value"]
 	EnvMap  map[string]string `json:"env,omitempty"` // JSON format: {"KEY": "value"}
+
+	// A remote server is reached over SSE at URL instead of running Command
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"` // HTTP headers sent to URL
+
+	DisabledTools []string `json:"disabled_tools,omitempty"` // Tools hidden from the model
+	CallTimeout   int      `json:"call_timeout,omitempty"`   // Seconds, overrides mcp.call_timeout
 }
 
 // MCPJSONConfig represents the Claude Desktop-style JSON config format.
@@ -70,12 +93,18 @@ type MCPServerConfig struct {
 //	    "ios": {
 //	      "command": "./mcp-ios",
 //	      "args": [],
-//	      "env": {"DEBUG": "1"}
+//	      "env": {"DEBUG": "1"},
+//	      "call_timeout": 600
 //	    },
 //	    "github": {
 //	      "command": "npx",
 //	      "args": ["-y", "@modelcontextprotocol/server-github"],
-//	      "env": {"GITHUB_TOKEN": "ghp_xxx"}
+//	      "env": {"GITHUB_TOKEN": "ghp_xxx"},
+//	      "disabled_tools": ["delete_file"]
+//	    },
+//	    "remote": {
+//	      "url": "https://mcp.example.com/sse",
+//	      "headers": {"Authorization": "Bearer xxx"}
 //	    }
 //	  }
 //	}
@@ -84,14 +113,74 @@ type MCPJSONConfig struct {
 }
 
 type DeepSeekConfig struct {
-	APIKey  string `koanf:"api_key"`
-	BaseURL string `koanf:"base_url"`
-	Timeout int    `koanf:"timeout"`
+	APIKey  string                   `koanf:"api_key"`
+	BaseURL string                   `koanf:"base_url"`
+	Timeout int                      `koanf:"timeout"`
+	Compat  CompatConfig             `koanf:"compat"`
+	Pricing map[string]PricingConfig `koanf:"pricing"` // per-model prices overriding the built-in table
+
+	VisionModels []string `koanf:"vision_models"` // models that accept images (/image)
+}
+
+// PricingConfig is the price of a model per 1M tokens (USD).
+type PricingConfig struct {
+	Input         float64 `koanf:"input"`           // input tokens (cache miss)
+	InputCacheHit float64 `koanf:"input_cache_hit"` // input tokens served from the context cache; 0 = same as input
+	Output        float64 `koanf:"output"`
+}
+
+// Message roles and tool schemas for CompatConfig.
+const (
+	RoleSystem    = "system"
+	RoleDeveloper = "developer"
+	RoleUser      = "user"
+	RoleTool      = "tool"
+	RoleFunction  = "function"
+
+	ToolSchemaTools     = "tools"
+	ToolSchemaFunctions = "functions"
+	ToolSchemaNone      = "none"
+)
+
+// CompatConfig describes which parts of the chat schema an OpenAI-compatible
+// server accepts, so requests can be adapted for stricter servers.
+type CompatConfig struct {
+	SystemRole string `koanf:"system_role"` // system, developer, or user (folded into the next user message)
+	ToolRole   string `koanf:"tool_role"`   // tool, function, or user (tool results sent as text)
+	ToolSchema string `koanf:"tool_schema"` // tools, functions (legacy function calling), or none
+}
+
+// IsDefault reports whether c describes the standard OpenAI schema.
+func (c CompatConfig) IsDefault() bool {
+	return (c.SystemRole == "" || c.SystemRole == RoleSystem) &&
+		(c.ToolRole == "" || c.ToolRole == RoleTool) &&
+		(c.ToolSchema == "" || c.ToolSchema == ToolSchemaTools)
+}
+
+// Validate checks that all compat values are known.
+func (c CompatConfig) Validate() error {
+	switch c.SystemRole {
+	case "", RoleSystem, RoleDeveloper, RoleUser:
+	default:
+		return fmt.Errorf("invalid compat.system_role %q (use %s, %s or %s)", c.SystemRole, RoleSystem, RoleDeveloper, RoleUser)
+	}
+	switch c.ToolRole {
+	case "", RoleTool, RoleFunction, RoleUser:
+	default:
+		return fmt.Errorf("invalid compat.tool_role %q (use %s, %s or %s)", c.ToolRole, RoleTool, RoleFunction, RoleUser)
+	}
+	switch c.ToolSchema {
+	case "", ToolSchemaTools, ToolSchemaFunctions, ToolSchemaNone:
+	default:
+		return fmt.Errorf("invalid compat.tool_schema %q (use %s, %s or %s)", c.ToolSchema, ToolSchemaTools, ToolSchemaFunctions, ToolSchemaNone)
+	}
+	return nil
 }
 
 type OllamaConfig struct {
-	BaseURL string `koanf:"base_url"`
-	Timeout int    `koanf:"timeout"`
+	BaseURL  string `koanf:"base_url"`
+	Timeout  int    `koanf:"timeout"`
+	AutoPull bool   `koanf:"auto_pull"` // Pull a missing model before first use
 }
 
 // APIConfig is kept for backwards compatibility with old config files.
@@ -110,38 +199,139 @@ type ModelConfig struct {
 }
 
 type ContextConfig struct {
-	SummarizeAt   float64 `koanf:"summarize_at"`   // Threshold percentage to trigger summarization (0.70 = 70%)
-	TargetAfter   float64 `koanf:"target_after"`   // Target percentage after summarization (0.40 = 40%)
-	AutoSummarize bool    `koanf:"auto_summarize"` // Enable automatic summarization
+	SummarizeAt   float64 `koanf:"summarize_at"`    // Threshold percentage to trigger summarization (0.70 = 70%)
+	TargetAfter   float64 `koanf:"target_after"`    // Target percentage after summarization (0.40 = 40%)
+	AutoSummarize bool    `koanf:"auto_summarize"`  // Enable automatic summarization
+	KeepLastPairs int     `koanf:"keep_last_pairs"` // Recent user/assistant pairs kept verbatim when summarizing
+	SummaryPrompt string  `koanf:"summary_prompt"`  // Instructions for the summary (empty = built-in prompt)
+	Strategy      string  `koanf:"strategy"`        // single or map-reduce
+	ChunkTokens   int     `koanf:"chunk_tokens"`    // Map-reduce chunk size in estimated tokens
+
+	ProjectContext bool `koanf:"project_context"` // Describe the project in the working directory to the model
 }
 
+// Summarization strategies for ContextConfig.Strategy.
+const (
+	SummaryStrategySingle    = "single"     // One request for the whole history
+	SummaryStrategyMapReduce = "map-reduce" // Summarize chunks, then merge the summaries
+)
+
 type SessionConfig struct {
 	MaxHistory  int    `koanf:"max_history"`
 	SaveHistory bool   `koanf:"save_history"`
 	HistoryFile string `koanf:"history_file"`
+	BackupCount int    `koanf:"backup_count"` // Timestamped history backups to keep (0 = disabled)
+	BackupDir   string `koanf:"backup_dir"`   // Directory for history backups
+
+	MaxCostUSD     float64 `koanf:"max_cost_usd"`     // Spending limit per session (0 = unlimited)
+	MaxTokensTotal int     `koanf:"max_tokens_total"` // Token limit per session (0 = unlimited)
+
+	InputHistoryFile       string `koanf:"input_history_file"`        // Up-arrow recall of typed inputs ("" = not kept)
+	InputHistorySize       int    `koanf:"input_history_size"`        // Inputs kept in InputHistoryFile
+	InputHistorySkipSystem bool   `koanf:"input_history_skip_system"` // Don't record /system prompts
 }
 
 type UIConfig struct {
-	ShowTokenCount bool `koanf:"show_token_count"`
-	ColoredOutput  bool `koanf:"colored_output"`
-	ShowTimestamps bool `koanf:"show_timestamps"`
+	ShowTokenCount bool              `koanf:"show_token_count"`
+	ColoredOutput  bool              `koanf:"colored_output"`
+	ShowTimestamps bool              `koanf:"show_timestamps"`
+	Pager          bool              `koanf:"pager"`  // Page output taller than the terminal (/pager)
+	Theme          string            `koanf:"theme"`  // "dark", "light" or "mono"
+	Colors         map[string]string `koanf:"colors"` // Role → color overrides on top of Theme
 }
 
-func Load(configPath string) (*Config, error) {
+// ConfigPathEnv lists extra config files, separated by the OS path list
+// separator (":" on Unix), layered after the system and user files.
+const ConfigPathEnv = "CLI_CHAT_CONFIG_PATH"
+
+// ProfileEnv selects the profile to load, overriding the profile key in
+// the config files.
+const ProfileEnv = "CLI_CHAT_PROFILE"
+
+// ConfigPaths returns the config files to merge, lowest precedence first:
+// the system file, the user file, files from CLI_CHAT_CONFIG_PATH and
+// finally the explicit paths (e.g. repeated --config flags).
+func ConfigPaths(explicit []string) []string {
+	paths := []string{GetSystemConfigPath(), GetDefaultConfigPath()}
+
+	if env := os.Getenv(ConfigPathEnv); env != "" {
+		paths = append(paths, filepath.SplitList(env)...)
+	}
+
+	return append(paths, explicit...)
+}
+
+// Load builds the configuration from defaults, then each config file in
+// order (later files override earlier ones), then environment variables.
+// Missing files are skipped.
+func Load(configPaths ...string) (*Config, error) {
+	return LoadProfile("", configPaths...)
+}
+
+// LoadProfile is Load with the given profile merged over the config files.
+// An empty profile falls back to CLI_CHAT_PROFILE, then to the profile key
+// in the files.
+func LoadProfile(profile string, configPaths ...string) (*Config, error) {
 	k := koanf.New(".")
 
 	if err := k.Load(NewDefaultProvider(), nil); err != nil {
 		return nil, fmt.Errorf("failed to load defaults: %w", err)
 	}
 
-	if configPath != "" {
-		configPath = expandPath(configPath)
+	var issues, envIssues []Issue // envIssues are kept unless missing_env is "empty"
+	seen := make(map[string]bool)
+	profileFiles := make(map[string]string) // Profile key -> file that set it
+	for _, configPath := range configPaths {
+		if configPath == "" {
+			continue
+		}
+		configPath = ExpandPath(configPath)
+		if seen[configPath] {
+			continue
+		}
+		seen[configPath] = true
 
-		if _, err := os.Stat(configPath); err == nil {
-			if err := k.Load(file.Provider(configPath), yaml.Parser()); err != nil {
-				return nil, fmt.Errorf("failed to load config file: %w", err)
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			continue
+		}
+		fileIssues, badKeys, ok := checkYAMLFile(configPath, data)
+		issues = append(issues, fileIssues...)
+		if !ok {
+			continue
+		}
+		fk := koanf.New(".")
+		if err := fk.Load(file.Provider(configPath), yaml.Parser()); err != nil {
+			return nil, fmt.Errorf("failed to load config file %s: %w", configPath, err)
+		}
+		// Drop values of the wrong type so the rest still unmarshals and
+		// Validate can report every issue at once
+		for _, key := range badKeys {
+			key, _, _ = strings.Cut(key, "[")
+			fk.Delete(key)
+		}
+		envIssues = append(envIssues, expandKoanfEnv(fk, configPath)...)
+		for key := range fk.All() {
+			if strings.HasPrefix(key, "profiles.") {
+				profileFiles[key] = configPath
 			}
 		}
+		if err := k.Merge(fk); err != nil {
+			return nil, fmt.Errorf("failed to load config file %s: %w", configPath, err)
+		}
+	}
+
+	if profile == "" {
+		profile = os.Getenv(ProfileEnv)
+	}
+	if profile == "" {
+		profile = k.String("profile")
+	}
+	if profile != "" {
+		envIssues = append(envIssues, expandProfileEnv(k, profile, profileFiles)...)
+		if issue := applyProfile(k, profile); issue != nil {
+			issues = append(issues, *issue)
+		}
 	}
 
 	if err := k.Load(env.Provider("DEEPSEEK_", ".", func(s string) string {
@@ -173,24 +363,57 @@ func Load(configPath string) (*Config, error) {
 		cfg.DeepSeek.Timeout = cfg.API.Timeout
 	}
 
-	cfg.Session.HistoryFile = expandPath(cfg.Session.HistoryFile)
+	cfg.Session.HistoryFile = ExpandPath(cfg.Session.HistoryFile)
+	cfg.Session.BackupDir = ExpandPath(cfg.Session.BackupDir)
+	cfg.Session.InputHistoryFile = ExpandPath(cfg.Session.InputHistoryFile)
+	cfg.MCP.AuditLog = ExpandPath(cfg.MCP.AuditLog)
+
+	cfg.issues = issues
+	if cfg.MissingEnv != MissingEnvEmpty {
+		cfg.issues = append(cfg.issues, envIssues...)
+	}
 
 	// Load MCP servers from JSON config file
-	if err := cfg.LoadMCPServers(); err != nil {
-		// Log warning but don't fail - MCP is optional
-		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	if cfg.MCP.Enabled {
+		cfg.mcpIssues = cfg.LoadMCPServers()
 	}
 
 	return &cfg, nil
 }
 
+// Validate reports the mistakes found in the config files while loading,
+// all at once as a *ValidationError, then checks that the values make sense.
+// Problems in the MCP config file count only if they leave MCP enabled with
+// no usable server; otherwise they are warnings (see MCPWarnings).
 func (c *Config) Validate() error {
+	issues := c.issues
+	if c.MCP.Enabled && len(c.MCP.Servers) == 0 {
+		issues = append(issues[:len(issues):len(issues)], c.mcpIssues...)
+	}
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+
+	switch c.MissingEnv {
+	case "", MissingEnvError, MissingEnvEmpty:
+	default:
+		return fmt.Errorf("invalid missing_env %q (use %s or %s)", c.MissingEnv, MissingEnvError, MissingEnvEmpty)
+	}
+
 	// Provider-specific validation
 	switch c.Provider {
 	case ProviderDeepSeek:
 		if c.DeepSeek.APIKey == "" {
 			return fmt.Errorf("DeepSeek API key is required (set DEEPSEEK_API_KEY or add to config file)")
 		}
+		if err := c.DeepSeek.Compat.Validate(); err != nil {
+			return err
+		}
+		for model, p := range c.DeepSeek.Pricing {
+			if p.Input < 0 || p.InputCacheHit < 0 || p.Output < 0 {
+				return fmt.Errorf("invalid deepseek.pricing for %s: prices cannot be negative", model)
+			}
+		}
 	case ProviderOllama:
 		// Ollama doesn't require API key, but we could check if Ollama is running
 		// For now, just validate that base URL is set (has a default)
@@ -198,6 +421,9 @@ func (c *Config) Validate() error {
 			c.Ollama.BaseURL = "http://localhost:11434"
 		}
 	default:
+		if s := suggest(c.Provider, []string{ProviderDeepSeek, ProviderOllama}); s != "" {
+			return fmt.Errorf("unknown provider: %s (did you mean %s?)", c.Provider, s)
+		}
 		return fmt.Errorf("unknown provider: %s (supported: %s, %s)",
 			c.Provider, ProviderDeepSeek, ProviderOllama)
 	}
@@ -206,6 +432,21 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("model name is required")
 	}
 
+	if c.Context.KeepLastPairs < 0 {
+		return fmt.Errorf("context.keep_last_pairs cannot be negative")
+	}
+
+	switch c.Context.Strategy {
+	case "", SummaryStrategySingle:
+	case SummaryStrategyMapReduce:
+		if c.Context.ChunkTokens <= 0 {
+			return fmt.Errorf("context.chunk_tokens must be positive for the %s strategy", SummaryStrategyMapReduce)
+		}
+	default:
+		return fmt.Errorf("invalid context.strategy %q (use %s or %s)",
+			c.Context.Strategy, SummaryStrategySingle, SummaryStrategyMapReduce)
+	}
+
 	if c.Model.MaxTokens <= 0 {
 		return fmt.Errorf("max_tokens must be positive")
 	}
@@ -218,6 +459,18 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max_history must be positive")
 	}
 
+	if c.Session.BackupCount < 0 {
+		return fmt.Errorf("backup_count must not be negative")
+	}
+
+	if c.Session.MaxCostUSD < 0 || c.Session.MaxTokensTotal < 0 {
+		return fmt.Errorf("max_cost_usd and max_tokens_total must not be negative")
+	}
+
+	if c.Session.InputHistoryFile != "" && c.Session.InputHistorySize <= 0 {
+		return fmt.Errorf("input_history_size must be positive")
+	}
+
 	return nil
 }
 
@@ -250,7 +503,8 @@ func (c *Config) GetProviderConfig() *ProviderConfig {
 	}
 }
 
-func expandPath(path string) string {
+// ExpandPath replaces a leading "~/" in path with the home directory.
+func ExpandPath(path string) string {
 	if path == "" {
 		return path
 	}
@@ -266,14 +520,12 @@ func expandPath(path string) string {
 	return path
 }
 
-// LoadMCPServers loads MCP server configuration from the JSON config file.
-func (c *Config) LoadMCPServers() error {
-	// Determine config file path
-	configFile := c.MCP.ConfigFile
-	if configFile == "" {
-		configFile = "~/.cli-chat/mcp.json"
-	}
-	configFile = expandPath(configFile)
+// LoadMCPServers loads MCP server configuration from the JSON config file
+// and returns the problems found in it. A server with a problem that keeps
+// it from running, such as a missing command or an unset ${VAR} (unless
+// missing_env is "empty"), is skipped; the others are loaded.
+func (c *Config) LoadMCPServers() []Issue {
+	configFile := c.GetMCPConfigPath()
 
 	// Check if file exists
 	data, err := os.ReadFile(configFile)
@@ -281,18 +533,19 @@ func (c *Config) LoadMCPServers() error {
 		if os.IsNotExist(err) {
 			return nil
 		}
-		return fmt.Errorf("failed to read MCP config file: %w", err)
+		return []Issue{{File: configFile, Message: fmt.Sprintf("failed to read MCP config file: %v", err)}}
 	}
 
-	// Parse JSON
-	var jsonConfig MCPJSONConfig
-	if err := json.Unmarshal(data, &jsonConfig); err != nil {
-		return fmt.Errorf("failed to parse MCP config file %s: %w", configFile, err)
-	}
+	servers, issues := checkMCPJSON(configFile, data)
 
 	// Convert JSON format to MCPServerConfig slice
-	for name, server := range jsonConfig.MCPServers {
+	for _, name := range sortedKeys(servers) {
+		server := servers[name]
 		server.Name = name
+		if envIssues := expandServerEnv(&server, configFile); len(envIssues) > 0 && c.MissingEnv != MissingEnvEmpty {
+			issues = append(issues, envIssues...)
+			continue
+		}
 
 		// Convert EnvMap to Env slice
 		if server.EnvMap != nil {
@@ -300,17 +553,20 @@ func (c *Config) LoadMCPServers() error {
 			for k, v := range server.EnvMap {
 				server.Env = append(server.Env, k+"="+v)
 			}
+			// Sorted, so an unchanged file gives an equal config on reload
+			sort.Strings(server.Env)
 		}
 
 		c.MCP.Servers = append(c.MCP.Servers, server)
 	}
 
-	// Enable MCP if we have any servers
-	if len(c.MCP.Servers) > 0 {
-		c.MCP.Enabled = true
-	}
+	return issues
+}
 
-	return nil
+// MCPWarnings returns the problems found in the MCP config file while
+// loading. They only fail Validate when no server could be loaded.
+func (c *Config) MCPWarnings() []Issue {
+	return c.mcpIssues
 }
 
 // GetMCPConfigPath returns the path to the MCP JSON config file.
@@ -319,5 +575,5 @@ func (c *Config) GetMCPConfigPath() string {
 	if configFile == "" {
 		configFile = "~/.cli-chat/mcp.json"
 	}
-	return expandPath(configFile)
+	return ExpandPath(configFile)
 }
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// loadTestConfig loads a config file with the given mcp section whose
+// mcp.json holds mcpJSON.
+func loadTestConfig(t *testing.T, mcpYAML, mcpJSON string) *Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	mcpFile := filepath.Join(dir, "mcp.json")
+	if err := os.WriteFile(mcpFile, []byte(mcpJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "config.yaml")
+	data := "deepseek:\n  api_key: test\nmcp:\n  config_file: " + mcpFile + "\n" + mcpYAML
+	if err := os.WriteFile(configFile, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestMCPIssuesSkipOnlyTheBadServer(t *testing.T) {
+	t.Setenv("CLI_CHAT_TEST_UNSET", "")
+	os.Unsetenv("CLI_CHAT_TEST_UNSET")
+
+	tests := []struct {
+		name         string
+		mcpYAML      string
+		mcpJSON      string
+		wantServers  []string
+		wantWarnings int
+		wantInvalid  bool
+	}{
+		{
+			name:        "all servers fine",
+			mcpJSON:     `{"mcpServers": {"a": {"command": "a"}, "b": {"url": "http://b"}}}`,
+			wantServers: []string{"a", "b"},
+		},
+		{
+			name:         "server without a command",
+			mcpJSON:      `{"mcpServers": {"a": {"command": "a"}, "b": {"args": ["x"]}}}`,
+			wantServers:  []string{"a"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "server with a value of the wrong type",
+			mcpJSON:      `{"mcpServers": {"a": {"command": "a"}, "b": {"command": "b", "args": "x"}}}`,
+			wantServers:  []string{"a"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "unset variable in one server",
+			mcpJSON:      `{"mcpServers": {"a": {"command": "a"}, "b": {"command": "b", "env": {"T": "${CLI_CHAT_TEST_UNSET}"}}}}`,
+			wantServers:  []string{"a"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "unknown key keeps the server",
+			mcpJSON:      `{"mcpServers": {"a": {"command": "a", "arg": ["x"]}}}`,
+			wantServers:  []string{"a"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "no usable server",
+			mcpJSON:      `{"mcpServers": {"a": {"args": ["x"]}}}`,
+			wantWarnings: 1,
+			wantInvalid:  true,
+		},
+		{
+			name:         "invalid JSON",
+			mcpJSON:      `{"mcpServers": {`,
+			wantWarnings: 1,
+			wantInvalid:  true,
+		},
+		{
+			name:    "MCP disabled",
+			mcpYAML: "  enabled: false\n",
+			mcpJSON: `{"mcpServers": {`,
+		},
+		{
+			name:    "no servers configured",
+			mcpJSON: `{"mcpServers": {}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, tt.mcpYAML, tt.mcpJSON)
+
+			var names []string
+			for _, s := range cfg.MCP.Servers {
+				names = append(names, s.Name)
+			}
+			if !slices.Equal(names, tt.wantServers) {
+				t.Errorf("servers = %v, want %v", names, tt.wantServers)
+			}
+
+			if got := len(cfg.MCPWarnings()); got != tt.wantWarnings {
+				t.Errorf("%d warnings (%v), want %d", got, cfg.MCPWarnings(), tt.wantWarnings)
+			}
+
+			err := cfg.Validate()
+			var verr *ValidationError
+			if tt.wantInvalid != errors.As(err, &verr) {
+				t.Errorf("Validate() = %v, want invalid: %v", err, tt.wantInvalid)
+			}
+			if !tt.wantInvalid && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+		})
+	}
+}
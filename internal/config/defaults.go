@@ -6,15 +6,22 @@ import (
 
 func DefaultConfig() map[string]interface{} {
 	return map[string]interface{}{
-		"provider": "deepseek",
+		"provider":    "deepseek",
+		"missing_env": "error",
 		"deepseek": map[string]interface{}{
 			"api_key":  "",
 			"base_url": "https://api.deepseek.com",
 			"timeout":  120,
+			"compat": map[string]interface{}{
+				"system_role": "system",
+				"tool_role":   "tool",
+				"tool_schema": "tools",
+			},
 		},
 		"ollama": map[string]interface{}{
-			"base_url": "http://localhost:11434",
-			"timeout":  120,
+			"base_url":  "http://localhost:11434",
+			"timeout":   120,
+			"auto_pull": false,
 		},
 		// Deprecated: kept for backwards compatibility
 		"api": map[string]interface{}{
@@ -30,23 +37,42 @@ func DefaultConfig() map[string]interface{} {
 			"context_window": 0, // 0 means use default for model
 		},
 		"context": map[string]interface{}{
-			"summarize_at":   0.70, // Summarize when context reaches 70%
-			"target_after":   0.40, // Target 40% after summarization
-			"auto_summarize": true, // Enable auto-summarization
+			"summarize_at":    0.70,     // Summarize when context reaches 70%
+			"target_after":    0.40,     // Target 40% after summarization
+			"auto_summarize":  true,     // Enable auto-summarization
+			"keep_last_pairs": 4,        // Keep the last 4 pairs verbatim
+			"summary_prompt":  "",       // Built-in summary prompt
+			"strategy":        "single", // Summarize in one request
+			"chunk_tokens":    8000,     // Map-reduce chunk size
+			"project_context": true,     // Detect the project in the working directory
 		},
 		"session": map[string]interface{}{
-			"max_history":  50,
-			"save_history": false,
-			"history_file": "~/.cli-chat/history.json",
+			"max_history":      50,
+			"save_history":     false,
+			"history_file":     "~/.cli-chat/history.json",
+			"backup_count":     5,
+			"backup_dir":       "~/.cli-chat/history-backups",
+			"max_cost_usd":     0.0,
+			"max_tokens_total": 0,
+
+			"input_history_file":        "~/.cli-chat/repl_history",
+			"input_history_size":        1000,
+			"input_history_skip_system": true,
 		},
 		"ui": map[string]interface{}{
 			"show_token_count": true,
 			"colored_output":   true,
 			"show_timestamps":  false,
+			"theme":            "dark",
+			"pager":            false,
 		},
 		"mcp": map[string]interface{}{
-			"enabled":     true,
-			"config_file": "~/.cli-chat/mcp.json",
+			"enabled":      true,
+			"config_file":  "~/.cli-chat/mcp.json",
+			"call_timeout": 60,
+			"confirm":      false,
+			"plan":         false,
+			"allowed_roots": []string{"."},
 		},
 		"scheduler": map[string]interface{}{
 			"enabled":  false,
@@ -69,6 +95,12 @@ func GetDefaultConfigPath() string {
 	return "~/.cli-chat/config.yaml"
 }
 
+// GetSystemConfigPath returns the shared, machine-wide config file that is
+// loaded before the per-user one.
+func GetSystemConfigPath() string {
+	return "/etc/cli-chat/config.yaml"
+}
+
 func GetDefaultMCPConfigPath() string {
 	return "~/.cli-chat/mcp.json"
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// What to do with ${VAR} references to unset environment variables.
+const (
+	MissingEnvError = "error" // Report them as config issues
+	MissingEnvEmpty = "empty" // Expand them to ""
+)
+
+// envRefRe matches ${NAME} references to environment variables, and
+// $${NAME}, which stands for a literal ${NAME}.
+var envRefRe = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${NAME} in s with the value of the environment
+// variable NAME. Unset variables expand to "" and are returned in missing.
+func expandEnv(s string) (expanded string, missing []string) {
+	expanded = envRefRe.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref[1] == '$' {
+			return ref[1:] // Escaped
+		}
+		name := ref[2 : len(ref)-1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	return expanded, missing
+}
+
+// expandKoanfEnv expands ${NAME} references in the string values loaded
+// from file into k, including strings in lists. It returns an issue for
+// every reference to an unset variable. Profiles are left alone until one
+// is selected (see expandProfileEnv), so variables that only another
+// profile uses need not be set.
+func expandKoanfEnv(k *koanf.Koanf, file string) []Issue {
+	return expandKoanfKeys(k,
+		func(key string) bool { return !strings.HasPrefix(key, "profiles.") },
+		func(string) string { return file })
+}
+
+// expandProfileEnv expands ${NAME} references in profiles.<name>. files
+// maps each key to the config file that set it.
+func expandProfileEnv(k *koanf.Koanf, name string, files map[string]string) []Issue {
+	prefix := "profiles." + name + "."
+	return expandKoanfKeys(k,
+		func(key string) bool { return strings.HasPrefix(key, prefix) },
+		func(key string) string { return files[key] })
+}
+
+// expandKoanfKeys expands ${NAME} references in the values of the keys of
+// k that match, reporting unset variables against fileOf(key).
+func expandKoanfKeys(k *koanf.Koanf, match func(key string) bool, fileOf func(key string) string) []Issue {
+	var issues []Issue
+	expand := func(key, path, s string) string {
+		expanded, missing := expandEnv(s)
+		for _, name := range missing {
+			issues = append(issues, missingEnvIssue(fileOf(key), path, name))
+		}
+		return expanded
+	}
+
+	for key, value := range k.All() {
+		if !match(key) {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			if expanded := expand(key, key, v); expanded != v {
+				k.Set(key, expanded)
+			}
+		case []interface{}:
+			changed := false
+			for i, item := range v {
+				if s, ok := item.(string); ok {
+					if expanded := expand(key, fmt.Sprintf("%s[%d]", key, i), s); expanded != s {
+						v[i] = expanded
+						changed = true
+					}
+				}
+			}
+			if changed {
+				k.Set(key, v)
+			}
+		}
+	}
+	return issues
+}
+
+// expandServerEnv expands ${NAME} references in the command, arguments,
+// environment, URL and headers of an MCP server read from file.
+func expandServerEnv(server *MCPServerConfig, file string) []Issue {
+	var issues []Issue
+	expand := func(path, s string) string {
+		expanded, missing := expandEnv(s)
+		for _, name := range missing {
+			issues = append(issues, missingEnvIssue(file, path, name))
+		}
+		return expanded
+	}
+
+	prefix := "mcpServers." + server.Name
+	server.Command = expand(prefix+".command", server.Command)
+	for i, arg := range server.Args {
+		server.Args[i] = expand(fmt.Sprintf("%s.args[%d]", prefix, i), arg)
+	}
+	for _, key := range sortedKeys(server.EnvMap) {
+		server.EnvMap[key] = expand(prefix+".env."+key, server.EnvMap[key])
+	}
+	server.URL = expand(prefix+".url", server.URL)
+	for _, key := range sortedKeys(server.Headers) {
+		server.Headers[key] = expand(prefix+".headers."+key, server.Headers[key])
+	}
+	return issues
+}
+
+func missingEnvIssue(file, path, name string) Issue {
+	return Issue{
+		File:    file,
+		Path:    path,
+		Message: fmt.Sprintf("environment variable %s is not set (set it, or set missing_env: %s to allow this)", name, MissingEnvEmpty),
+	}
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("CLI_CHAT_TEST_SET", "value")
+	t.Setenv("CLI_CHAT_TEST_EMPTY", "")
+	t.Setenv("CLI_CHAT_TEST_UNSET", "")
+	os.Unsetenv("CLI_CHAT_TEST_UNSET")
+
+	tests := []struct {
+		in          string
+		want        string
+		wantMissing []string
+	}{
+		{"plain", "plain", nil},
+		{"${CLI_CHAT_TEST_SET}", "value", nil},
+		{"a-${CLI_CHAT_TEST_SET}-b", "a-value-b", nil},
+		{"${CLI_CHAT_TEST_SET}${CLI_CHAT_TEST_SET}", "valuevalue", nil},
+		{"${CLI_CHAT_TEST_EMPTY}", "", nil},
+		{"$${CLI_CHAT_TEST_SET}", "${CLI_CHAT_TEST_SET}", nil},
+		{"$${CLI_CHAT_TEST_UNSET}", "${CLI_CHAT_TEST_UNSET}", nil},
+		{"x${CLI_CHAT_TEST_UNSET}y", "xy", []string{"CLI_CHAT_TEST_UNSET"}},
+		{"$CLI_CHAT_TEST_SET", "$CLI_CHAT_TEST_SET", nil}, // Only the braced form
+		{"${1ABC}", "${1ABC}", nil},                       // Not a variable name
+		{"${}", "${}", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, missing := expandEnv(tt.in)
+			if got != tt.want {
+				t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("expandEnv(%q) missing = %v, want %v", tt.in, missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestExpandServerEnv(t *testing.T) {
+	t.Setenv("CLI_CHAT_TEST_TOKEN", "secret")
+	t.Setenv("CLI_CHAT_TEST_UNSET", "")
+	os.Unsetenv("CLI_CHAT_TEST_UNSET")
+
+	tests := []struct {
+		name      string
+		server    MCPServerConfig
+		want      MCPServerConfig
+		wantPaths []string // Paths of the issues
+	}{
+		{
+			name: "every field is expanded",
+			server: MCPServerConfig{
+				Name:    "gh",
+				Command: "${CLI_CHAT_TEST_TOKEN}-cmd",
+				Args:    []string{"--token", "${CLI_CHAT_TEST_TOKEN}"},
+				EnvMap:  map[string]string{"TOKEN": "${CLI_CHAT_TEST_TOKEN}"},
+				URL:     "https://example.com/${CLI_CHAT_TEST_TOKEN}",
+				Headers: map[string]string{"Authorization": "Bearer ${CLI_CHAT_TEST_TOKEN}"},
+			},
+			want: MCPServerConfig{
+				Name:    "gh",
+				Command: "secret-cmd",
+				Args:    []string{"--token", "secret"},
+				EnvMap:  map[string]string{"TOKEN": "secret"},
+				URL:     "https://example.com/secret",
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+			},
+		},
+		{
+			name: "escaped references are kept",
+			server: MCPServerConfig{
+				Name: "s",
+				Args: []string{"$${CLI_CHAT_TEST_TOKEN}"},
+			},
+			want: MCPServerConfig{
+				Name: "s",
+				Args: []string{"${CLI_CHAT_TEST_TOKEN}"},
+			},
+		},
+		{
+			name: "unset variables are reported",
+			server: MCPServerConfig{
+				Name:    "s",
+				Command: "run",
+				Args:    []string{"${CLI_CHAT_TEST_UNSET}"},
+				EnvMap:  map[string]string{"A": "${CLI_CHAT_TEST_UNSET}"},
+			},
+			want: MCPServerConfig{
+				Name:    "s",
+				Command: "run",
+				Args:    []string{""},
+				EnvMap:  map[string]string{"A": ""},
+			},
+			wantPaths: []string{"mcpServers.s.args[0]", "mcpServers.s.env.A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server
+			issues := expandServerEnv(&server, "mcp.json")
+
+			if !reflect.DeepEqual(server, tt.want) {
+				t.Errorf("server = %+v, want %+v", server, tt.want)
+			}
+
+			var paths []string
+			for _, issue := range issues {
+				paths = append(paths, issue.Path)
+				if issue.File != "mcp.json" {
+					t.Errorf("issue file = %q, want mcp.json", issue.File)
+				}
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("issue paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestMissingEnvSetting(t *testing.T) {
+	t.Setenv("CLI_CHAT_TEST_UNSET", "")
+	os.Unsetenv("CLI_CHAT_TEST_UNSET")
+
+	tests := []struct {
+		missingEnv  string
+		wantServers int
+		wantModel   string
+	}{
+		{MissingEnvError, 0, ""},
+		{MissingEnvEmpty, 1, "x-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.missingEnv, func(t *testing.T) {
+			cfg := loadTestConfig(t, "missing_env: "+tt.missingEnv+"\nmodel:\n  name: x-${CLI_CHAT_TEST_UNSET}\n",
+				`{"mcpServers": {"a": {"command": "a", "args": ["${CLI_CHAT_TEST_UNSET}"]}}}`)
+
+			if len(cfg.MCP.Servers) != tt.wantServers {
+				t.Errorf("%d servers, want %d", len(cfg.MCP.Servers), tt.wantServers)
+			}
+			err := cfg.Validate()
+			if tt.missingEnv == MissingEnvError && err == nil {
+				t.Error("Validate() = nil, want the unset variable reported")
+			}
+			if tt.missingEnv == MissingEnvEmpty {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				if cfg.Model.Name != tt.wantModel {
+					t.Errorf("model name = %q, want %q", cfg.Model.Name, tt.wantModel)
+				}
+			}
+		})
+	}
+}
+
+func TestInactiveProfileEnvIsNotExpanded(t *testing.T) {
+	t.Setenv("CLI_CHAT_TEST_PERSONAL_KEY", "personal-key")
+	t.Setenv("CLI_CHAT_TEST_WORK_KEY", "")
+	os.Unsetenv("CLI_CHAT_TEST_WORK_KEY")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	data := `profiles:
+  personal:
+    deepseek:
+      api_key: ${CLI_CHAT_TEST_PERSONAL_KEY}
+  work:
+    deepseek:
+      api_key: ${CLI_CHAT_TEST_WORK_KEY}
+`
+	if err := os.WriteFile(configFile, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProfile("personal", configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if cfg.DeepSeek.APIKey != "personal-key" {
+		t.Errorf("api key = %q, want %q", cfg.DeepSeek.APIKey, "personal-key")
+	}
+
+	// The selected profile's references still count
+	cfg, err = LoadProfile("work", configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var verr *ValidationError
+	if err := cfg.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want the unset variable reported", err)
+	}
+	if len(verr.Issues) != 1 || verr.Issues[0].File != configFile || verr.Issues[0].Path != "profiles.work.deepseek.api_key" {
+		t.Errorf("issues = %+v, want one for profiles.work.deepseek.api_key in %s", verr.Issues, configFile)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// InitOptions are the choices asked for when scaffolding a config file.
+type InitOptions struct {
+	Provider string // ProviderDeepSeek or ProviderOllama
+	APIKey   string // DeepSeek API key; empty leaves it to DEEPSEEK_API_KEY
+	Model    string // Model name; empty uses the default
+}
+
+// initTemplate is the config.yaml written by InitConfig. Settings the user
+// is not asked about are filled in from DefaultConfig, so the file starts
+// out equivalent to having no config at all. See config.example.yaml for
+// every option.
+var initTemplate = template.Must(template.New("config.yaml").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`# CLI Chat Configuration
+# Generated by "chat --init". See config.example.yaml for every option.
+
+# Provider to use: "deepseek" or "ollama"
+provider: {{.Provider}}
+
+# DeepSeek API Configuration
+deepseek:
+  # API key - can be overridden by DEEPSEEK_API_KEY environment variable
+  # Get your key at: https://platform.deepseek.com/api_keys
+  api_key: {{quote .APIKey}}
+
+  # Base URL for the API
+  base_url: {{quote .Config.DeepSeek.BaseURL}}
+
+  # Request timeout in seconds
+  timeout: {{.Config.DeepSeek.Timeout}}
+
+# Ollama Configuration (for local models)
+ollama:
+  # Base URL for Ollama server
+  base_url: {{quote .Config.Ollama.BaseURL}}
+
+  # Request timeout in seconds
+  timeout: {{.Config.Ollama.Timeout}}
+
+  # Pull the model before first use if it is not installed
+  auto_pull: {{.Config.Ollama.AutoPull}}
+
+# Model Configuration
+model:
+  # Model name ("deepseek-chat", "deepseek-reasoner", or an Ollama model)
+  name: {{quote .Model}}
+
+  # Maximum tokens in response
+  max_tokens: {{.Config.Model.MaxTokens}}
+
+  # Temperature (0.0 - 2.0)
+  temperature: {{.Config.Model.Temperature}}
+
+  # System prompt to set assistant behavior
+  system_prompt: {{quote .Config.Model.SystemPrompt}}
+
+# Session Configuration
+session:
+  # Maximum number of messages to keep in history
+  max_history: {{.Config.Session.MaxHistory}}
+
+  # Save conversation history between runs
+  save_history: {{.Config.Session.SaveHistory}}
+
+  # History file path
+  history_file: {{quote .Config.Session.HistoryFile}}
+
+# UI Configuration
+ui:
+  # Show token usage after each response
+  show_token_count: {{.Config.UI.ShowTokenCount}}
+
+  # Enable colored output
+  colored_output: {{.Config.UI.ColoredOutput}}
+
+  # Color theme: "dark", "light" or "mono"
+  theme: {{quote .Config.UI.Theme}}
+
+# MCP Configuration
+mcp:
+  # Enable MCP servers listed in the config file below
+  enabled: {{.Config.MCP.Enabled}}
+
+  # Path to the Claude Desktop-style server list
+  config_file: {{quote .Config.MCP.ConfigFile}}
+
+  # Seconds a single tool call may run
+  call_timeout: {{.Config.MCP.CallTimeout}}
+`))
+
+// InitConfig renders a commented config.yaml for opts.
+func InitConfig(opts InitOptions) (string, error) {
+	k := koanf.New(".")
+	if err := k.Load(NewDefaultProvider(), nil); err != nil {
+		return "", fmt.Errorf("failed to load defaults: %w", err)
+	}
+	var defaults Config
+	if err := k.Unmarshal("", &defaults); err != nil {
+		return "", fmt.Errorf("failed to unmarshal defaults: %w", err)
+	}
+
+	switch opts.Provider {
+	case "":
+		opts.Provider = defaults.Provider
+	case ProviderDeepSeek, ProviderOllama:
+	default:
+		return "", fmt.Errorf("unknown provider %q (use %s or %s)", opts.Provider, ProviderDeepSeek, ProviderOllama)
+	}
+	if opts.Model == "" {
+		opts.Model = defaults.Model.Name
+	}
+
+	var sb strings.Builder
+	err := initTemplate.Execute(&sb, struct {
+		InitOptions
+		Config Config
+	}{opts, defaults})
+	if err != nil {
+		return "", fmt.Errorf("failed to render config: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// InitMCPConfig returns an example mcp.json with a single filesystem server
+// that needs no credentials, as a starting point for adding others.
+func InitMCPConfig() (string, error) {
+	example := MCPJSONConfig{
+		MCPServers: map[string]MCPServerConfig{
+			"filesystem": {
+				Command: "npx",
+				Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "."},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode MCP config: %w", err)
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// applyProfile merges profiles.<name> over the top-level keys of k, so a
+// profile only lists the settings it changes. Maps merge key by key; lists
+// and other values are replaced.
+func applyProfile(k *koanf.Koanf, name string) *Issue {
+	names := k.MapKeys("profiles")
+	sort.Strings(names)
+	if !k.Exists("profiles." + name) {
+		msg := fmt.Sprintf("unknown profile %q", name)
+		switch {
+		case len(names) == 0:
+			msg += " (no profiles are defined)"
+		case suggest(name, names) != "":
+			msg += fmt.Sprintf(" (did you mean %q?)", suggest(name, names))
+		default:
+			msg += fmt.Sprintf(" (available: %s)", strings.Join(names, ", "))
+		}
+		return &Issue{Path: "profiles", Message: msg}
+	}
+
+	if err := k.Merge(k.Cut("profiles." + name)); err != nil {
+		return &Issue{Path: "profiles." + name, Message: err.Error()}
+	}
+	k.Set("profile", name)
+	return nil
+}
+
+// ProfileNames returns the names of the profiles defined in the config.
+func (c *Config) ProfileNames() []string {
+	return sortedKeys(c.Profiles)
+}
@@ -0,0 +1,376 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Issue is a mistake found in a config file, located as precisely as the
+// file allows.
+type Issue struct {
+	File    string // Path of the file
+	Line    int    // 1-based line, 0 if unknown
+	Path    string // Dotted key, e.g. "model.temperature"
+	Message string // What is wrong, with a suggestion where there is one
+}
+
+func (i Issue) Error() string {
+	var sb strings.Builder
+	if i.File != "" {
+		sb.WriteString(i.File)
+		if i.Line > 0 {
+			fmt.Fprintf(&sb, ":%d", i.Line)
+		}
+		sb.WriteString(": ")
+	}
+	if i.Path != "" {
+		sb.WriteString(i.Path + ": ")
+	}
+	sb.WriteString(i.Message)
+	return sb.String()
+}
+
+// ValidationError lists every issue found in the config files, so they can
+// all be fixed in one go.
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Issues) == 1 {
+		return e.Issues[0].Error()
+	}
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = "  " + issue.Error()
+	}
+	return fmt.Sprintf("%d problems found:\n%s", len(e.Issues), strings.Join(lines, "\n"))
+}
+
+// yamlLineRe extracts the line number from a YAML syntax error.
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// checkYAMLFile checks a config file against the Config schema. It
+// returns the issues found and the keys whose values have the wrong type,
+// which must be dropped for the rest of the file to load. ok is false if
+// the file is not valid YAML and can't be loaded at all.
+func checkYAMLFile(file string, data []byte) (issues []Issue, badKeys []string, ok bool) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		issue := Issue{File: file, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+			issue.Message = m[2]
+		}
+		return []Issue{issue}, nil, false
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil, true // Empty file
+	}
+
+	c := &yamlChecker{file: file}
+	c.check(doc.Content[0], reflect.TypeOf(Config{}), "")
+	return c.issues, c.badKeys, true
+}
+
+// yamlChecker walks a YAML document alongside the Go type it is decoded
+// into.
+type yamlChecker struct {
+	file    string
+	issues  []Issue
+	badKeys []string
+}
+
+func (c *yamlChecker) add(node *yaml.Node, path, format string, args ...any) {
+	c.issues = append(c.issues, Issue{File: c.file, Line: node.Line, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// mismatch records a value of the wrong type.
+func (c *yamlChecker) mismatch(node *yaml.Node, path, want string) {
+	got := node.Value
+	if node.Kind != yaml.ScalarNode {
+		got = yamlKindName(node.Kind)
+	} else {
+		got = strconv.Quote(got)
+	}
+	c.add(node, path, "expected %s, got %s", want, got)
+	c.badKeys = append(c.badKeys, path)
+}
+
+func (c *yamlChecker) check(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return // Leaves the default
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			c.mismatch(node, path, "a section of keys")
+			return
+		}
+		fields := koanfFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue // Merge key
+			}
+			keyPath := joinPath(path, key.Value)
+			field, ok := fields[key.Value]
+			if !ok {
+				msg := "unknown key"
+				if s := suggest(key.Value, sortedKeys(fields)); s != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", s)
+				}
+				c.add(key, keyPath, "%s", msg)
+				continue
+			}
+			c.check(value, field, keyPath)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			c.mismatch(node, path, "a map of keys")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c.check(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			c.mismatch(node, path, "a list")
+			return
+		}
+		for i, item := range node.Content {
+			c.check(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Int, reflect.Int64:
+		if node.Kind != yaml.ScalarNode {
+			c.mismatch(node, path, "a whole number")
+		} else if _, err := strconv.ParseInt(node.Value, 0, 64); err != nil {
+			c.mismatch(node, path, "a whole number")
+		}
+	case reflect.Float64:
+		if node.Kind != yaml.ScalarNode {
+			c.mismatch(node, path, "a number")
+		} else if _, err := strconv.ParseFloat(node.Value, 64); err != nil {
+			c.mismatch(node, path, "a number")
+		}
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode {
+			c.mismatch(node, path, "true or false")
+		} else if _, err := strconv.ParseBool(node.Value); err != nil && node.Tag != "!!bool" {
+			c.mismatch(node, path, "true or false")
+		}
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			c.mismatch(node, path, "a text value")
+		}
+	}
+}
+
+// koanfFields maps the koanf keys of a struct to their field types.
+// Fields without a koanf tag are not read from config files.
+func koanfFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag := f.Tag.Get("koanf"); tag != "" && tag != "-" {
+			fields[tag] = f.Type
+		}
+	}
+	return fields
+}
+
+func yamlKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "a section of keys"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return "a value"
+	}
+}
+
+// checkMCPJSON checks an mcp.json file and returns the servers that can
+// be used: those that decode and have either a command or a url. Unknown
+// keys are reported without skipping the server they are in.
+func checkMCPJSON(file string, data []byte) (map[string]MCPServerConfig, []Issue) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, []Issue{jsonIssue(file, data, 0, "", err)}
+	}
+
+	var issues []Issue
+	for _, key := range sortedKeys(top) {
+		if key != "mcpServers" {
+			issues = append(issues, unknownKeyIssue(file, key, []string{"mcpServers"}))
+		}
+	}
+
+	var raw map[string]json.RawMessage
+	if rawServers, ok := top["mcpServers"]; ok {
+		if err := json.Unmarshal(rawServers, &raw); err != nil {
+			offset := int64(bytes.Index(data, rawServers))
+			issues = append(issues, Issue{File: file, Line: lineAt(data, offset), Path: "mcpServers", Message: "expected an object of servers"})
+			return nil, issues
+		}
+	}
+
+	servers := make(map[string]MCPServerConfig, len(raw))
+	known := jsonFields(reflect.TypeOf(MCPServerConfig{}))
+	for _, name := range sortedKeys(raw) {
+		path := "mcpServers." + name
+		// Each server is decoded on its own so one bad entry doesn't
+		// take the others down with it
+		var server MCPServerConfig
+		if err := json.Unmarshal(raw[name], &server); err != nil {
+			issues = append(issues, jsonIssue(file, data, int64(bytes.Index(data, raw[name])), path, err))
+			continue
+		}
+
+		var keys map[string]json.RawMessage
+		_ = json.Unmarshal(raw[name], &keys) // Decodes, since server did
+		for _, key := range sortedKeys(keys) {
+			if !slices.Contains(known, key) {
+				issues = append(issues, unknownKeyIssue(file, path+"."+key, known))
+			}
+		}
+
+		hasCommand := strings.TrimSpace(server.Command) != ""
+		hasURL := strings.TrimSpace(server.URL) != ""
+		switch {
+		case hasCommand && hasURL:
+			issues = append(issues, Issue{File: file, Path: path, Message: `set either "command" or "url", not both`})
+		case !hasCommand && !hasURL:
+			issues = append(issues, Issue{File: file, Path: path, Message: `"command" (the program that runs the server) or "url" (a remote SSE server) is required`})
+		default:
+			servers[name] = server
+		}
+	}
+	return servers, issues
+}
+
+// jsonIssue describes a JSON decoding error in the value at offset in
+// data, whose dotted key is path.
+func jsonIssue(file string, data []byte, offset int64, path string, err error) Issue {
+	issue := Issue{File: file, Path: path, Message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		issue.Line = lineAt(data, offset+syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		issue.Line = lineAt(data, offset+typeErr.Offset)
+		if typeErr.Field != "" {
+			issue.Path = joinPath(path, typeErr.Field)
+		}
+		issue.Message = fmt.Sprintf("expected %s, got %s", jsonTypeName(typeErr.Type), typeErr.Value)
+	}
+	return issue
+}
+
+func unknownKeyIssue(file, path string, candidates []string) Issue {
+	key := path[strings.LastIndex(path, ".")+1:]
+	msg := "unknown key"
+	if s := suggest(key, candidates); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	return Issue{File: file, Path: path, Message: msg}
+}
+
+// jsonFields returns the JSON keys of a struct.
+func jsonFields(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return t.String()
+	}
+}
+
+// lineAt returns the 1-based line of a byte offset in data.
+func lineAt(data []byte, offset int64) int {
+	offset = min(max(offset, 0), int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// suggest returns the candidate closest to key if it is close enough to
+// be a likely typo, or "".
+func suggest(key string, candidates []string) string {
+	best, bestDist := "", len(key)/3+2
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(key), c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,191 @@
+package ios
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/ios/wda"
+)
+
+// maxActionWait caps a single wait step so a batch cannot stall the server.
+const maxActionWait = 30 * time.Second
+
+// UIAction is a single step of a perform_actions batch.
+type UIAction struct {
+	Type      string   `json:"type"`                 // tap, long_press, type, swipe, wait, press_button
+	X         *float64 `json:"x,omitempty"`          // tap, long_press
+	Y         *float64 `json:"y,omitempty"`          // tap, long_press
+	ElementID string   `json:"element_id,omitempty"` // tap
+	Text      string   `json:"text,omitempty"`       // type
+	Direction string   `json:"direction,omitempty"`  // swipe
+	StartX    float64  `json:"start_x,omitempty"`    // swipe
+	StartY    float64  `json:"start_y,omitempty"`    // swipe
+	EndX      float64  `json:"end_x,omitempty"`      // swipe
+	EndY      float64  `json:"end_y,omitempty"`      // swipe
+	Duration  float64  `json:"duration,omitempty"`   // long_press, swipe
+	Seconds   float64  `json:"seconds,omitempty"`    // wait
+	Button    string   `json:"button,omitempty"`     // press_button
+}
+
+// ActionResult reports the outcome of one executed step.
+type ActionResult struct {
+	Step   int    `json:"step"`
+	Type   string `json:"type"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	TookMs int64  `json:"took_ms"`
+}
+
+// parseUIActions converts the raw "actions" tool argument into typed steps.
+func parseUIActions(raw any) ([]UIAction, error) {
+	if raw == nil {
+		return nil, fmt.Errorf("actions is required")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actions: %w", err)
+	}
+
+	var actions []UIAction
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, fmt.Errorf("invalid actions (expected an array of objects): %w", err)
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("actions must contain at least one step")
+	}
+
+	for i, a := range actions {
+		actions[i].Type = strings.ToLower(strings.TrimSpace(a.Type))
+	}
+
+	return actions, nil
+}
+
+// runUIActions executes actions in order and stops at the first failure.
+// It returns the results of all attempted steps.
+func runUIActions(ctx context.Context, client *wda.Client, actions []UIAction) []ActionResult {
+	results := make([]ActionResult, 0, len(actions))
+
+	for i, a := range actions {
+		start := time.Now()
+		err := runUIAction(ctx, client, a)
+
+		result := ActionResult{
+			Step:   i + 1,
+			Type:   a.Type,
+			OK:     err == nil,
+			TookMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+
+		if err != nil {
+			break
+		}
+	}
+
+	return results
+}
+
+// runUIAction executes a single step.
+func runUIAction(ctx context.Context, client *wda.Client, a UIAction) error {
+	switch a.Type {
+	case "tap":
+		if a.ElementID != "" {
+			return client.Click(ctx, a.ElementID)
+		}
+		if a.X == nil || a.Y == nil {
+			return fmt.Errorf("tap requires element_id or x and y")
+		}
+		return client.Tap(ctx, int(*a.X), int(*a.Y))
+
+	case "long_press":
+		if a.X == nil || a.Y == nil {
+			return fmt.Errorf("long_press requires x and y")
+		}
+		duration := a.Duration
+		if duration <= 0 {
+			duration = 1.0
+		}
+		return client.LongPress(ctx, int(*a.X), int(*a.Y), duration)
+
+	case "type":
+		if a.Text == "" {
+			return fmt.Errorf("type requires text")
+		}
+		return client.SendKeys(ctx, a.Text)
+
+	case "swipe":
+		duration := a.Duration
+		if duration <= 0 {
+			duration = 0.3
+		}
+		startX, startY, endX, endY := int(a.StartX), int(a.StartY), int(a.EndX), int(a.EndY)
+		if a.Direction != "" {
+			size, err := client.WindowSize(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get window size: %w", err)
+			}
+			startX, startY, endX, endY, err = directionSwipe(size, a.Direction)
+			if err != nil {
+				return err
+			}
+		}
+		return client.Swipe(ctx, startX, startY, endX, endY, duration)
+
+	case "wait":
+		wait := time.Duration(a.Seconds * float64(time.Second))
+		if wait <= 0 {
+			return fmt.Errorf("wait requires seconds > 0")
+		}
+		if wait > maxActionWait {
+			return fmt.Errorf("wait of %v exceeds maximum of %v", wait, maxActionWait)
+		}
+		select {
+		case <-time.After(wait):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+	case "press_button":
+		if a.Button == "" {
+			return fmt.Errorf("press_button requires button")
+		}
+		return client.PressButton(ctx, a.Button)
+
+	case "":
+		return fmt.Errorf("action type is required")
+
+	default:
+		return fmt.Errorf("unknown action type %q (use tap, long_press, type, swipe, wait, press_button)", a.Type)
+	}
+}
+
+// directionSwipe returns swipe coordinates for a direction across the
+// middle of the screen.
+func directionSwipe(size *wda.WindowSize, direction string) (startX, startY, endX, endY int, err error) {
+	centerX := size.Width / 2
+	centerY := size.Height / 2
+	offsetY := size.Height / 4
+	offsetX := size.Width / 4
+
+	switch direction {
+	case "up":
+		return centerX, centerY + offsetY, centerX, centerY - offsetY, nil
+	case "down":
+		return centerX, centerY - offsetY, centerX, centerY + offsetY, nil
+	case "left":
+		return centerX + offsetX, centerY, centerX - offsetX, centerY, nil
+	case "right":
+		return centerX - offsetX, centerY, centerX + offsetX, centerY, nil
+	default:
+		return 0, 0, 0, 0, fmt.Errorf("invalid direction, use: up, down, left, right")
+	}
+}
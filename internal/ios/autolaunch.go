@@ -0,0 +1,90 @@
+package ios
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/notexe/cli-chat/internal/ios/wda"
+)
+
+const (
+	// defaultAppReadyTimeout is how long to wait for an auto-launched app
+	// to reach the foreground.
+	defaultAppReadyTimeout = 10 * time.Second
+
+	appReadyPollInterval = 500 * time.Millisecond
+)
+
+// SetTargetApp enables auto-launch of bundleID before UI interactions.
+// An empty bundleID disables auto-launch. A non-positive timeout uses the
+// default readiness wait.
+func (s *Server) SetTargetApp(bundleID string, readyTimeout time.Duration) {
+	if readyTimeout <= 0 {
+		readyTimeout = defaultAppReadyTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targetApp = bundleID
+	s.appReadyTimeout = readyTimeout
+}
+
+// targetAppSettings returns the configured auto-launch app and wait.
+func (s *Server) targetAppSettings() (string, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.targetApp, s.appReadyTimeout
+}
+
+// ensureTargetApp launches the configured target app via simctl if another
+// app is in the foreground and waits until it is ready.
+func (s *Server) ensureTargetApp(ctx context.Context, client *wda.Client) error {
+	bundleID, timeout := s.targetAppSettings()
+	if bundleID == "" {
+		return nil
+	}
+
+	if app, err := client.ActiveAppInfo(ctx); err == nil && app.BundleID == bundleID {
+		return nil
+	}
+
+	deviceID := s.wdaManager.DeviceID()
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return err
+		}
+		if booted == "" {
+			return fmt.Errorf("no booted simulator found to launch %s", bundleID)
+		}
+		deviceID = booted
+	}
+
+	if err := s.simctl.Launch(ctx, deviceID, bundleID); err != nil {
+		return fmt.Errorf("auto-launch of %s failed: %w", bundleID, err)
+	}
+
+	return waitForForegroundApp(ctx, client, bundleID, timeout)
+}
+
+// waitForForegroundApp polls WDA until bundleID is the active app.
+func waitForForegroundApp(ctx context.Context, client *wda.Client, bundleID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(appReadyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if app, err := client.ActiveAppInfo(ctx); err == nil && app.BundleID == bundleID {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s did not reach the foreground within %v", bundleID, timeout)
+		case <-ticker.C:
+		}
+	}
+}
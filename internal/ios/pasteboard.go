@@ -0,0 +1,88 @@
+package ios
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// pasteMenuWait is how long paste waits for the edit menu to appear.
+const pasteMenuWait = 3 * time.Second
+
+func (s *Server) handleSetPasteboard(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := req.GetString("device_id", "")
+	text := req.GetString("text", "")
+
+	if text == "" {
+		return mcp.NewToolResultError("text is required"), nil
+	}
+
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if booted == "" {
+			return mcp.NewToolResultError("no booted simulator found"), nil
+		}
+		deviceID = booted
+	}
+
+	if err := s.simctl.SetPasteboard(ctx, deviceID, text); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Pasteboard set (%d characters)", len([]rune(text)))), nil
+}
+
+// handlePaste long-presses a text field and taps "Paste" in the edit menu.
+func (s *Server) handlePaste(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	x := req.GetFloat("x", -1)
+	y := req.GetFloat("y", -1)
+	elementID := req.GetString("element_id", "")
+
+	if elementID == "" && (x < 0 || y < 0) {
+		return mcp.NewToolResultError("either element_id or x,y coordinates are required"), nil
+	}
+
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to start WDA: %v", err)), nil
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create WDA session: %v", err)), nil
+		}
+	}
+
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if elementID != "" {
+		rect, err := client.GetElementRect(ctx, elementID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get element rect: %v", err)), nil
+		}
+		x = rect.X + rect.Width/2
+		y = rect.Y + rect.Height/2
+	}
+
+	if err := client.LongPress(ctx, int(x), int(y), 1.0); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	menuItem, err := waitForElement(ctx, client, "accessibility id", "Paste", false, pasteMenuWait)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("edit menu did not show Paste (is the field editable and the pasteboard set?): %v", err)), nil
+	}
+
+	if err := client.Click(ctx, menuItem.ElementID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText("Pasted from pasteboard"), nil
+}
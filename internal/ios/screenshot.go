@@ -0,0 +1,165 @@
+package ios
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultScreenshotMaxBytes caps the encoded image of screenshot_base64.
+	defaultScreenshotMaxBytes = 1 << 20
+
+	// defaultJPEGQuality is used when screenshot_base64 re-encodes as JPEG.
+	defaultJPEGQuality = 80
+
+	// minJPEGQuality is the lowest quality tried to fit the size cap.
+	minJPEGQuality = 30
+)
+
+// encodeScreenshot converts a PNG screenshot for inline transfer. The image
+// is downscaled to maxWidth (0 keeps the size) and re-encoded as JPEG if
+// format is "jpeg". A JPEG over maxBytes is retried at lower quality; a PNG
+// over maxBytes is an error. It returns the MIME type and encoded bytes.
+func encodeScreenshot(data []byte, format string, quality, maxWidth, maxBytes int) (string, []byte, error) {
+	if format == "png" && maxWidth <= 0 {
+		if len(data) > maxBytes {
+			return "", nil, fmt.Errorf("screenshot is %d bytes, over the %d byte limit; use format 'jpeg' or max_width", len(data), maxBytes)
+		}
+		return "image/png", data, nil
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	img = downscale(img, maxWidth)
+
+	var buf bytes.Buffer
+	if format == "png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return "", nil, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		if buf.Len() > maxBytes {
+			return "", nil, fmt.Errorf("screenshot is %d bytes, over the %d byte limit; use format 'jpeg' or a smaller max_width", buf.Len(), maxBytes)
+		}
+		return "image/png", buf.Bytes(), nil
+	}
+
+	for q := quality; ; q -= 10 {
+		if q < minJPEGQuality {
+			q = minJPEGQuality
+		}
+		buf.Reset()
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: q}); err != nil {
+			return "", nil, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+		if buf.Len() <= maxBytes {
+			return "image/jpeg", buf.Bytes(), nil
+		}
+		if q == minJPEGQuality {
+			return "", nil, fmt.Errorf("screenshot is %d bytes at JPEG quality %d, over the %d byte limit; use a smaller max_width", buf.Len(), q, maxBytes)
+		}
+	}
+}
+
+// downscale shrinks img to maxWidth, keeping the aspect ratio. Each target
+// pixel is the average of the source pixels it covers, so text stays
+// readable. Images already narrow enough are returned unchanged.
+func downscale(img image.Image, maxWidth int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if maxWidth <= 0 || w <= maxWidth {
+		return img
+	}
+
+	nw := maxWidth
+	nh := h * nw / w
+	if nh < 1 {
+		nh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0, y1 := y*h/nh, (y+1)*h/nh
+		for x := 0; x < nw; x++ {
+			x0, x1 := x*w/nw, (x+1)*w/nw
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
+
+func (s *Server) handleScreenshotBase64(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := req.GetString("device_id", "")
+	format := req.GetString("format", "png")
+	quality := req.GetInt("quality", defaultJPEGQuality)
+	maxWidth := req.GetInt("max_width", 0)
+	maxBytes := req.GetInt("max_bytes", defaultScreenshotMaxBytes)
+
+	if format == "jpg" {
+		format = "jpeg"
+	}
+	if format != "png" && format != "jpeg" {
+		return mcp.NewToolResultError("format must be 'png' or 'jpeg'"), nil
+	}
+	if quality < minJPEGQuality || quality > 100 {
+		quality = defaultJPEGQuality
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultScreenshotMaxBytes
+	}
+
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if booted == "" {
+			return mcp.NewToolResultError("no booted simulator found, specify device_id or boot a simulator first"), nil
+		}
+		deviceID = booted
+	}
+
+	path, err := s.simctl.Screenshot(ctx, deviceID, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read screenshot: %v", err)), nil
+	}
+
+	mimeType, encoded, err := encodeScreenshot(data, format, quality, maxWidth, maxBytes)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Image content rather than a data URI in text: clients cut long text
+	// results, and a vision model only sees images sent as images
+	text := fmt.Sprintf("Screenshot of %s (%s, %d KB)", deviceID, mimeType, len(encoded)>>10)
+	return mcp.NewToolResultImage(text, base64.StdEncoding.EncodeToString(encoded), mimeType), nil
+}
@@ -0,0 +1,145 @@
+package ios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// elementRoles maps XCUIElementType suffixes to short, human-readable roles.
+// Longer suffixes must come first so "SecureTextField" wins over "TextField".
+var elementRoles = []struct {
+	suffix      string
+	role        string
+	interactive bool
+}{
+	{"SecureTextField", "secure text field", true},
+	{"SearchField", "search field", true},
+	{"TextField", "text field", true},
+	{"TextView", "text area", true},
+	{"StaticText", "text", false},
+	{"Button", "button", true},
+	{"Link", "link", true},
+	{"Switch", "switch", true},
+	{"Toggle", "switch", true},
+	{"Slider", "slider", true},
+	{"Stepper", "stepper", true},
+	{"PickerWheel", "picker", true},
+	{"SegmentedControl", "segmented control", true},
+	{"MenuItem", "menu item", true},
+	{"Cell", "cell", true},
+	{"Tab", "tab", true},
+	{"Key", "key", false},
+	{"Image", "image", false},
+	{"NavigationBar", "navigation bar", false},
+	{"Alert", "alert", false},
+}
+
+// inferRole returns a short role name for a WDA element type and whether
+// elements of that role are normally tappable or editable.
+func inferRole(elementType string) (string, bool) {
+	short := strings.TrimPrefix(elementType, "XCUIElementType")
+	for _, r := range elementRoles {
+		if strings.HasSuffix(short, r.suffix) {
+			return r.role, r.interactive
+		}
+	}
+	return strings.ToLower(short), false
+}
+
+// elementText picks the most descriptive text for an element.
+func elementText(el UIElement) string {
+	switch {
+	case el.Label != "":
+		return el.Label
+	case el.Name != "":
+		return el.Name
+	default:
+		return el.Value
+	}
+}
+
+// renderScreenText renders parsed elements as a compact numbered list that
+// text-only models can use to reason about the screen without a screenshot.
+// When interactiveOnly is set, only tappable or editable elements are listed.
+func renderScreenText(elements []UIElement, interactiveOnly bool) string {
+	var header string
+	var lines []string
+	seen := make(map[string]bool)
+
+	for _, el := range elements {
+		if header == "" && strings.HasSuffix(el.Type, "Application") {
+			header = fmt.Sprintf("Screen: %q %dx%d", elementText(el), el.Width, el.Height)
+			continue
+		}
+
+		role, interactive := inferRole(el.Type)
+		if interactiveOnly && !interactive {
+			continue
+		}
+		if !interactive && elementText(el) == "" {
+			continue
+		}
+
+		// Nested containers often repeat the same label at the same spot
+		key := fmt.Sprintf("%s|%s|%d|%d", role, elementText(el), el.TapX, el.TapY)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		line := role
+		if text := elementText(el); text != "" {
+			line += fmt.Sprintf(" %q", text)
+		} else {
+			line += " (unlabeled)"
+		}
+		if state := elementState(role, el); state != "" {
+			line += " " + state
+		}
+		line += fmt.Sprintf(" @ (%d, %d)", el.TapX, el.TapY)
+
+		lines = append(lines, line)
+	}
+
+	var out strings.Builder
+	if header == "" {
+		header = "Screen"
+	}
+	kind := "elements"
+	if interactiveOnly {
+		kind = "interactive elements"
+	}
+	fmt.Fprintf(&out, "%s, %d %s:\n", header, len(lines), kind)
+
+	for i, line := range lines {
+		fmt.Fprintf(&out, "%d. %s\n", i+1, line)
+	}
+
+	if len(lines) == 0 {
+		out.WriteString("(nothing found, try interactive_only=false or get_ui_tree)\n")
+	}
+
+	return out.String()
+}
+
+// elementState describes the current value of stateful controls.
+func elementState(role string, el UIElement) string {
+	switch role {
+	case "switch":
+		switch el.Value {
+		case "1", "true":
+			return "[on]"
+		case "0", "false":
+			return "[off]"
+		}
+	case "text field", "search field", "text area", "slider", "picker", "stepper", "segmented control":
+		if el.Value != "" && el.Value != elementText(el) {
+			return fmt.Sprintf("= %q", el.Value)
+		}
+	case "secure text field":
+		if el.Value != "" {
+			return "[filled]"
+		}
+	}
+	return ""
+}
@@ -7,12 +7,19 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/notexe/cli-chat/internal/ios/wda"
+	mcpclient "github.com/notexe/cli-chat/internal/mcp"
 )
 
+// buildCallTimeout is the call timeout build_app declares; a clean build
+// of a large project takes many minutes.
+const buildCallTimeout = 30 * time.Minute
+
 const (
 	serverName    = "ios-simulator"
 	serverVersion = "1.0.0"
@@ -25,6 +32,10 @@ type Server struct {
 	xcodebuild *XcodeBuild
 	wdaManager *wda.Manager
 	wdaPort    int
+
+	mu              sync.Mutex
+	targetApp       string        // Bundle ID auto-launched before UI actions (opt-in)
+	appReadyTimeout time.Duration // How long to wait for the target app to come up
 }
 
 // NewServer creates a new iOS MCP server.
@@ -34,6 +45,8 @@ func NewServer() *Server {
 		xcodebuild: NewXcodeBuild(),
 		wdaManager: wda.NewManager(8100),
 		wdaPort:    8100,
+
+		appReadyTimeout: defaultAppReadyTimeout,
 	}
 
 	s.mcpServer = server.NewMCPServer(
@@ -61,6 +74,7 @@ func (s *Server) registerSimulatorTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("list_simulators",
 			mcp.WithDescription("List all available iOS simulators with their UDID, name, state, and runtime"),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleListSimulators,
 	)
@@ -83,6 +97,35 @@ func (s *Server) registerSimulatorTools() {
 		s.handleShutdownSimulator,
 	)
 
+	// list_device_types
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_device_types",
+			mcp.WithDescription("List simulator device types and available runtimes, for use with create_simulator"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		s.handleListDeviceTypes,
+	)
+
+	// create_simulator
+	s.mcpServer.AddTool(
+		mcp.NewTool("create_simulator",
+			mcp.WithDescription("Create a new iOS simulator and return its UDID"),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Name of the new simulator")),
+			mcp.WithString("device_type", mcp.Required(), mcp.Description("Device type name or identifier, e.g. 'iPhone 15'")),
+			mcp.WithString("runtime", mcp.Required(), mcp.Description("Runtime name or identifier, e.g. 'iOS 17.5'")),
+		),
+		s.handleCreateSimulator,
+	)
+
+	// delete_simulator
+	s.mcpServer.AddTool(
+		mcp.NewTool("delete_simulator",
+			mcp.WithDescription("Delete an iOS simulator and its data"),
+			mcp.WithString("device_id", mcp.Required(), mcp.Description("Simulator UDID")),
+		),
+		s.handleDeleteSimulator,
+	)
+
 	// screenshot
 	s.mcpServer.AddTool(
 		mcp.NewTool("screenshot",
@@ -93,6 +136,19 @@ func (s *Server) registerSimulatorTools() {
 		s.handleScreenshot,
 	)
 
+	// screenshot_base64
+	s.mcpServer.AddTool(
+		mcp.NewTool("screenshot_base64",
+			mcp.WithDescription("Take a screenshot and return it inline as an image, for review by a vision-capable model"),
+			mcp.WithString("device_id", mcp.Description("Simulator UDID (uses booted device if not specified)")),
+			mcp.WithString("format", mcp.Description("Image format: 'png' or 'jpeg' (default: png; jpeg is much smaller)")),
+			mcp.WithNumber("quality", mcp.Description("JPEG quality 30-100 (default: 80); lowered automatically to fit max_bytes")),
+			mcp.WithNumber("max_width", mcp.Description("Downscale to at most this width in pixels (default: original size)")),
+			mcp.WithNumber("max_bytes", mcp.Description("Maximum encoded image size in bytes (default: 1048576)")),
+		),
+		s.handleScreenshotBase64,
+	)
+
 	// record_video_start
 	s.mcpServer.AddTool(
 		mcp.NewTool("record_video_start",
@@ -107,6 +163,7 @@ func (s *Server) registerSimulatorTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("record_video_stop",
 			mcp.WithDescription("Stop video recording and return the video file path"),
+			mcp.WithString("device_id", mcp.Description("Simulator UDID (uses the only recording device, or the booted device, if not specified)")),
 		),
 		s.handleRecordVideoStop,
 	)
@@ -120,6 +177,28 @@ func (s *Server) registerSimulatorTools() {
 		),
 		s.handleOpenURL,
 	)
+
+	// set_appearance
+	s.mcpServer.AddTool(
+		mcp.NewTool("set_appearance",
+			mcp.WithDescription("Switch the simulator between light and dark mode"),
+			mcp.WithString("device_id", mcp.Description("Simulator UDID (uses booted device if not specified)")),
+			mcp.WithString("mode", mcp.Required(), mcp.Description("Appearance: 'light' or 'dark'")),
+		),
+		s.handleSetAppearance,
+	)
+
+	// set_permission
+	s.mcpServer.AddTool(
+		mcp.NewTool("set_permission",
+			mcp.WithDescription("Grant, revoke or reset an app's privacy permission so permission dialogs do not block automation"),
+			mcp.WithString("device_id", mcp.Description("Simulator UDID (uses booted device if not specified)")),
+			mcp.WithString("action", mcp.Required(), mcp.Description("Action: 'grant', 'revoke' or 'reset'")),
+			mcp.WithString("service", mcp.Required(), mcp.Description("Service: "+strings.Join(PrivacyServices, ", "))),
+			mcp.WithString("bundle_id", mcp.Description("App bundle identifier (required for grant and revoke; reset without it applies to all apps)")),
+		),
+		s.handleSetPermission,
+	)
 }
 
 // registerAppTools registers app management tools.
@@ -127,11 +206,13 @@ func (s *Server) registerAppTools() {
 	// build_app
 	s.mcpServer.AddTool(
 		mcp.NewTool("build_app",
-			mcp.WithDescription("Build an Xcode project for iOS simulator"),
-			mcp.WithString("project_path", mcp.Required(), mcp.Description("Path to .xcodeproj or directory containing it")),
+			mcp.WithDescription("Build an Xcode project or workspace for iOS simulator"),
+			mcp.WithString("project_path", mcp.Description("Path to .xcodeproj, .xcworkspace or a directory containing them (a workspace is preferred, as needed for CocoaPods)")),
+			mcp.WithString("workspace_path", mcp.Description("Path to .xcworkspace (alternative to project_path)")),
 			mcp.WithString("scheme", mcp.Required(), mcp.Description("Build scheme name")),
 			mcp.WithString("simulator", mcp.Description("Simulator name (default: iPhone 15)")),
 			mcp.WithString("configuration", mcp.Description("Build configuration (default: Debug)")),
+			mcpclient.WithCallTimeout(buildCallTimeout),
 		),
 		s.handleBuildApp,
 	)
@@ -176,11 +257,25 @@ func (s *Server) registerAppTools() {
 		s.handleUninstallApp,
 	)
 
+	// get_app_logs
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_app_logs",
+			mcp.WithDescription("Get recent console (unified log) output from the simulator, e.g. to see errors or crashes after a failed step"),
+			mcp.WithString("device_id", mcp.Description("Simulator UDID (uses booted device if not specified)")),
+			mcp.WithString("process", mcp.Description("App/process name to filter by (matched against the process image path)")),
+			mcp.WithString("predicate", mcp.Description("Raw log predicate, overrides process (e.g. 'subsystem == \"com.example.app\"')")),
+			mcp.WithNumber("last_seconds", mcp.Description("How far back to look in seconds (default: 300)")),
+			mcp.WithNumber("max_bytes", mcp.Description("Maximum output size; older entries are dropped first (default: 32000)")),
+		),
+		s.handleGetAppLogs,
+	)
+
 	// list_schemes
 	s.mcpServer.AddTool(
 		mcp.NewTool("list_schemes",
 			mcp.WithDescription("List available schemes in an Xcode project"),
 			mcp.WithString("project_path", mcp.Required(), mcp.Description("Path to Xcode project or workspace")),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleListSchemes,
 	)
@@ -197,6 +292,16 @@ func (s *Server) registerUITools() {
 		s.handleWDASetDevice,
 	)
 
+	// set_target_app - opt-in auto-launch before UI interactions
+	s.mcpServer.AddTool(
+		mcp.NewTool("set_target_app",
+			mcp.WithDescription("Set an app to auto-launch before UI interactions (tap, swipe, get_ui_tree, ...) when it is not in the foreground. Pass an empty bundle_id to disable."),
+			mcp.WithString("bundle_id", mcp.Description("App bundle identifier, or empty to disable auto-launch")),
+			mcp.WithNumber("wait_seconds", mcp.Description("How long to wait for the app to reach the foreground (default: 10)")),
+		),
+		s.handleSetTargetApp,
+	)
+
 	// wda_status
 	s.mcpServer.AddTool(
 		mcp.NewTool("wda_status",
@@ -205,6 +310,14 @@ func (s *Server) registerUITools() {
 		s.handleWDAStatus,
 	)
 
+	// get_device_state
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_device_state",
+			mcp.WithDescription("Get the simulator's current state in one call: device name and boot state, foreground app bundle ID, orientation and screen size. WDA will be auto-started if not running."),
+		),
+		s.handleGetDeviceState,
+	)
+
 	// wda_create_session
 	s.mcpServer.AddTool(
 		mcp.NewTool("wda_create_session",
@@ -232,6 +345,50 @@ func (s *Server) registerUITools() {
 		s.handleFindElement,
 	)
 
+	// find_elements
+	s.mcpServer.AddTool(
+		mcp.NewTool("find_elements",
+			mcp.WithDescription("Find all UI elements matching a selector and return their element IDs and rects. WDA will be auto-started if not running."),
+			mcp.WithString("using", mcp.Required(), mcp.Description("Search strategy: 'accessibility id', 'name', 'class name', 'xpath', 'predicate string'")),
+			mcp.WithString("value", mcp.Required(), mcp.Description("Value to search for")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of elements to return (default: 50)")),
+		),
+		s.handleFindElements,
+	)
+
+	// get_element_text
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_element_text",
+			mcp.WithDescription("Get the label, value and name of a single element without dumping the whole UI tree. WDA will be auto-started if not running."),
+			mcp.WithString("element_id", mcp.Description("Element ID from find_element or find_elements")),
+			mcp.WithString("using", mcp.Description("Search strategy to locate the element instead of element_id")),
+			mcp.WithString("value", mcp.Description("Value to search for (with using)")),
+		),
+		s.handleGetElementText,
+	)
+
+	// wait_for_element
+	s.mcpServer.AddTool(
+		mcp.NewTool("wait_for_element",
+			mcp.WithDescription("Wait until a UI element appears and return its element_id and rect. Use after navigation instead of fixed sleeps. WDA will be auto-started if not running."),
+			mcp.WithString("using", mcp.Required(), mcp.Description("Search strategy: 'accessibility id', 'name', 'class name', 'xpath', 'predicate string'")),
+			mcp.WithString("value", mcp.Required(), mcp.Description("Value to search for")),
+			mcp.WithNumber("timeout", mcp.Description("Maximum wait in seconds (default: 10, max: 120)")),
+		),
+		s.handleWaitForElement,
+	)
+
+	// wait_for_element_gone
+	s.mcpServer.AddTool(
+		mcp.NewTool("wait_for_element_gone",
+			mcp.WithDescription("Wait until no UI element matches, e.g. a spinner or dismissed dialog. WDA will be auto-started if not running."),
+			mcp.WithString("using", mcp.Required(), mcp.Description("Search strategy: 'accessibility id', 'name', 'class name', 'xpath', 'predicate string'")),
+			mcp.WithString("value", mcp.Required(), mcp.Description("Value to search for")),
+			mcp.WithNumber("timeout", mcp.Description("Maximum wait in seconds (default: 10, max: 120)")),
+		),
+		s.handleWaitForElementGone,
+	)
+
 	// tap
 	s.mcpServer.AddTool(
 		mcp.NewTool("tap",
@@ -277,6 +434,27 @@ func (s *Server) registerUITools() {
 		s.handleInputText,
 	)
 
+	// set_pasteboard
+	s.mcpServer.AddTool(
+		mcp.NewTool("set_pasteboard",
+			mcp.WithDescription("Set the simulator pasteboard (clipboard) text. Combine with paste to enter long text, emoji or non-Latin text instantly; input_text types key by key and is slow or fails for such text."),
+			mcp.WithString("device_id", mcp.Description("Simulator UDID (uses booted device if not specified)")),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Text to put on the pasteboard")),
+		),
+		s.handleSetPasteboard,
+	)
+
+	// paste
+	s.mcpServer.AddTool(
+		mcp.NewTool("paste",
+			mcp.WithDescription("Paste the pasteboard into a text field by long-pressing it and choosing Paste. Set the text with set_pasteboard first. WDA will be auto-started if not running."),
+			mcp.WithNumber("x", mcp.Description("X coordinate of the field (required if element_id not specified)")),
+			mcp.WithNumber("y", mcp.Description("Y coordinate of the field (required if element_id not specified)")),
+			mcp.WithString("element_id", mcp.Description("Element ID of the field (alternative to coordinates)")),
+		),
+		s.handlePaste,
+	)
+
 	// press_button
 	s.mcpServer.AddTool(
 		mcp.NewTool("press_button",
@@ -286,14 +464,42 @@ func (s *Server) registerUITools() {
 		s.handlePressButton,
 	)
 
+	// perform_actions - run several UI actions in one call
+	s.mcpServer.AddTool(
+		mcp.NewTool("perform_actions",
+			mcp.WithDescription("Run an ordered batch of UI actions in one call, stopping at the first failure. "+
+				"Each action is an object with a 'type' of tap (x, y or element_id), long_press (x, y, duration), "+
+				"type (text), swipe (direction or start_x/start_y/end_x/end_y, duration), wait (seconds) or press_button (button). "+
+				"WDA will be auto-started if not running."),
+			mcp.WithArray("actions", mcp.Required(),
+				mcp.Items(map[string]any{"type": "object"}),
+				mcp.Description("Ordered list of actions, e.g. [{\"type\":\"tap\",\"x\":100,\"y\":200},{\"type\":\"type\",\"text\":\"hello\"},{\"type\":\"wait\",\"seconds\":1}]")),
+		),
+		s.handlePerformActions,
+	)
+
 	// get_elements_with_coords - parse UI tree and show tappable coordinates
 	s.mcpServer.AddTool(
 		mcp.NewTool("get_elements_with_coords",
 			mcp.WithDescription("Get all visible UI elements with their tap coordinates (center point). Useful when accessibility labels are missing."),
 			mcp.WithBoolean("visible_only", mcp.Description("Only show visible elements (default: true)")),
+			mcp.WithArray("include_types", mcp.WithStringItems(), mcp.Description("Extra element type substrings to always list, e.g. 'Other' or 'MyCustomView'")),
+			mcp.WithArray("exclude_types", mcp.WithStringItems(), mcp.Description("Element type substrings to never list, e.g. 'Image' or 'StaticText'")),
+			mcp.WithNumber("max_depth", mcp.Description("Ignore elements nested deeper than this (default: unlimited)")),
+			mcp.WithNumber("container_depth", mcp.Description("Always list elements at or above this depth, even without labels (default: 2, -1 to disable)")),
+			mcp.WithNumber("min_size", mcp.Description("Minimum width and height in points (default: 1)")),
 		),
 		s.handleGetElementsWithCoords,
 	)
+
+	// get_screen_text - compact text rendering of the screen for text-only models
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_screen_text",
+			mcp.WithDescription("Describe the current screen as a compact numbered list of elements with inferred roles and tap coordinates. Cheaper than a screenshot and works with text-only models."),
+			mcp.WithBoolean("interactive_only", mcp.Description("Only list tappable or editable elements (default: true)")),
+		),
+		s.handleGetScreenText,
+	)
 }
 
 // Tool handlers
@@ -339,6 +545,75 @@ func (s *Server) handleShutdownSimulator(ctx context.Context, req mcp.CallToolRe
 	return mcp.NewToolResultText(fmt.Sprintf("Simulator %s shut down successfully", deviceID)), nil
 }
 
+func (s *Server) handleListDeviceTypes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	types, err := s.simctl.ListDeviceTypes(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	runtimes, err := s.simctl.ListRuntimes(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var available []map[string]any
+	for _, rt := range runtimes {
+		if !rt.IsAvailable {
+			continue
+		}
+		supported := make([]string, 0, len(rt.SupportedDeviceTypes))
+		for _, t := range rt.SupportedDeviceTypes {
+			supported = append(supported, t.Name)
+		}
+		available = append(available, map[string]any{
+			"name":                   rt.Name,
+			"identifier":             rt.Identifier,
+			"version":                rt.Version,
+			"supported_device_types": supported,
+		})
+	}
+
+	output, _ := json.MarshalIndent(map[string]any{
+		"device_types": types,
+		"runtimes":     available,
+	}, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+func (s *Server) handleCreateSimulator(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := req.GetString("name", "")
+	deviceType := req.GetString("device_type", "")
+	runtime := req.GetString("runtime", "")
+
+	if name == "" || deviceType == "" || runtime == "" {
+		return mcp.NewToolResultError("name, device_type and runtime are required"), nil
+	}
+
+	udid, err := s.simctl.Create(ctx, name, deviceType, runtime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	output, _ := json.MarshalIndent(map[string]any{
+		"udid": udid,
+		"name": name,
+	}, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+func (s *Server) handleDeleteSimulator(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := req.GetString("device_id", "")
+	if deviceID == "" {
+		return mcp.NewToolResultError("device_id is required"), nil
+	}
+
+	if err := s.simctl.Delete(ctx, deviceID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Simulator %s deleted", deviceID)), nil
+}
+
 func (s *Server) handleScreenshot(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	deviceID := req.GetString("device_id", "")
 	outputPath := req.GetString("output_path", "")
@@ -386,7 +661,24 @@ func (s *Server) handleRecordVideoStart(ctx context.Context, req mcp.CallToolReq
 }
 
 func (s *Server) handleRecordVideoStop(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := s.simctl.StopRecording()
+	deviceID := req.GetString("device_id", "")
+
+	if deviceID == "" {
+		if recording := s.simctl.RecordingDevices(); len(recording) == 1 {
+			deviceID = recording[0]
+		} else {
+			booted, err := s.simctl.GetBooted(ctx)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if booted == "" {
+				return mcp.NewToolResultError("no booted simulator found"), nil
+			}
+			deviceID = booted
+		}
+	}
+
+	path, err := s.simctl.StopRecording(deviceID)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -420,25 +712,134 @@ func (s *Server) handleOpenURL(ctx context.Context, req mcp.CallToolRequest) (*m
 	return mcp.NewToolResultText(fmt.Sprintf("Opened URL: %s", url)), nil
 }
 
+func (s *Server) handleGetAppLogs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := req.GetString("device_id", "")
+	opts := LogOptions{
+		Process:   req.GetString("process", ""),
+		Predicate: req.GetString("predicate", ""),
+		Last:      time.Duration(req.GetFloat("last_seconds", DefaultLogWindow.Seconds()) * float64(time.Second)),
+		MaxBytes:  req.GetInt("max_bytes", DefaultLogMaxBytes),
+	}
+
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if booted == "" {
+			return mcp.NewToolResultError("no booted simulator found"), nil
+		}
+		deviceID = booted
+	}
+
+	output, truncated, err := s.simctl.Logs(ctx, deviceID, opts)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if strings.TrimSpace(output) == "" {
+		return mcp.NewToolResultText("No log entries found"), nil
+	}
+	if truncated {
+		output = "[... older entries truncated]\n" + output
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
+
+func (s *Server) handleSetAppearance(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := req.GetString("device_id", "")
+	mode := strings.ToLower(strings.TrimSpace(req.GetString("mode", "")))
+
+	if mode != AppearanceLight && mode != AppearanceDark {
+		return mcp.NewToolResultError("mode must be 'light' or 'dark'"), nil
+	}
+
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if booted == "" {
+			return mcp.NewToolResultError("no booted simulator found"), nil
+		}
+		deviceID = booted
+	}
+
+	if err := s.simctl.SetAppearance(ctx, deviceID, mode); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Appearance set to %s", mode)), nil
+}
+
+func (s *Server) handleSetPermission(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := req.GetString("device_id", "")
+	action := strings.ToLower(strings.TrimSpace(req.GetString("action", "")))
+	service := strings.ToLower(strings.TrimSpace(req.GetString("service", "")))
+	bundleID := req.GetString("bundle_id", "")
+
+	if action != PrivacyGrant && action != PrivacyRevoke && action != PrivacyReset {
+		return mcp.NewToolResultError("action must be 'grant', 'revoke' or 'reset'"), nil
+	}
+	if err := ValidatePrivacyService(service); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if bundleID == "" && action != PrivacyReset {
+		return mcp.NewToolResultError("bundle_id is required for grant and revoke"), nil
+	}
+
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if booted == "" {
+			return mcp.NewToolResultError("no booted simulator found"), nil
+		}
+		deviceID = booted
+	}
+
+	if err := s.simctl.SetPrivacy(ctx, deviceID, action, service, bundleID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	target := bundleID
+	if target == "" {
+		target = "all apps"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Permission %s: %s for %s", action, service, target)), nil
+}
+
 func (s *Server) handleBuildApp(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	projectPath := req.GetString("project_path", "")
+	workspacePath := req.GetString("workspace_path", "")
 	scheme := req.GetString("scheme", "")
 	simulator := req.GetString("simulator", "")
 	configuration := req.GetString("configuration", "")
 
-	if projectPath == "" || scheme == "" {
-		return mcp.NewToolResultError("project_path and scheme are required"), nil
+	if projectPath == "" && workspacePath == "" {
+		return mcp.NewToolResultError("project_path or workspace_path is required"), nil
+	}
+	if scheme == "" {
+		return mcp.NewToolResultError("scheme is required"), nil
 	}
 
 	opts := BuildOptions{
 		ProjectPath:   projectPath,
+		WorkspacePath: workspacePath,
 		Scheme:        scheme,
 		SimulatorName: simulator,
 		Configuration: configuration,
+		OnOutput:      buildProgressNotifier(ctx, req),
 	}
 
 	result, err := s.xcodebuild.Build(ctx, opts)
 	if err != nil {
+		if result != nil && len(result.Errors) > 0 {
+			details, _ := json.MarshalIndent(result.Errors, "", "  ")
+			return mcp.NewToolResultError(fmt.Sprintf("%v\n%s", err, details)), nil
+		}
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
@@ -446,6 +847,34 @@ func (s *Server) handleBuildApp(ctx context.Context, req mcp.CallToolRequest) (*
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+// buildProgressNotifier returns an output callback that reports xcodebuild
+// step lines (e.g. "CompileSwift ...", "** BUILD SUCCEEDED **") as MCP
+// progress notifications, or nil if the client did not ask for progress.
+func buildProgressNotifier(ctx context.Context, req mcp.CallToolRequest) func(string) {
+	if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+
+	token := req.Params.Meta.ProgressToken
+	steps := 0
+	return func(line string) {
+		// Step headers start at column 0; details are indented
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			return
+		}
+		steps++
+		_ = mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      steps,
+			"message":       line,
+		})
+	}
+}
+
 func (s *Server) handleInstallApp(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	deviceID := req.GetString("device_id", "")
 	appPath := req.GetString("app_path", "")
@@ -583,6 +1012,19 @@ func (s *Server) handleWDASetDevice(ctx context.Context, req mcp.CallToolRequest
 	return mcp.NewToolResultText(fmt.Sprintf("WDA target device set to: %s", deviceID)), nil
 }
 
+func (s *Server) handleSetTargetApp(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	bundleID := strings.TrimSpace(req.GetString("bundle_id", ""))
+	wait := req.GetFloat("wait_seconds", 0)
+
+	s.SetTargetApp(bundleID, time.Duration(wait*float64(time.Second)))
+
+	if bundleID == "" {
+		return mcp.NewToolResultText("Auto-launch disabled"), nil
+	}
+	_, timeout := s.targetAppSettings()
+	return mcp.NewToolResultText(fmt.Sprintf("UI tools will launch %s if it is not in the foreground (waiting up to %v)", bundleID, timeout)), nil
+}
+
 func (s *Server) handleWDAStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	client, err := s.getWDAClient(ctx)
 	if err != nil {
@@ -598,6 +1040,78 @@ func (s *Server) handleWDAStatus(ctx context.Context, req mcp.CallToolRequest) (
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+func (s *Server) handleGetDeviceState(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deviceID := s.wdaManager.DeviceID()
+	if deviceID == "" {
+		booted, err := s.simctl.GetBooted(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if booted == "" {
+			return mcp.NewToolResultError("no booted simulator found"), nil
+		}
+		deviceID = booted
+	}
+
+	state := DeviceState{UDID: deviceID}
+
+	devices, err := s.simctl.ListDevices(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, d := range devices {
+		if d.UDID == deviceID || d.Name == deviceID {
+			state.UDID = d.UDID
+			state.Name = d.Name
+			state.State = d.State
+			state.Runtime = d.RuntimeName
+			break
+		}
+	}
+
+	// WDA details are best effort; simctl info is still useful without them
+	if err := s.fillWDAState(ctx, &state); err != nil {
+		state.WDAError = err.Error()
+	}
+
+	output, _ := json.MarshalIndent(state, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// fillWDAState adds foreground app, orientation and screen size from WDA.
+func (s *Server) fillWDAState(ctx context.Context, state *DeviceState) error {
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start WDA: %w", err)
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return fmt.Errorf("failed to create WDA session: %w", err)
+		}
+	}
+
+	app, err := client.ActiveAppInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get foreground app: %w", err)
+	}
+	state.ForegroundApp = app.BundleID
+	state.ForegroundName = app.Name
+
+	if state.Orientation, err = client.Orientation(ctx); err != nil {
+		return fmt.Errorf("failed to get orientation: %w", err)
+	}
+
+	size, err := client.WindowSize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get window size: %w", err)
+	}
+	state.ScreenWidth = size.Width
+	state.ScreenHeight = size.Height
+
+	return nil
+}
+
 func (s *Server) handleWDACreateSession(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	client, err := s.getWDAClient(ctx)
 	if err != nil {
@@ -627,6 +1141,10 @@ func (s *Server) handleGetUITree(ctx context.Context, req mcp.CallToolRequest) (
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	var source string
 	if format == "json" {
 		source, err = client.SourceAccessible(ctx)
@@ -660,6 +1178,10 @@ func (s *Server) handleFindElement(ctx context.Context, req mcp.CallToolRequest)
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	element, err := client.FindElement(ctx, using, value)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -679,6 +1201,112 @@ func (s *Server) handleFindElement(ctx context.Context, req mcp.CallToolRequest)
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+// defaultFindElementsLimit caps find_elements results when no limit is given.
+const defaultFindElementsLimit = 50
+
+func (s *Server) handleFindElements(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	using := req.GetString("using", "")
+	value := req.GetString("value", "")
+
+	if using == "" || value == "" {
+		return mcp.NewToolResultError("using and value are required"), nil
+	}
+
+	limit := int(req.GetFloat("limit", defaultFindElementsLimit))
+	if limit < 1 {
+		limit = defaultFindElementsLimit
+	}
+
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to start WDA: %v", err)), nil
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create WDA session: %v", err)), nil
+		}
+	}
+
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	elements, err := client.FindElements(ctx, using, value)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	total := len(elements)
+	if total > limit {
+		elements = elements[:limit]
+	}
+
+	matches := make([]map[string]any, 0, len(elements))
+	for _, element := range elements {
+		match := map[string]any{
+			"element_id": element.ElementID,
+		}
+		if rect, _ := client.GetElementRect(ctx, element.ElementID); rect != nil {
+			match["rect"] = rect
+		}
+		matches = append(matches, match)
+	}
+
+	output, _ := json.MarshalIndent(map[string]any{
+		"count":    total,
+		"elements": matches,
+	}, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+func (s *Server) handleGetElementText(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	elementID := req.GetString("element_id", "")
+	using := req.GetString("using", "")
+	value := req.GetString("value", "")
+
+	if elementID == "" && (using == "" || value == "") {
+		return mcp.NewToolResultError("element_id or using and value are required"), nil
+	}
+
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to start WDA: %v", err)), nil
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create WDA session: %v", err)), nil
+		}
+	}
+
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if elementID == "" {
+		element, err := client.FindElement(ctx, using, value)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		elementID = element.ElementID
+	}
+
+	result := map[string]any{
+		"element_id": elementID,
+	}
+	for _, attribute := range []string{"label", "value", "name"} {
+		text, err := client.GetElementAttribute(ctx, elementID, attribute)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get %s: %v", attribute, err)), nil
+		}
+		result[attribute] = text
+	}
+
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
 func (s *Server) handleTap(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	x := req.GetFloat("x", -1)
 	y := req.GetFloat("y", -1)
@@ -695,6 +1323,10 @@ func (s *Server) handleTap(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if elementID != "" {
 		err = client.Click(ctx, elementID)
 	} else if x >= 0 && y >= 0 {
@@ -726,6 +1358,10 @@ func (s *Server) handleLongPress(ctx context.Context, req mcp.CallToolRequest) (
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if err := client.LongPress(ctx, int(x), int(y), duration); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -752,6 +1388,10 @@ func (s *Server) handleSwipe(ctx context.Context, req mcp.CallToolRequest) (*mcp
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Get window size for direction-based swipes
 	if direction != "" {
 		size, err := client.WindowSize(ctx)
@@ -759,26 +1399,11 @@ func (s *Server) handleSwipe(ctx context.Context, req mcp.CallToolRequest) (*mcp
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get window size: %v", err)), nil
 		}
 
-		centerX := size.Width / 2
-		centerY := size.Height / 2
-		offset := size.Height / 4
-
-		switch direction {
-		case "up":
-			startX, startY = float64(centerX), float64(centerY+offset)
-			endX, endY = float64(centerX), float64(centerY-offset)
-		case "down":
-			startX, startY = float64(centerX), float64(centerY-offset)
-			endX, endY = float64(centerX), float64(centerY+offset)
-		case "left":
-			startX, startY = float64(centerX+size.Width/4), float64(centerY)
-			endX, endY = float64(centerX-size.Width/4), float64(centerY)
-		case "right":
-			startX, startY = float64(centerX-size.Width/4), float64(centerY)
-			endX, endY = float64(centerX+size.Width/4), float64(centerY)
-		default:
-			return mcp.NewToolResultError("invalid direction, use: up, down, left, right"), nil
+		sx, sy, ex, ey, err := directionSwipe(size, direction)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
+		startX, startY, endX, endY = float64(sx), float64(sy), float64(ex), float64(ey)
 	}
 
 	if err := client.Swipe(ctx, int(startX), int(startY), int(endX), int(endY), duration); err != nil {
@@ -806,6 +1431,10 @@ func (s *Server) handleInputText(ctx context.Context, req mcp.CallToolRequest) (
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if err := client.SendKeys(ctx, text); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -838,6 +1467,38 @@ func (s *Server) handlePressButton(ctx context.Context, req mcp.CallToolRequest)
 	return mcp.NewToolResultText(fmt.Sprintf("Pressed button: %s", button)), nil
 }
 
+func (s *Server) handlePerformActions(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	actions, err := parseUIActions(req.GetArguments()["actions"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to start WDA: %v", err)), nil
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create WDA session: %v", err)), nil
+		}
+	}
+
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	results := runUIActions(ctx, client, actions)
+	output, _ := json.MarshalIndent(results, "", "  ")
+
+	if last := results[len(results)-1]; !last.OK {
+		return mcp.NewToolResultError(fmt.Sprintf("step %d/%d (%s) failed: %s\n%s",
+			last.Step, len(actions), last.Type, last.Error, output)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("All %d actions succeeded\n%s", len(actions), output)), nil
+}
+
 // UIElement represents a parsed UI element with coordinates
 type UIElement struct {
 	Type    string `json:"type"`
@@ -854,7 +1515,13 @@ type UIElement struct {
 }
 
 func (s *Server) handleGetElementsWithCoords(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	visibleOnly := req.GetBool("visible_only", true)
+	filter := DefaultElementFilter()
+	filter.VisibleOnly = req.GetBool("visible_only", filter.VisibleOnly)
+	filter.IncludeTypes = append(filter.IncludeTypes, req.GetStringSlice("include_types", nil)...)
+	filter.ExcludeTypes = req.GetStringSlice("exclude_types", nil)
+	filter.MaxDepth = req.GetInt("max_depth", filter.MaxDepth)
+	filter.ContainerDepth = req.GetInt("container_depth", filter.ContainerDepth)
+	filter.MinSize = req.GetInt("min_size", filter.MinSize)
 
 	client, err := s.getWDAClient(ctx)
 	if err != nil {
@@ -867,6 +1534,10 @@ func (s *Server) handleGetElementsWithCoords(ctx context.Context, req mcp.CallTo
 		}
 	}
 
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Get XML source
 	source, err := client.Source(ctx)
 	if err != nil {
@@ -876,7 +1547,7 @@ func (s *Server) handleGetElementsWithCoords(ctx context.Context, req mcp.CallTo
 	// Parse XML using decoder for flexible element names
 	var elements []UIElement
 	decoder := xml.NewDecoder(strings.NewReader(source))
-	parseXMLElements(decoder, &elements, visibleOnly, 0)
+	parseXMLElements(decoder, &elements, filter, 0)
 
 	// Format output
 	var output strings.Builder
@@ -905,8 +1576,71 @@ func (s *Server) handleGetElementsWithCoords(ctx context.Context, req mcp.CallTo
 	return mcp.NewToolResultText(output.String()), nil
 }
 
+func (s *Server) handleGetScreenText(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	interactiveOnly := req.GetBool("interactive_only", true)
+
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to start WDA: %v", err)), nil
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create WDA session: %v", err)), nil
+		}
+	}
+
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	source, err := client.Source(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var elements []UIElement
+	decoder := xml.NewDecoder(strings.NewReader(source))
+	parseXMLElements(decoder, &elements, DefaultElementFilter(), 0)
+
+	return mcp.NewToolResultText(renderScreenText(elements, interactiveOnly)), nil
+}
+
+// ElementFilter controls which elements parseXMLElements reports.
+type ElementFilter struct {
+	VisibleOnly    bool     // Skip invisible elements (and their children)
+	IncludeTypes   []string // Type substrings that are always interesting
+	ExcludeTypes   []string // Type substrings that are never reported
+	MaxDepth       int      // Stop descending below this depth (0 = unlimited)
+	ContainerDepth int      // Report every element at or above this depth (-1 = off)
+	MinSize        int      // Minimum width and height to report an element
+}
+
+// DefaultElementFilter returns the filter used when no overrides are given.
+func DefaultElementFilter() ElementFilter {
+	return ElementFilter{
+		VisibleOnly: true,
+		IncludeTypes: []string{
+			"Button", "TextField", "Text", "Image", "Cell",
+			"Switch", "Slider", "ScrollView", "Table",
+		},
+		ContainerDepth: 2,
+		MinSize:        1,
+	}
+}
+
+// matchesAny reports whether elementType contains any of the substrings.
+func matchesAny(elementType string, substrings []string) bool {
+	for _, sub := range substrings {
+		if sub != "" && strings.Contains(elementType, sub) {
+			return true
+		}
+	}
+	return false
+}
+
 // parseXMLElements recursively parses WDA XML using a streaming decoder
-func parseXMLElements(decoder *xml.Decoder, elements *[]UIElement, visibleOnly bool, depth int) {
+func parseXMLElements(decoder *xml.Decoder, elements *[]UIElement, filter ElementFilter, depth int) {
 	for {
 		token, err := decoder.Token()
 		if err != nil {
@@ -923,12 +1657,17 @@ func parseXMLElements(decoder *xml.Decoder, elements *[]UIElement, visibleOnly b
 
 			// Check visibility
 			visible := attrs["visible"] == "true"
-			if visibleOnly && !visible && depth > 0 {
+			if filter.VisibleOnly && !visible && depth > 0 {
 				// Skip this element but still need to consume its content
 				decoder.Skip()
 				continue
 			}
 
+			if filter.MaxDepth > 0 && depth > filter.MaxDepth {
+				decoder.Skip()
+				continue
+			}
+
 			// Parse coordinates
 			x, _ := strconv.Atoi(attrs["x"])
 			y, _ := strconv.Atoi(attrs["y"])
@@ -939,20 +1678,13 @@ func parseXMLElements(decoder *xml.Decoder, elements *[]UIElement, visibleOnly b
 			name := attrs["name"]
 			label := attrs["label"]
 
-			// Add element if it has size
-			if w > 0 && h > 0 {
+			// Add element if it is large enough
+			if w >= filter.MinSize && h >= filter.MinSize && w > 0 && h > 0 &&
+				!matchesAny(elementType, filter.ExcludeTypes) {
 				// Filter to interesting elements
 				isInteresting := name != "" || label != "" ||
-					strings.Contains(elementType, "Button") ||
-					strings.Contains(elementType, "TextField") ||
-					strings.Contains(elementType, "Text") ||
-					strings.Contains(elementType, "Image") ||
-					strings.Contains(elementType, "Cell") ||
-					strings.Contains(elementType, "Switch") ||
-					strings.Contains(elementType, "Slider") ||
-					strings.Contains(elementType, "ScrollView") ||
-					strings.Contains(elementType, "Table") ||
-					depth <= 2
+					matchesAny(elementType, filter.IncludeTypes) ||
+					depth <= filter.ContainerDepth
 
 				if isInteresting {
 					*elements = append(*elements, UIElement{
@@ -972,7 +1704,7 @@ func parseXMLElements(decoder *xml.Decoder, elements *[]UIElement, visibleOnly b
 			}
 
 			// Recursively parse children
-			parseXMLElements(decoder, elements, visibleOnly, depth+1)
+			parseXMLElements(decoder, elements, filter, depth+1)
 
 		case xml.EndElement:
 			return
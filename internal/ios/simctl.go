@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -16,19 +17,51 @@ import (
 
 // SimCtl provides methods to interact with xcrun simctl commands.
 type SimCtl struct {
-	mu              sync.Mutex
-	activeRecording *activeRecording
+	mu         sync.Mutex
+	runner     commandRunner
+	recordings map[string]*activeRecording // Keyed by device ID
 }
 
 type activeRecording struct {
 	deviceID   string
 	outputPath string
-	cmd        *exec.Cmd
+	proc       process
 }
 
+// commandRunner starts commands that keep running in the background, such
+// as video recordings.
+type commandRunner interface {
+	Start(name string, args ...string) (process, error)
+}
+
+// process is a command started by a commandRunner.
+type process interface {
+	Signal(sig os.Signal) error
+	Wait() error
+}
+
+// execRunner starts commands with os/exec.
+type execRunner struct{}
+
+func (execRunner) Start(name string, args ...string) (process, error) {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return execProcess{cmd}, nil
+}
+
+// execProcess is a process started by execRunner.
+type execProcess struct {
+	cmd *exec.Cmd
+}
+
+func (p execProcess) Signal(sig os.Signal) error { return p.cmd.Process.Signal(sig) }
+func (p execProcess) Wait() error                { return p.cmd.Wait() }
+
 // NewSimCtl creates a new SimCtl instance.
 func NewSimCtl() *SimCtl {
-	return &SimCtl{}
+	return &SimCtl{runner: execRunner{}, recordings: make(map[string]*activeRecording)}
 }
 
 // ListDevices returns all available iOS simulators.
@@ -77,6 +110,94 @@ func (s *SimCtl) ListRuntimes(ctx context.Context) ([]Runtime, error) {
 	return runtimeList.Runtimes, nil
 }
 
+// ListDeviceTypes returns all simulator device types.
+func (s *SimCtl) ListDeviceTypes(ctx context.Context) ([]DeviceType, error) {
+	cmd := exec.CommandContext(ctx, "xcrun", "simctl", "list", "devicetypes", "-j")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("simctl list devicetypes failed: %w", err)
+	}
+
+	var typeList DeviceTypeList
+	if err := json.Unmarshal(out, &typeList); err != nil {
+		return nil, fmt.Errorf("failed to parse device types JSON: %w", err)
+	}
+
+	return typeList.DeviceTypes, nil
+}
+
+// Create creates a simulator and returns its UDID. deviceType and runtime
+// may be identifiers or names (e.g. "iPhone 15", "iOS 17.5"); both are
+// checked to exist, and the runtime to support the device type.
+func (s *SimCtl) Create(ctx context.Context, name, deviceType, runtime string) (string, error) {
+	types, err := s.ListDeviceTypes(ctx)
+	if err != nil {
+		return "", err
+	}
+	var dt *DeviceType
+	for i := range types {
+		if types[i].Identifier == deviceType || strings.EqualFold(types[i].Name, deviceType) {
+			dt = &types[i]
+			break
+		}
+	}
+	if dt == nil {
+		return "", fmt.Errorf("unknown device type %q (see list_device_types)", deviceType)
+	}
+
+	runtimes, err := s.ListRuntimes(ctx)
+	if err != nil {
+		return "", err
+	}
+	var rt *Runtime
+	for i := range runtimes {
+		if runtimes[i].Identifier == runtime || strings.EqualFold(runtimes[i].Name, runtime) {
+			rt = &runtimes[i]
+			break
+		}
+	}
+	if rt == nil {
+		return "", fmt.Errorf("unknown runtime %q (see list_device_types)", runtime)
+	}
+	if !rt.IsAvailable {
+		return "", fmt.Errorf("runtime %s is not available", rt.Name)
+	}
+	if len(rt.SupportedDeviceTypes) > 0 {
+		supported := false
+		for _, t := range rt.SupportedDeviceTypes {
+			if t.Identifier == dt.Identifier {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return "", fmt.Errorf("runtime %s does not support device type %s", rt.Name, dt.Name)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "xcrun", "simctl", "create", name, dt.Identifier, rt.Identifier)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("simctl create failed: %s", stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Delete deletes a simulator.
+func (s *SimCtl) Delete(ctx context.Context, deviceID string) error {
+	cmd := exec.CommandContext(ctx, "xcrun", "simctl", "delete", deviceID)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("simctl delete failed: %s", stderr.String())
+	}
+	return nil
+}
+
 // Boot boots a simulator by UDID or name.
 func (s *SimCtl) Boot(ctx context.Context, deviceID string) error {
 	cmd := exec.CommandContext(ctx, "xcrun", "simctl", "boot", deviceID)
@@ -135,18 +256,19 @@ func (s *SimCtl) Screenshot(ctx context.Context, deviceID string, outputPath str
 	return outputPath, nil
 }
 
-// StartRecording starts video recording on the simulator.
+// StartRecording starts video recording on the simulator. Each device can
+// record independently.
 func (s *SimCtl) StartRecording(ctx context.Context, deviceID string, outputPath string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.activeRecording != nil {
-		return fmt.Errorf("recording already in progress for device %s", s.activeRecording.deviceID)
+	if _, ok := s.recordings[deviceID]; ok {
+		return fmt.Errorf("recording already in progress for device %s", deviceID)
 	}
 
 	if outputPath == "" {
 		timestamp := time.Now().Format("20060102_150405")
-		outputPath = filepath.Join(os.TempDir(), fmt.Sprintf("ios_recording_%s.mov", timestamp))
+		outputPath = filepath.Join(os.TempDir(), fmt.Sprintf("ios_recording_%s_%s.mov", deviceID, timestamp))
 	}
 
 	// Ensure directory exists
@@ -155,51 +277,63 @@ func (s *SimCtl) StartRecording(ctx context.Context, deviceID string, outputPath
 	}
 
 	// Start recording in background
-	cmd := exec.Command("xcrun", "simctl", "io", deviceID, "recordVideo", outputPath)
-	if err := cmd.Start(); err != nil {
+	proc, err := s.runner.Start("xcrun", "simctl", "io", deviceID, "recordVideo", outputPath)
+	if err != nil {
 		return fmt.Errorf("failed to start recording: %w", err)
 	}
 
-	s.activeRecording = &activeRecording{
+	s.recordings[deviceID] = &activeRecording{
 		deviceID:   deviceID,
 		outputPath: outputPath,
-		cmd:        cmd,
+		proc:       proc,
 	}
 
 	return nil
 }
 
-// StopRecording stops the current video recording.
+// StopRecording stops the video recording on the device.
 // Returns the path to the recorded video.
-func (s *SimCtl) StopRecording() (string, error) {
+func (s *SimCtl) StopRecording(deviceID string) (string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.activeRecording == nil {
-		return "", fmt.Errorf("no recording in progress")
+	rec, ok := s.recordings[deviceID]
+	if !ok {
+		return "", fmt.Errorf("no recording in progress for device %s", deviceID)
 	}
 
 	// Send SIGINT to stop recording gracefully
-	if s.activeRecording.cmd.Process != nil {
-		if err := s.activeRecording.cmd.Process.Signal(syscall.SIGINT); err != nil {
-			return "", fmt.Errorf("failed to stop recording: %w", err)
-		}
+	if err := rec.proc.Signal(syscall.SIGINT); err != nil {
+		return "", fmt.Errorf("failed to stop recording: %w", err)
 	}
 
 	// Wait for process to finish
-	_ = s.activeRecording.cmd.Wait()
+	_ = rec.proc.Wait()
 
-	outputPath := s.activeRecording.outputPath
-	s.activeRecording = nil
+	delete(s.recordings, deviceID)
 
-	return outputPath, nil
+	return rec.outputPath, nil
+}
+
+// IsRecording returns whether a recording is in progress on the device.
+func (s *SimCtl) IsRecording(deviceID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.recordings[deviceID]
+	return ok
 }
 
-// IsRecording returns whether a recording is in progress.
-func (s *SimCtl) IsRecording() bool {
+// RecordingDevices returns the IDs of devices that are currently recording.
+func (s *SimCtl) RecordingDevices() []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.activeRecording != nil
+
+	devices := make([]string, 0, len(s.recordings))
+	for deviceID := range s.recordings {
+		devices = append(devices, deviceID)
+	}
+	sort.Strings(devices)
+	return devices
 }
 
 // Install installs an app bundle on the simulator.
@@ -277,6 +411,166 @@ func (s *SimCtl) OpenURL(ctx context.Context, deviceID string, url string) error
 	return nil
 }
 
+// Appearance modes accepted by SetAppearance.
+const (
+	AppearanceLight = "light"
+	AppearanceDark  = "dark"
+)
+
+// SetAppearance switches the simulator between light and dark mode.
+func (s *SimCtl) SetAppearance(ctx context.Context, deviceID string, mode string) error {
+	if mode != AppearanceLight && mode != AppearanceDark {
+		return fmt.Errorf("invalid appearance %q (expected %s or %s)", mode, AppearanceLight, AppearanceDark)
+	}
+
+	cmd := exec.CommandContext(ctx, "xcrun", "simctl", "ui", deviceID, "appearance", mode)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("simctl ui appearance failed: %s", stderr.String())
+	}
+	return nil
+}
+
+// Privacy actions accepted by SetPrivacy.
+const (
+	PrivacyGrant  = "grant"
+	PrivacyRevoke = "revoke"
+	PrivacyReset  = "reset"
+)
+
+// PrivacyServices are the services xcrun simctl privacy can change.
+var PrivacyServices = []string{
+	"all", "calendar", "contacts", "contacts-limited", "location", "location-always",
+	"media-library", "microphone", "motion", "photos", "photos-add", "reminders", "siri",
+}
+
+// unsupportedPrivacyServices explains services simctl privacy cannot change.
+var unsupportedPrivacyServices = map[string]string{
+	"camera":        "the simulator has no camera and simctl cannot grant camera access",
+	"notifications": "simctl cannot grant notification permission; accept the alert via UI automation instead",
+}
+
+// ValidatePrivacyService returns an error if simctl privacy does not
+// support service.
+func ValidatePrivacyService(service string) error {
+	if reason, ok := unsupportedPrivacyServices[service]; ok {
+		return fmt.Errorf("unsupported privacy service %q: %s", service, reason)
+	}
+	for _, known := range PrivacyServices {
+		if service == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown privacy service %q (expected one of: %s)", service, strings.Join(PrivacyServices, ", "))
+}
+
+// SetPrivacy grants, revokes or resets a privacy permission for an app.
+// bundleID may be empty only for reset, which then applies to all apps.
+func (s *SimCtl) SetPrivacy(ctx context.Context, deviceID, action, service, bundleID string) error {
+	switch action {
+	case PrivacyGrant, PrivacyRevoke:
+		if bundleID == "" {
+			return fmt.Errorf("bundle ID is required to %s a permission", action)
+		}
+	case PrivacyReset:
+	default:
+		return fmt.Errorf("invalid privacy action %q (expected grant, revoke or reset)", action)
+	}
+	if err := ValidatePrivacyService(service); err != nil {
+		return err
+	}
+
+	args := []string{"simctl", "privacy", deviceID, action, service}
+	if bundleID != "" {
+		args = append(args, bundleID)
+	}
+
+	cmd := exec.CommandContext(ctx, "xcrun", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("simctl privacy failed: %s", stderr.String())
+	}
+	return nil
+}
+
+// DefaultLogWindow is how far back Logs looks when LogOptions.Last is zero.
+const DefaultLogWindow = 5 * time.Minute
+
+// DefaultLogMaxBytes caps the output returned by Logs when MaxBytes is zero.
+const DefaultLogMaxBytes = 32000
+
+// LogOptions selects the unified log entries returned by Logs.
+type LogOptions struct {
+	Process   string        // Match entries whose process image path contains this (e.g. the app name)
+	Predicate string        // Raw log predicate; overrides Process
+	Last      time.Duration // How far back to look (default DefaultLogWindow)
+	MaxBytes  int           // Keep at most this many trailing bytes (default DefaultLogMaxBytes)
+}
+
+// Logs returns recent unified log output of the simulator via
+// `log show`. When the output exceeds MaxBytes, the most recent entries are
+// kept and truncated reports true.
+func (s *SimCtl) Logs(ctx context.Context, deviceID string, opts LogOptions) (output string, truncated bool, err error) {
+	last := opts.Last
+	if last <= 0 {
+		last = DefaultLogWindow
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultLogMaxBytes
+	}
+
+	predicate := opts.Predicate
+	if predicate == "" && opts.Process != "" {
+		escaped := strings.ReplaceAll(strings.ReplaceAll(opts.Process, `\`, `\\`), `"`, `\"`)
+		predicate = fmt.Sprintf(`processImagePath contains "%s"`, escaped)
+	}
+
+	args := []string{"simctl", "spawn", deviceID, "log", "show",
+		"--style", "compact", "--last", fmt.Sprintf("%ds", int(last.Seconds()))}
+	if predicate != "" {
+		args = append(args, "--predicate", predicate)
+	}
+
+	cmd := exec.CommandContext(ctx, "xcrun", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", false, fmt.Errorf("simctl log show failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	output = stdout.String()
+	if len(output) > maxBytes {
+		output = output[len(output)-maxBytes:]
+		// Drop the partial first line
+		if i := strings.IndexByte(output, '\n'); i >= 0 {
+			output = output[i+1:]
+		}
+		truncated = true
+	}
+
+	return output, truncated, nil
+}
+
+// SetPasteboard replaces the simulator's pasteboard with text.
+func (s *SimCtl) SetPasteboard(ctx context.Context, deviceID string, text string) error {
+	cmd := exec.CommandContext(ctx, "xcrun", "simctl", "pbcopy", deviceID)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("simctl pbcopy failed: %s", stderr.String())
+	}
+	return nil
+}
+
 // StatusBarOverride overrides the status bar on the simulator.
 func (s *SimCtl) StatusBarOverride(ctx context.Context, deviceID string, time string, battery string) error {
 	args := []string{"simctl", "status_bar", deviceID, "override"}
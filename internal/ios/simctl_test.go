@@ -0,0 +1,95 @@
+package ios
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeRunner records the commands it starts instead of running them.
+type fakeRunner struct {
+	mu      sync.Mutex
+	started map[string]*fakeProcess // Keyed by device ID
+}
+
+func (r *fakeRunner) Start(name string, args ...string) (process, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// xcrun simctl io <device> recordVideo <path>
+	p := &fakeProcess{}
+	r.started[args[2]] = p
+	return p, nil
+}
+
+// fakeProcess remembers the signals it was sent.
+type fakeProcess struct {
+	signals []os.Signal
+	waited  bool
+}
+
+func (p *fakeProcess) Signal(sig os.Signal) error {
+	p.signals = append(p.signals, sig)
+	return nil
+}
+
+func (p *fakeProcess) Wait() error {
+	p.waited = true
+	return nil
+}
+
+func newTestSimCtl() (*SimCtl, *fakeRunner) {
+	runner := &fakeRunner{started: make(map[string]*fakeProcess)}
+	s := NewSimCtl()
+	s.runner = runner
+	return s, runner
+}
+
+func TestRecordingsArePerDevice(t *testing.T) {
+	s, runner := newTestSimCtl()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	for _, device := range []string{"device-a", "device-b"} {
+		if err := s.StartRecording(ctx, device, filepath.Join(dir, device+".mov")); err != nil {
+			t.Fatalf("start %s: %v", device, err)
+		}
+	}
+	if err := s.StartRecording(ctx, "device-a", ""); err == nil {
+		t.Error("second recording on device-a started, want an error")
+	}
+	if got, want := s.RecordingDevices(), []string{"device-a", "device-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordingDevices() = %v, want %v", got, want)
+	}
+
+	path, err := s.StopRecording("device-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "device-a.mov"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+
+	a, b := runner.started["device-a"], runner.started["device-b"]
+	if len(a.signals) != 1 || !a.waited {
+		t.Errorf("device-a got signals %v, waited %v; want one signal and a wait", a.signals, a.waited)
+	}
+	if len(b.signals) != 0 || b.waited {
+		t.Errorf("device-b got signals %v, waited %v; want it left running", b.signals, b.waited)
+	}
+	if s.IsRecording("device-a") || !s.IsRecording("device-b") {
+		t.Errorf("IsRecording = %v, %v; want false, true", s.IsRecording("device-a"), s.IsRecording("device-b"))
+	}
+
+	if _, err := s.StopRecording("device-a"); err == nil {
+		t.Error("stopping device-a twice succeeded, want an error")
+	}
+	if _, err := s.StopRecording("device-b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.RecordingDevices(); len(got) != 0 {
+		t.Errorf("RecordingDevices() = %v after stopping both, want none", got)
+	}
+}
@@ -44,12 +44,35 @@ type RuntimeList struct {
 	Runtimes []Runtime `json:"runtimes"`
 }
 
-// BuildResult contains information about a successful Xcode build.
+// DeviceType represents a simulator device type (hardware model).
+type DeviceType struct {
+	Identifier    string `json:"identifier"`
+	Name          string `json:"name"`
+	ProductFamily string `json:"productFamily"`
+}
+
+// DeviceTypeList represents the JSON output from simctl list devicetypes.
+type DeviceTypeList struct {
+	DeviceTypes []DeviceType `json:"devicetypes"`
+}
+
+// BuildResult contains information about an Xcode build. On failure only
+// Scheme, BuildDir and Errors are set.
 type BuildResult struct {
 	AppPath   string `json:"appPath"`
 	BundleID  string `json:"bundleId"`
 	Scheme    string `json:"scheme"`
 	BuildDir  string `json:"buildDir"`
+	Errors    []BuildError `json:"errors,omitempty"`
+}
+
+// BuildError is a compiler or build system error reported by xcodebuild.
+// File and Line are empty for errors without a source location.
+type BuildError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
 }
 
 // RecordingState tracks video recording state.
@@ -58,3 +81,18 @@ type RecordingState struct {
 	OutputPath  string
 	ProcessID   int
 }
+
+
+// DeviceState summarizes what is currently on a simulator's screen.
+type DeviceState struct {
+	UDID           string `json:"udid"`
+	Name           string `json:"name,omitempty"`
+	State          string `json:"state,omitempty"`
+	Runtime        string `json:"runtime,omitempty"`
+	ForegroundApp  string `json:"foreground_app,omitempty"`
+	ForegroundName string `json:"foreground_app_name,omitempty"`
+	Orientation    string `json:"orientation,omitempty"`
+	ScreenWidth    int    `json:"screen_width,omitempty"`
+	ScreenHeight   int    `json:"screen_height,omitempty"`
+	WDAError       string `json:"wda_error,omitempty"`
+}
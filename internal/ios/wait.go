@@ -0,0 +1,129 @@
+package ios
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/notexe/cli-chat/internal/ios/wda"
+)
+
+const (
+	// defaultElementWait is the wait_for_element timeout when none is given.
+	defaultElementWait = 10 * time.Second
+
+	// maxElementWait caps a single wait so it cannot stall the server.
+	maxElementWait = 120 * time.Second
+
+	// elementPollInterval is the pause between element lookups.
+	elementPollInterval = 500 * time.Millisecond
+)
+
+// waitForElement polls until an element matching using/value is present
+// (or, with gone, absent) or timeout expires. It returns the first matching
+// element when waiting for presence.
+func waitForElement(ctx context.Context, client *wda.Client, using, value string, gone bool, timeout time.Duration) (*wda.Element, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(elementPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		// FindElements returns an empty list instead of an error when
+		// nothing matches, so errors here are real failures
+		elements, err := client.FindElements(ctx, using, value)
+		switch {
+		case err != nil:
+			lastErr = err
+		case gone && len(elements) == 0:
+			return nil, nil
+		case !gone && len(elements) > 0:
+			return &elements[0], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			state := "appear"
+			if gone {
+				state = "disappear"
+			}
+			if lastErr != nil && ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("element %s=%q did not %s within %v (last error: %v)", using, value, state, timeout, lastErr)
+			}
+			return nil, fmt.Errorf("element %s=%q did not %s within %v", using, value, state, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// elementWaitTimeout reads the timeout argument in seconds.
+func elementWaitTimeout(req mcp.CallToolRequest) time.Duration {
+	timeout := time.Duration(req.GetFloat("timeout", defaultElementWait.Seconds()) * float64(time.Second))
+	if timeout <= 0 {
+		timeout = defaultElementWait
+	}
+	if timeout > maxElementWait {
+		timeout = maxElementWait
+	}
+	return timeout
+}
+
+func (s *Server) handleWaitForElement(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.waitForElementResult(ctx, req, false)
+}
+
+func (s *Server) handleWaitForElementGone(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.waitForElementResult(ctx, req, true)
+}
+
+// waitForElementResult implements wait_for_element and wait_for_element_gone.
+func (s *Server) waitForElementResult(ctx context.Context, req mcp.CallToolRequest, gone bool) (*mcp.CallToolResult, error) {
+	using := req.GetString("using", "")
+	value := req.GetString("value", "")
+
+	if using == "" || value == "" {
+		return mcp.NewToolResultError("using and value are required"), nil
+	}
+
+	timeout := elementWaitTimeout(req)
+
+	client, err := s.getWDAClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to start WDA: %v", err)), nil
+	}
+
+	if client.GetSessionID() == "" {
+		if _, err := client.CreateSession(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create WDA session: %v", err)), nil
+		}
+	}
+
+	if err := s.ensureTargetApp(ctx, client); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	start := time.Now()
+	element, err := waitForElement(ctx, client, using, value, gone, timeout)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]any{
+		"waited_ms": time.Since(start).Milliseconds(),
+	}
+	if gone {
+		result["gone"] = true
+	} else {
+		result["element_id"] = element.ElementID
+		if rect, _ := client.GetElementRect(ctx, element.ElementID); rect != nil {
+			result["rect"] = rect
+		}
+	}
+
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
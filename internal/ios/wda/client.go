@@ -164,6 +164,44 @@ func (c *Client) WindowSize(ctx context.Context) (*WindowSize, error) {
 	return &sizeResp.Value, nil
 }
 
+// Orientation returns the device orientation, e.g. "PORTRAIT" or "LANDSCAPE".
+func (c *Client) Orientation(ctx context.Context) (string, error) {
+	if c.sessionID == "" {
+		return "", fmt.Errorf("no active session")
+	}
+
+	resp, err := c.get(ctx, fmt.Sprintf("/session/%s/orientation", c.sessionID))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse orientation response: %w", err)
+	}
+
+	return result.Value, nil
+}
+
+// ActiveAppInfo returns the app currently in the foreground.
+func (c *Client) ActiveAppInfo(ctx context.Context) (*AppInfo, error) {
+	resp, err := c.get(ctx, "/wda/activeAppInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Value AppInfo `json:"value"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse active app response: %w", err)
+	}
+
+	return &result.Value, nil
+}
+
 // FindElement finds a single element.
 // using can be: "accessibility id", "class name", "name", "xpath", "predicate string", "class chain"
 func (c *Client) FindElement(ctx context.Context, using, value string) (*Element, error) {
@@ -42,6 +42,13 @@ func (m *Manager) SetDeviceID(deviceID string) {
 	m.deviceID = deviceID
 }
 
+// DeviceID returns the target simulator device ID, or empty if not set.
+func (m *Manager) DeviceID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deviceID
+}
+
 // GetClient returns a WDA client, starting WDA if necessary.
 func (m *Manager) GetClient(ctx context.Context) (*Client, error) {
 	m.mu.Lock()
@@ -69,6 +69,13 @@ type WindowSizeResponse struct {
 	SessionID string     `json:"sessionId"`
 }
 
+// AppInfo describes the app currently in the foreground.
+type AppInfo struct {
+	BundleID string `json:"bundleId"`
+	Name     string `json:"name,omitempty"`
+	PID      int    `json:"pid,omitempty"`
+}
+
 // FindElementRequest is the request body for finding elements.
 type FindElementRequest struct {
 	Using string `json:"using"`
@@ -4,52 +4,73 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // XcodeBuild provides methods to interact with xcodebuild commands.
-type XcodeBuild struct{}
+type XcodeBuild struct {
+	output outputFunc // Runs the commands whose output is parsed
+}
+
+// outputFunc runs a command and returns its standard output.
+type outputFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// commandOutput is the outputFunc that runs commands with os/exec.
+func commandOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
 
 // NewXcodeBuild creates a new XcodeBuild instance.
 func NewXcodeBuild() *XcodeBuild {
-	return &XcodeBuild{}
+	return &XcodeBuild{output: commandOutput}
 }
 
 // BuildOptions contains options for building an Xcode project.
 type BuildOptions struct {
-	ProjectPath    string // Path to .xcodeproj or directory containing it
+	ProjectPath    string // Path to .xcodeproj, .xcworkspace or a directory containing them
 	WorkspacePath  string // Path to .xcworkspace (takes precedence over ProjectPath)
 	Scheme         string // Build scheme name
 	Configuration  string // Build configuration (Debug, Release), default: Debug
 	SimulatorName  string // Simulator name, default: iPhone 15
 	DerivedDataPath string // Custom derived data path
+	OnOutput       func(line string) // Optional, receives each output line as the build runs
 }
 
+// maxBuildErrors caps the errors returned in BuildResult.Errors.
+const maxBuildErrors = 20
+
+// buildErrorTail is how many output lines are included in the error when
+// no structured errors could be parsed.
+const buildErrorTail = 30
+
+// buildErrorPattern matches "path:line:col: error: message" (col optional).
+var buildErrorPattern = regexp.MustCompile(`^(/[^:]+):(\d+):(?:(\d+):)? (?:fatal )?error: (.+)$`)
+
+// toolErrorPattern matches errors without a location, either bare
+// ("error: message") or from a tool ("clang: error: linker command failed").
+var toolErrorPattern = regexp.MustCompile(`^(?:[\w.+-]+: )?(?:fatal )?error: (.+)$`)
+
+// undefinedSymbolPattern matches a symbol listed under ld's "Undefined
+// symbols for architecture" header.
+var undefinedSymbolPattern = regexp.MustCompile(`^"(.+)", referenced from:$`)
+
 // Build builds an Xcode project for iOS simulator.
 // Returns the path to the built .app bundle.
 func (x *XcodeBuild) Build(ctx context.Context, opts BuildOptions) (*BuildResult, error) {
-	args := []string{}
-
-	// Add workspace or project
-	if opts.WorkspacePath != "" {
-		args = append(args, "-workspace", opts.WorkspacePath)
-	} else if opts.ProjectPath != "" {
-		// Check if it's a directory or .xcodeproj file
-		if !strings.HasSuffix(opts.ProjectPath, ".xcodeproj") {
-			// Try to find .xcodeproj in directory
-			matches, err := filepath.Glob(filepath.Join(opts.ProjectPath, "*.xcodeproj"))
-			if err != nil || len(matches) == 0 {
-				return nil, fmt.Errorf("no .xcodeproj found in %s", opts.ProjectPath)
-			}
-			opts.ProjectPath = matches[0]
-		}
-		args = append(args, "-project", opts.ProjectPath)
-	} else {
-		return nil, fmt.Errorf("either ProjectPath or WorkspacePath must be specified")
+	flag, containerPath, err := resolveContainer(opts.WorkspacePath, opts.ProjectPath)
+	if err != nil {
+		return nil, err
 	}
+	args := []string{flag, containerPath}
 
 	// Add scheme
 	if opts.Scheme == "" {
@@ -75,25 +96,61 @@ func (x *XcodeBuild) Build(ctx context.Context, opts BuildOptions) (*BuildResult
 	// Add derived data path if specified
 	derivedDataPath := opts.DerivedDataPath
 	if derivedDataPath == "" {
-		derivedDataPath = filepath.Join(filepath.Dir(opts.ProjectPath), "build")
+		derivedDataPath = filepath.Join(filepath.Dir(containerPath), "build")
 	}
 	args = append(args, "-derivedDataPath", derivedDataPath)
 
 	// Build only (no run)
 	args = append(args, "build")
 
-	// Run xcodebuild
+	// Run xcodebuild, reading stdout and stderr line by line as they arrive
 	cmd := exec.CommandContext(ctx, "xcodebuild", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("xcodebuild failed: %s\n%s", err.Error(), stderr.String())
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start xcodebuild: %w", err)
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.CloseWithError(err)
+		waitErr <- err
+	}()
+
+	var lines []string
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		if opts.OnOutput != nil {
+			opts.OnOutput(line)
+		}
+	}
+	// Drain the rest if a line was too long, so xcodebuild can exit
+	_, _ = io.Copy(io.Discard, pr)
+
+	if err := <-waitErr; err != nil {
+		result := &BuildResult{
+			Scheme:   opts.Scheme,
+			BuildDir: derivedDataPath,
+			Errors:   parseBuildErrors(lines),
+		}
+		if len(result.Errors) > 0 {
+			return result, fmt.Errorf("xcodebuild failed: %w (%d errors)", err, len(result.Errors))
+		}
+		tail := lines
+		if len(tail) > buildErrorTail {
+			tail = tail[len(tail)-buildErrorTail:]
+		}
+		return result, fmt.Errorf("xcodebuild failed: %w\n%s", err, strings.Join(tail, "\n"))
 	}
 
 	// Find the built .app path from build output
-	appPath, err := x.findAppPath(stdout.String(), derivedDataPath, opts.Scheme, config)
+	appPath, err := x.findAppPath(strings.Join(lines, "\n"), derivedDataPath, opts.Scheme, config)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +166,85 @@ func (x *XcodeBuild) Build(ctx context.Context, opts BuildOptions) (*BuildResult
 	}, nil
 }
 
+// parseBuildErrors extracts the distinct errors from xcodebuild output,
+// keeping the last maxBuildErrors. xcodebuild repeats each compiler error in
+// its summary, so duplicates are dropped. A failed link gives an error per
+// undefined symbol ld lists, besides the linker's own error.
+func parseBuildErrors(lines []string) []BuildError {
+	var errs []BuildError
+	seen := make(map[string]bool)
+	undefined := false // In ld's list of undefined symbols
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Undefined symbols for architecture") {
+			undefined = true
+			continue
+		}
+
+		var be BuildError
+		if m := undefinedSymbolPattern.FindStringSubmatch(line); undefined && m != nil {
+			be.Message = "Undefined symbol: " + m[1]
+		} else if m := buildErrorPattern.FindStringSubmatch(line); m != nil {
+			be.File = m[1]
+			be.Line, _ = strconv.Atoi(m[2])
+			be.Column, _ = strconv.Atoi(m[3])
+			be.Message = m[4]
+		} else if m := toolErrorPattern.FindStringSubmatch(line); m != nil {
+			be.Message = m[1]
+		} else {
+			if strings.HasPrefix(line, "ld: ") {
+				undefined = false
+			}
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d:%d:%s", be.File, be.Line, be.Column, be.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		errs = append(errs, be)
+	}
+
+	if len(errs) > maxBuildErrors {
+		errs = errs[len(errs)-maxBuildErrors:]
+	}
+	return errs
+}
+
+// resolveContainer picks what xcodebuild should build and returns the
+// matching flag ("-workspace" or "-project") and path. An explicit workspace
+// wins. projectPath may be a .xcodeproj, a .xcworkspace or a directory; in a
+// directory a .xcworkspace is preferred over a .xcodeproj, since CocoaPods
+// projects only build through their workspace.
+func resolveContainer(workspacePath, projectPath string) (string, string, error) {
+	switch {
+	case workspacePath != "":
+		return "-workspace", workspacePath, nil
+	case projectPath == "":
+		return "", "", fmt.Errorf("either project or workspace path must be specified")
+	case strings.HasSuffix(projectPath, ".xcworkspace"):
+		return "-workspace", projectPath, nil
+	case strings.HasSuffix(projectPath, ".xcodeproj"):
+		return "-project", projectPath, nil
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(projectPath, "*.xcworkspace")); len(matches) > 0 {
+		return "-workspace", matches[0], nil
+	}
+
+	if _, err := os.Stat(filepath.Join(projectPath, "Podfile")); err == nil {
+		return "", "", fmt.Errorf("found a Podfile in %s but no .xcworkspace; run 'pod install' first", projectPath)
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(projectPath, "*.xcodeproj")); len(matches) > 0 {
+		return "-project", matches[0], nil
+	}
+
+	return "", "", fmt.Errorf("no .xcworkspace or .xcodeproj found in %s", projectPath)
+}
+
 // findAppPath finds the .app bundle path from build output or derived data.
 func (x *XcodeBuild) findAppPath(output, derivedDataPath, scheme, config string) (string, error) {
 	// Try to find from build settings output
@@ -157,54 +293,62 @@ func (x *XcodeBuild) getBundleID(appPath string) (string, error) {
 
 // ListSchemes lists available schemes in a project/workspace.
 func (x *XcodeBuild) ListSchemes(ctx context.Context, projectPath string) ([]string, error) {
-	args := []string{"-list", "-json"}
-
-	if strings.HasSuffix(projectPath, ".xcworkspace") {
-		args = append(args, "-workspace", projectPath)
-	} else {
-		if !strings.HasSuffix(projectPath, ".xcodeproj") {
-			matches, err := filepath.Glob(filepath.Join(projectPath, "*.xcodeproj"))
-			if err != nil || len(matches) == 0 {
-				// Try workspace
-				matches, err = filepath.Glob(filepath.Join(projectPath, "*.xcworkspace"))
-				if err != nil || len(matches) == 0 {
-					return nil, fmt.Errorf("no Xcode project found in %s", projectPath)
-				}
-				args = append(args, "-workspace", matches[0])
-			} else {
-				args = append(args, "-project", matches[0])
-			}
-		} else {
-			args = append(args, "-project", projectPath)
-		}
+	flag, containerPath, err := resolveContainer("", projectPath)
+	if err != nil {
+		return nil, err
 	}
+	args := []string{"-list", "-json", flag, containerPath}
 
-	cmd := exec.CommandContext(ctx, "xcodebuild", args...)
-	out, err := cmd.Output()
+	out, err := x.output(ctx, "xcodebuild", args...)
 	if err != nil {
 		return nil, fmt.Errorf("xcodebuild -list failed: %w", err)
 	}
 
-	// Parse JSON output (simplified - just extract scheme names)
-	var schemes []string
-	lines := strings.Split(string(out), "\n")
-	inSchemes := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, `"schemes"`) {
-			inSchemes = true
+	return parseSchemeList(out)
+}
+
+// schemeList is the output of `xcodebuild -list -json`. Exactly one of
+// Project and Workspace is set, depending on what was listed.
+type schemeList struct {
+	Project *struct {
+		Schemes []string `json:"schemes"`
+	} `json:"project"`
+	Workspace *struct {
+		Schemes []string `json:"schemes"`
+	} `json:"workspace"`
+}
+
+// parseSchemeList extracts the schemes from `xcodebuild -list -json` output
+// as a sorted list without duplicates.
+func parseSchemeList(out []byte) ([]string, error) {
+	// xcodebuild may print warnings before the JSON document
+	if i := bytes.IndexByte(out, '{'); i > 0 {
+		out = out[i:]
+	}
+
+	var list schemeList
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse xcodebuild -list output: %w", err)
+	}
+
+	var all []string
+	if list.Project != nil {
+		all = append(all, list.Project.Schemes...)
+	}
+	if list.Workspace != nil {
+		all = append(all, list.Workspace.Schemes...)
+	}
+
+	seen := make(map[string]bool, len(all))
+	schemes := make([]string, 0, len(all))
+	for _, scheme := range all {
+		if scheme == "" || seen[scheme] {
 			continue
 		}
-		if inSchemes {
-			if line == "]" || line == "]," {
-				break
-			}
-			scheme := strings.Trim(line, `",`)
-			if scheme != "" && scheme != "[" {
-				schemes = append(schemes, scheme)
-			}
-		}
+		seen[scheme] = true
+		schemes = append(schemes, scheme)
 	}
+	sort.Strings(schemes)
 
 	return schemes, nil
 }
@@ -0,0 +1,212 @@
+package ios
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Output of `xcodebuild -list -json` for a project, as Xcode 16 prints it.
+const projectSchemeList = `{
+  "project" : {
+    "configurations" : [
+      "Debug",
+      "Release"
+    ],
+    "name" : "Weather",
+    "schemes" : [
+      "WeatherWidget",
+      "Weather"
+    ],
+    "targets" : [
+      "Weather",
+      "WeatherTests",
+      "WeatherUITests",
+      "WeatherWidget"
+    ]
+  }
+}
+`
+
+// Output of `xcodebuild -list -json` for a CocoaPods workspace, as Xcode
+// 16 prints it. The warning goes to stdout before the JSON document.
+const workspaceSchemeList = `2024-10-02 14:21:07.513 xcodebuild[8123:301276] [MT] DVTDeviceOperation: Encountered a build number "" that is incompatible with DVTBuildVersion.
+{
+  "workspace" : {
+    "name" : "Weather",
+    "schemes" : [
+      "Alamofire",
+      "Pods-Weather",
+      "Weather",
+      "Weather",
+      "WeatherWidget"
+    ]
+  }
+}
+`
+
+func TestListSchemes(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Weather.xcodeproj", "Weather.xcworkspace"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		output   string
+		wantArgs []string
+		want     []string
+	}{
+		{
+			name:     "project",
+			path:     filepath.Join(dir, "Weather.xcodeproj"),
+			output:   projectSchemeList,
+			wantArgs: []string{"-list", "-json", "-project", filepath.Join(dir, "Weather.xcodeproj")},
+			want:     []string{"Weather", "WeatherWidget"},
+		},
+		{
+			name:     "workspace found in the directory",
+			path:     dir,
+			output:   workspaceSchemeList,
+			wantArgs: []string{"-list", "-json", "-workspace", filepath.Join(dir, "Weather.xcworkspace")},
+			want:     []string{"Alamofire", "Pods-Weather", "Weather", "WeatherWidget"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			x := &XcodeBuild{output: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+				if name != "xcodebuild" {
+					t.Errorf("ran %s, want xcodebuild", name)
+				}
+				gotArgs = args
+				return []byte(tt.output), nil
+			}}
+
+			got, err := x.ListSchemes(context.Background(), tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("schemes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListSchemesBadOutput(t *testing.T) {
+	x := &XcodeBuild{output: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte("xcodebuild: error: 'Weather.xcodeproj' does not exist.\n"), nil
+	}}
+	if _, err := x.ListSchemes(context.Background(), "Weather.xcodeproj"); err == nil {
+		t.Error("ListSchemes succeeded on output without JSON, want an error")
+	}
+}
+
+// Compiler output from a failed xcodebuild run, as Xcode 16 prints it.
+// The errors are repeated in the summary at the end.
+const compileFailureOutput = `CompileSwift normal arm64 /Users/dev/Weather/Weather/ContentView.swift (in target 'Weather' from project 'Weather')
+/Users/dev/Weather/Weather/ContentView.swift:12:9: warning: initialization of immutable value 'unused' was never used
+/Users/dev/Weather/Weather/ContentView.swift:24:17: error: cannot find 'forecast' in scope
+            Text(forecast.summary)
+                 ^~~~~~~~
+/Users/dev/Weather/Weather/Model.swift:8:1: error: expected declaration
+/Users/dev/Weather/Weather/Bridge.h:3:9: fatal error: 'Missing.h' file not found
+/Users/dev/Weather/Weather/Legacy.m:40: error: use of undeclared identifier 'x'
+
+** BUILD FAILED **
+
+The following build commands failed:
+	CompileSwift normal arm64 /Users/dev/Weather/Weather/ContentView.swift (in target 'Weather' from project 'Weather')
+/Users/dev/Weather/Weather/ContentView.swift:24:17: error: cannot find 'forecast' in scope
+(1 failure)
+`
+
+// Linker output from a failed xcodebuild run.
+const linkFailureOutput = `Ld /Users/dev/Library/Developer/Xcode/DerivedData/Weather/Build/Products/Debug-iphonesimulator/Weather.app/Weather normal (in target 'Weather' from project 'Weather')
+ld: warning: ignoring duplicate libraries: '-lc++'
+Undefined symbols for architecture arm64:
+  "_OBJC_CLASS_$_WeatherClient", referenced from:
+       in ContentView.o
+  "_fetchForecast", referenced from:
+      _main in main.o
+ld: symbol(s) not found for architecture arm64
+clang: error: linker command failed with exit code 1 (use -v to see invocation)
+
+** BUILD FAILED **
+`
+
+func TestParseBuildErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []BuildError
+	}{
+		{
+			name:   "compiler errors with locations, warnings left out",
+			output: compileFailureOutput,
+			want: []BuildError{
+				{File: "/Users/dev/Weather/Weather/ContentView.swift", Line: 24, Column: 17, Message: "cannot find 'forecast' in scope"},
+				{File: "/Users/dev/Weather/Weather/Model.swift", Line: 8, Column: 1, Message: "expected declaration"},
+				{File: "/Users/dev/Weather/Weather/Bridge.h", Line: 3, Column: 9, Message: "'Missing.h' file not found"},
+				{File: "/Users/dev/Weather/Weather/Legacy.m", Line: 40, Message: "use of undeclared identifier 'x'"},
+			},
+		},
+		{
+			name:   "linker errors",
+			output: linkFailureOutput,
+			want: []BuildError{
+				{Message: "Undefined symbol: _OBJC_CLASS_$_WeatherClient"},
+				{Message: "Undefined symbol: _fetchForecast"},
+				{Message: "linker command failed with exit code 1 (use -v to see invocation)"},
+			},
+		},
+		{
+			name:   "error without a location",
+			output: "xcodebuild: error: Unable to find a destination matching the provided destination specifier.\nerror: No such module 'Charts'\n",
+			want: []BuildError{
+				{Message: "Unable to find a destination matching the provided destination specifier."},
+				{Message: "No such module 'Charts'"},
+			},
+		},
+		{
+			name:   "warnings only",
+			output: "/Users/dev/Weather/Weather/App.swift:3:5: warning: 'foo' is deprecated\n** BUILD SUCCEEDED **\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseBuildErrors(strings.Split(tt.output, "\n"))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBuildErrors() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBuildErrorsKeepsLast(t *testing.T) {
+	var lines []string
+	for i := 1; i <= maxBuildErrors+5; i++ {
+		lines = append(lines, fmt.Sprintf("/src/App.swift:%d:1: error: problem %d", i, i))
+	}
+
+	got := parseBuildErrors(lines)
+	if len(got) != maxBuildErrors {
+		t.Fatalf("got %d errors, want %d", len(got), maxBuildErrors)
+	}
+	if got[0].Line != 6 || got[len(got)-1].Line != maxBuildErrors+5 {
+		t.Errorf("kept lines %d to %d, want 6 to %d", got[0].Line, got[len(got)-1].Line, maxBuildErrors+5)
+	}
+}
@@ -85,6 +85,14 @@ func GetAskUserTool() request.Tool {
 									"type":        "boolean",
 									"description": "Allow multiple selections (default: false)",
 								},
+								"default": map[string]interface{}{
+									"type":        "integer",
+									"description": "0-based index of the option that is highlighted and chosen when the user just presses Enter",
+								},
+								"optional": map[string]interface{}{
+									"type":        "boolean",
+									"description": "Let the user skip the question without answering (default: false)",
+								},
 							},
 							"required": []string{"question", "options"},
 						},
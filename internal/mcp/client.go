@@ -5,6 +5,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,6 +16,8 @@ type Tool struct {
 	Name        string
 	Description string
 	InputSchema mcp.ToolInputSchema
+	Timeout     time.Duration // Call timeout declared by the server (0 = none, see WithCallTimeout)
+	ReadOnly    bool          // The server marks the tool as not modifying anything (readOnlyHint)
 }
 
 // Client wraps MCP client functionality
@@ -86,7 +89,19 @@ func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
 	return tools, nil
 }
 
+// ToolError is returned by Client.CallTool when the tool ran but reported
+// a failure. Output holds the text it returned.
+type ToolError struct {
+	Name   string
+	Output string
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("tool %s reported an error", e.Name)
+}
+
 // CallTool executes a tool on the MCP server with the given arguments.
+// A result the server marks as an error is returned as a *ToolError.
 func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (string, error) {
 	if !c.connected {
 		return "", fmt.Errorf("not connected to MCP server")
@@ -108,6 +123,9 @@ func (c *Client) CallTool(ctx context.Context, name string, args map[string]inte
 			output += textContent.Text
 		}
 	}
+	if result.IsError {
+		return "", &ToolError{Name: name, Output: output}
+	}
 
 	return output, nil
 }
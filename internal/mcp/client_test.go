@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClientCallToolError(t *testing.T) {
+	t.Setenv(stdioServerEnv, "0s")
+	client, err := NewClient(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := client.CallTool(ctx, "stdio_sleep", map[string]interface{}{"ms": 1}); err != nil || got != "done" {
+		t.Errorf("stdio_sleep = %q, %v; want done", got, err)
+	}
+
+	_, err = client.CallTool(ctx, "stdio_fail", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) {
+		t.Fatalf("stdio_fail error = %v, want a *ToolError", err)
+	}
+	if toolErr.Name != "stdio_fail" || toolErr.Output != "no such file" {
+		t.Errorf("ToolError = %+v, want stdio_fail with its output", toolErr)
+	}
+}
@@ -3,34 +3,96 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-deepseek/deepseek/request"
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-// ServerConfig defines MCP server configuration.
+// ServerConfig defines MCP server configuration. A server is either a
+// local process started with Command, or a remote one reached over SSE at
+// URL.
 type ServerConfig struct {
-	Name    string
-	Command string
-	Args    []string
-	Env     []string
+	Name          string
+	Command       string
+	Args          []string
+	Env           []string
+	URL           string            // SSE endpoint of a remote server
+	Headers       map[string]string // HTTP headers sent to URL, e.g. Authorization
+	DisabledTools []string          // Tools hidden from the model and rejected by CallTool
+	CallTimeout   time.Duration     // Per-call timeout for this server's tools (0 = manager default or the tool's own)
 }
 
+// DefaultCallTimeout bounds a single tool call unless configured otherwise
+// or the tool declares a longer timeout with WithCallTimeout.
+const DefaultCallTimeout = 60 * time.Second
+
+// ErrToolTimeout is returned by CallTool when a tool does not finish within
+// its call timeout.
+var ErrToolTimeout = errors.New("tool call timed out")
+
+// maxAutoRestarts caps how often a crashed server is restarted
+// automatically, so a server that dies on every call does not loop.
+const maxAutoRestarts = 3
+
+// healthCheckTimeout bounds the ping used to tell a failed call from a
+// dead server.
+const healthCheckTimeout = 3 * time.Second
+
+// healthCheckInterval is how often a server is pinged while a tool call is
+// in flight. The stdio transport never fails a request whose server exits
+// mid-call, so the watch is what turns a crash into an error.
+const healthCheckInterval = 5 * time.Second
+
+// healthCheckMisses is how many pings in a row must go unanswered before
+// an in-flight call is abandoned, so a server busy with a long call is not
+// mistaken for a dead one.
+const healthCheckMisses = 3
+
+// restartTimeout bounds reconnecting to a restarted server.
+const restartTimeout = 60 * time.Second
+
+// sseResponseTimeout bounds waiting for a remote server to answer an HTTP
+// request. The SSE stream outlives the connect context, so it can't be
+// bounded by that.
+const sseResponseTimeout = 30 * time.Second
+
 // Manager manages multiple MCP server connections.
 type Manager struct {
-	servers map[string]*serverInstance
-	tools   map[string]*toolInfo // tool name -> server that provides it
+	mu       sync.RWMutex
+	servers  map[string]*serverInstance
+	tools    map[string]*toolInfo // tool name -> server that provides it
+	disabled map[string]bool      // tool name -> hidden from the model
+
+	callTimeout time.Duration
+	sandbox     *pathSandbox               // Directories filesystem tools may use; nil allows any
+	restarting  map[string]*pendingRestart // server name -> restart in progress
+}
+
+// pendingRestart is a restart in progress; done is closed once srv and err
+// are set.
+type pendingRestart struct {
+	done chan struct{}
+	srv  *serverInstance
+	err  error
 }
 
 type serverInstance struct {
-	name   string
-	client *client.Client
-	tools  []Tool
+	name     string
+	config   ServerConfig
+	client   *client.Client
+	tools    []Tool
+	restarts int // Automatic restarts so far
 }
 
 type toolInfo struct {
@@ -41,28 +103,96 @@ type toolInfo struct {
 // NewManager creates a new MCP manager.
 func NewManager() *Manager {
 	return &Manager{
-		servers: make(map[string]*serverInstance),
-		tools:   make(map[string]*toolInfo),
+		servers:  make(map[string]*serverInstance),
+		tools:    make(map[string]*toolInfo),
+		disabled: make(map[string]bool),
+
+		callTimeout: DefaultCallTimeout,
+		restarting:  make(map[string]*pendingRestart),
+	}
+}
+
+// SetCallTimeout sets the default per-call timeout. Zero or negative
+// restores DefaultCallTimeout.
+func (m *Manager) SetCallTimeout(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d <= 0 {
+		d = DefaultCallTimeout
 	}
+	m.callTimeout = d
+}
+
+// SetAllowedRoots confines filesystem tool calls to the given directories.
+// Calls with a path outside them fail without reaching the server. An
+// empty list allows any path.
+func (m *Manager) SetAllowedRoots(roots []string) error {
+	sandbox, err := newPathSandbox(roots)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sandbox = sandbox
+	return nil
 }
 
 // AddServer connects to an MCP server and registers its tools.
 func (m *Manager) AddServer(ctx context.Context, cfg ServerConfig) error {
-	// Verify command exists before spawning to avoid mcp-go nil reader panic
-	if _, err := exec.LookPath(cfg.Command); err != nil {
-		return fmt.Errorf("MCP server command not found for %s: %w", cfg.Name, err)
+	srv, err := connectServer(ctx, cfg)
+	if err != nil {
+		return err
 	}
 
-	// Build environment
-	env := os.Environ()
-	for _, e := range cfg.Env {
-		env = append(env, e)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.register(srv)
+	for _, name := range cfg.DisabledTools {
+		m.disabled[name] = true
 	}
 
-	// Create client
-	c, err := client.NewStdioMCPClient(cfg.Command, env, cfg.Args...)
+	return nil
+}
+
+// connectServer starts or connects to an MCP server, initializes it and
+// lists its tools.
+func connectServer(ctx context.Context, cfg ServerConfig) (*serverInstance, error) {
+	var c *client.Client
+	var err error
+	switch {
+	case cfg.Command != "" && cfg.URL != "":
+		return nil, fmt.Errorf("MCP server %s has both a command and a url; set only one", cfg.Name)
+	case cfg.URL != "":
+		c, err = newSSEClient(cfg)
+	case cfg.Command != "":
+		c, err = newStdioClient(cfg)
+	default:
+		return nil, fmt.Errorf("MCP server %s needs a command or a url", cfg.Name)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create MCP client for %s: %w", cfg.Name, err)
+		return nil, err
+	}
+	return initServer(ctx, cfg, c)
+}
+
+// initServer starts c, initializes the server and lists its tools. c is
+// closed if any step fails.
+func initServer(ctx context.Context, cfg ServerConfig, c *client.Client) (*serverInstance, error) {
+	// Start connects the SSE stream; for stdio the transport is already
+	// running and Start only hooks up notification delivery. Notifications
+	// carry tool progress.
+	c.OnNotification(progress.handle)
+	startCtx := ctx
+	if cfg.URL != "" {
+		// The stream is closed when its context ends, so it must live
+		// until Close rather than the connect timeout
+		startCtx = context.Background()
+	}
+	if err := c.Start(startCtx); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to start MCP client for %s: %w", cfg.Name, err)
 	}
 
 	// Initialize
@@ -73,102 +203,429 @@ func (m *Manager) AddServer(ctx context.Context, cfg ServerConfig) error {
 		Version: "1.0.0",
 	}
 
-	_, err = c.Initialize(ctx, initReq)
+	_, err := c.Initialize(ctx, initReq)
 	if err != nil {
 		c.Close()
-		return fmt.Errorf("failed to initialize MCP server %s: %w", cfg.Name, err)
+		return nil, fmt.Errorf("failed to initialize MCP server %s: %w", cfg.Name, err)
 	}
 
 	// Get tools
 	toolsResult, err := c.ListTools(ctx, mcp.ListToolsRequest{})
 	if err != nil {
 		c.Close()
-		return fmt.Errorf("failed to list tools from %s: %w", cfg.Name, err)
+		return nil, fmt.Errorf("failed to list tools from %s: %w", cfg.Name, err)
 	}
 
 	// Convert tools
 	tools := make([]Tool, 0, len(toolsResult.Tools))
 	for _, t := range toolsResult.Tools {
-		tool := Tool{
+		tools = append(tools, Tool{
 			Name:        t.Name,
 			Description: t.Description,
 			InputSchema: t.InputSchema,
-		}
-		tools = append(tools, tool)
-
-		// Register tool -> server mapping
-		m.tools[t.Name] = &toolInfo{
-			serverName: cfg.Name,
-			tool:       tool,
-		}
+			Timeout:     declaredTimeout(t),
+			ReadOnly:    t.Annotations.ReadOnlyHint != nil && *t.Annotations.ReadOnlyHint,
+		})
 	}
 
-	m.servers[cfg.Name] = &serverInstance{
+	return &serverInstance{
 		name:   cfg.Name,
+		config: cfg,
 		client: c,
 		tools:  tools,
+	}, nil
+}
+
+// newStdioClient starts the server process of cfg.
+func newStdioClient(cfg ServerConfig) (*client.Client, error) {
+	// Verify command exists before spawning to avoid mcp-go nil reader panic
+	if _, err := exec.LookPath(cfg.Command); err != nil {
+		return nil, fmt.Errorf("MCP server command not found for %s: %w", cfg.Name, err)
 	}
 
-	return nil
+	// Build environment
+	env := os.Environ()
+	for _, e := range cfg.Env {
+		env = append(env, e)
+	}
+
+	c, err := client.NewStdioMCPClient(cfg.Command, env, cfg.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP client for %s: %w", cfg.Name, err)
+	}
+	return c, nil
+}
+
+// newSSEClient creates a client for the remote server at cfg.URL. It
+// connects when started.
+func newSSEClient(cfg ServerConfig) (*client.Client, error) {
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	httpTransport.ResponseHeaderTimeout = sseResponseTimeout
+
+	c, err := client.NewSSEMCPClient(cfg.URL,
+		transport.WithHeaders(cfg.Headers),
+		transport.WithHTTPClient(&http.Client{Transport: httpTransport}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP client for %s: %w", cfg.Name, err)
+	}
+	return c, nil
 }
 
-// GetAllTools returns all tools from all connected servers.
+// register adds srv and its tool mappings, replacing a previous instance
+// of the same server. Must be called with m.mu held.
+func (m *Manager) register(srv *serverInstance) {
+	for name, info := range m.tools {
+		if info.serverName == srv.name {
+			delete(m.tools, name)
+		}
+	}
+
+	for _, tool := range srv.tools {
+		m.tools[tool.Name] = &toolInfo{
+			serverName: srv.name,
+			tool:       tool,
+		}
+	}
+
+	m.servers[srv.name] = srv
+}
+
+// GetAllTools returns all enabled tools from all connected servers.
 func (m *Manager) GetAllTools() []Tool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	var all []Tool
 	for _, srv := range m.servers {
-		all = append(all, srv.tools...)
+		for _, tool := range srv.tools {
+			if !m.disabled[tool.Name] {
+				all = append(all, tool)
+			}
+		}
 	}
 	return all
 }
 
+// ToolServer returns the server that provides a tool and whether the tool
+// is read-only. ok is false for an unknown tool.
+func (m *Manager) ToolServer(name string) (server string, readOnly, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	info, ok := m.tools[name]
+	if !ok {
+		return "", false, false
+	}
+	return info.serverName, info.tool.ReadOnly, true
+}
+
+// DisableTool hides a tool from the model and rejects calls to it.
+func (m *Manager) DisableTool(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tools[name]; !ok {
+		return fmt.Errorf("unknown tool: %s", name)
+	}
+	m.disabled[name] = true
+	return nil
+}
+
+// EnableTool makes a disabled tool available again.
+func (m *Manager) EnableTool(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.disabled[name] {
+		if _, ok := m.tools[name]; !ok {
+			return fmt.Errorf("unknown tool: %s", name)
+		}
+		return fmt.Errorf("tool %s is not disabled", name)
+	}
+	delete(m.disabled, name)
+	return nil
+}
+
+// DisabledTools returns the names of disabled tools, sorted.
+func (m *Manager) DisabledTools() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.disabled))
+	for name := range m.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetDeepSeekTools returns all tools in DeepSeek format.
 func (m *Manager) GetDeepSeekTools() []request.Tool {
 	return ToDeepSeekTools(m.GetAllTools())
 }
 
-// CallTool calls a tool by name with given arguments.
+// CallTool calls a tool by name with given arguments. Filesystem tool
+// calls with a path outside the allowed roots are rejected. A call that
+// outlives its call timeout fails with ErrToolTimeout: the server's
+// configured call_timeout if it has one, otherwise the longer of the
+// manager's default and the timeout the tool declares. If the call fails
+// because the server process died, the server is restarted with its
+// original configuration and the call is retried once.
 func (m *Manager) CallTool(ctx context.Context, name string, argsJSON string) (string, error) {
+	output, err := m.CallToolOutput(ctx, name, argsJSON)
+	return output.Text, err
+}
+
+// CallToolOutput is CallTool returning the images in the result as well
+// as its text.
+func (m *Manager) CallToolOutput(ctx context.Context, name string, argsJSON string) (ToolOutput, error) {
+	m.mu.RLock()
 	info, ok := m.tools[name]
-	if !ok {
-		return "", fmt.Errorf("unknown tool: %s", name)
+	disabled := m.disabled[name]
+	defaultTimeout := m.callTimeout
+	sandbox := m.sandbox
+	var srv *serverInstance
+	if ok {
+		srv = m.servers[info.serverName]
 	}
+	m.mu.RUnlock()
 
-	srv, ok := m.servers[info.serverName]
 	if !ok {
-		return "", fmt.Errorf("server not found for tool %s", name)
+		return ToolOutput{}, fmt.Errorf("unknown tool: %s", name)
+	}
+	if disabled {
+		return ToolOutput{}, fmt.Errorf("tool %s is disabled", name)
+	}
+	if srv == nil {
+		return ToolOutput{}, fmt.Errorf("server not found for tool %s", name)
+	}
+	if err := sandbox.check(name, argsJSON, srv.config.URL != ""); err != nil {
+		return ToolOutput{}, err
 	}
 
 	// Parse arguments
 	var args map[string]interface{}
 	if argsJSON != "" && argsJSON != "{}" {
 		if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
-			return "", fmt.Errorf("failed to parse tool arguments: %w", err)
+			return ToolOutput{}, fmt.Errorf("failed to parse tool arguments: %w", err)
 		}
 	}
 
-	// Call tool
+	timeout := max(defaultTimeout, info.tool.Timeout)
+	if srv.config.CallTimeout > 0 {
+		timeout = srv.config.CallTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output, err := watchCall(callCtx, srv.client, name, args)
+	if err != nil && callCtx.Err() == nil && !isAlive(srv.client) {
+		// The server is gone; restart it and retry once
+		restarted, restartErr := m.autoRestart(srv)
+		if restartErr != nil {
+			return ToolOutput{}, fmt.Errorf("%w (server %s is not responding: %v)", err, srv.name, restartErr)
+		}
+		output, err = watchCall(callCtx, restarted.client, name, args)
+	}
+
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		return ToolOutput{}, fmt.Errorf("%w: %s did not finish within %v", ErrToolTimeout, name, timeout)
+	}
+	return output, err
+}
+
+// watchCall runs callTool while pinging the server in the background, and
+// cancels the call once the server stops answering.
+func watchCall(ctx context.Context, c *client.Client, name string, args map[string]interface{}) (ToolOutput, error) {
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go watchServer(callCtx, healthCheckInterval, func() bool { return isAlive(c) }, cancel)
+
+	return callTool(callCtx, c, name, args)
+}
+
+// watchServer calls alive every interval until ctx is done, and calls
+// cancel once healthCheckMisses checks in a row have failed.
+func watchServer(ctx context.Context, interval time.Duration, alive func() bool, cancel func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	misses := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if alive() {
+				misses = 0
+				continue
+			}
+			if misses++; misses >= healthCheckMisses {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// callTool calls a tool, joins its text content and collects its images.
+// If ctx carries a ProgressFunc, the server is asked to report progress to
+// it.
+func callTool(ctx context.Context, c *client.Client, name string, args map[string]interface{}) (ToolOutput, error) {
 	req := mcp.CallToolRequest{}
 	req.Params.Name = name
 	req.Params.Arguments = args
+	if fn := progressFromContext(ctx); fn != nil {
+		token, release := progress.register(fn)
+		defer release()
+		req.Params.Meta = &mcp.Meta{ProgressToken: token}
+	}
 
-	result, err := srv.client.CallTool(ctx, req)
+	result, err := c.CallTool(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("tool call failed: %w", err)
+		return ToolOutput{}, fmt.Errorf("tool call failed: %w", err)
 	}
 
 	// Extract result
+	var output ToolOutput
 	var parts []string
 	for _, content := range result.Content {
-		if tc, ok := content.(mcp.TextContent); ok {
-			parts = append(parts, tc.Text)
+		switch content := content.(type) {
+		case mcp.TextContent:
+			parts = append(parts, content.Text)
+		case mcp.ImageContent:
+			output.Images = append(output.Images, ToolImage{Data: content.Data, MIMEType: content.MIMEType})
+		}
+	}
+	output.Text = strings.Join(parts, "\n")
+
+	return output, nil
+}
+
+// isAlive reports whether the server answers a ping.
+func isAlive(c *client.Client) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return c.Ping(ctx) == nil
+}
+
+// autoRestart restarts a dead server unless it has used up its automatic
+// restarts.
+func (m *Manager) autoRestart(dead *serverInstance) (*serverInstance, error) {
+	m.mu.RLock()
+	current, ok := m.servers[dead.name]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("server %s was removed", dead.name)
+	}
+	if current != dead {
+		return current, nil // Already restarted by another caller
+	}
+	if dead.restarts >= maxAutoRestarts {
+		return nil, fmt.Errorf("not restarting after %d automatic restarts; use /mcp restart %s", maxAutoRestarts, dead.name)
+	}
+
+	fmt.Fprintf(os.Stderr, "MCP server %s stopped responding, restarting (%d/%d)...\n", dead.name, dead.restarts+1, maxAutoRestarts)
+
+	srv, err := m.restart(dead, dead.restarts+1)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "MCP server %s restart failed: %v\n", dead.name, err)
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "MCP server %s restarted: %d tools available\n", srv.name, len(srv.tools))
+	return srv, nil
+}
+
+// RestartServer stops a server and starts it again with its original
+// configuration. A manual restart resets the automatic restart limit.
+func (m *Manager) RestartServer(name string) error {
+	m.mu.RLock()
+	old, ok := m.servers[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("unknown MCP server: %s", name)
+	}
+
+	srv, err := m.restart(old, 0)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "MCP server %s restarted: %d tools available\n", srv.name, len(srv.tools))
+	return nil
+}
+
+// restart closes old and connects a new instance with the same
+// configuration and the given restart count. The connection is made
+// without holding the lock, like Reload, so calls to other servers go on
+// meanwhile. Concurrent restarts of the same instance share one
+// connection, and an instance that was already replaced is not restarted
+// again.
+func (m *Manager) restart(old *serverInstance, restarts int) (*serverInstance, error) {
+	m.mu.Lock()
+	current, ok := m.servers[old.name]
+	pending := m.restarting[old.name]
+	var p *pendingRestart
+	if ok && current == old && pending == nil {
+		p = &pendingRestart{done: make(chan struct{})}
+		m.restarting[old.name] = p
+	}
+	m.mu.Unlock()
+
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("server %s was removed", old.name)
+	case current != old:
+		return current, nil
+	case pending != nil:
+		<-pending.done
+		return pending.srv, pending.err
+	}
+
+	defer close(p.done)
+
+	_ = old.client.Close() // The process may already be gone
+
+	ctx, cancel := context.WithTimeout(context.Background(), restartTimeout)
+	defer cancel()
+	srv, err := connectServer(ctx, old.config)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.restarting, old.name)
+
+	if err != nil {
+		p.err = err
+		return nil, err
+	}
+
+	// Reload may have replaced or removed the server meanwhile
+	if current, ok = m.servers[old.name]; !ok || current != old {
+		_ = srv.client.Close()
+		if !ok {
+			p.err = fmt.Errorf("server %s was removed", old.name)
+			return nil, p.err
 		}
+		p.srv = current
+		return current, nil
 	}
 
-	return strings.Join(parts, "\n"), nil
+	srv.restarts = restarts
+	m.register(srv)
+	p.srv = srv
+	return srv, nil
 }
 
 // Close closes all server connections.
 func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	var errs []string
 	for name, srv := range m.servers {
 		if err := srv.client.Close(); err != nil {
@@ -183,6 +640,9 @@ func (m *Manager) Close() error {
 
 // ListServers returns names of all connected servers.
 func (m *Manager) ListServers() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	names := make([]string, 0, len(m.servers))
 	for name := range m.servers {
 		names = append(names, name)
@@ -192,6 +652,9 @@ func (m *Manager) ListServers() []string {
 
 // ServerToolCount returns number of tools per server.
 func (m *Manager) ServerToolCount() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	counts := make(map[string]int)
 	for name, srv := range m.servers {
 		counts[name] = len(srv.tools)
@@ -201,6 +664,9 @@ func (m *Manager) ServerToolCount() map[string]int {
 
 // HasFilesystemTools checks if filesystem tools (read_text_file, directory_tree, etc.) are available.
 func (m *Manager) HasFilesystemTools() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	filesystemTools := []string{"read_text_file", "read_file", "directory_tree", "list_directory", "search_files"}
 	for _, toolName := range filesystemTools {
 		if _, ok := m.tools[toolName]; ok {
@@ -212,6 +678,9 @@ func (m *Manager) HasFilesystemTools() bool {
 
 // HasCodeIndexTools checks if code index tools (semantic_search, index_directory, etc.) are available.
 func (m *Manager) HasCodeIndexTools() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	codeIndexTools := []string{"semantic_search", "index_directory", "index_stats"}
 	for _, toolName := range codeIndexTools {
 		if _, ok := m.tools[toolName]; ok {
@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioServerEnv makes the test binary run as a stdio MCP server that
+// starts after the delay it holds.
+const stdioServerEnv = "MCP_TEST_STDIO_SERVER_DELAY"
+
+func TestMain(m *testing.M) {
+	if delay, ok := os.LookupEnv(stdioServerEnv); ok {
+		d, _ := time.ParseDuration(delay)
+		time.Sleep(d)
+		s := server.NewMCPServer("stdio-test", "1.0.0")
+		s.AddTool(mcp.NewTool("stdio_sleep", mcp.WithNumber("ms")), sleepHandler)
+		s.AddTool(mcp.NewTool("stdio_fail"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("no such file"), nil
+		})
+		if err := server.ServeStdio(s); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// sleepHandler sleeps for the "ms" argument, or until the call is
+// cancelled.
+func sleepHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-time.After(time.Duration(req.GetFloat("ms", 0)) * time.Millisecond):
+		return mcp.NewToolResultText("done"), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newTestManager returns a manager connected to an in-process server with
+// a "sleep" tool, a "slow_sleep" tool that declares a 5s timeout and an
+// "image" tool that returns a picture.
+func newTestManager(t *testing.T, cfg ServerConfig) *Manager {
+	t.Helper()
+
+	s := server.NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("sleep", mcp.WithNumber("ms")), sleepHandler)
+	s.AddTool(mcp.NewTool("slow_sleep", mcp.WithNumber("ms"), WithCallTimeout(5*time.Second)), sleepHandler)
+	s.AddTool(mcp.NewTool("image"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultImage("a red pixel", "iVBORw0KGgo=", "image/png"), nil
+	})
+
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := initServer(context.Background(), cfg, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager()
+	m.register(srv)
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestCallToolTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		tool          string
+		sleep         string
+		serverTimeout time.Duration
+		wantTimeout   bool
+	}{
+		{"finishes in time", "sleep", `{"ms": 10}`, 0, false},
+		{"sleeps past the deadline", "sleep", `{"ms": 5000}`, 0, true},
+		{"declares a longer timeout", "slow_sleep", `{"ms": 300}`, 0, false},
+		{"server timeout overrides declared", "slow_sleep", `{"ms": 5000}`, 100 * time.Millisecond, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, ServerConfig{Name: "test", CallTimeout: tt.serverTimeout})
+			m.SetCallTimeout(100 * time.Millisecond)
+
+			start := time.Now()
+			out, err := m.CallTool(context.Background(), tt.tool, tt.sleep)
+			elapsed := time.Since(start)
+
+			if tt.wantTimeout {
+				if !errors.Is(err, ErrToolTimeout) {
+					t.Fatalf("err = %v, want ErrToolTimeout", err)
+				}
+				if elapsed > 2*time.Second {
+					t.Errorf("timed out after %v, want about 100ms", elapsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != "done" {
+				t.Errorf("output = %q, want %q", out, "done")
+			}
+		})
+	}
+}
+
+func TestCallToolCancelledIsNotTimeout(t *testing.T) {
+	m := newTestManager(t, ServerConfig{Name: "test"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := m.CallTool(ctx, "sleep", `{"ms": 5000}`)
+	if err == nil || errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("err = %v, want a cancellation error", err)
+	}
+}
+
+func TestWatchServerNeedsConsecutiveMisses(t *testing.T) {
+	tests := []struct {
+		name       string
+		pings      []bool
+		wantCancel bool
+	}{
+		{"always answers", []bool{true, true, true, true, true}, false},
+		{"misses are interrupted", []bool{false, false, true, false, false, true, false}, false},
+		{"misses in a row", []bool{true, false, false, false}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stop := context.WithCancel(context.Background())
+			defer stop()
+
+			// Answer the scripted pings, then end the watch
+			pings := tt.pings
+			alive := func() bool {
+				if len(pings) == 0 {
+					stop()
+					return true
+				}
+				ok := pings[0]
+				pings = pings[1:]
+				return ok
+			}
+			cancelled := false
+
+			watchServer(ctx, time.Millisecond, alive, func() { cancelled = true })
+
+			if cancelled != tt.wantCancel {
+				t.Errorf("cancelled = %v, want %v", cancelled, tt.wantCancel)
+			}
+		})
+	}
+}
+
+func TestRestartDoesNotBlockOtherCalls(t *testing.T) {
+	m := newTestManager(t, ServerConfig{Name: "test"})
+
+	const startDelay = 500 * time.Millisecond
+	err := m.AddServer(context.Background(), ServerConfig{
+		Name:    "slow",
+		Command: os.Args[0],
+		Env:     []string{stdioServerEnv + "=" + startDelay.String()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := make(chan error, 1)
+	go func() { restarted <- m.RestartServer("slow") }()
+	time.Sleep(50 * time.Millisecond) // Let the restart begin
+
+	start := time.Now()
+	if _, err := m.CallTool(context.Background(), "sleep", `{"ms": 1}`); err != nil {
+		t.Fatal(err)
+	}
+	_ = m.GetAllTools()
+	if elapsed := time.Since(start); elapsed > startDelay/2 {
+		t.Errorf("calls took %v during a restart, want them not to wait for it", elapsed)
+	}
+
+	if err := <-restarted; err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+}
+
+func TestCallToolOutputReturnsImages(t *testing.T) {
+	m := newTestManager(t, ServerConfig{Name: "test"})
+
+	out, err := m.CallToolOutput(context.Background(), "image", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Text != "a red pixel" {
+		t.Errorf("text = %q, want %q", out.Text, "a red pixel")
+	}
+	want := []ToolImage{{Data: "iVBORw0KGgo=", MIMEType: "image/png"}}
+	if !reflect.DeepEqual(out.Images, want) {
+		t.Errorf("images = %+v, want %+v", out.Images, want)
+	}
+}
+
+func TestReloadRebuildsDisabledTools(t *testing.T) {
+	m := NewManager()
+	t.Cleanup(func() { m.Close() })
+
+	cfg := ServerConfig{
+		Name:          "stdio",
+		Command:       os.Args[0],
+		Env:           []string{stdioServerEnv + "=0s"},
+		DisabledTools: []string{"stdio_sleep"},
+	}
+	if err := m.AddServer(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.DisabledTools(); !reflect.DeepEqual(got, []string{"stdio_sleep"}) {
+		t.Fatalf("disabled = %v before reload, want [stdio_sleep]", got)
+	}
+
+	// The tool is no longer listed as disabled
+	cfg.DisabledTools = nil
+	result := m.Reload(context.Background(), []ServerConfig{cfg})
+	if len(result.Failed) > 0 {
+		t.Fatalf("reload failed: %v", result.Failed)
+	}
+	if got := m.DisabledTools(); len(got) != 0 {
+		t.Errorf("disabled = %v after enabling in the config, want none", got)
+	}
+
+	// A removed server leaves nothing disabled behind
+	cfg.DisabledTools = []string{"stdio_sleep"}
+	m.Reload(context.Background(), []ServerConfig{cfg})
+	m.Reload(context.Background(), nil)
+	if got := m.DisabledTools(); len(got) != 0 {
+		t.Errorf("disabled = %v after removing the server, want none", got)
+	}
+}
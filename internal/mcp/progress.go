@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ProgressFunc receives the progress messages a server reports while a
+// tool call runs.
+type ProgressFunc func(message string)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose tool calls ask the server for
+// progress notifications and pass their messages to fn. Servers that do
+// not report progress simply send none.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFromContext returns the ProgressFunc set with WithProgress, or nil.
+func progressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressRouter hands out progress tokens and passes each progress
+// notification to the call that requested it. Tokens are unique across
+// servers, so all clients share one router.
+type progressRouter struct {
+	mu       sync.Mutex
+	next     int
+	handlers map[string]ProgressFunc
+}
+
+var progress = &progressRouter{handlers: make(map[string]ProgressFunc)}
+
+// register returns a new token for fn and a function that releases it once
+// the call is done.
+func (p *progressRouter) register(fn ProgressFunc) (string, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	token := fmt.Sprintf("cli-chat-%d", p.next)
+	p.handlers[token] = fn
+
+	return token, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.handlers, token)
+	}
+}
+
+// handle is registered with every client as its notification handler.
+func (p *progressRouter) handle(n mcp.JSONRPCNotification) {
+	if n.Method != "notifications/progress" {
+		return
+	}
+	fields := n.Params.AdditionalFields
+	token, _ := fields["progressToken"].(string)
+	message, _ := fields["message"].(string)
+	if token == "" || message == "" {
+		return
+	}
+
+	p.mu.Lock()
+	fn := p.handlers[token]
+	p.mu.Unlock()
+
+	if fn != nil {
+		fn(message)
+	}
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"sort"
+)
+
+// ReloadResult reports what Reload changed.
+type ReloadResult struct {
+	Added     []string
+	Removed   []string
+	Restarted []string         // Servers whose configuration changed
+	Failed    map[string]error // Servers that could not be connected
+}
+
+// Reload makes the connected servers match configs: servers that are no
+// longer listed are stopped, new ones are connected and those whose
+// configuration changed are restarted. A server that fails to connect is
+// reported in Failed; if it was already running, it keeps running with its
+// old configuration. The disabled tools are reset to those listed in the
+// configurations of the servers left running, so tools disabled with
+// DisableTool are enabled again.
+func (m *Manager) Reload(ctx context.Context, configs []ServerConfig) ReloadResult {
+	result := ReloadResult{Failed: make(map[string]error)}
+
+	m.mu.RLock()
+	wanted := make(map[string]bool, len(configs))
+	var connect []ServerConfig
+	for _, cfg := range configs {
+		wanted[cfg.Name] = true
+		if old, ok := m.servers[cfg.Name]; !ok || !reflect.DeepEqual(old.config, cfg) {
+			connect = append(connect, cfg)
+		}
+	}
+	m.mu.RUnlock()
+
+	// Connect without holding the lock, so tool calls to other servers
+	// are not blocked while servers start
+	var started []*serverInstance
+	for _, cfg := range connect {
+		srv, err := connectServer(ctx, cfg)
+		if err != nil {
+			result.Failed[cfg.Name] = err
+			continue
+		}
+		started = append(started, srv)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, srv := range started {
+		if old, ok := m.servers[srv.name]; ok {
+			_ = old.client.Close()
+			result.Restarted = append(result.Restarted, srv.name)
+		} else {
+			result.Added = append(result.Added, srv.name)
+		}
+		m.register(srv)
+	}
+
+	for name, srv := range m.servers {
+		if wanted[name] {
+			continue
+		}
+		_ = srv.client.Close()
+		for tool, info := range m.tools {
+			if info.serverName == name {
+				delete(m.tools, tool)
+			}
+		}
+		delete(m.servers, name)
+		result.Removed = append(result.Removed, name)
+	}
+
+	m.disabled = make(map[string]bool)
+	for _, srv := range m.servers {
+		for _, name := range srv.config.DisabledTools {
+			m.disabled[name] = true
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Restarted)
+	return result
+}
@@ -0,0 +1,27 @@
+package mcp
+
+// MaxToolResultSize caps a tool result added to the conversation.
+// 32K chars ≈ 8K tokens, a reasonable limit for a single result.
+const MaxToolResultSize = 32000
+
+// TruncateResult cuts result to MaxToolResultSize, noting the cut so the
+// model knows the result is incomplete.
+func TruncateResult(result string) string {
+	if len(result) <= MaxToolResultSize {
+		return result
+	}
+	return result[:MaxToolResultSize] + "\n\n[... truncated - result too large]"
+}
+
+// ToolImage is an image in a tool result.
+type ToolImage struct {
+	Data     string // Base64-encoded
+	MIMEType string // e.g. "image/png"
+}
+
+// ToolOutput is the content of a tool result: its text parts joined by
+// newlines, and its images.
+type ToolOutput struct {
+	Text   string
+	Images []ToolImage
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/config"
+)
+
+// filesystemTools are the tools of the filesystem MCP server that take
+// paths, which the sandbox checks.
+var filesystemTools = map[string]bool{
+	"read_file":                 true,
+	"read_text_file":            true,
+	"read_media_file":           true,
+	"read_multiple_files":       true,
+	"write_file":                true,
+	"edit_file":                 true,
+	"create_directory":          true,
+	"list_directory":            true,
+	"list_directory_with_sizes": true,
+	"directory_tree":            true,
+	"move_file":                 true,
+	"search_files":              true,
+	"get_file_info":             true,
+}
+
+// pathSandbox keeps filesystem tool calls inside the allowed roots, so the
+// model can't read or write files elsewhere on the machine.
+type pathSandbox struct {
+	roots    []string // Absolute with symlinks resolved; empty allows any path
+	absRoots []string // The same roots, absolute but not resolved
+}
+
+// newPathSandbox resolves the configured roots. Relative roots, such as
+// the default ".", are taken from the working directory.
+func newPathSandbox(roots []string) (*pathSandbox, error) {
+	s := &pathSandbox{}
+	for _, root := range roots {
+		expanded := config.ExpandPath(root)
+		abs, err := filepath.Abs(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed root %s: %w", root, err)
+		}
+		resolved, err := resolvePath(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed root %s: %w", root, err)
+		}
+		s.roots = append(s.roots, resolved)
+		s.absRoots = append(s.absRoots, abs)
+	}
+	return s, nil
+}
+
+// check returns an error, sent to the model as the tool result, if a
+// filesystem tool call uses a path outside the roots. Other tools are not
+// checked. Paths for a local server are resolved through symlinks; paths
+// for a remote one can only be compared as written, since local symlinks
+// say nothing about the remote machine.
+func (s *pathSandbox) check(tool, argsJSON string, remote bool) error {
+	if s == nil || len(s.roots) == 0 || !filesystemTools[tool] {
+		return nil
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+		return fmt.Errorf("access denied: the arguments of %s could not be read to check its paths", tool)
+	}
+
+	var paths []string
+	for _, key := range []string{"path", "source", "destination"} {
+		if p, ok := args[key].(string); ok {
+			paths = append(paths, p)
+		}
+	}
+	if list, ok := args["paths"].([]any); ok {
+		for _, item := range list {
+			if p, ok := item.(string); ok {
+				paths = append(paths, p)
+			}
+		}
+	}
+
+	roots, resolve := s.roots, resolvePath
+	if remote {
+		roots, resolve = s.absRoots, filepath.Abs
+	}
+	for _, p := range paths {
+		resolved, err := resolve(config.ExpandPath(p))
+		if err != nil || !within(roots, resolved) {
+			return fmt.Errorf("access denied: %s is outside the allowed directories (%s); only use paths inside them", p, strings.Join(roots, ", "))
+		}
+	}
+	return nil
+}
+
+// within reports whether path is one of roots or inside one.
+func within(roots []string, path string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath makes path absolute and resolves symlinks, so a link inside
+// a root can't point outside it. Path elements that don't exist yet, as
+// for a file about to be written, are kept as they are.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	existing, rest := abs, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return abs, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestCallToolSandbox(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := server.NewMCPServer("fs", "1.0.0")
+	s.AddTool(mcp.NewTool("read_text_file", mcp.WithString("path")), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("contents"), nil
+	})
+
+	tests := []struct {
+		name   string
+		remote bool
+		path   string
+		allow  bool
+	}{
+		{"inside", false, filepath.Join(root, "a.txt"), true},
+		{"outside", false, filepath.Join(outside, "a.txt"), false},
+		{"dot-dot", false, filepath.Join(root, "..", "a.txt"), false},
+		{"symlink out", false, filepath.Join(root, "link", "a.txt"), false},
+		{"remote symlink compared as written", true, filepath.Join(root, "link", "a.txt"), true},
+		{"remote outside", true, "/etc/passwd", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := client.NewInProcessClient(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := ServerConfig{Name: "fs"}
+			if tt.remote {
+				cfg.URL = "https://example.com/sse"
+			}
+			srv, err := initServer(context.Background(), cfg, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := NewManager()
+			m.register(srv)
+			defer m.Close()
+			if err := m.SetAllowedRoots([]string{root}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = m.CallTool(context.Background(), "read_text_file", `{"path": "`+tt.path+`"}`)
+			if tt.allow && err != nil {
+				t.Errorf("path %s rejected: %v", tt.path, err)
+			}
+			if !tt.allow && (err == nil || !strings.Contains(err.Error(), "access denied")) {
+				t.Errorf("path %s: err = %v, want access denied", tt.path, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TimeoutMetaKey is the _meta key of a tool definition in which a server
+// declares, in seconds, how long a call to the tool may run. Tools that
+// block by design, such as waiting for a reply or indexing a directory,
+// declare a limit longer than the default call timeout.
+const TimeoutMetaKey = "cli-chat/timeout"
+
+// WithCallTimeout is an mcp.NewTool option declaring that calls to the
+// tool may run for up to d.
+func WithCallTimeout(d time.Duration) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		if t.Meta == nil {
+			t.Meta = &mcp.Meta{}
+		}
+		if t.Meta.AdditionalFields == nil {
+			t.Meta.AdditionalFields = make(map[string]any)
+		}
+		t.Meta.AdditionalFields[TimeoutMetaKey] = d.Seconds()
+	}
+}
+
+// declaredTimeout returns the call timeout a tool declares in its _meta,
+// or 0 if it declares none.
+func declaredTimeout(t mcp.Tool) time.Duration {
+	if t.Meta == nil {
+		return 0
+	}
+	seconds, ok := t.Meta.AdditionalFields[TimeoutMetaKey].(float64)
+	if !ok || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
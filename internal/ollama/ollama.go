@@ -0,0 +1,141 @@
+// Package ollama holds the Ollama API calls shared by the chat provider and
+// the code index: checking for a local model, pulling one, and turning
+// connection failures into setup errors.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Setup errors. They carry the command that fixes them.
+var (
+	ErrNotRunning     = errors.New("ollama is not running")
+	ErrModelNotPulled = errors.New("ollama model is not pulled")
+)
+
+// IsSetupError reports whether err means Ollama is not running or the model
+// is missing, as opposed to a failure of a single request.
+func IsSetupError(err error) bool {
+	return errors.Is(err, ErrNotRunning) || errors.Is(err, ErrModelNotPulled)
+}
+
+// RequestError turns a failed request to the Ollama server at baseURL into
+// an actionable error when Ollama is unreachable or slower than timeout.
+func RequestError(ctx context.Context, baseURL string, timeout time.Duration, err error) error {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("send request: %w", err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("%w at %s: start it with `ollama serve`", ErrNotRunning, baseURL)
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("cannot resolve the ollama host in %s (check OLLAMA_URL): %w", baseURL, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("ollama did not respond within %s (a model may still be loading; raise OLLAMA_TIMEOUT): %w",
+			timeout, err)
+	}
+	return fmt.Errorf("send request: %w", err)
+}
+
+// PullProgress is a single status update streamed by the Ollama pull API.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HasModel reports whether model is available on the Ollama server at
+// baseURL.
+func HasModel(ctx context.Context, client *http.Client, baseURL, model string) (bool, error) {
+	body, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		return false, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/show", bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return false, RequestError(ctx, baseURL, client.Timeout, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+}
+
+// PullModel downloads model to the Ollama server at baseURL, streaming
+// progress updates to progress if non-nil. It blocks until the pull
+// completes; the timeout of client does not apply, only ctx.
+func PullModel(ctx context.Context, client *http.Client, baseURL, model string, progress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":  model,
+		"stream": true,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Downloads can take far longer than the request timeout; rely on ctx.
+	resp, err := (&http.Client{Transport: client.Transport}).Do(httpReq)
+	if err != nil {
+		return RequestError(ctx, baseURL, client.Timeout, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama pull %s failed (status %d): %s", model, resp.StatusCode, string(bodyBytes))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var update PullProgress
+		if err := decoder.Decode(&update); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("decode pull progress: %w", err)
+		}
+
+		if update.Error != "" {
+			return fmt.Errorf("ollama pull %s failed: %s", model, update.Error)
+		}
+		if progress != nil {
+			progress(update)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("ollama pull %s ended without success", model)
+}
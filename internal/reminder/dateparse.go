@@ -0,0 +1,241 @@
+package reminder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHour is used when a relative day such as "tomorrow" has no time.
+const defaultHour = 9
+
+// absoluteLayouts are tried, in order, before natural-language parsing.
+// Layouts without a zone are interpreted in the configured location.
+var absoluteLayouts = []string{
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var (
+	inPattern      = regexp.MustCompile(`^in\s+(\d+)\s*([a-z]+)$`)
+	timePattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	dayWeekPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)([dw])`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// ParseDueDate parses an absolute or relative due date relative to now in
+// loc. Accepted forms include RFC3339 ("2025-01-15T09:00:00Z"), local dates
+// ("2025-01-15 09:00", "2025-01-15"), offsets ("in 2 hours", "in 3 days"),
+// days with an optional time ("tomorrow 9am", "today at 17:30",
+// "next monday", "friday 3pm", "tonight") and bare times ("18:00").
+// The result is in loc; callers convert to UTC for storage.
+func ParseDueDate(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+
+	s := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty due date")
+	}
+
+	raw := strings.TrimSpace(input)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			if layout == "2006-01-02" {
+				t = t.Add(defaultHour * time.Hour)
+			}
+			return t, nil
+		}
+	}
+
+	if s == "now" {
+		return now, nil
+	}
+
+	if m := inPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return addOffset(now, n, m[2])
+	}
+
+	day, rest, ok := parseDay(s, now)
+	if !ok {
+		// A bare time means the next time the clock shows it.
+		hour, minute, err := parseClock(s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unrecognized due date %q (try RFC3339, \"tomorrow 9am\", \"in 2 hours\" or \"next monday\")", input)
+		}
+		t := atClock(now, hour, minute)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "at"))
+	if rest == "" {
+		hour := defaultHour
+		if strings.HasPrefix(s, "tonight") {
+			hour = 20
+		}
+		return atClock(day, hour, 0), nil
+	}
+
+	hour, minute, err := parseClock(rest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognized time %q in due date %q", rest, input)
+	}
+	return atClock(day, hour, minute), nil
+}
+
+// parseDay recognizes a leading day expression and returns that day (at
+// now's clock time) and the remaining input.
+func parseDay(s string, now time.Time) (time.Time, string, bool) {
+	word, rest, _ := strings.Cut(s, " ")
+
+	switch word {
+	case "today", "tonight":
+		return now, rest, true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), rest, true
+	case "next":
+		// "next monday" is the first monday after today; "next week" is +7 days.
+		word, rest, _ = strings.Cut(rest, " ")
+		if word == "week" {
+			return now.AddDate(0, 0, 7), rest, true
+		}
+	}
+
+	wd, ok := weekdays[word]
+	if !ok {
+		return time.Time{}, "", false
+	}
+
+	days := (int(wd) - int(now.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return now.AddDate(0, 0, days), rest, true
+}
+
+// parseClock parses "9am", "9:30pm", "21:00", "noon" and "midnight".
+func parseClock(s string) (int, int, error) {
+	switch s {
+	case "noon":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+
+	m := timePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time %q", s)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time %q", s)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	default:
+		// A bare number without minutes is ambiguous with other input
+		if m[2] == "" {
+			return 0, 0, fmt.Errorf("invalid time %q (use 9am or 09:00)", s)
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour, minute, nil
+}
+
+// addOffset adds n units to t for "in N <unit>" expressions.
+func addOffset(t time.Time, n int, unit string) (time.Time, error) {
+	switch unit {
+	case "m", "min", "mins", "minute", "minutes":
+		return t.Add(time.Duration(n) * time.Minute), nil
+	case "h", "hr", "hrs", "hour", "hours":
+		return t.Add(time.Duration(n) * time.Hour), nil
+	case "d", "day", "days":
+		return t.AddDate(0, 0, n), nil
+	case "w", "week", "weeks":
+		return t.AddDate(0, 0, 7*n), nil
+	case "month", "months":
+		return t.AddDate(0, n, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown time unit %q", unit)
+	}
+}
+
+// atClock returns day's date at the given wall-clock time.
+func atClock(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
+// ParseDuration parses a Go duration ("30m", "1h30m") extended with day
+// and week units ("1d", "2w", "1d12h"). Negative and zero durations are
+// rejected.
+func ParseDuration(input string) (time.Duration, error) {
+	s := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(input), " ", ""))
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	if strings.HasPrefix(s, "-") {
+		return 0, fmt.Errorf("duration %q must be positive", input)
+	}
+
+	// time.ParseDuration has no day or week units; rewrite them as hours.
+	s = dayWeekPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := dayWeekPattern.FindStringSubmatch(m)
+		hours, _ := strconv.ParseFloat(parts[1], 64)
+		hours *= 24
+		if parts[2] == "w" {
+			hours *= 7
+		}
+		return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+	})
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 30m, 1h, 1d)", input)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", input)
+	}
+	return d, nil
+}
@@ -0,0 +1,153 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Zones for the tests below on systems without zoneinfo
+)
+
+func TestParseDueDate(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wednesday afternoon
+	now := time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC)
+	at := func(loc *time.Location, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		input string
+		loc   *time.Location
+		want  time.Time
+	}{
+		// Absolute
+		{"2025-02-01T08:00:00Z", time.UTC, at(time.UTC, 2, 1, 8, 0)},
+		{"2025-02-01T08:00:00+09:00", time.UTC, at(time.UTC, 1, 31, 23, 0)},
+		{"2025-02-01 08:00", time.UTC, at(time.UTC, 2, 1, 8, 0)},
+		{"2025-02-01T08:00:30", time.UTC, time.Date(2025, 2, 1, 8, 0, 30, 0, time.UTC)},
+		{"2025-02-01", time.UTC, at(time.UTC, 2, 1, 9, 0)},
+
+		// Offsets
+		{"now", time.UTC, now},
+		{"in 2 hours", time.UTC, at(time.UTC, 1, 15, 16, 30)},
+		{"in 30 min", time.UTC, at(time.UTC, 1, 15, 15, 0)},
+		{"in 3 days", time.UTC, at(time.UTC, 1, 18, 14, 30)},
+		{"in 1 week", time.UTC, at(time.UTC, 1, 22, 14, 30)},
+		{"in 1 month", time.UTC, at(time.UTC, 2, 15, 14, 30)},
+		{"In  2   Hours", time.UTC, at(time.UTC, 1, 15, 16, 30)},
+
+		// Days with and without a time
+		{"tomorrow", time.UTC, at(time.UTC, 1, 16, 9, 0)},
+		{"tomorrow 9am", time.UTC, at(time.UTC, 1, 16, 9, 0)},
+		{"tomorrow at 9:15pm", time.UTC, at(time.UTC, 1, 16, 21, 15)},
+		{"today 17:30", time.UTC, at(time.UTC, 1, 15, 17, 30)},
+		{"tonight", time.UTC, at(time.UTC, 1, 15, 20, 0)},
+		{"today noon", time.UTC, at(time.UTC, 1, 15, 12, 0)},
+		{"tomorrow midnight", time.UTC, at(time.UTC, 1, 16, 0, 0)},
+		{"tomorrow 12am", time.UTC, at(time.UTC, 1, 16, 0, 0)},
+		{"tomorrow 12pm", time.UTC, at(time.UTC, 1, 16, 12, 0)},
+
+		// Weekdays: always the next one, a week ahead for today's
+		{"next monday", time.UTC, at(time.UTC, 1, 20, 9, 0)},
+		{"friday 3pm", time.UTC, at(time.UTC, 1, 17, 15, 0)},
+		{"wednesday", time.UTC, at(time.UTC, 1, 22, 9, 0)},
+		{"next wed", time.UTC, at(time.UTC, 1, 22, 9, 0)},
+		{"next week", time.UTC, at(time.UTC, 1, 22, 9, 0)},
+
+		// Bare times: the next time the clock shows it
+		{"18:00", time.UTC, at(time.UTC, 1, 15, 18, 0)},
+		{"9am", time.UTC, at(time.UTC, 1, 16, 9, 0)},
+
+		// The configured zone decides what "tomorrow" and "9am" mean:
+		// 14:30 UTC is already 23:30 in Tokyo
+		{"tomorrow 9am", tokyo, at(tokyo, 1, 16, 9, 0)},
+		{"9am", tokyo, at(tokyo, 1, 16, 9, 0)},
+		{"in 2 hours", tokyo, at(tokyo, 1, 16, 1, 30)},
+		{"2025-02-01 08:00", tokyo, at(tokyo, 2, 1, 8, 0)},
+		{"next monday", tokyo, at(tokyo, 1, 20, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.loc.String()+"/"+tt.input, func(t *testing.T) {
+			got, err := ParseDueDate(tt.input, now, tt.loc)
+			if err != nil {
+				t.Fatalf("ParseDueDate(%q): %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDueDate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if got.Location() != tt.loc {
+				t.Errorf("ParseDueDate(%q) is in %v, want %v", tt.input, got.Location(), tt.loc)
+			}
+		})
+	}
+}
+
+func TestParseDueDateAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The evening before clocks go forward on 2025-03-09
+	now := time.Date(2025, 3, 8, 20, 0, 0, 0, newYork)
+	got, err := ParseDueDate("tomorrow 9am", now, newYork)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 9, 9, 0, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("tomorrow 9am = %v, want %v", got, want)
+	}
+	if got.Hour() != 9 {
+		t.Errorf("tomorrow 9am is at %d:00 local time", got.Hour())
+	}
+}
+
+func TestParseDueDateErrors(t *testing.T) {
+	now := time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC)
+	for _, input := range []string{
+		"",
+		"   ",
+		"someday",
+		"in 2 fortnights",
+		"tomorrow 25:00",
+		"tomorrow 13pm",
+		"tomorrow 9",
+		"tomorrow evening",
+		"next month",
+	} {
+		if got, err := ParseDueDate(input, now, time.UTC); err == nil {
+			t.Errorf("ParseDueDate(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"30m", 30 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1d", 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{" 1 H ", time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "-1h", "0s", "soon", "1y"} {
+		if _, err := ParseDuration(input); err == nil {
+			t.Errorf("ParseDuration(%q) succeeded, want an error", input)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultNotifyInterval is how often the notifier polls for due reminders.
+const DefaultNotifyInterval = time.Minute
+
+// Notifier polls the store for due reminders and sends each one to a
+// Telegram chat once. Sent reminders are marked in the store, so
+// notifications are not repeated after a restart.
+type Notifier struct {
+	store    *Store
+	client   *http.Client
+	botToken string
+	chatID   string
+	interval time.Duration
+	loc      *time.Location
+	logger   *log.Logger
+}
+
+// NewNotifier creates a Telegram notifier. A non-positive interval uses
+// DefaultNotifyInterval; a nil loc uses the system timezone.
+func NewNotifier(store *Store, botToken, chatID string, interval time.Duration, loc *time.Location, logger *log.Logger) *Notifier {
+	if interval <= 0 {
+		interval = DefaultNotifyInterval
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	return &Notifier{
+		store:    store,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		botToken: botToken,
+		chatID:   chatID,
+		interval: interval,
+		loc:      loc,
+		logger:   logger,
+	}
+}
+
+// Run checks for due reminders immediately and then on every interval
+// until ctx is cancelled.
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		n.notifyDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyDue sends one message per unnotified due reminder. Failed sends
+// are left unmarked and retried on the next poll.
+func (n *Notifier) notifyDue(ctx context.Context) {
+	reminders, err := n.store.GetDueUnnotified()
+	if err != nil {
+		n.logger.Printf("notifier: %v", err)
+		return
+	}
+
+	for _, r := range reminders {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := n.sendMessage(ctx, n.formatMessage(r)); err != nil {
+			n.logger.Printf("notifier: failed to send reminder %d: %v", r.ID, err)
+			continue
+		}
+		if err := n.store.MarkNotified(r.ID, r.DueDate); err != nil {
+			n.logger.Printf("notifier: %v", err)
+		}
+	}
+}
+
+// formatMessage renders a reminder as a Telegram HTML message.
+func (n *Notifier) formatMessage(r Reminder) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "⏰ <b>%s</b>\n", html.EscapeString(r.Title))
+	if r.Description != "" {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(r.Description))
+	}
+	fmt.Fprintf(&b, "\nDue: %s", r.DueDate.In(n.loc).Format("Mon Jan 2 15:04 MST"))
+	if r.Priority == PriorityHigh {
+		b.WriteString("\nPriority: high")
+	}
+	if r.Recurrence != "" && r.Recurrence != RecurrenceNone {
+		fmt.Fprintf(&b, "\nRepeats: %s", r.Recurrence)
+	}
+	if len(r.Tags) > 0 {
+		fmt.Fprintf(&b, "\nTags: %s", html.EscapeString(strings.Join(r.Tags, ", ")))
+	}
+	fmt.Fprintf(&b, "\nID: %d", r.ID)
+
+	return b.String()
+}
+
+// sendMessage posts text to the configured chat via the Bot API.
+func (n *Notifier) sendMessage(ctx context.Context, text string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"chat_id":    n.chatID,
+		"text":       text,
+		"parse_mode": "HTML",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", n.botToken)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(payload)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
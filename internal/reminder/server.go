@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -19,12 +20,20 @@ const (
 type Server struct {
 	mcpServer *server.MCPServer
 	store     *Store
+	location  *time.Location // Timezone for parsing and displaying due dates
 }
 
 // NewServer creates a new Reminder MCP server backed by the given store.
-func NewServer(store *Store) *Server {
+// Relative due dates such as "tomorrow 9am" are resolved in loc; a nil loc
+// uses the local timezone.
+func NewServer(store *Store, loc *time.Location) *Server {
+	if loc == nil {
+		loc = time.Local
+	}
+
 	s := &Server{
-		store: store,
+		store:    store,
+		location: loc,
 	}
 
 	s.mcpServer = server.NewMCPServer(
@@ -42,15 +51,21 @@ func (s *Server) MCPServer() *server.MCPServer {
 	return s.mcpServer
 }
 
+// dueDateHelp describes the accepted due date formats to the model.
+const dueDateHelp = "Due date: RFC3339 (2025-01-15T09:00:00Z), local date/time (2025-01-15 09:00), " +
+	"or relative (tomorrow 9am, in 2 hours, next monday, friday 3pm)"
+
 func (s *Server) registerTools() {
 	// add_reminder
 	s.mcpServer.AddTool(
 		mcp.NewTool("add_reminder",
 			mcp.WithDescription("Add a new reminder with a title, due date, optional description and priority"),
 			mcp.WithString("title", mcp.Required(), mcp.Description("Reminder title")),
-			mcp.WithString("due_date", mcp.Required(), mcp.Description("Due date in RFC3339 format (e.g. 2025-01-15T09:00:00Z)")),
+			mcp.WithString("due_date", mcp.Required(), mcp.Description(dueDateHelp)),
 			mcp.WithString("description", mcp.Description("Optional description")),
 			mcp.WithString("priority", mcp.Description("Priority: low, medium, high (default: medium)")),
+			mcp.WithString("recurrence", mcp.Description("Repeat interval: none, daily, weekly, monthly (default: none)")),
+			mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Optional tags, e.g. [\"work\", \"errands\"]")),
 		),
 		s.handleAddReminder,
 	)
@@ -58,29 +73,72 @@ func (s *Server) registerTools() {
 	// list_reminders
 	s.mcpServer.AddTool(
 		mcp.NewTool("list_reminders",
-			mcp.WithDescription("List all reminders, optionally filtered by status (pending or completed)"),
+			mcp.WithDescription("List all reminders, optionally filtered by status (pending or completed) and tag"),
 			mcp.WithString("status", mcp.Description("Filter by status: pending, completed, or empty for all")),
+			mcp.WithString("tag", mcp.Description("Only list reminders with this tag")),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleListReminders,
 	)
 
+	// search_reminders
+	s.mcpServer.AddTool(
+		mcp.NewTool("search_reminders",
+			mcp.WithDescription("Search reminders by text in the title or description (case-insensitive, all words must match), ordered by due date"),
+			mcp.WithString("query", mcp.Required(), mcp.Description("Words to search for")),
+			mcp.WithString("status", mcp.Description("Filter by status: pending, completed, or empty for all")),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		s.handleSearchReminders,
+	)
+
 	// get_due_reminders
 	s.mcpServer.AddTool(
 		mcp.NewTool("get_due_reminders",
 			mcp.WithDescription("Get all pending reminders that are due now or overdue"),
+			mcp.WithReadOnlyHintAnnotation(true),
 		),
 		s.handleGetDueReminders,
 	)
 
+	// get_upcoming_reminders
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_upcoming_reminders",
+			mcp.WithDescription("Get pending reminders due within the given horizon from now, soonest first, with the time remaining for each"),
+			mcp.WithString("within", mcp.Description("Look-ahead window, e.g. 6h, 1d, 1w (default: 24h)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		s.handleGetUpcomingReminders,
+	)
+
 	// complete_reminder
 	s.mcpServer.AddTool(
 		mcp.NewTool("complete_reminder",
-			mcp.WithDescription("Mark a reminder as completed"),
+			mcp.WithDescription("Mark a reminder as completed. Recurring reminders are rescheduled to their next occurrence instead."),
 			mcp.WithNumber("id", mcp.Required(), mcp.Description("Reminder ID")),
 		),
 		s.handleCompleteReminder,
 	)
 
+	// complete_reminders
+	s.mcpServer.AddTool(
+		mcp.NewTool("complete_reminders",
+			mcp.WithDescription("Mark several reminders as completed at once (recurring ones are rescheduled). Reports how many were updated and which IDs were not found."),
+			mcp.WithArray("ids", mcp.Required(), mcp.WithNumberItems(), mcp.Description("Reminder IDs, e.g. [1, 2, 5]")),
+		),
+		s.handleCompleteReminders,
+	)
+
+	// snooze_reminder
+	s.mcpServer.AddTool(
+		mcp.NewTool("snooze_reminder",
+			mcp.WithDescription("Push a reminder's due date out by a duration and keep it pending. Overdue reminders are snoozed from now."),
+			mcp.WithNumber("id", mcp.Required(), mcp.Description("Reminder ID")),
+			mcp.WithString("duration", mcp.Required(), mcp.Description("How long to snooze, e.g. 30m, 1h, 1d, 1h30m")),
+		),
+		s.handleSnoozeReminder,
+	)
+
 	// delete_reminder
 	s.mcpServer.AddTool(
 		mcp.NewTool("delete_reminder",
@@ -90,15 +148,34 @@ func (s *Server) registerTools() {
 		s.handleDeleteReminder,
 	)
 
+	// delete_reminders
+	s.mcpServer.AddTool(
+		mcp.NewTool("delete_reminders",
+			mcp.WithDescription("Delete several reminders permanently at once. Reports how many were deleted and which IDs were not found."),
+			mcp.WithArray("ids", mcp.Required(), mcp.WithNumberItems(), mcp.Description("Reminder IDs, e.g. [1, 2, 5]")),
+		),
+		s.handleDeleteReminders,
+	)
+
+	// delete_completed
+	s.mcpServer.AddTool(
+		mcp.NewTool("delete_completed",
+			mcp.WithDescription("Permanently delete all completed reminders"),
+		),
+		s.handleDeleteCompleted,
+	)
+
 	// update_reminder
 	s.mcpServer.AddTool(
 		mcp.NewTool("update_reminder",
-			mcp.WithDescription("Update a reminder's fields (title, description, due_date, priority)"),
+			mcp.WithDescription("Update a reminder's fields (title, description, due_date, priority, recurrence, tags)"),
 			mcp.WithNumber("id", mcp.Required(), mcp.Description("Reminder ID")),
 			mcp.WithString("title", mcp.Description("New title")),
 			mcp.WithString("description", mcp.Description("New description")),
-			mcp.WithString("due_date", mcp.Description("New due date in RFC3339 format")),
+			mcp.WithString("due_date", mcp.Description("New due date. "+dueDateHelp)),
 			mcp.WithString("priority", mcp.Description("New priority: low, medium, high")),
+			mcp.WithString("recurrence", mcp.Description("New repeat interval: none, daily, weekly, monthly")),
+			mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Replacement tags; pass an empty array to clear")),
 		),
 		s.handleUpdateReminder,
 	)
@@ -109,6 +186,7 @@ func (s *Server) handleAddReminder(_ context.Context, req mcp.CallToolRequest) (
 	dueDateStr := req.GetString("due_date", "")
 	description := req.GetString("description", "")
 	priority := req.GetString("priority", "")
+	recurrence := req.GetString("recurrence", RecurrenceNone)
 
 	if title == "" {
 		return mcp.NewToolResultError("title is required"), nil
@@ -117,20 +195,25 @@ func (s *Server) handleAddReminder(_ context.Context, req mcp.CallToolRequest) (
 		return mcp.NewToolResultError("due_date is required"), nil
 	}
 
-	dueDate, err := time.Parse(time.RFC3339, dueDateStr)
+	dueDate, err := ParseDueDate(dueDateStr, time.Now(), s.location)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid due_date format: %v (use RFC3339, e.g. 2025-01-15T09:00:00Z)", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("invalid due_date: %v", err)), nil
 	}
 
 	if priority == "" {
 		priority = PriorityMedium
 	}
+	if !ValidRecurrence(recurrence) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid recurrence %q (use none, daily, weekly, monthly)", recurrence)), nil
+	}
 
 	r := Reminder{
 		Title:       title,
 		Description: description,
 		DueDate:     dueDate,
 		Priority:    priority,
+		Recurrence:  recurrence,
+		Tags:        req.GetStringSlice("tags", nil),
 	}
 
 	added, err := s.store.Add(r)
@@ -139,13 +222,20 @@ func (s *Server) handleAddReminder(_ context.Context, req mcp.CallToolRequest) (
 	}
 
 	output, _ := json.MarshalIndent(added, "", "  ")
-	return mcp.NewToolResultText(string(output)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Due: %s\n%s", s.formatDue(added.DueDate), output)), nil
 }
 
 func (s *Server) handleListReminders(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	status := req.GetString("status", "")
-
-	reminders, err := s.store.List(status)
+	tag := req.GetString("tag", "")
+
+	var reminders []Reminder
+	var err error
+	if tag != "" {
+		reminders, err = s.store.ListByTag(tag, status)
+	} else {
+		reminders, err = s.store.List(status)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list reminders: %v", err)), nil
 	}
@@ -158,6 +248,27 @@ func (s *Server) handleListReminders(_ context.Context, req mcp.CallToolRequest)
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+func (s *Server) handleSearchReminders(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := req.GetString("query", "")
+	status := req.GetString("status", "")
+
+	if query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	reminders, err := s.store.Search(query, status)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search reminders: %v", err)), nil
+	}
+
+	if len(reminders) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No reminders match %q.", query)), nil
+	}
+
+	output, _ := json.MarshalIndent(reminders, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
 func (s *Server) handleGetDueReminders(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	reminders, err := s.store.GetDue()
 	if err != nil {
@@ -172,6 +283,63 @@ func (s *Server) handleGetDueReminders(_ context.Context, _ mcp.CallToolRequest)
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+// upcomingReminder is a reminder annotated with the time left until it is due.
+type upcomingReminder struct {
+	Reminder
+	DueIn string `json:"due_in"`
+}
+
+func (s *Server) handleGetUpcomingReminders(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	within, err := ParseDuration(req.GetString("within", "24h"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	reminders, err := s.store.GetUpcoming(within)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get upcoming reminders: %v", err)), nil
+	}
+
+	if len(reminders) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No reminders due in the next %s.", formatRemaining(within))), nil
+	}
+
+	now := time.Now()
+	upcoming := make([]upcomingReminder, len(reminders))
+	for i, r := range reminders {
+		upcoming[i] = upcomingReminder{Reminder: r, DueIn: formatRemaining(r.DueDate.Sub(now))}
+	}
+
+	output, _ := json.MarshalIndent(upcoming, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
+// formatRemaining renders a duration as days, hours and minutes, e.g. "1d 3h 20m".
+func formatRemaining(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "less than a minute"
+	}
+
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
 func (s *Server) handleCompleteReminder(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	idFloat := req.GetFloat("id", -1)
 	if idFloat < 0 {
@@ -179,13 +347,52 @@ func (s *Server) handleCompleteReminder(_ context.Context, req mcp.CallToolReque
 	}
 	id := int64(idFloat)
 
-	if err := s.store.Complete(id); err != nil {
+	r, err := s.store.Complete(id)
+	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to complete reminder: %v", err)), nil
 	}
 
+	if r.Status == StatusPending {
+		return mcp.NewToolResultText(fmt.Sprintf("Reminder %d done; next %s occurrence due %s.",
+			id, r.Recurrence, s.formatDue(r.DueDate))), nil
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("Reminder %d marked as completed.", id)), nil
 }
 
+func (s *Server) handleCompleteReminders(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ids, err := requireIDs(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := s.store.CompleteMany(ids)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to complete reminders: %v", err)), nil
+	}
+
+	return bulkResultText(result)
+}
+
+func (s *Server) handleSnoozeReminder(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	idFloat := req.GetFloat("id", -1)
+	if idFloat < 0 {
+		return mcp.NewToolResultError("id is required and must be a positive number"), nil
+	}
+	id := int64(idFloat)
+
+	d, err := ParseDuration(req.GetString("duration", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	r, err := s.store.Snooze(id, d)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to snooze reminder: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Reminder %d snoozed by %v; now due %s.", id, d, s.formatDue(r.DueDate))), nil
+}
+
 func (s *Server) handleDeleteReminder(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	idFloat := req.GetFloat("id", -1)
 	if idFloat < 0 {
@@ -200,6 +407,55 @@ func (s *Server) handleDeleteReminder(_ context.Context, req mcp.CallToolRequest
 	return mcp.NewToolResultText(fmt.Sprintf("Reminder %d deleted.", id)), nil
 }
 
+func (s *Server) handleDeleteReminders(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ids, err := requireIDs(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := s.store.DeleteMany(ids)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete reminders: %v", err)), nil
+	}
+
+	return bulkResultText(result)
+}
+
+func (s *Server) handleDeleteCompleted(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	n, err := s.store.DeleteCompleted()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted %d completed reminder(s).", n)), nil
+}
+
+// requireIDs reads the "ids" array argument of the bulk tools.
+func requireIDs(req mcp.CallToolRequest) ([]int64, error) {
+	raw, err := req.RequireIntSlice("ids")
+	if err != nil {
+		return nil, fmt.Errorf("ids is required and must be an array of reminder IDs")
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("ids must contain at least one reminder ID")
+	}
+
+	ids := make([]int64, len(raw))
+	for i, id := range raw {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid reminder ID %d", id)
+		}
+		ids[i] = int64(id)
+	}
+	return ids, nil
+}
+
+// bulkResultText renders a BulkResult as JSON.
+func bulkResultText(result *BulkResult) (*mcp.CallToolResult, error) {
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(output)), nil
+}
+
 func (s *Server) handleUpdateReminder(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	idFloat := req.GetFloat("id", -1)
 	if idFloat < 0 {
@@ -216,7 +472,7 @@ func (s *Server) handleUpdateReminder(_ context.Context, req mcp.CallToolRequest
 		fields.Description = &v
 	}
 	if v := req.GetString("due_date", ""); v != "" {
-		t, err := time.Parse(time.RFC3339, v)
+		t, err := ParseDueDate(v, time.Now(), s.location)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("invalid due_date: %v", err)), nil
 		}
@@ -225,6 +481,16 @@ func (s *Server) handleUpdateReminder(_ context.Context, req mcp.CallToolRequest
 	if v := req.GetString("priority", ""); v != "" {
 		fields.Priority = &v
 	}
+	if v := req.GetString("recurrence", ""); v != "" {
+		if !ValidRecurrence(v) {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid recurrence %q (use none, daily, weekly, monthly)", v)), nil
+		}
+		fields.Recurrence = &v
+	}
+	if _, ok := req.GetArguments()["tags"]; ok {
+		tags := req.GetStringSlice("tags", nil)
+		fields.Tags = &tags
+	}
 
 	updated, err := s.store.Update(id, fields)
 	if err != nil {
@@ -232,5 +498,14 @@ func (s *Server) handleUpdateReminder(_ context.Context, req mcp.CallToolRequest
 	}
 
 	output, _ := json.MarshalIndent(updated, "", "  ")
+	if fields.DueDate != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("Due: %s\n%s", s.formatDue(updated.DueDate), output)), nil
+	}
 	return mcp.NewToolResultText(string(output)), nil
 }
+
+// formatDue renders a due date in the server timezone alongside UTC so the
+// user can confirm how a relative date was resolved.
+func (s *Server) formatDue(t time.Time) string {
+	return fmt.Sprintf("%s (%s)", t.In(s.location).Format("Mon, 02 Jan 2006 15:04 MST"), t.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,53 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSearchRemindersTool(t *testing.T) {
+	store := newTestStore(t)
+	srv := NewServer(store, time.UTC)
+
+	due := time.Now().UTC().Add(time.Hour)
+	for _, title := range []string{"Renew passport", "Pay rent", "Passport photos"} {
+		if _, err := store.Add(Reminder{Title: title, DueDate: due}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "search_reminders"
+		req.Params.Arguments = args
+		result, err := srv.handleSearchReminders(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	result := call(map[string]any{"query": "passport"})
+	var found []Reminder
+	if err := json.Unmarshal([]byte(text(result)), &found); err != nil {
+		t.Fatalf("result is not a list of reminders: %v\n%s", err, text(result))
+	}
+	if len(found) != 2 {
+		t.Errorf("found %d reminders for \"passport\", want 2", len(found))
+	}
+
+	if result := call(map[string]any{"query": "dragon"}); result.IsError || text(result) != `No reminders match "dragon".` {
+		t.Errorf("no match: %q", text(result))
+	}
+	if result := call(map[string]any{}); !result.IsError {
+		t.Error("a search without a query succeeded")
+	}
+}
@@ -3,11 +3,15 @@ package reminder
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+// reminderColumns is the column list shared by all reminder queries.
+const reminderColumns = "id, title, description, due_date, priority, status, recurrence, tags, created_at, updated_at"
+
 // Store provides SQLite-backed storage for reminders.
 type Store struct {
 	db *sql.DB
@@ -32,6 +36,11 @@ func NewStore(dbPath string) (*Store, error) {
 		return nil, err
 	}
 
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return &Store{db: db}, nil
 }
 
@@ -44,6 +53,9 @@ func createTable(db *sql.DB) error {
 			due_date    TEXT    NOT NULL,
 			priority    TEXT    NOT NULL DEFAULT 'medium',
 			status      TEXT    NOT NULL DEFAULT 'pending',
+			recurrence  TEXT    NOT NULL DEFAULT 'none',
+			tags        TEXT    NOT NULL DEFAULT '',
+			notified_at TEXT    NOT NULL DEFAULT '',
 			created_at  TEXT    NOT NULL,
 			updated_at  TEXT    NOT NULL
 		)
@@ -54,6 +66,61 @@ func createTable(db *sql.DB) error {
 	return nil
 }
 
+// migrations lists columns introduced after the initial schema, in order.
+var migrations = []struct {
+	column string
+	ddl    string
+}{
+	{"recurrence", `ALTER TABLE reminders ADD COLUMN recurrence TEXT NOT NULL DEFAULT 'none'`},
+	{"tags", `ALTER TABLE reminders ADD COLUMN tags TEXT NOT NULL DEFAULT ''`},
+	{"notified_at", `ALTER TABLE reminders ADD COLUMN notified_at TEXT NOT NULL DEFAULT ''`},
+}
+
+// migrate adds columns introduced after the initial schema to existing
+// databases.
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		exists, err := hasColumn(db, "reminders", m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(m.ddl); err != nil {
+			return fmt.Errorf("failed to add %s column: %w", m.column, err)
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether table has a column with the given name.
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to read table info: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan table info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
 // Close closes the underlying database connection.
 func (s *Store) Close() error {
 	return s.db.Close()
@@ -71,12 +138,15 @@ func (s *Store) Add(r Reminder) (*Reminder, error) {
 	if r.Status == "" {
 		r.Status = StatusPending
 	}
+	if r.Recurrence == "" {
+		r.Recurrence = RecurrenceNone
+	}
 
 	result, err := s.db.Exec(`
-		INSERT INTO reminders (title, description, due_date, priority, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO reminders (title, description, due_date, priority, status, recurrence, tags, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, r.Title, r.Description, r.DueDate.UTC().Format(time.RFC3339),
-		r.Priority, r.Status,
+		r.Priority, r.Status, r.Recurrence, joinTags(r.Tags),
 		r.CreatedAt.Format(time.RFC3339), r.UpdatedAt.Format(time.RFC3339))
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert reminder: %w", err)
@@ -87,6 +157,7 @@ func (s *Store) Add(r Reminder) (*Reminder, error) {
 		return nil, fmt.Errorf("failed to get inserted ID: %w", err)
 	}
 	r.ID = id
+	r.Tags = NormalizeTags(r.Tags)
 
 	return &r, nil
 }
@@ -99,12 +170,12 @@ func (s *Store) List(statusFilter string) ([]Reminder, error) {
 
 	if statusFilter != "" {
 		rows, err = s.db.Query(`
-			SELECT id, title, description, due_date, priority, status, created_at, updated_at
+			SELECT `+reminderColumns+`
 			FROM reminders WHERE status = ? ORDER BY due_date ASC
 		`, statusFilter)
 	} else {
 		rows, err = s.db.Query(`
-			SELECT id, title, description, due_date, priority, status, created_at, updated_at
+			SELECT ` + reminderColumns + `
 			FROM reminders ORDER BY due_date ASC
 		`)
 	}
@@ -116,12 +187,76 @@ func (s *Store) List(statusFilter string) ([]Reminder, error) {
 	return scanReminders(rows)
 }
 
+// ListByTag returns reminders carrying tag, optionally filtered by status.
+// Tags are matched case-insensitively as whole tags.
+func (s *Store) ListByTag(tag, statusFilter string) ([]Reminder, error) {
+	tags := NormalizeTags([]string{tag})
+	if len(tags) == 0 {
+		return s.List(statusFilter)
+	}
+	pattern := "%," + escapeLike(tags[0]) + ",%"
+
+	var rows *sql.Rows
+	var err error
+
+	if statusFilter != "" {
+		rows, err = s.db.Query(`
+			SELECT `+reminderColumns+`
+			FROM reminders WHERE (',' || tags || ',') LIKE ? ESCAPE '\' AND status = ? ORDER BY due_date ASC
+		`, pattern, statusFilter)
+	} else {
+		rows, err = s.db.Query(`
+			SELECT `+reminderColumns+`
+			FROM reminders WHERE (',' || tags || ',') LIKE ? ESCAPE '\' ORDER BY due_date ASC
+		`, pattern)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminders by tag: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReminders(rows)
+}
+
+// Search returns reminders whose title or description contains every word
+// of query, ordered by due date. Matching is case-insensitive for all
+// Unicode letters, so it is done in Go: SQLite's LIKE only folds ASCII.
+// An optional statusFilter restricts results to that status.
+func (s *Store) Search(query, statusFilter string) ([]Reminder, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query is empty")
+	}
+
+	reminders, err := s.List(statusFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Reminder
+	for _, r := range reminders {
+		text := strings.ToLower(r.Title + "\n" + r.Description)
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, r)
+		}
+	}
+
+	return matches, nil
+}
+
 // GetDue returns all pending reminders whose due_date is at or before now.
 func (s *Store) GetDue() ([]Reminder, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	rows, err := s.db.Query(`
-		SELECT id, title, description, due_date, priority, status, created_at, updated_at
+		SELECT `+reminderColumns+`
 		FROM reminders WHERE status = ? AND due_date <= ? ORDER BY due_date ASC
 	`, StatusPending, now)
 	if err != nil {
@@ -132,10 +267,58 @@ func (s *Store) GetDue() ([]Reminder, error) {
 	return scanReminders(rows)
 }
 
+// GetDueUnnotified returns pending reminders that are due and have not
+// been notified for their current due date yet.
+func (s *Store) GetDueUnnotified() ([]Reminder, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	rows, err := s.db.Query(`
+		SELECT `+reminderColumns+`
+		FROM reminders WHERE status = ? AND due_date <= ? AND notified_at = '' ORDER BY due_date ASC
+	`, StatusPending, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unnotified reminders: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReminders(rows)
+}
+
+// MarkNotified records that a notification was sent for reminder id at
+// the given due date. If the due date has changed in the meantime (the
+// reminder was snoozed or rescheduled) the mark is skipped so the new
+// due date is notified again.
+func (s *Store) MarkNotified(id int64, due time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE reminders SET notified_at = ? WHERE id = ? AND due_date = ?
+	`, time.Now().UTC().Format(time.RFC3339), id, due.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to mark reminder notified: %w", err)
+	}
+	return nil
+}
+
+// GetUpcoming returns pending reminders due between now and now+within,
+// ordered by due date.
+func (s *Store) GetUpcoming(within time.Duration) ([]Reminder, error) {
+	now := time.Now().UTC()
+
+	rows, err := s.db.Query(`
+		SELECT `+reminderColumns+`
+		FROM reminders WHERE status = ? AND due_date > ? AND due_date <= ? ORDER BY due_date ASC
+	`, StatusPending, now.Format(time.RFC3339), now.Add(within).Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upcoming reminders: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReminders(rows)
+}
+
 // GetByID returns a single reminder by ID.
 func (s *Store) GetByID(id int64) (*Reminder, error) {
 	row := s.db.QueryRow(`
-		SELECT id, title, description, due_date, priority, status, created_at, updated_at
+		SELECT `+reminderColumns+`
 		FROM reminders WHERE id = ?
 	`, id)
 
@@ -149,22 +332,104 @@ func (s *Store) GetByID(id int64) (*Reminder, error) {
 	return r, nil
 }
 
-// Complete marks a reminder as completed.
-func (s *Store) Complete(id int64) error {
-	now := time.Now().UTC().Format(time.RFC3339)
+// Complete marks a reminder as completed. Recurring reminders stay pending
+// and have their due date advanced to the next occurrence instead.
+// It returns the reminder as stored after the change.
+func (s *Store) Complete(id int64) (*Reminder, error) {
+	result, err := s.CompleteMany([]int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.NotFound) > 0 {
+		return nil, fmt.Errorf("reminder %d not found", id)
+	}
+	return s.GetByID(id)
+}
+
+// BulkResult reports the outcome of a bulk operation.
+type BulkResult struct {
+	Affected int64   `json:"affected"`
+	NotFound []int64 `json:"not_found,omitempty"`
+}
+
+// CompleteMany completes every reminder in ids within a single
+// transaction, rescheduling recurring ones as Complete does. Unknown IDs
+// are reported in NotFound; any database error rolls back all changes.
+func (s *Store) CompleteMany(ids []int64) (*BulkResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result := &BulkResult{}
+
+	for _, id := range uniqueIDs(ids) {
+		var dueDate, recurrence string
+		err := tx.QueryRow(`SELECT due_date, recurrence FROM reminders WHERE id = ?`, id).Scan(&dueDate, &recurrence)
+		if err == sql.ErrNoRows {
+			result.NotFound = append(result.NotFound, id)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reminder %d: %w", id, err)
+		}
+
+		if recurrence != "" && recurrence != RecurrenceNone {
+			due, _ := time.Parse(time.RFC3339, dueDate)
+			next := NextOccurrence(due, recurrence, now)
+			_, err = tx.Exec(`
+				UPDATE reminders SET status = ?, due_date = ?, notified_at = '', updated_at = ? WHERE id = ?
+			`, StatusPending, next.UTC().Format(time.RFC3339), now.Format(time.RFC3339), id)
+		} else {
+			_, err = tx.Exec(`
+				UPDATE reminders SET status = ?, updated_at = ? WHERE id = ?
+			`, StatusCompleted, now.Format(time.RFC3339), id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete reminder %d: %w", id, err)
+		}
+		result.Affected++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	return result, nil
+}
+
+// Snooze pushes a reminder's due date out by d and keeps it pending.
+// Reminders that are already overdue are snoozed from now, so the new
+// due date is always in the future.
+func (s *Store) Snooze(id int64, d time.Duration) (*Reminder, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("snooze duration must be positive")
+	}
+
+	r, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	base := r.DueDate
+	if base.Before(now) {
+		base = now
+	}
 
 	result, err := s.db.Exec(`
-		UPDATE reminders SET status = ?, updated_at = ? WHERE id = ?
-	`, StatusCompleted, now, id)
+		UPDATE reminders SET due_date = ?, status = ?, notified_at = '', updated_at = ? WHERE id = ?
+	`, base.Add(d).UTC().Format(time.RFC3339), StatusPending, now.Format(time.RFC3339), id)
 	if err != nil {
-		return fmt.Errorf("failed to complete reminder: %w", err)
+		return nil, fmt.Errorf("failed to snooze reminder: %w", err)
 	}
 
 	n, _ := result.RowsAffected()
 	if n == 0 {
-		return fmt.Errorf("reminder %d not found", id)
+		return nil, fmt.Errorf("reminder %d not found", id)
 	}
-	return nil
+	return s.GetByID(id)
 }
 
 // Delete removes a reminder by ID.
@@ -181,12 +446,72 @@ func (s *Store) Delete(id int64) error {
 	return nil
 }
 
+// DeleteMany deletes every reminder in ids within a single transaction.
+// Unknown IDs are reported in NotFound; any database error rolls back all
+// deletions.
+func (s *Store) DeleteMany(ids []int64) (*BulkResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &BulkResult{}
+
+	for _, id := range uniqueIDs(ids) {
+		res, err := tx.Exec(`DELETE FROM reminders WHERE id = ?`, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete reminder %d: %w", id, err)
+		}
+
+		n, _ := res.RowsAffected()
+		if n == 0 {
+			result.NotFound = append(result.NotFound, id)
+			continue
+		}
+		result.Affected += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteCompleted removes all completed reminders and returns how many
+// were deleted.
+func (s *Store) DeleteCompleted() (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM reminders WHERE status = ?`, StatusCompleted)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed reminders: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return n, nil
+}
+
+// uniqueIDs returns ids without duplicates, preserving order.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
 // UpdateFields holds optional fields for a partial update.
 type UpdateFields struct {
 	Title       *string
 	Description *string
 	DueDate     *time.Time
 	Priority    *string
+	Recurrence  *string
+	Tags        *[]string // Replaces all tags; an empty slice clears them
 }
 
 // Update applies partial updates to a reminder.
@@ -204,13 +529,22 @@ func (s *Store) Update(id int64, fields UpdateFields) (*Reminder, error) {
 		args = append(args, *fields.Description)
 	}
 	if fields.DueDate != nil {
-		setClauses = append(setClauses, "due_date = ?")
+		// A new due date deserves a new notification
+		setClauses = append(setClauses, "due_date = ?", "notified_at = ''")
 		args = append(args, fields.DueDate.UTC().Format(time.RFC3339))
 	}
 	if fields.Priority != nil {
 		setClauses = append(setClauses, "priority = ?")
 		args = append(args, *fields.Priority)
 	}
+	if fields.Recurrence != nil {
+		setClauses = append(setClauses, "recurrence = ?")
+		args = append(args, *fields.Recurrence)
+	}
+	if fields.Tags != nil {
+		setClauses = append(setClauses, "tags = ?")
+		args = append(args, joinTags(*fields.Tags))
+	}
 
 	if len(setClauses) == 0 {
 		return s.GetByID(id)
@@ -248,14 +582,16 @@ func scanReminders(rows *sql.Rows) ([]Reminder, error) {
 	var reminders []Reminder
 	for rows.Next() {
 		var r Reminder
-		var dueDate, createdAt, updatedAt string
+		var dueDate, tags, createdAt, updatedAt string
 
 		if err := rows.Scan(&r.ID, &r.Title, &r.Description,
-			&dueDate, &r.Priority, &r.Status,
+			&dueDate, &r.Priority, &r.Status, &r.Recurrence, &tags,
 			&createdAt, &updatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan reminder: %w", err)
 		}
 
+		r.Tags = splitTags(tags)
+
 		r.DueDate, _ = time.Parse(time.RFC3339, dueDate)
 		r.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		r.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
@@ -268,17 +604,55 @@ func scanReminders(rows *sql.Rows) ([]Reminder, error) {
 // scanReminder reads a single row into a Reminder.
 func scanReminder(row *sql.Row) (*Reminder, error) {
 	var r Reminder
-	var dueDate, createdAt, updatedAt string
+	var dueDate, tags, createdAt, updatedAt string
 
 	if err := row.Scan(&r.ID, &r.Title, &r.Description,
-		&dueDate, &r.Priority, &r.Status,
+		&dueDate, &r.Priority, &r.Status, &r.Recurrence, &tags,
 		&createdAt, &updatedAt); err != nil {
 		return nil, err
 	}
 
+	r.Tags = splitTags(tags)
+
 	r.DueDate, _ = time.Parse(time.RFC3339, dueDate)
 	r.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	r.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 
 	return &r, nil
 }
+
+// NormalizeTags lowercases and trims tags, dropping empty and duplicate
+// entries. Commas are not allowed inside a tag and split it.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part == "" || seen[part] {
+				continue
+			}
+			seen[part] = true
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// joinTags encodes tags for the tags column.
+func joinTags(tags []string) string {
+	return strings.Join(NormalizeTags(tags), ",")
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// splitTags decodes the tags column.
+func splitTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,117 @@
+package reminder
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := NewStore(filepath.Join(t.TempDir(), "reminders.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestCompleteRecurring(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name       string
+		due        time.Time
+		recurrence string
+		wantDue    time.Time
+		wantStatus string
+	}{
+		{"not yet due advances one period", now.Add(48 * time.Hour), RecurrenceDaily, now.Add(72 * time.Hour), StatusPending},
+		{"weekly not yet due", now.Add(24 * time.Hour), RecurrenceWeekly, now.Add(8 * 24 * time.Hour), StatusPending},
+		{"overdue skips to the future", now.Add(-36 * time.Hour), RecurrenceDaily, now.Add(12 * time.Hour), StatusPending},
+		{"one-off is completed", now.Add(24 * time.Hour), RecurrenceNone, now.Add(24 * time.Hour), StatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, err := s.Add(Reminder{Title: tt.name, DueDate: tt.due, Recurrence: tt.recurrence})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.Complete(added.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if !got.DueDate.Equal(tt.wantDue) {
+				t.Errorf("due = %s, want %s", got.DueDate, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for i, r := range []Reminder{
+		{Title: "Renew passport", Description: "Bring two photos"},
+		{Title: "Call the dentist", Description: "Ask about the PASSPORT photo booth next door"},
+		{Title: "Pay rent"},
+		{Title: "Team sync", Description: "Prepare the Q3 roadmap"},
+		{Title: "Buy photo frames", Description: "For the new flat"},
+	} {
+		// Added out of due-date order; results come back sorted by it
+		r.DueDate = now.Add(time.Duration(5-i) * time.Hour)
+		if _, err := s.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paid, _ := s.Search("rent", "")
+	if _, err := s.Complete(paid[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query  string
+		status string
+		want   []string
+	}{
+		{"passport", "", []string{"Call the dentist", "Renew passport"}},
+		{"PassPort", "", []string{"Call the dentist", "Renew passport"}},
+		{"photo", "", []string{"Buy photo frames", "Call the dentist", "Renew passport"}},
+		{"passport photo", "", []string{"Call the dentist", "Renew passport"}},
+		{"photo flat", "", []string{"Buy photo frames"}},
+		{"road", "", []string{"Team sync"}},
+		{"rent", "", []string{"Pay rent"}},
+		{"rent", StatusPending, nil},
+		{"rent", StatusCompleted, []string{"Pay rent"}},
+		{"passport rent", "", nil},
+		{"dragon", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.status, func(t *testing.T) {
+			got, err := s.Search(tt.query, tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, r := range got {
+				titles = append(titles, r.Title)
+			}
+			if !slices.Equal(titles, tt.want) {
+				t.Errorf("Search(%q, %q) = %q, want %q", tt.query, tt.status, titles, tt.want)
+			}
+		})
+	}
+
+	if _, err := s.Search("   ", ""); err == nil {
+		t.Error("Search with an empty query succeeded")
+	}
+}
@@ -15,6 +15,14 @@ const (
 	StatusCompleted = "completed"
 )
 
+// Recurrence intervals for repeating reminders.
+const (
+	RecurrenceNone    = "none"
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
 // Reminder represents a scheduled reminder item.
 type Reminder struct {
 	ID          int64     `json:"id"`
@@ -23,6 +31,55 @@ type Reminder struct {
 	DueDate     time.Time `json:"due_date"`
 	Priority    string    `json:"priority"`
 	Status      string    `json:"status"`
+	Recurrence  string    `json:"recurrence"`
+	Tags        []string  `json:"tags,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// ValidRecurrence reports whether r is a supported recurrence value.
+func ValidRecurrence(r string) bool {
+	switch r {
+	case RecurrenceNone, RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
+// NextOccurrence returns the occurrence of a recurring reminder due at due
+// that follows it once it is completed at now: at least one period after
+// due, and after now, so completing a reminder early still moves it on.
+// Monthly reminders keep the day of month of due, clamped to the last day
+// of shorter months (Jan 31 -> Feb 28). For non-recurring reminders it
+// returns due unchanged.
+func NextOccurrence(due time.Time, recurrence string, now time.Time) time.Time {
+	for step := 1; ; step++ {
+		var next time.Time
+		switch recurrence {
+		case RecurrenceDaily:
+			next = due.AddDate(0, 0, step)
+		case RecurrenceWeekly:
+			next = due.AddDate(0, 0, 7*step)
+		case RecurrenceMonthly:
+			next = addMonthsClamped(due, step)
+		default:
+			return due
+		}
+		if next.After(now) {
+			return next
+		}
+	}
+}
+
+// addMonthsClamped adds n months to t without overflowing into the following
+// month when the target month is shorter.
+func addMonthsClamped(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	firstOfTarget := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
@@ -0,0 +1,46 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	date := func(s string) time.Time {
+		t.Helper()
+		d, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name       string
+		due        string
+		recurrence string
+		now        string
+		want       string
+	}{
+		{"daily overdue", "2024-03-01 09:00", RecurrenceDaily, "2024-03-05 12:00", "2024-03-06 09:00"},
+		{"daily completed early", "2024-03-10 09:00", RecurrenceDaily, "2024-03-05 12:00", "2024-03-11 09:00"},
+		{"daily completed on time", "2024-03-05 09:00", RecurrenceDaily, "2024-03-05 09:00", "2024-03-06 09:00"},
+		{"weekly overdue", "2024-03-01 09:00", RecurrenceWeekly, "2024-03-20 12:00", "2024-03-22 09:00"},
+		{"weekly completed early", "2024-03-08 09:00", RecurrenceWeekly, "2024-03-05 12:00", "2024-03-15 09:00"},
+		{"monthly keeps day", "2024-01-15 09:00", RecurrenceMonthly, "2024-01-20 12:00", "2024-02-15 09:00"},
+		{"monthly clamps to short month", "2023-01-31 09:00", RecurrenceMonthly, "2023-02-01 12:00", "2023-02-28 09:00"},
+		{"monthly clamps in leap year", "2024-01-31 09:00", RecurrenceMonthly, "2024-02-01 12:00", "2024-02-29 09:00"},
+		{"monthly after clamp returns to day", "2024-01-31 09:00", RecurrenceMonthly, "2024-03-01 12:00", "2024-03-31 09:00"},
+		{"monthly completed early", "2024-04-30 09:00", RecurrenceMonthly, "2024-04-01 12:00", "2024-05-30 09:00"},
+		{"none unchanged", "2024-03-01 09:00", RecurrenceNone, "2024-03-05 12:00", "2024-03-01 09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextOccurrence(date(tt.due), tt.recurrence, date(tt.now))
+			if want := date(tt.want); !got.Equal(want) {
+				t.Errorf("NextOccurrence(%s, %s, %s) = %s, want %s", tt.due, tt.recurrence, tt.now, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
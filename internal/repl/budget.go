@@ -0,0 +1,111 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/ui"
+)
+
+// callProvider sends req and records its token usage and cost in the
+// session, so every API call counts towards the session budget. The
+// current turn is allowed to finish; a warning is shown when the budget
+// runs out.
+func (r *REPL) callProvider(ctx context.Context, op string, req api.MessageRequest) (*api.MessageResponse, error) {
+	response, err := r.provider.SendMessage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	wasExceeded := r.session.BudgetExceeded() != ""
+	r.session.RecordUsage(op, response.Usage, r.formatter.Cost(response.Usage, req.Model))
+	if reason := r.session.BudgetExceeded(); reason != "" && !wasExceeded {
+		r.displaySystem("Warning: session budget exceeded: " + reason + ". Use /budget extend to keep chatting.")
+	}
+	return response, nil
+}
+
+// checkBudget refuses new requests once the session budget is used up.
+func (r *REPL) checkBudget() error {
+	if reason := r.session.BudgetExceeded(); reason != "" {
+		return fmt.Errorf("session budget exceeded: %s (use /budget extend to continue)", reason)
+	}
+	return nil
+}
+
+func (r *REPL) handleBudgetCommand(args string) error {
+	parts := strings.Fields(args)
+	subcommand := "show"
+	if len(parts) > 0 {
+		subcommand = strings.ToLower(parts[0])
+	}
+
+	switch subcommand {
+	case "show", "status":
+		r.displayInfo(r.budgetStatus())
+		return nil
+
+	case "extend":
+		usd := 0.0
+		if len(parts) > 1 {
+			v, err := strconv.ParseFloat(strings.TrimPrefix(parts[1], "$"), 64)
+			if err != nil || v <= 0 {
+				return fmt.Errorf("invalid amount: %s (use a positive USD value)", parts[1])
+			}
+			usd = v
+		}
+
+		if !r.session.GetBudget().Enabled() && usd == 0 {
+			return fmt.Errorf("no budget is configured, use /budget extend <usd> to set one")
+		}
+
+		r.session.ExtendBudget(usd)
+		r.displaySystem("Budget extended.\n" + r.budgetStatus())
+		return nil
+
+	default:
+		return fmt.Errorf("unknown budget command: %s (use: show, extend [usd])", subcommand)
+	}
+}
+
+func (r *REPL) handleCostCommand() error {
+	var rows []ui.CostRow
+	for _, op := range r.session.UsageByOperation() {
+		rows = append(rows, ui.CostRow{
+			Operation: op.Operation,
+			Calls:     op.Calls,
+			Usage:     op.Usage,
+			Cost:      op.Cost,
+		})
+	}
+
+	fmt.Println()
+	fmt.Println(r.formatter.FormatCostTable(rows))
+	fmt.Println()
+	return nil
+}
+
+// budgetStatus describes session spending against the budget.
+func (r *REPL) budgetStatus() string {
+	usage := r.session.TotalUsage()
+	tokens := usage.InputTokens + usage.OutputTokens
+	budget := r.session.GetBudget()
+
+	costLimit, tokenLimit := "unlimited", "unlimited"
+	if budget.MaxCostUSD > 0 {
+		costLimit = fmt.Sprintf("$%.4f", budget.MaxCostUSD)
+	}
+	if budget.MaxTokensTotal > 0 {
+		tokenLimit = strconv.Itoa(budget.MaxTokensTotal)
+	}
+
+	status := fmt.Sprintf("Session cost: $%.4f / %s\n", r.session.TotalCost(), costLimit)
+	status += fmt.Sprintf("Session tokens: %d / %s (input=%d, output=%d)", tokens, tokenLimit, usage.InputTokens, usage.OutputTokens)
+	if reason := r.session.BudgetExceeded(); reason != "" {
+		status += "\nBudget exceeded: " + reason
+	}
+	return status
+}
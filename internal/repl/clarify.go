@@ -11,16 +11,9 @@ import (
 
 // Styles for question display
 var (
-	questionTitleStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("81")).
-		Bold(true)
-
-	counterStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
-
-	selectedResultStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("114")).
-		Bold(true)
+	questionTitleStyle  lipgloss.Style
+	counterStyle        lipgloss.Style
+	selectedResultStyle lipgloss.Style
 )
 
 // AskClarifyingQuestions presents questions interactively and collects answers
@@ -50,7 +43,7 @@ func (r *REPL) AskClarifyingQuestions(questions []chat.ClarifyQuestion) ([]chat.
 		}
 
 		// Create and run selector
-		selector := ui.NewSelector(q.Question, options, false, r.config.UI.ColoredOutput)
+		selector := ui.NewSelector(q.Question, options, false, r.formatter.Colored())
 
 		var answer string
 		var err error
@@ -59,14 +52,14 @@ func (r *REPL) AskClarifyingQuestions(questions []chat.ClarifyQuestion) ([]chat.
 			result, needsCustom, runErr := selector.RunWithCustomOption()
 			if runErr != nil {
 				// Restore readline before returning
-				if newRl, rlErr := setupReadline(); rlErr == nil {
+				if newRl, rlErr := setupReadline(r.config); rlErr == nil {
 					r.rl = newRl
 				}
 				return nil, runErr
 			}
 			if needsCustom {
 				// Restore readline for custom input
-				if newRl, rlErr := setupReadline(); rlErr == nil {
+				if newRl, rlErr := setupReadline(r.config); rlErr == nil {
 					r.rl = newRl
 				}
 				answer, err = r.getCustomInput()
@@ -81,7 +74,7 @@ func (r *REPL) AskClarifyingQuestions(questions []chat.ClarifyQuestion) ([]chat.
 		} else {
 			result, runErr := selector.Run()
 			if runErr != nil {
-				if newRl, rlErr := setupReadline(); rlErr == nil {
+				if newRl, rlErr := setupReadline(r.config); rlErr == nil {
 					r.rl = newRl
 				}
 				return nil, runErr
@@ -100,71 +93,85 @@ func (r *REPL) AskClarifyingQuestions(questions []chat.ClarifyQuestion) ([]chat.
 	}
 
 	// Restore readline
-	if newRl, rlErr := setupReadline(); rlErr == nil {
+	if newRl, rlErr := setupReadline(r.config); rlErr == nil {
 		r.rl = newRl
 	}
 
 	return answers, nil
 }
 
-// AskUserQuestion presents a single question with options using interactive selector
-func (r *REPL) AskUserQuestion(question string, options []string, multiSelect bool) ([]string, error) {
+// AskUserQuestion presents a single ask_user question using the
+// interactive selector, with an extra option for typing an answer of the
+// user's own. The answer is empty when the user skips an optional question.
+func (r *REPL) AskUserQuestion(q chat.AskUserQuestion) (chat.AskUserAnswer, error) {
 	fmt.Println()
 
 	// Convert to SelectorOption
-	selectorOptions := make([]ui.SelectorOption, len(options))
-	for i, opt := range options {
-		// Split label and description if present
-		parts := strings.SplitN(opt, " - ", 2)
-		selectorOptions[i] = ui.SelectorOption{Label: parts[0]}
-		if len(parts) > 1 {
-			selectorOptions[i].Description = parts[1]
-		}
+	selectorOptions := make([]ui.SelectorOption, len(q.Options))
+	for i, opt := range q.Options {
+		selectorOptions[i] = ui.SelectorOption{Label: opt.Label, Description: opt.Description}
 	}
 
 	// Temporarily close readline to avoid terminal conflicts
 	r.rl.Close()
 
 	// Create selector
-	selector := ui.NewSelector(question, selectorOptions, multiSelect, r.config.UI.ColoredOutput)
+	selector := ui.NewSelector(q.Question, selectorOptions, q.MultiSelect, r.formatter.Colored())
+	selector.SetDefault(q.DefaultIndex())
+	selector.SetOptional(q.Optional)
 
 	// Run with custom option
 	result, needsCustom, err := selector.RunWithCustomOption()
 
 	// Recreate readline
-	newRl, rlErr := setupReadline()
+	newRl, rlErr := setupReadline(r.config)
 	if rlErr == nil {
 		r.rl = newRl
 	}
 
 	if err != nil {
-		return nil, err
+		return chat.AskUserAnswer{}, err
 	}
 
-	if needsCustom {
+	answer := chat.AskUserAnswer{Selected: result}
+	for needsCustom {
 		custom, err := r.getCustomInput()
 		if err != nil {
-			return nil, err
+			return chat.AskUserAnswer{}, err
 		}
-		result = []string{custom}
+		// An empty answer is only fine if something else was chosen or
+		// the question may be skipped
+		if custom != "" || len(result) > 0 || q.Optional {
+			answer.Custom = custom
+			break
+		}
+		r.displayInfo("Type an answer, or press Ctrl+C to cancel.")
 	}
 
 	// Show selection
-	fmt.Println(selectedResultStyle.Render("→ " + strings.Join(result, ", ")))
+	shown := result
+	if answer.Custom != "" {
+		shown = append(shown, fmt.Sprintf("%q", answer.Custom))
+	}
+	if answer.Skipped() {
+		fmt.Println(counterStyle.Render("→ (skipped)"))
+	} else {
+		fmt.Println(selectedResultStyle.Render("→ " + strings.Join(shown, ", ")))
+	}
 	fmt.Println()
 
-	return result, nil
+	return answer, nil
 }
 
 // getCustomInput prompts for custom text input
 func (r *REPL) getCustomInput() (string, error) {
-	promptStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("147"))
+	promptStyle := lipgloss.NewStyle().Foreground(ui.CurrentTheme().Accent)
 	fmt.Print(promptStyle.Render("Your answer: "))
 
 	r.rl.SetPrompt("")
 	defer r.rl.SetPrompt("you > ")
 
-	input, err := r.rl.Readline()
+	input, _, err := r.readLine()
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,189 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// commandArgs lists the slash commands offered on Tab and the fixed values
+// of their first argument. Keep it in sync with handleCommand.
+var commandArgs = map[string][]string{
+	"/help":       nil,
+	"/clear":      nil,
+	"/system":     nil,
+	"/show":       nil,
+	"/quit":       nil,
+	"/count":      nil,
+	"/provider":   nil,
+	"/profile":    nil,
+	"/models":     {"refresh"},
+	"/format":     {"json", "yaml", "schema", "strict", "show", "clear"},
+	"/clarify":    {"on", "off", "show"},
+	"/temp":       nil,
+	"/file":       {"--head"},
+	"/edit":       nil,
+	"/image":      {"clear"},
+	"/paste":      nil,
+	"/context":    {"show", "on", "off", "keep"},
+	"/mcp":        {"status", "tools", "reload", "restart", "disable", "enable"},
+	"/askuser":    {"on", "off", "show"},
+	"/reasoning":  {"on", "off", "show"},
+	"/confirm":    {"on", "off", "show", "allow", "revoke"},
+	"/plan":       {"on", "off", "show"},
+	"/pager":      {"on", "off", "show"},
+	"/export":     nil,
+	"/save":       nil,
+	"/load":       nil,
+	"/history":    {"messages", "trim", "backups", "restore"},
+	"/cost":       nil,
+	"/budget":     {"show", "extend"},
+	"/search":     {"--role"},
+	"/checkpoint": {"list", "save", "load"},
+}
+
+// commandAliases maps short command names to the names in commandArgs.
+var commandAliases = map[string]string{
+	"/h":           "/help",
+	"/c":           "/clear",
+	"/s":           "/system",
+	"/exit":        "/quit",
+	"/q":           "/quit",
+	"/p":           "/provider",
+	"/f":           "/format",
+	"/cl":          "/clarify",
+	"/t":           "/temp",
+	"/temperature": "/temp",
+	"/e":           "/edit",
+	"/img":         "/image",
+	"/ctx":         "/context",
+	"/ask":         "/askuser",
+	"/cp":          "/checkpoint",
+}
+
+// pathCommands take file paths as arguments.
+var pathCommands = map[string]bool{
+	"/file":   true,
+	"/export": true,
+	"/save":   true,
+	"/load":   true,
+	"/image":  true,
+}
+
+// commandCompleter completes slash commands, their fixed arguments and,
+// for commands that take files, filesystem paths.
+type commandCompleter struct{}
+
+// Do implements readline.AutoCompleter. Candidates are the text to append
+// to the word before the cursor, whose length is returned.
+func (commandCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	if !strings.HasPrefix(text, "/") {
+		return nil, 0
+	}
+
+	command, rest, hasArgs := strings.Cut(text, " ")
+	if !hasArgs {
+		return completeWords(commandNames(), command)
+	}
+
+	command = strings.ToLower(command)
+	if name, ok := commandAliases[command]; ok {
+		command = name
+	}
+
+	// The word being completed and the complete ones before it
+	args := strings.Fields(rest)
+	word := ""
+	if len(args) > 0 && !strings.HasSuffix(rest, " ") {
+		word = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	prev := ""
+	if len(args) > 0 {
+		prev = args[len(args)-1]
+	}
+
+	var candidates [][]rune
+	switch {
+	case command == "/format" && len(args) == 1 && args[0] == "strict":
+		return completeWords([]string{"on", "off"}, word)
+	case command == "/format" && len(args) == 1 && args[0] == "schema":
+		return completePath(word)
+	case command == "/search" && prev == "--role":
+		return completeWords([]string{"user", "assistant", "tool"}, word)
+	case command == "/file" && prev == "--head":
+		return nil, 0
+	case len(args) == 0:
+		candidates, _ = completeWords(commandArgs[command], word)
+	}
+
+	if pathCommands[command] && !strings.HasPrefix(word, "-") {
+		paths, _ := completePath(word)
+		candidates = append(candidates, paths...)
+	}
+
+	// Fixed values contain no "/", so both kinds of candidates complete
+	// the last path element of word
+	_, base := filepath.Split(word)
+	return candidates, len([]rune(base))
+}
+
+// commandNames returns the names in commandArgs, sorted.
+func commandNames() []string {
+	names := make([]string, 0, len(commandArgs))
+	for name := range commandArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeWords returns the rest of each word starting with prefix,
+// followed by a space.
+func completeWords(words []string, prefix string) ([][]rune, int) {
+	var candidates [][]rune
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			candidates = append(candidates, []rune(w[len(prefix):]+" "))
+		}
+	}
+	return candidates, len([]rune(prefix))
+}
+
+// completePath returns the rest of each file or directory name that starts
+// with the last element of word. Directories get a trailing "/" so Tab can
+// descend into them; hidden entries are offered only if asked for.
+func completePath(word string) ([][]rune, int) {
+	dir, base := filepath.Split(word)
+
+	readDir := dir
+	switch {
+	case readDir == "":
+		readDir = "."
+	case strings.HasPrefix(readDir, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			readDir = filepath.Join(home, readDir[2:])
+		}
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil, 0
+	}
+
+	var candidates [][]rune
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		suffix := " "
+		if e.IsDir() {
+			suffix = "/"
+		}
+		candidates = append(candidates, []rune(name[len(base):]+suffix))
+	}
+	return candidates, len([]rune(base))
+}
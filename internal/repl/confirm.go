@@ -0,0 +1,167 @@
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/ui"
+)
+
+// deniedToolResult is sent to the model in place of a rejected tool call.
+const deniedToolResult = "Tool call denied by the user. Do not retry it unless the user asks; continue without it or explain what you needed it for."
+
+// Tool approval choices shown to the user, in menu order.
+const (
+	approveOnce = iota
+	approveAlways
+	approveDeny
+)
+
+// approveToolCalls asks the user to approve the whole batch in plan mode,
+// or each tool call when confirmation mode is on. It returns one flag per
+// call; outside plan mode, tools on the auto-approve list are allowed
+// without asking.
+func (r *REPL) approveToolCalls(calls []api.ToolCall) []bool {
+	if r.planMode {
+		return r.approvePlan(calls)
+	}
+
+	approved := make([]bool, len(calls))
+	for i, tc := range calls {
+		if !r.confirmTools || r.autoApprove[tc.Name] {
+			approved[i] = true
+			continue
+		}
+
+		ok, err := r.confirmToolCall(tc)
+		if err != nil {
+			r.displayError(fmt.Errorf("tool approval failed: %w", err))
+		}
+		approved[i] = ok
+	}
+	return approved
+}
+
+// confirmToolCall shows the approval menu for one tool call. "Always allow"
+// adds the tool to the auto-approve list for the rest of the session.
+func (r *REPL) confirmToolCall(tc api.ToolCall) (bool, error) {
+	fmt.Println()
+
+	question := fmt.Sprintf("Run tool %s?", tc.Name)
+	options := []ui.SelectorOption{
+		approveOnce:   {Label: "Allow", Description: "Run this call"},
+		approveAlways: {Label: "Always allow", Description: fmt.Sprintf("Run %s without asking for this session", tc.Name)},
+		approveDeny:   {Label: "Deny", Description: "Skip it and tell the model"},
+	}
+	if args := compactArgs(tc.Arguments); args != "" {
+		options[approveOnce].Description = args
+	}
+
+	var choice []int
+	err := r.releaseTerminal(func() error {
+		// Input that names no choice, such as an empty line or EOF when
+		// stdin isn't a terminal, denies the call
+		selector := ui.NewSelector(question, options, false, r.formatter.Colored())
+		selector.SetDefault(approveDeny)
+		var err error
+		choice, err = selector.RunIndexes()
+		return err
+	})
+	if err != nil || len(choice) == 0 {
+		return false, err
+	}
+
+	fmt.Println(selectedResultStyle.Render("→ " + options[choice[0]].Label))
+
+	switch choice[0] {
+	case approveAlways:
+		r.autoApprove[tc.Name] = true
+		return true, nil
+	case approveOnce:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// compactArgs returns tool arguments on one line, shortened for the menu.
+func compactArgs(args string) string {
+	const maxLen = 120
+
+	if args == "" || args == "{}" {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(args), &v); err == nil {
+		if b, err := json.Marshal(v); err == nil {
+			args = string(b)
+		}
+	}
+	if len(args) > maxLen {
+		args = args[:maxLen] + "..."
+	}
+	return args
+}
+
+func (r *REPL) handleConfirmCommand(args string) error {
+	fields := strings.Fields(args)
+	subcommand := ""
+	if len(fields) > 0 {
+		subcommand = strings.ToLower(fields[0])
+	}
+
+	switch subcommand {
+	case "", "show", "status":
+		state := "DISABLED\nTools run without asking."
+		if r.confirmTools {
+			state = "ENABLED\nEach tool call must be approved before it runs."
+		}
+		info := "Tool confirmation: " + state
+		if len(r.autoApprove) > 0 {
+			names := make([]string, 0, len(r.autoApprove))
+			for name := range r.autoApprove {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			info += "\nAuto-approved: " + strings.Join(names, ", ")
+		}
+		r.displayInfo(info)
+		return nil
+
+	case "on", "enable":
+		r.confirmTools = true
+		r.displaySystem("Tool confirmation ENABLED. You will be asked before each tool call.")
+		return nil
+
+	case "off", "disable":
+		r.confirmTools = false
+		r.displaySystem("Tool confirmation DISABLED.")
+		return nil
+
+	case "allow":
+		if len(fields) < 2 {
+			return fmt.Errorf("usage: /confirm allow <tool>")
+		}
+		r.autoApprove[fields[1]] = true
+		r.displaySystem(fmt.Sprintf("Tool %s will run without confirmation.", fields[1]))
+		return nil
+
+	case "revoke":
+		if len(fields) < 2 {
+			return fmt.Errorf("usage: /confirm revoke <tool>")
+		}
+		if !r.autoApprove[fields[1]] {
+			return fmt.Errorf("tool %s is not auto-approved", fields[1])
+		}
+		delete(r.autoApprove, fields[1])
+		r.displaySystem(fmt.Sprintf("Tool %s requires confirmation again.", fields[1]))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown confirm command: %s (use: on, off, show, allow <tool>, revoke <tool>)", subcommand)
+	}
+}
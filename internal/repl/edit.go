@@ -0,0 +1,118 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+// multilineEnd is the line that finishes a message in multiline mode.
+const multilineEnd = "."
+
+// handleEditCommand composes a message in $VISUAL/$EDITOR, or in the
+// built-in multiline mode when no editor is configured, and sends it.
+// Text after /edit is used as the initial content.
+func (r *REPL) handleEditCommand(ctx context.Context, args string) error {
+	// A blank variable counts as unset
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+
+	if len(editor) == 0 {
+		r.displayInfo("No $EDITOR set, using multiline input.")
+		return r.handlePasteCommand(ctx, args)
+	}
+
+	message, err := r.composeInEditor(editor, args)
+	if err != nil {
+		return err
+	}
+
+	return r.sendComposed(ctx, message)
+}
+
+// handlePasteCommand reads a message in the built-in multiline mode.
+func (r *REPL) handlePasteCommand(ctx context.Context, args string) error {
+	message, ok := r.readMultiline(args)
+	if !ok {
+		r.displayInfo("Cancelled.")
+		return nil
+	}
+	return r.sendComposed(ctx, message)
+}
+
+func (r *REPL) sendComposed(ctx context.Context, message string) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		r.displayInfo("Empty message, nothing sent.")
+		return nil
+	}
+
+	lines := strings.Count(message, "\n") + 1
+	r.displayInfo(fmt.Sprintf("Sending %d lines (%d characters)", lines, len(message)))
+	return r.handleMessage(ctx, message)
+}
+
+// composeInEditor opens editor on a temp file holding initial and returns
+// the saved content. editor is the command followed by its arguments, as
+// in EDITOR="code --wait".
+func (r *REPL) composeInEditor(editor []string, initial string) (string, error) {
+	f, err := os.CreateTemp("", "cli-chat-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(initial); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	f.Close()
+
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if runErr := r.releaseTerminal(cmd.Run); runErr != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor[0], runErr)
+	}
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read temp file: %w", err)
+	}
+	return string(content), nil
+}
+
+// readMultiline reads lines until a single "." line or Ctrl+D. It returns
+// false when the user cancels with Ctrl+C.
+func (r *REPL) readMultiline(initial string) (string, bool) {
+	r.displayInfo(fmt.Sprintf("Enter your message. Finish with a line containing only %q or Ctrl+D; Ctrl+C cancels.", multilineEnd))
+
+	var lines []string
+	if initial != "" {
+		lines = append(lines, initial)
+	}
+
+	r.rl.SetPrompt(pastedStyle.Render("... "))
+	defer r.rl.SetPrompt(getPrompt())
+
+	for {
+		line, _, err := r.readLine()
+		if err == readline.ErrInterrupt {
+			return "", false
+		}
+		if err != nil || strings.TrimSpace(line) == multilineEnd {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"), true
+}
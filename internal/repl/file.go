@@ -0,0 +1,240 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/notexe/cli-chat/internal/chat"
+)
+
+// minFileTokenBudget is the smallest budget /file works with, even when
+// the context is nearly full.
+const minFileTokenBudget = 2000
+
+// binarySniffLen is how much of a file is checked for binary content.
+const binarySniffLen = 8000
+
+// loadedFile is one file read by /file.
+type loadedFile struct {
+	path    string
+	content string
+}
+
+func (r *REPL) handleFileCommand(ctx context.Context, args string) error {
+	usage := fmt.Errorf("usage: /file [--head N] <path|glob>...")
+	if strings.TrimSpace(args) == "" {
+		return usage
+	}
+
+	head, patterns, err := parseFileArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(patterns) == 0 {
+		return usage
+	}
+
+	paths, err := expandFilePatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	var files []loadedFile
+	for _, path := range paths {
+		file, skip, err := readTextFile(path, head)
+		if err != nil {
+			return err
+		}
+		if skip != "" {
+			r.displayInfo(fmt.Sprintf("Skipping %s: %s", path, skip))
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no text files to send")
+	}
+
+	message, notice := buildFileMessage(files, r.fileTokenBudget())
+
+	info := fmt.Sprintf("Loaded %d characters from %s", len(message), describeFiles(files))
+	if notice != "" {
+		info += "\n" + notice
+	}
+	r.displayInfo(info)
+
+	return r.handleMessage(ctx, message)
+}
+
+// parseFileArgs splits /file arguments into the --head option and path
+// patterns. An argument string that names an existing file is taken as
+// one path, so file names with spaces keep working.
+func parseFileArgs(args string) (int, []string, error) {
+	args = strings.TrimSpace(args)
+	if _, err := os.Stat(args); err == nil {
+		return 0, []string{args}, nil
+	}
+
+	head := 0
+	var patterns []string
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		if fields[i] != "--head" {
+			patterns = append(patterns, fields[i])
+			continue
+		}
+		if i+1 >= len(fields) {
+			return 0, nil, fmt.Errorf("--head requires a line count")
+		}
+		n, err := strconv.Atoi(fields[i+1])
+		if err != nil || n <= 0 {
+			return 0, nil, fmt.Errorf("invalid --head value: %s", fields[i+1])
+		}
+		head = n
+		i++
+	}
+	return head, patterns, nil
+}
+
+// expandFilePatterns resolves globs and plain paths, keeping the order
+// given and dropping duplicates.
+func expandFilePatterns(patterns []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			if strings.ContainsAny(pattern, "*?[") {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+			matches = []string{pattern} // Reported by readTextFile
+		}
+
+		for _, path := range matches {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// readTextFile reads path, limited to the first head lines when head > 0.
+// Directories, empty and binary files are returned with a skip reason.
+func readTextFile(path string, head int) (loadedFile, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return loadedFile{}, "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return loadedFile{}, "is a directory", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return loadedFile{}, "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	if len(data) == 0 {
+		return loadedFile{}, "file is empty", nil
+	}
+
+	sniff := data
+	if len(sniff) > binarySniffLen {
+		sniff = sniff[:binarySniffLen]
+	}
+	if bytes.IndexByte(sniff, 0) >= 0 || !utf8.Valid(data) {
+		return loadedFile{}, "binary file", nil
+	}
+
+	content := string(data)
+	if head > 0 {
+		lines := strings.SplitAfter(content, "\n")
+		if lines[len(lines)-1] == "" {
+			// The final newline ends the last line rather than starting one.
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) > head {
+			content = strings.Join(lines[:head], "") +
+				fmt.Sprintf("[... first %d of %d lines]\n", head, len(lines))
+		}
+	}
+
+	return loadedFile{path: path, content: content}, "", nil
+}
+
+// buildFileMessage joins files into one message. A single file is sent as
+// is; several files get a header each. Content beyond budget tokens is cut
+// off, and the returned notice says what was left out.
+func buildFileMessage(files []loadedFile, budget int) (string, string) {
+	var sb strings.Builder
+	budgetChars := chat.EstimateChars(budget)
+	var dropped []string
+
+	for i, f := range files {
+		part := f.content
+		if len(files) > 1 {
+			part = fmt.Sprintf("### File: %s\n```\n%s\n```\n\n", f.path, strings.TrimRight(f.content, "\n"))
+		}
+
+		if sb.Len()+len(part) <= budgetChars {
+			sb.WriteString(part)
+			continue
+		}
+
+		room := budgetChars - sb.Len()
+		for room > 0 && !utf8.RuneStart(part[room]) {
+			room--
+		}
+		if room > 0 {
+			sb.WriteString(part[:room])
+			sb.WriteString("\n\n[... truncated to fit the context budget]")
+			dropped = append(dropped, f.path+" (truncated)")
+		} else {
+			dropped = append(dropped, f.path)
+		}
+		for _, rest := range files[i+1:] {
+			dropped = append(dropped, rest.path)
+		}
+		break
+	}
+
+	if len(dropped) == 0 {
+		return sb.String(), ""
+	}
+	notice := fmt.Sprintf("Warning: files exceed the ~%d token budget, not sent in full: %s", budget, strings.Join(dropped, ", "))
+	return sb.String(), notice
+}
+
+// fileTokenBudget is how many tokens /file may add: half of the context
+// left after the history and the response reserve.
+func (r *REPL) fileTokenBudget() int {
+	used, limit, _ := r.session.GetContextStatus()
+	budget := (limit - used - r.session.GetMaxTokens()) / 2
+	if budget < minFileTokenBudget {
+		budget = minFileTokenBudget
+	}
+	return budget
+}
+
+// describeFiles names the loaded files for the status line.
+func describeFiles(files []loadedFile) string {
+	if len(files) == 1 {
+		return files[0].path
+	}
+	total := 0
+	for _, f := range files {
+		total += chat.EstimateTokens(f.content)
+	}
+	return fmt.Sprintf("%d files (~%d tokens)", len(files), total)
+}
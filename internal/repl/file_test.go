@@ -0,0 +1,40 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadTextFileHead(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		head    int
+		want    string
+	}{
+		{"fewer lines than head", "a\nb\n", 3, "a\nb\n"},
+		{"exactly head lines", "a\nb\nc\n", 3, "a\nb\nc\n"},
+		{"exactly head lines without final newline", "a\nb\nc", 3, "a\nb\nc"},
+		{"more lines than head", "a\nb\nc\nd\n", 2, "a\nb\n[... first 2 of 4 lines]\n"},
+		{"more lines without final newline", "a\nb\nc", 2, "a\nb\n[... first 2 of 3 lines]\n"},
+		{"no head", "a\nb\nc\n", 0, "a\nb\nc\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			file, skip, err := readTextFile(path, tt.head)
+			if err != nil || skip != "" {
+				t.Fatalf("readTextFile() skip %q, error %v", skip, err)
+			}
+			if file.content != tt.want {
+				t.Errorf("content = %q, want %q", file.content, tt.want)
+			}
+		})
+	}
+}
@@ -11,17 +11,19 @@ import (
 	"time"
 
 	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/chat"
+	"github.com/notexe/cli-chat/internal/mcp"
 	"github.com/notexe/cli-chat/internal/ui"
 )
 
 // helpSearchPrompt is the system prompt for /help queries that use code index results.
 const helpSearchPrompt = `You are a project assistant. The user asked a question about the codebase using the /help command.
-Below are search results from the project's code index, grouped by priority.
+Below are search results from the project's indexes. Each result names the index it came from.
 
 Your task:
 - Answer the question based ONLY on the provided search results
-- Results labeled "Documentation index" have HIGHEST priority — prefer them over code results
-- Results labeled "Code index" are supplementary — use them only if docs don't cover the question
+- Results from the documentation index (named below) have HIGHEST priority — prefer them over code results
+- Results from other indexes are supplementary — use them only if docs don't cover the question
 - Show relevant code fragments in your answer when appropriate
 - Point out style patterns, conventions, and architectural rules you can see
 - If the snippets don't contain enough info, say so honestly
@@ -37,6 +39,10 @@ This lets the user click on file paths in the terminal to navigate directly to t
 
 // handleHelpQuery searches the code index and asks the AI to answer based on results.
 func (r *REPL) handleHelpQuery(ctx context.Context, query string) error {
+	if err := r.checkBudget(); err != nil {
+		return err
+	}
+
 	if r.mcpManager == nil || !r.mcpManager.HasCodeIndexTools() {
 		r.displayInfo("Code index not available. Make sure mcp-codeindex server is configured and running.\nUse /help without arguments to see available commands.")
 		return nil
@@ -45,72 +51,30 @@ func (r *REPL) handleHelpQuery(ctx context.Context, query string) error {
 	// Detect project root from git or CWD
 	projectRoot := detectProjectRoot()
 
-	// Phase 1: Search documentation index (docs/.codeindex) — highest priority
-	r.status.Show("Searching documentation...")
-
+	// Phase 1: Make sure the documentation (docs/.codeindex) and project
+	// (.codeindex) indexes exist, creating missing ones
 	docsDir := filepath.Join(projectRoot, "docs")
-	docsIndexDir := filepath.Join(docsDir, ".codeindex")
-	var docsResult string
-
-	if _, err := os.Stat(docsIndexDir); err == nil {
-		// docs/.codeindex exists — search it
-		result, err := r.searchIndex(ctx, query, docsDir, 5, 0.2, 1000)
-		if err == nil {
-			docsResult = result
-		}
-	} else if _, err := os.Stat(docsDir); err == nil {
-		// docs/ exists but no index — create it
-		r.status.Show("Indexing documentation...")
-		indexArgs, _ := json.Marshal(map[string]interface{}{
-			"path": docsDir,
-		})
-		if _, err := r.mcpManager.CallTool(ctx, "index_directory", string(indexArgs)); err == nil {
-			result, err := r.searchIndex(ctx, query, docsDir, 5, 0.2, 1000)
-			if err == nil {
-				docsResult = result
-			}
+	var indexDirs []string
+	if _, err := os.Stat(docsDir); err == nil {
+		if r.ensureIndex(ctx, docsDir, "Indexing documentation...") {
+			indexDirs = append(indexDirs, docsDir)
 		}
 	}
-
-	// Phase 2: Search main code index (.codeindex) — second priority
-	r.status.Show("Searching code index...")
-
-	mainIndexDir := filepath.Join(projectRoot, ".codeindex")
-	var codeResult string
-
-	if _, err := os.Stat(mainIndexDir); err == nil {
-		// .codeindex exists — search it
-		result, err := r.searchIndex(ctx, query, "", 5, 0.3, 600)
-		if err == nil {
-			codeResult = result
-		}
-	} else {
-		// No main index — create it
-		r.status.Show("Indexing project...")
-		indexArgs, _ := json.Marshal(map[string]interface{}{
-			"path": projectRoot,
-		})
-		if _, err := r.mcpManager.CallTool(ctx, "index_directory", string(indexArgs)); err == nil {
-			result, err := r.searchIndex(ctx, query, "", 5, 0.3, 600)
-			if err == nil {
-				codeResult = result
-			}
-		}
+	if r.ensureIndex(ctx, projectRoot, "Indexing project...") {
+		indexDirs = append(indexDirs, projectRoot)
 	}
 
-	// Combine results with priority labels
-	var combined strings.Builder
-	if isValidResult(docsResult) {
-		combined.WriteString("=== Documentation index (HIGHEST PRIORITY) ===\n")
-		combined.WriteString(docsResult)
-		combined.WriteString("\n\n")
-	}
-	if isValidResult(codeResult) {
-		combined.WriteString("=== Code index ===\n")
-		combined.WriteString(codeResult)
+	// Phase 2: Search both in one call; docs come first so they win ties
+	// and duplicates
+	var searchResult string
+	if len(indexDirs) > 0 {
+		r.status.Show("Searching indexes...")
+		result, err := r.searchIndexes(ctx, query, indexDirs, 8, 0.2, 800)
+		if err == nil && isValidResult(result) {
+			searchResult = result
+		}
 	}
 
-	searchResult := combined.String()
 	if strings.TrimSpace(searchResult) == "" {
 		r.status.Hide()
 		r.displayInfo(fmt.Sprintf("No results found for: %s\nTry a different query or check that the project is indexed.", query))
@@ -120,7 +84,7 @@ func (r *REPL) handleHelpQuery(ctx context.Context, query string) error {
 	// Phase 3: Send to AI
 	r.status.Show("Generating answer...")
 
-	prompt := fmt.Sprintf("Question: %s\n\n%s", query, searchResult)
+	prompt := fmt.Sprintf("Question: %s\n\nDocumentation index: %s\n\n%s", query, docsDir, searchResult)
 
 	req := api.MessageRequest{
 		Model:       r.session.GetModelName(),
@@ -133,7 +97,7 @@ func (r *REPL) handleHelpQuery(ctx context.Context, query string) error {
 	}
 
 	start := time.Now()
-	response, err := r.provider.SendMessage(ctx, req)
+	response, err := r.callProvider(ctx, chat.OpHelp, req)
 	duration := time.Since(start)
 	if err != nil {
 		r.status.Hide()
@@ -143,7 +107,7 @@ func (r *REPL) handleHelpQuery(ctx context.Context, query string) error {
 	r.status.Hide()
 
 	fmt.Println()
-	fmt.Println(r.formatter.FormatAssistantMessage(response.Content))
+	r.printPaged(r.formatter.FormatAssistantMessage(response.Content))
 
 	if r.config.UI.ShowTokenCount {
 		fmt.Println(r.formatter.FormatTokenUsage(response.Usage, ui.TokenUsageOptions{
@@ -156,16 +120,31 @@ func (r *REPL) handleHelpQuery(ctx context.Context, query string) error {
 	return nil
 }
 
-// searchIndex performs a semantic search, optionally at a specific index path.
-func (r *REPL) searchIndex(ctx context.Context, query string, indexPath string, topK int, minSim float64, maxLen int) (string, error) {
+// ensureIndex reports whether dir has a .codeindex, indexing it first if
+// it has none.
+func (r *REPL) ensureIndex(ctx context.Context, dir, status string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".codeindex")); err == nil {
+		return true
+	}
+
+	r.status.Show(status)
+	indexArgs, _ := json.Marshal(map[string]interface{}{
+		"path": dir,
+	})
+	ctx = mcp.WithProgress(ctx, r.status.Update)
+	_, err := r.mcpManager.CallTool(ctx, "index_directory", string(indexArgs))
+	return err == nil
+}
+
+// searchIndexes performs one semantic search across the indexes of several
+// directories, merging the results.
+func (r *REPL) searchIndexes(ctx context.Context, query string, indexDirs []string, topK int, minSim float64, maxLen int) (string, error) {
 	args := map[string]interface{}{
 		"query":              query,
 		"top_k":              topK,
 		"min_similarity":     minSim,
 		"max_content_length": maxLen,
-	}
-	if indexPath != "" {
-		args["index_path"] = indexPath
+		"index_paths":        indexDirs,
 	}
 
 	argsJSON, _ := json.Marshal(args)
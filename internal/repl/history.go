@@ -0,0 +1,82 @@
+package repl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/chat"
+)
+
+// historyPreviewLength is how many characters of each message /history shows.
+const historyPreviewLength = 80
+
+// listMessages shows the messages sent with the next request, numbered as
+// in /search, with a token estimate for each.
+func (r *REPL) listMessages() error {
+	messages := r.session.GetMessages()
+	if len(messages) == 0 {
+		r.displayInfo("No messages in the conversation.")
+		return nil
+	}
+
+	total := 0
+	lines := make([]string, 0, len(messages))
+	for i, msg := range messages {
+		tokens := chat.EstimateMessageTokens(msg)
+		total += tokens
+		lines = append(lines, fmt.Sprintf("  #%-3d %s %6s  %s",
+			i+1, r.renderSearchRole(msg.Role), fmt.Sprintf("~%d", tokens), messagePreview(msg)))
+	}
+
+	fmt.Println()
+	fmt.Printf("%d messages, ~%d tokens:\n", len(messages), total)
+	fmt.Println(strings.Join(lines, "\n"))
+	fmt.Println("Use /history trim <n> to drop the messages before #n.")
+	fmt.Println()
+	return nil
+}
+
+// trimMessages drops the messages before the one numbered arg in
+// listMessages.
+func (r *REPL) trimMessages(arg string) error {
+	count := r.session.MessageCount()
+	if count == 0 {
+		return fmt.Errorf("no messages to trim")
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > count {
+		return fmt.Errorf("usage: /history trim <n> (n from 1 to %d)", count)
+	}
+	if n == 1 {
+		r.displayInfo("Nothing before #1; history unchanged.")
+		return nil
+	}
+
+	removed := r.session.TrimMessagesBefore(n - 1)
+	msg := fmt.Sprintf("Removed %d message(s); %d left.", removed, r.session.MessageCount())
+	if removed > n-1 {
+		msg += " Tool results whose calls were trimmed were removed too."
+	}
+	r.displaySystem(msg)
+	return nil
+}
+
+// messagePreview returns a one-line preview of a message, describing tool
+// calls when there is no text.
+func messagePreview(msg api.Message) string {
+	text := strings.Join(strings.Fields(msg.Content), " ")
+	if text == "" && len(msg.ToolCalls) > 0 {
+		calls := make([]string, 0, len(msg.ToolCalls))
+		for _, tc := range msg.ToolCalls {
+			calls = append(calls, tc.Name)
+		}
+		text = "calls " + strings.Join(calls, ", ")
+	}
+	if len(msg.Images) > 0 {
+		text = fmt.Sprintf("[%d image(s)] %s", len(msg.Images), text)
+	}
+	return truncateRunes(text, historyPreviewLength)
+}
@@ -0,0 +1,137 @@
+package repl
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/chat"
+	"github.com/notexe/cli-chat/internal/mcp"
+)
+
+// maxImageSize is the largest image /image attaches, well above what
+// screenshots need but below the request size limits of vision APIs.
+const maxImageSize = 20 << 20
+
+// imageMediaTypes are the formats vision models accept.
+var imageMediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+func (r *REPL) handleImageCommand(ctx context.Context, args string) error {
+	args = strings.TrimSpace(args)
+
+	switch args {
+	case "":
+		if len(r.pendingImages) == 0 {
+			r.displayInfo("No images attached. Usage: /image <path|url>")
+			return nil
+		}
+		lines := make([]string, 0, len(r.pendingImages))
+		for _, img := range r.pendingImages {
+			lines = append(lines, "  "+img.Label())
+		}
+		r.displayInfo(fmt.Sprintf("%d image(s) attached to the next message:\n%s",
+			len(r.pendingImages), strings.Join(lines, "\n")))
+		return nil
+
+	case "clear":
+		r.pendingImages = nil
+		r.displaySystem("Attached images removed.")
+		return nil
+	}
+
+	model := r.session.GetModelName()
+	supported, err := api.SupportsVision(ctx, r.provider, model)
+	if err != nil {
+		return fmt.Errorf("failed to check vision support: %w", err)
+	}
+	if !supported {
+		return fmt.Errorf("model %s does not support image input", model)
+	}
+
+	img, err := loadImage(args)
+	if err != nil {
+		return err
+	}
+
+	r.pendingImages = append(r.pendingImages, img)
+	r.displaySystem(fmt.Sprintf("Attached %s. It will be sent with your next message.", img.Label()))
+	return nil
+}
+
+// loadImage returns an image for source: http(s) URLs are passed on as
+// is, anything else is read as a local file and inlined as base64.
+func loadImage(source string) (api.Image, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return api.Image{URL: source}, nil
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return api.Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	if info.IsDir() {
+		return api.Image{}, fmt.Errorf("%s is a directory", source)
+	}
+	if info.Size() > maxImageSize {
+		return api.Image{}, fmt.Errorf("image too large: %s is %d MB (limit %d MB)",
+			source, info.Size()>>20, maxImageSize>>20)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return api.Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	mediaType := http.DetectContentType(data)
+	if !imageMediaTypes[mediaType] {
+		return api.Image{}, fmt.Errorf("unsupported image type %s (use PNG, JPEG, GIF or WebP)", mediaType)
+	}
+
+	return api.Image{
+		Data:      base64.StdEncoding.EncodeToString(data),
+		MediaType: mediaType,
+	}, nil
+}
+
+// toolImages collects the images returned by the tool calls of one turn.
+// Tool messages carry only text, so the images follow the results in a
+// user message.
+type toolImages struct {
+	images []api.Image
+	tools  []string
+}
+
+// collectToolImages adds the images returned by tool to t. It returns a
+// note for the tool result: where the images went, or why the model can't
+// see them.
+func (r *REPL) collectToolImages(ctx context.Context, t *toolImages, tool string, images []mcp.ToolImage) string {
+	model := r.session.GetModelName()
+	if supported, err := api.SupportsVision(ctx, r.provider, model); err != nil || !supported {
+		return fmt.Sprintf("\n\n[%d image(s) not shown: model %s does not support image input]", len(images), model)
+	}
+
+	for _, img := range images {
+		t.images = append(t.images, api.Image{Data: img.Data, MediaType: img.MIMEType})
+	}
+	t.tools = append(t.tools, tool)
+	return fmt.Sprintf("\n\n[%d image(s) attached in the next message]", len(images))
+}
+
+// attach adds the collected images to the session. It reports whether
+// there were any.
+func (t *toolImages) attach(session *chat.Session) bool {
+	if len(t.images) == 0 {
+		return false
+	}
+	session.AddUserMessageWithImages(fmt.Sprintf("Images returned by %s.", strings.Join(t.tools, ", ")), t.images)
+	return true
+}
@@ -3,27 +3,31 @@ package repl
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/chzyer/readline"
+	"github.com/notexe/cli-chat/internal/config"
+	"github.com/notexe/cli-chat/internal/ui"
 )
 
 // Styles for input UI
 var (
-	pastedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245")).
-		Italic(true)
+	pastedStyle lipgloss.Style
 )
 
 func (r *REPL) readInput() (string, error) {
-	line, err := r.rl.Readline()
+	line, pasted, err := r.readLine()
 	if err != nil {
 		return "", err
 	}
 
 	trimmed := strings.TrimSpace(line)
 
+	r.recordInput(trimmed)
+
 	// If it's a command, return immediately
 	if strings.HasPrefix(trimmed, "/") {
 		return trimmed, nil
@@ -34,31 +38,28 @@ func (r *REPL) readInput() (string, error) {
 		return "", nil
 	}
 
-	// Check for bracketed paste (embedded newlines)
-	if strings.Contains(line, "\n") {
-		lineCount := strings.Count(line, "\n") + 1
-		r.showPastedIndicator(lineCount)
+	if pasted > 0 {
+		fmt.Println(r.formatter.FormatPasteInfo(strings.Count(trimmed, "\n") + 1))
 	}
 
 	return trimmed, nil
 }
 
-// showPastedIndicator clears the pasted lines and shows "[Pasted X lines]"
-func (r *REPL) showPastedIndicator(lineCount int) {
-	// Clear the pasted content and show indicator
-	// Move up for each line we need to clear
-	for i := 0; i < lineCount; i++ {
-		fmt.Print("\033[1A") // Move up
-		fmt.Print("\033[K")  // Clear line
+// recordInput adds a typed input to the readline history. Answers to
+// prompts read with r.rl are not recorded, and neither are multi-line
+// pastes, which the line-based history file cannot hold.
+func (r *REPL) recordInput(input string) {
+	if input == "" || strings.Contains(input, "\n") {
+		return
 	}
-
-	plural := "lines"
-	if lineCount == 1 {
-		plural = "line"
+	if r.config.Session.InputHistorySkipSystem {
+		command, _, _ := strings.Cut(input, " ")
+		if command == "/system" || command == "/s" {
+			return
+		}
 	}
-
-	indicator := pastedStyle.Render(fmt.Sprintf("[Pasted %d %s]", lineCount, plural))
-	fmt.Println(getPrompt() + indicator)
+	// Fails only if the history file can't be written; recall still works
+	_ = r.rl.SaveHistory(input)
 }
 
 func (r *REPL) parseCommand(input string) (bool, string, string) {
@@ -80,21 +81,46 @@ func (r *REPL) parseCommand(input string) (bool, string, string) {
 // getPrompt returns the styled prompt string
 func getPrompt() string {
 	promptStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("62"))
+		Foreground(ui.CurrentTheme().Border)
 	arrowStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("114")).
+		Foreground(ui.CurrentTheme().Success).
 		Bold(true)
 	return promptStyle.Render("you") + arrowStyle.Render(" > ")
 }
 
-func setupReadline() (*readline.Instance, error) {
+// setupReadline creates the input line editor. Inputs are added to its
+// history by recordInput rather than automatically, and persisted in the
+// configured input history file, which readline appends to as they are
+// entered and cuts to the size limit when loading.
+func setupReadline(cfg *config.Config) (*readline.Instance, error) {
+	historyFile := cfg.Session.InputHistoryFile
+	if historyFile != "" {
+		// Create it private: inputs can contain anything the user typed
+		if err := os.MkdirAll(filepath.Dir(historyFile), 0o755); err == nil {
+			if f, err := os.OpenFile(historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600); err == nil {
+				f.Close()
+			}
+		}
+	}
+
+	// Filter pastes only from a terminal; piped input arrives in chunks of
+	// many lines that are not pastes
+	var stdin io.ReadCloser
+	if readline.DefaultIsTerminal() {
+		stdin = newPasteStdin()
+	}
+
 	rl, err := readline.NewEx(&readline.Config{
-		Prompt:              getPrompt(),
-		HistoryFile:         "",
-		InterruptPrompt:     "^C",
-		EOFPrompt:           "exit",
-		HistorySearchFold:   true,
-		FuncFilterInputRune: filterInput,
+		Stdin:                  stdin,
+		Prompt:                 getPrompt(),
+		HistoryFile:            historyFile,
+		HistoryLimit:           cfg.Session.InputHistorySize,
+		DisableAutoSaveHistory: true,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              "exit",
+		HistorySearchFold:      true,
+		AutoComplete:           commandCompleter{},
+		FuncFilterInputRune:    filterInput,
 	})
 
 	return rl, err
@@ -0,0 +1,50 @@
+package repl
+
+import (
+	"context"
+
+	"github.com/notexe/cli-chat/internal/api"
+)
+
+// runRequest runs fn with a context that Interrupt cancels. An aborted
+// request is not an error: the history is rolled back to what it was
+// before, so no partial turn (user message without an answer, unanswered
+// tool calls) is kept, and the REPL returns to the prompt.
+func (r *REPL) runRequest(ctx context.Context, fn func(context.Context) error) error {
+	snapshot := append([]api.Message(nil), r.session.GetMessages()...)
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	r.requestMu.Lock()
+	r.cancelRequest = cancel
+	r.requestMu.Unlock()
+
+	defer func() {
+		r.requestMu.Lock()
+		r.cancelRequest = nil
+		r.requestMu.Unlock()
+		cancel()
+	}()
+
+	err := fn(reqCtx)
+	if reqCtx.Err() != nil && ctx.Err() == nil {
+		r.status.Hide()
+		r.session.RestoreMessages(snapshot)
+		r.displayInfo("Request aborted.")
+		return nil
+	}
+	return err
+}
+
+// Interrupt cancels the request in flight and reports whether there was
+// one. It is safe to call from a signal handler goroutine; when it returns
+// false the REPL is idle and the caller should exit.
+func (r *REPL) Interrupt() bool {
+	r.requestMu.Lock()
+	defer r.requestMu.Unlock()
+
+	if r.cancelRequest == nil {
+		return false
+	}
+	r.cancelRequest()
+	return true
+}
@@ -0,0 +1,57 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/chat"
+)
+
+func (r *REPL) handleModelsCommand(ctx context.Context, args string) error {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+	case "refresh":
+		r.models = nil
+	default:
+		return fmt.Errorf("usage: /models [refresh]")
+	}
+
+	if r.models == nil {
+		r.status.Show("Fetching models...")
+		models, err := r.provider.ListModels(ctx)
+		r.status.Hide()
+		if err != nil {
+			return err
+		}
+		r.models = withKnownContextWindows(models)
+	}
+
+	fmt.Println()
+	fmt.Println(r.formatter.FormatModelTable(r.models, r.session.GetModelName()))
+	fmt.Println()
+	return nil
+}
+
+// withKnownContextWindows fills in context windows the provider did not
+// report from the built-in limits, matching Ollama tags ("llama3:8b") by
+// their base name.
+func withKnownContextWindows(models []api.ModelInfo) []api.ModelInfo {
+	limits := chat.DefaultModelLimits()
+	for i, m := range models {
+		if m.ContextWindow > 0 {
+			continue
+		}
+		name := m.ID
+		if limit, ok := limits[name]; ok {
+			models[i].ContextWindow = limit
+			continue
+		}
+		name, _, _ = strings.Cut(name, ":")
+		if limit, ok := limits[name]; ok {
+			models[i].ContextWindow = limit
+		}
+	}
+	return models
+}
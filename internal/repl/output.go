@@ -25,12 +25,12 @@ func (r *REPL) displayResponseWithUsage(response *api.MessageResponse, duration
 		if chat.HasMarkdownCodeBlocks(response.Content) {
 			// Modern styled error box
 			borderStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("203"))
+				Foreground(ui.CurrentTheme().Error)
 			titleStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("203")).
+				Foreground(ui.CurrentTheme().Error).
 				Bold(true)
 			textStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("252"))
+				Foreground(ui.CurrentTheme().Text)
 
 			fmt.Println()
 			fmt.Println(borderStyle.Render("╭──────────────────────────────────────────╮"))
@@ -44,13 +44,20 @@ func (r *REPL) displayResponseWithUsage(response *api.MessageResponse, duration
 		displayContent = chat.CleanMarkdownCodeBlocks(displayContent)
 	}
 
+	r.displayReasoning(response.ReasoningContent)
+
 	fmt.Println()
-	fmt.Println(r.formatter.FormatAssistantMessage(displayContent))
+	r.printPaged(r.formatter.FormatAssistantMessage(displayContent))
 
-	if r.session.GetFormatPrompt() != "" {
+	switch r.session.GetFormatName() {
+	case "json":
 		if parsed, err := chat.ParseJSONResponse(response.Content); err == nil {
 			fmt.Println(chat.FormatJSONTable(parsed))
 		}
+	case "yaml":
+		if parsed, err := chat.ParseYAMLResponse(response.Content); err == nil {
+			fmt.Println(chat.FormatYAMLTable(parsed))
+		}
 	}
 
 	if r.config.UI.ShowTokenCount {
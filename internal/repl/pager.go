@@ -0,0 +1,110 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notexe/cli-chat/internal/ui"
+)
+
+// defaultPager is used when $PAGER is not set.
+const defaultPager = "less"
+
+func (r *REPL) handlePagerCommand(args string) error {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "", "show", "status":
+		state := "DISABLED\nLong output is printed in full."
+		if r.pager {
+			state = fmt.Sprintf("ENABLED\nOutput taller than the terminal opens in %s.", pagerCommand())
+		}
+		r.displayInfo("Pager: " + state)
+		return nil
+
+	case "on", "enable":
+		r.pager = true
+		r.displaySystem(fmt.Sprintf("Pager ENABLED. Responses and tool results taller than the terminal open in %s; quit it with q.", pagerCommand()))
+		return nil
+
+	case "off", "disable":
+		r.pager = false
+		r.displaySystem("Pager DISABLED.")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown pager command: %s (use: on, off, show)", args)
+	}
+}
+
+// printPaged prints text, through the pager when /pager is on and the text
+// does not fit on the terminal. If the pager cannot run, the text is
+// printed instead.
+func (r *REPL) printPaged(text string) {
+	if !r.pager || fitsTerminal(text) {
+		fmt.Println(text)
+		return
+	}
+
+	if err := r.runPager(text); err != nil {
+		r.displayError(err)
+		fmt.Println(text)
+	}
+}
+
+// fitsTerminal reports whether text, with long lines wrapped, fits on one
+// screen of the terminal. Output that is not going to a terminal always
+// fits, so it is never paged.
+func fitsTerminal(text string) bool {
+	width, height := ui.TerminalSize()
+	if width <= 0 || height <= 0 {
+		return true
+	}
+
+	rows := 0
+	for _, line := range strings.Split(text, "\n") {
+		// lipgloss.Width ignores color escapes
+		rows += max(1, (lipgloss.Width(line)+width-1)/width)
+	}
+	// Leave a row for the prompt that follows
+	return rows < height
+}
+
+// pagerCommand returns $PAGER, or defaultPager when it is unset.
+func pagerCommand() string {
+	if pager := strings.TrimSpace(os.Getenv("PAGER")); pager != "" {
+		return pager
+	}
+	return defaultPager
+}
+
+// runPager shows text in the pager. Like git, it sets LESS=R when LESS is
+// unset so that less keeps the colors.
+func (r *REPL) runPager(text string) error {
+	parts := strings.Fields(pagerCommand())
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdin = strings.NewReader(text + "\n")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=R")
+	}
+
+	if err := r.releaseTerminal(cmd.Run); err != nil {
+		return fmt.Errorf("pager %s failed: %w", parts[0], err)
+	}
+	return nil
+}
+
+// releaseTerminal closes readline while run uses the terminal, so it does
+// not read keys meant for an editor or pager, and reopens it afterwards.
+func (r *REPL) releaseTerminal(run func() error) error {
+	r.rl.Close()
+	err := run()
+	if newRl, rlErr := setupReadline(r.config); rlErr == nil {
+		r.rl = newRl
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/chzyer/readline"
+)
+
+// Bracketed paste mode makes the terminal wrap pasted text in pasteStart
+// and pasteEnd, so a paste can be told from typing however slowly it
+// arrives. Terminals that do not support it ignore the mode switch.
+const (
+	bracketedPasteOn  = "\x1b[?2004h"
+	bracketedPasteOff = "\x1b[?2004l"
+	pasteStart        = "\x1b[200~"
+	pasteEnd          = "\x1b[201~"
+)
+
+// pastes holds multi-line pastes until the line they were pasted into is
+// entered. It is shared by all readline instances, which are recreated
+// whenever another program takes over the terminal.
+var pastes = &pasteStore{texts: make(map[string]string)}
+
+// pasteStore maps the placeholders shown in the input line to the text
+// they stand for.
+type pasteStore struct {
+	mu    sync.Mutex
+	next  int
+	texts map[string]string
+}
+
+// add stores text and returns its placeholder.
+func (s *pasteStore) add(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	lines := strings.Count(text, "\n") + 1
+	placeholder := fmt.Sprintf("[Pasted %d lines #%d]", lines, s.next)
+	s.texts[placeholder] = text
+	return placeholder
+}
+
+// expand replaces the placeholders in line with the pasted text and
+// forgets all stored pastes. It returns how many pastes were expanded.
+func (s *pasteStore) expand(line string) (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expanded := 0
+	for placeholder, text := range s.texts {
+		if strings.Contains(line, placeholder) {
+			line = strings.ReplaceAll(line, placeholder, text)
+			expanded++
+		}
+	}
+	clear(s.texts)
+	return line, expanded
+}
+
+// pasteReader filters the terminal input read by readline. Pasted text
+// with line breaks is stored in pastes and replaced by a placeholder, so
+// its newlines do not submit the line; single-line pastes are passed on
+// with control characters removed. Without bracketed paste, several lines
+// arriving in one read are taken as a paste, since typing delivers Enter
+// on its own.
+type pasteReader struct {
+	in      io.Reader
+	out     []byte // Filtered input not yet returned
+	pending []byte // Possible start of a paste marker, completed by the next read
+	pasting bool
+	paste   []byte
+}
+
+// newPasteStdin returns the stdin for a readline instance.
+func newPasteStdin() io.ReadCloser {
+	return readline.NewCancelableStdin(&pasteReader{in: readline.Stdin})
+}
+
+func (p *pasteReader) Read(b []byte) (int, error) {
+	buf := make([]byte, 4096)
+	for len(p.out) == 0 {
+		n, err := p.in.Read(buf)
+		if n > 0 {
+			p.process(buf[:n])
+		}
+		if err != nil && len(p.out) == 0 {
+			return 0, err
+		}
+	}
+
+	n := copy(b, p.out)
+	p.out = p.out[n:]
+	return n, nil
+}
+
+// process filters one chunk read from the terminal.
+func (p *pasteReader) process(data []byte) {
+	data = append(p.pending, data...)
+	p.pending = nil
+
+	for len(data) > 0 {
+		if p.pasting {
+			end := bytes.Index(data, []byte(pasteEnd))
+			if end < 0 {
+				keep := markerPrefixLen(data, pasteEnd)
+				p.paste = append(p.paste, data[:len(data)-keep]...)
+				p.pending = append(p.pending, data[len(data)-keep:]...)
+				return
+			}
+			p.paste = append(p.paste, data[:end]...)
+			p.pasting = false
+			p.emitPaste(p.paste)
+			p.paste = nil
+			data = data[end+len(pasteEnd):]
+			continue
+		}
+
+		start := bytes.Index(data, []byte(pasteStart))
+		if start < 0 {
+			keep := markerPrefixLen(data, pasteStart)
+			p.typed(data[:len(data)-keep])
+			p.pending = append(p.pending, data[len(data)-keep:]...)
+			return
+		}
+		p.typed(data[:start])
+		p.pasting = true
+		data = data[start+len(pasteStart):]
+	}
+}
+
+// typed passes on input outside a bracketed paste, unless a line break
+// is followed by more input in the same chunk.
+func (p *pasteReader) typed(data []byte) {
+	trimmed := bytes.TrimRight(data, "\r\n")
+	if bytes.ContainsAny(trimmed, "\r\n") {
+		p.emitPaste(data)
+		return
+	}
+	p.out = append(p.out, data...)
+}
+
+// emitPaste passes on a paste: its cleaned text if it is one line, or a
+// placeholder for it otherwise.
+func (p *pasteReader) emitPaste(data []byte) {
+	text := cleanPaste(string(data))
+	if !strings.Contains(text, "\n") {
+		// A tab would trigger completion
+		p.out = append(p.out, strings.ReplaceAll(text, "\t", "    ")...)
+		return
+	}
+	p.out = append(p.out, pastes.add(text)...)
+}
+
+// cleanPaste normalizes line breaks to "\n", drops trailing line breaks and
+// removes escape sequences and control characters other than tabs, which
+// could otherwise move the cursor or change the terminal's state when the
+// text is shown.
+func cleanPaste(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var sb strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\x1b':
+			// Skip a CSI sequence (ESC [ params final) or a two-byte escape
+			if i+1 < len(runes) && runes[i+1] == '[' {
+				i += 2
+				for i < len(runes) && (runes[i] < 0x40 || runes[i] > 0x7e) {
+					i++
+				}
+			} else {
+				i++
+			}
+		case r == '\n' || r == '\t':
+			sb.WriteRune(r)
+		case unicode.IsControl(r):
+			// Dropped
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// markerPrefixLen returns the length of the longest end of data that is a
+// proper prefix of marker, which the next read may complete.
+func markerPrefixLen(data []byte, marker string) int {
+	for n := min(len(data), len(marker)-1); n > 0; n-- {
+		if bytes.Equal(data[len(data)-n:], []byte(marker[:n])) {
+			return n
+		}
+	}
+	return 0
+}
+
+// readLine reads a line with readline, with bracketed paste enabled while
+// it waits, and puts pasted text back in place of its placeholders. It
+// returns how many pastes the line contained.
+func (r *REPL) readLine() (string, int, error) {
+	terminal := readline.DefaultIsTerminal()
+	if terminal {
+		os.Stdout.WriteString(bracketedPasteOn)
+	}
+	line, err := r.rl.Readline()
+	if terminal {
+		os.Stdout.WriteString(bracketedPasteOff)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	line, count := pastes.expand(line)
+	return line, count, nil
+}
@@ -0,0 +1,113 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notexe/cli-chat/internal/api"
+	"github.com/notexe/cli-chat/internal/ui"
+)
+
+// Plan review choices shown to the user, in menu order.
+const (
+	planRunAll = iota
+	planChoose
+	planRunNone
+)
+
+func (r *REPL) handlePlanCommand(args string) error {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "", "show", "status":
+		state := "DISABLED\nTool calls run as the model requests them."
+		if r.planMode {
+			state = "ENABLED\nEach batch of tool calls is listed for review before any of them runs."
+		}
+		r.displayInfo("Plan mode: " + state)
+		return nil
+
+	case "on", "enable":
+		r.planMode = true
+		r.displaySystem("Plan mode ENABLED. Tool calls are listed and wait for your approval before running.")
+		return nil
+
+	case "off", "disable":
+		r.planMode = false
+		r.displaySystem("Plan mode DISABLED.")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown plan command: %s (use: on, off, show)", args)
+	}
+}
+
+// approvePlan shows a batch of tool calls as a plan and asks whether to
+// run all of them, a chosen subset or none. It returns one flag per call.
+func (r *REPL) approvePlan(calls []api.ToolCall) []bool {
+	approved := make([]bool, len(calls))
+
+	fmt.Println()
+	noun := "tool calls"
+	if len(calls) == 1 {
+		noun = "tool call"
+	}
+	question := fmt.Sprintf("The model plans %d %s. Run them?", len(calls), noun)
+	options := []ui.SelectorOption{
+		planRunAll:  {Label: "Run all", Description: "Run every call listed above"},
+		planChoose:  {Label: "Choose calls", Description: "Pick the calls to run"},
+		planRunNone: {Label: "Run none", Description: "Skip them all and tell the model"},
+	}
+
+	err := r.releaseTerminal(func() error {
+		// Input that names no choice, such as an empty line when stdin
+		// isn't a terminal, runs nothing
+		selector := ui.NewSelector(question, options, false, r.formatter.Colored())
+		selector.SetDefault(planRunNone)
+		choice, err := selector.RunIndexes()
+		if err != nil || len(choice) == 0 {
+			return err
+		}
+
+		switch choice[0] {
+		case planRunAll:
+			for i := range approved {
+				approved[i] = true
+			}
+			return nil
+		case planRunNone:
+			return nil
+		}
+
+		// The selector numbers the options, which tells repeated calls to
+		// one tool apart
+		callOptions := make([]ui.SelectorOption, len(calls))
+		for i, tc := range calls {
+			callOptions[i] = ui.SelectorOption{
+				Label:       tc.Name,
+				Description: compactArgs(tc.Arguments),
+			}
+		}
+		selector = ui.NewSelector("Calls to run", callOptions, true, r.formatter.Colored())
+		selector.SetOptional(true)
+		chosen, err := selector.RunIndexes()
+		if err != nil {
+			return err
+		}
+		for _, i := range chosen {
+			approved[i] = true
+		}
+		return nil
+	})
+	if err != nil {
+		r.displayError(fmt.Errorf("plan approval failed: %w", err))
+		return approved
+	}
+
+	count := 0
+	for _, ok := range approved {
+		if ok {
+			count++
+		}
+	}
+	fmt.Println(selectedResultStyle.Render(fmt.Sprintf("→ Running %d of %d", count, len(calls))))
+	return approved
+}
@@ -0,0 +1,42 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (r *REPL) handleReasoningCommand(args string) error {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "", "show", "status":
+		state := "DISABLED\nOnly final answers are shown."
+		if r.showReasoning {
+			state = "ENABLED\nThe chain of thought of reasoning models (e.g. deepseek-reasoner) is shown before the answer."
+		}
+		r.displayInfo("Reasoning display: " + state)
+		return nil
+
+	case "on", "enable":
+		r.showReasoning = true
+		r.displaySystem("Reasoning display ENABLED. Reasoning models will show their chain of thought.")
+		return nil
+
+	case "off", "disable":
+		r.showReasoning = false
+		r.displaySystem("Reasoning display DISABLED.")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown reasoning command: %s (use: on, off, show)", args)
+	}
+}
+
+// displayReasoning prints the chain of thought when /reasoning is on. It is
+// never added to the history: DeepSeek rejects requests that send it back.
+func (r *REPL) displayReasoning(reasoning string) {
+	if !r.showReasoning || strings.TrimSpace(reasoning) == "" {
+		return
+	}
+	r.status.Hide()
+	fmt.Println()
+	fmt.Println(r.formatter.FormatReasoning(reasoning))
+}
@@ -3,10 +3,14 @@ package repl
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -27,29 +31,83 @@ type REPL struct {
 	formatter  *ui.Formatter
 	status     *ui.StatusDisplay
 	mcpManager *mcp.Manager
+
+	confirmTools  bool            // Ask before each tool call
+	planMode      bool            // Review each batch of tool calls before any runs
+	showReasoning bool            // Display reasoning_content from reasoning models
+	pager         bool            // Page output taller than the terminal
+	autoApprove   map[string]bool // Tools run without asking
+
+	audit *chat.ToolAuditLogger // nil when mcp.audit_log is unset
+
+	schemaRetry bool // A schema correction was already requested for this answer
+
+	pendingImages []api.Image // Attached with /image, sent with the next message
+
+	models []api.ModelInfo // Cached /models result, nil until first listed
+
+	requestMu     sync.Mutex
+	cancelRequest context.CancelFunc // Aborts the request in flight, nil when idle
 }
 
 func NewREPL(session *chat.Session, provider api.Provider, cfg *config.Config) (*REPL, error) {
-	rl, err := setupReadline()
+	rl, err := setupReadline(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup readline: %w", err)
 	}
 
+	theme, err := ui.LoadTheme(cfg.UI.Theme, cfg.UI.Colors)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ui theme: %w", err)
+	}
+	applyTheme(theme)
+
 	formatter := ui.NewFormatter(cfg.UI.ColoredOutput, provider.Name())
+	if len(cfg.DeepSeek.Pricing) > 0 {
+		pricing := make(map[string]ui.ModelPricing, len(cfg.DeepSeek.Pricing))
+		for model, p := range cfg.DeepSeek.Pricing {
+			pricing[model] = ui.ModelPricing{
+				InputPer1M:         p.Input,
+				InputCacheHitPer1M: p.InputCacheHit,
+				OutputPer1M:        p.Output,
+			}
+		}
+		formatter.SetPricing(pricing)
+	}
 	status := ui.NewStatusDisplay(formatter, true)
 
+	redactKeys := cfg.MCP.AuditRedact
+	if len(redactKeys) == 0 {
+		redactKeys = chat.DefaultAuditRedactKeys
+	}
+	audit, err := chat.NewToolAuditLogger(cfg.MCP.AuditLog, redactKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tool audit log disabled: %v\n", err)
+	}
+
+	autoApprove := make(map[string]bool, len(cfg.MCP.AutoApprove))
+	for _, name := range cfg.MCP.AutoApprove {
+		autoApprove[name] = true
+	}
+
 	return &REPL{
-		session:    session,
-		provider:   provider,
-		config:     cfg,
-		rl:         rl,
-		formatter:  formatter,
-		status:     status,
-		mcpManager: nil, // Set via SetMCPManager if MCP is enabled
+		session:      session,
+		provider:     provider,
+		config:       cfg,
+		rl:           rl,
+		formatter:    formatter,
+		status:       status,
+		mcpManager:   nil, // Set via SetMCPManager if MCP is enabled
+		confirmTools: cfg.MCP.Confirm,
+		planMode:     cfg.MCP.Plan,
+		pager:        cfg.UI.Pager,
+		autoApprove:  autoApprove,
+		audit:        audit,
 	}, nil
 }
 
-// SetMCPManager sets the MCP manager for tool integration.
+// SetMCPManager sets the MCP manager for tool integration and the tools
+// prompt for the tools it provides. It is called again after /mcp reload.
 func (r *REPL) SetMCPManager(m *mcp.Manager) {
 	r.mcpManager = m
 
@@ -71,13 +129,12 @@ func (r *REPL) SetMCPManager(m *mcp.Manager) {
 		toolsPrompt += chat.CodeIndexToolsPrompt
 	}
 
-	if toolsPrompt != "" {
-		r.session.SetToolsPrompt(toolsPrompt)
-	}
+	r.session.SetToolsPrompt(toolsPrompt)
 }
 
 func (r *REPL) Start(ctx context.Context) error {
 	defer r.rl.Close()
+	defer r.audit.Close()
 
 	r.displayWelcome()
 
@@ -97,7 +154,10 @@ func (r *REPL) Start(ctx context.Context) error {
 
 		isCommand, command, args := r.parseCommand(input)
 		if isCommand {
-			if err := r.handleCommand(ctx, command, args); err != nil {
+			err := r.runRequest(ctx, func(ctx context.Context) error {
+				return r.handleCommand(ctx, command, args)
+			})
+			if err != nil {
 				r.displayError(err)
 			}
 
@@ -108,7 +168,10 @@ func (r *REPL) Start(ctx context.Context) error {
 			continue
 		}
 
-		if err := r.handleMessage(ctx, input); err != nil {
+		err = r.runRequest(ctx, func(ctx context.Context) error {
+			return r.handleMessage(ctx, input)
+		})
+		if err != nil {
 			r.displayError(err)
 		}
 	}
@@ -119,8 +182,19 @@ func (r *REPL) Stop() {
 }
 
 func (r *REPL) handleMessage(ctx context.Context, message string) error {
+	if err := r.checkBudget(); err != nil {
+		return err
+	}
+
 	// Phase 1: Add user message
-	r.session.AddUserMessage(message)
+	if len(r.pendingImages) > 0 {
+		r.session.AddUserMessageWithImages(message, r.pendingImages)
+		r.pendingImages = nil
+		// Images go with this turn only; later requests get a note instead
+		defer r.session.DropImages()
+	} else {
+		r.session.AddUserMessage(message)
+	}
 
 	// Check if clarify mode is enabled
 	if r.session.IsClarifyEnabled() {
@@ -137,7 +211,7 @@ func (r *REPL) handleMessageWithClarify(ctx context.Context, originalMessage str
 
 	req := r.session.BuildAPIRequest()
 	start := time.Now()
-	response, err := r.provider.SendMessage(ctx, req)
+	response, err := r.callProvider(ctx, chat.OpChat, req)
 	duration := time.Since(start)
 	if err != nil {
 		return fmt.Errorf("API request failed: %w", err)
@@ -206,20 +280,18 @@ func (r *REPL) sendMessageAndDisplay(ctx context.Context, includeClarify bool) e
 	r.status.Show("Generating response...")
 
 	start := time.Now()
-	response, err := r.provider.SendMessage(ctx, req)
+	response, err := r.callProvider(ctx, chat.OpChat, req)
 	if err != nil {
 		r.status.Hide()
 		return fmt.Errorf("API request failed: %w", err)
 	}
 
 	// Track cumulative usage across all API calls in this interaction
-	cumulativeUsage := api.Usage{
-		InputTokens:  response.Usage.InputTokens,
-		OutputTokens: response.Usage.OutputTokens,
-	}
+	cumulativeUsage := response.Usage
 	apiCallCount := 1
 
 	// Handle tool calls loop
+	imagesAttached := false
 	for len(response.ToolCalls) > 0 {
 		r.status.Hide()
 
@@ -233,27 +305,40 @@ func (r *REPL) sendMessageAndDisplay(ctx context.Context, includeClarify bool) e
 		// This is required by DeepSeek API - tool results must follow a message with tool_calls
 		r.session.AddAssistantMessageWithToolCalls(response.Content, response.ToolCalls)
 
-		// Process each tool call
+		r.displayReasoning(response.ReasoningContent)
+
+		// Execute the approved tool calls via MCP, independent calls run concurrently
 		for _, tc := range response.ToolCalls {
 			r.displayToolCall(tc.Name, tc.Arguments)
+		}
+		approved := r.approveToolCalls(response.ToolCalls)
+		outcomes := r.executeToolCalls(ctx, response.ToolCalls, approved)
 
-			// Execute tool via MCP
-			result, err := r.mcpManager.CallTool(ctx, tc.Name, tc.Arguments)
+		// Results are added in call order to match the tool_call IDs
+		var images toolImages
+		for i, tc := range response.ToolCalls {
+			result, err := outcomes[i].result, outcomes[i].err
 			if err != nil {
 				result = fmt.Sprintf("Error: %v", err)
 			}
 
-			r.displayToolResult(tc.Name, result)
+			if errors.Is(err, mcp.ErrToolTimeout) {
+				r.displayToolTimeout(err)
+			} else {
+				r.displayToolResult(tc.Name, result)
+			}
 
-			// Truncate large results to prevent context overflow
-			// 32K chars ≈ 8K tokens, reasonable limit for tool results
-			const maxToolResultSize = 32000
-			if len(result) > maxToolResultSize {
-				result = result[:maxToolResultSize] + "\n\n[... truncated - result too large]"
+			if len(outcomes[i].images) > 0 {
+				result += r.collectToolImages(ctx, &images, tc.Name, outcomes[i].images)
 			}
 
-			// Add tool result to session
-			r.session.AddToolResult(tc.ID, tc.Name, result)
+			// Truncate large results to prevent context overflow
+			r.session.AddToolResult(tc.ID, tc.Name, mcp.TruncateResult(result))
+		}
+		if images.attach(r.session) && !imagesAttached {
+			imagesAttached = true
+			// Images go with this turn only; later requests get a note instead
+			defer r.session.DropImages()
 		}
 
 		// Send follow-up request with tool results
@@ -268,14 +353,13 @@ func (r *REPL) sendMessageAndDisplay(ctx context.Context, includeClarify bool) e
 		}
 		req.Tools = toolsForResults
 
-		response, err = r.provider.SendMessage(ctx, req)
+		response, err = r.callProvider(ctx, chat.OpToolFollowUp, req)
 		if err != nil {
 			return fmt.Errorf("API request failed: %w", err)
 		}
 
 		// Accumulate usage from this API call
-		cumulativeUsage.InputTokens += response.Usage.InputTokens
-		cumulativeUsage.OutputTokens += response.Usage.OutputTokens
+		cumulativeUsage.Add(response.Usage)
 		apiCallCount++
 	}
 
@@ -293,7 +377,78 @@ func (r *REPL) sendMessageAndDisplay(ctx context.Context, includeClarify bool) e
 	// Update token tracking from response for next iteration
 	r.session.UpdateTokensFromResponse(cumulativeUsage)
 
-	return nil
+	return r.checkFormattedResponse(ctx, response.Content)
+}
+
+// checkFormattedResponse validates the response when strict format mode is
+// on. On failure it shows the parse error and offers to re-request a
+// corrected response.
+func (r *REPL) checkFormattedResponse(ctx context.Context, content string) error {
+	if schema := r.session.GetResponseSchema(); schema != nil {
+		return r.checkSchemaResponse(ctx, schema, content)
+	}
+
+	if r.session.GetFormatPrompt() == "" || !r.session.IsStrictFormat() {
+		return nil
+	}
+
+	validate, correction, label := chat.ValidateJSONResponse, chat.JSONCorrectionPrompt, "JSON"
+	if r.session.GetFormatName() == "yaml" {
+		validate, correction, label = chat.ValidateYAMLResponse, chat.YAMLCorrectionPrompt, "YAML"
+	}
+
+	_, err := validate(content)
+	if err == nil {
+		return nil
+	}
+
+	r.displaySystem("Invalid " + label + " response: " + err.Error())
+
+	if !r.askYesNo("Re-request with a correction?") {
+		return nil
+	}
+	r.session.AddUserMessage(correction(err))
+	return r.sendMessageAndDisplay(ctx, false)
+}
+
+// askYesNo prints question with a [y/N] hint and reads the answer. Anything
+// but "y" or "yes", including a read error, counts as no.
+func (r *REPL) askYesNo(question string) bool {
+	fmt.Print(r.formatter.FormatInfo(question + " [y/N] "))
+	r.rl.SetPrompt("")
+	answer, _, err := r.readLine()
+	r.rl.SetPrompt("you > ")
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkSchemaResponse validates the response against the user's schema
+// and, on failure, re-requests it once with the validation errors.
+func (r *REPL) checkSchemaResponse(ctx context.Context, schema *chat.ResponseSchema, content string) error {
+	_, err := schema.Validate(content)
+	if err == nil {
+		return nil
+	}
+
+	if r.schemaRetry {
+		r.displaySystem("Response still does not match the schema: " + err.Error())
+		return nil
+	}
+
+	r.displaySystem("Response does not match the schema, re-requesting once: " + err.Error())
+	r.schemaRetry = true
+	defer func() { r.schemaRetry = false }()
+
+	r.session.AddUserMessage(chat.SchemaCorrectionPrompt(err))
+	return r.sendMessageAndDisplay(ctx, false)
 }
 
 // findAskUserCall finds an ask_user tool call in the list
@@ -325,7 +480,7 @@ func (r *REPL) handleAskUserToolCallWithUsage(ctx context.Context, response *api
 	// Display any text content from the response
 	if response.Content != "" {
 		fmt.Println()
-		fmt.Println(r.formatter.FormatAssistantMessage(response.Content))
+		r.printPaged(r.formatter.FormatAssistantMessage(response.Content))
 	}
 
 	// Display token usage for the request
@@ -338,20 +493,10 @@ func (r *REPL) handleAskUserToolCallWithUsage(ctx context.Context, response *api
 	}
 
 	// Collect answers for all questions
-	var allAnswers [][]string
+	var allAnswers []chat.AskUserAnswer
 	for _, q := range askReq.Questions {
-		// Convert options to string slice
-		options := make([]string, len(q.Options))
-		for i, opt := range q.Options {
-			if opt.Description != "" {
-				options[i] = opt.Label + " - " + opt.Description
-			} else {
-				options[i] = opt.Label
-			}
-		}
-
 		// Ask the question using interactive UI
-		answers, err := r.AskUserQuestion(q.Question, options, q.MultiSelect)
+		answers, err := r.AskUserQuestion(q)
 		if err != nil {
 			return fmt.Errorf("failed to get user answer: %w", err)
 		}
@@ -405,20 +550,10 @@ func (r *REPL) handleAskUserResponseWithUsage(ctx context.Context, response *api
 	}
 
 	// Collect answers for all questions
-	var allAnswers [][]string
+	var allAnswers []chat.AskUserAnswer
 	for _, q := range askReq.Questions {
-		// Convert to simple string slice for options
-		options := make([]string, len(q.Options))
-		for i, opt := range q.Options {
-			if opt.Description != "" {
-				options[i] = opt.Label + " - " + opt.Description
-			} else {
-				options[i] = opt.Label
-			}
-		}
-
 		// Ask the question using the interactive UI
-		answers, err := r.AskUserQuestion(q.Question, options, q.MultiSelect)
+		answers, err := r.AskUserQuestion(q)
 		if err != nil {
 			return fmt.Errorf("failed to get user answer: %w", err)
 		}
@@ -438,12 +573,148 @@ func (r *REPL) handleAskUserResponseWithUsage(ctx context.Context, response *api
 	return r.sendMessageAndDisplay(ctx, false)
 }
 
+// maxParallelToolCalls bounds how many tool calls of one turn run at once.
+const maxParallelToolCalls = 4
+
+// toolOutcome is the result of one tool call.
+type toolOutcome struct {
+	result string
+	images []mcp.ToolImage
+	err    error
+}
+
+// executeToolCalls runs the approved tool calls of one assistant turn.
+// Calls to different servers run concurrently, and so do consecutive
+// read-only calls to one server; otherwise calls keep the model's order,
+// so a write is never overtaken by a later read of the same file. At most
+// maxParallelToolCalls run at once. Denied calls get deniedToolResult.
+// Outcomes are returned in call order.
+//
+// The status spinner runs until the last call returns, showing the tool
+// name and the latest progress message the tool's server reports.
+func (r *REPL) executeToolCalls(ctx context.Context, calls []api.ToolCall, approved []bool) []toolOutcome {
+	outcomes := make([]toolOutcome, len(calls))
+	sem := make(chan struct{}, maxParallelToolCalls)
+
+	running := toolRunningStatus(calls, approved)
+	if running != "" {
+		r.status.Show(running)
+		defer r.status.Hide()
+	}
+
+	for i := range calls {
+		if !approved[i] {
+			outcomes[i] = toolOutcome{result: deniedToolResult}
+		}
+	}
+
+	if r.mcpManager == nil {
+		for i, tc := range calls {
+			if approved[i] {
+				outcomes[i] = toolOutcome{err: fmt.Errorf("tool %s is not available", tc.Name)}
+			}
+		}
+		return outcomes
+	}
+
+	call := func(tc api.ToolCall) toolOutcome {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+
+		callCtx := mcp.WithProgress(ctx, func(msg string) {
+			r.status.Update(fmt.Sprintf("%s: %s", tc.Name, msg))
+		})
+		start := time.Now()
+		output, err := r.mcpManager.CallToolOutput(callCtx, tc.Name, tc.Arguments)
+		r.audit.Log(tc.Name, tc.Arguments, output.Text, time.Since(start), err)
+		return toolOutcome{result: output.Text, images: output.Images, err: err}
+	}
+
+	var lanes sync.WaitGroup
+	for _, lane := range toolLanes(calls, approved, r.mcpManager.ToolServer) {
+		lanes.Add(1)
+		go func(lane [][]int) {
+			defer lanes.Done()
+			for _, step := range lane {
+				var wg sync.WaitGroup
+				for _, i := range step {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						outcomes[i] = call(calls[i])
+					}(i)
+				}
+				wg.Wait()
+			}
+		}(lane)
+	}
+	lanes.Wait()
+
+	return outcomes
+}
+
+// toolLanes groups the indexes of the approved calls into lanes that run
+// concurrently, one per server. A lane is a list of steps run one after
+// another, and the calls of a step run together. Only consecutive
+// read-only calls share a step. Calls to unknown tools get a lane of their
+// own, since they fail without reaching a server.
+func toolLanes(calls []api.ToolCall, approved []bool, toolServer func(string) (string, bool, bool)) [][][]int {
+	var lanes [][][]int
+	laneOf := make(map[string]int)
+	lastReadOnly := make(map[string]bool) // Whether the lane's last step is read-only calls
+
+	for i, tc := range calls {
+		if !approved[i] {
+			continue
+		}
+		server, readOnly, ok := toolServer(tc.Name)
+		if !ok {
+			lanes = append(lanes, [][]int{{i}})
+			continue
+		}
+
+		n, exists := laneOf[server]
+		if !exists {
+			n = len(lanes)
+			laneOf[server] = n
+			lanes = append(lanes, nil)
+		}
+		steps := lanes[n]
+		if readOnly && lastReadOnly[server] {
+			steps[len(steps)-1] = append(steps[len(steps)-1], i)
+		} else {
+			lanes[n] = append(steps, []int{i})
+		}
+		lastReadOnly[server] = readOnly
+	}
+	return lanes
+}
+
+// toolRunningStatus returns the status shown while the approved calls run:
+// the tool name for a single call, the count for several, or "" for none.
+func toolRunningStatus(calls []api.ToolCall, approved []bool) string {
+	var names []string
+	for i, tc := range calls {
+		if approved[i] {
+			names = append(names, tc.Name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("Running %s...", names[0])
+	default:
+		return fmt.Sprintf("Running %d tools...", len(names))
+	}
+}
+
 func (r *REPL) displayToolCall(name, args string) {
 	toolStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("215")).
+		Foreground(ui.CurrentTheme().Tool).
 		Bold(true)
 	argsStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
+		Foreground(ui.CurrentTheme().Status)
 
 	fmt.Printf("\n%s %s\n", toolStyle.Render("Tool:"), name)
 	if args != "" && args != "{}" {
@@ -453,7 +724,13 @@ func (r *REPL) displayToolCall(name, args string) {
 
 func (r *REPL) displayToolResult(name, result string) {
 	resultLabelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("114"))
+		Foreground(ui.CurrentTheme().Success)
+
+	// With the pager on, long results are paged in full instead
+	if r.pager {
+		r.printPaged(fmt.Sprintf("  %s %s", resultLabelStyle.Render("Result:"), result))
+		return
+	}
 
 	// Truncate long results for display
 	display := result
@@ -465,33 +742,37 @@ func (r *REPL) displayToolResult(name, result string) {
 	os.Stdout.Sync() // Flush immediately
 }
 
+// displayToolTimeout reports a tool call that was abandoned after its
+// timeout, so it is not mistaken for an ordinary tool error.
+func (r *REPL) displayToolTimeout(err error) {
+	timeoutStyle := lipgloss.NewStyle().
+		Foreground(ui.CurrentTheme().Error).
+		Bold(true)
+
+	fmt.Printf("  %s %v\n", timeoutStyle.Render("Timed out:"), err)
+	os.Stdout.Sync()
+}
+
 // performSummarization compresses the conversation history using AI summarization.
 func (r *REPL) performSummarization(ctx context.Context) error {
 	r.status.Show("Compressing history...")
 	defer r.status.Hide()
 
-	// Get messages to summarize (keep last 4 message pairs = 8 messages)
-	toSummarize, toKeep := r.session.GetMessagesToSummarize(4)
+	opts := r.session.GetSummaryOptions()
+
+	// Get messages to summarize, keeping the last pairs verbatim
+	toSummarize, toKeep := r.session.GetMessagesToSummarize(opts.KeepLastPairs)
 	if len(toSummarize) == 0 {
 		return nil // Nothing to summarize
 	}
 
-	// Build summarization request
-	req := chat.BuildSummarizationRequest(
-		toSummarize,
-		r.session.GetModelName(),
-		r.session.GetMaxTokens(),
-		r.session.GetTemperature(),
-	)
-
-	// Send summarization request
-	response, err := r.provider.SendMessage(ctx, req)
+	summary, err := r.summarize(ctx, toSummarize, opts)
 	if err != nil {
-		return fmt.Errorf("summarization API request failed: %w", err)
+		return err
 	}
 
 	// Create summary message and apply it
-	summaryMsg := chat.FormatSummaryMessage(response.Content)
+	summaryMsg := chat.FormatSummaryMessage(summary)
 	r.session.ApplySummary(summaryMsg, len(toKeep))
 
 	// Reset lastInputTokens — will be updated after next API call
@@ -501,6 +782,45 @@ func (r *REPL) performSummarization(ctx context.Context) error {
 	return nil
 }
 
+// summarize returns a summary of messages. The map-reduce strategy
+// summarizes chunks separately and then merges the partial summaries, for
+// histories too long for one request.
+func (r *REPL) summarize(ctx context.Context, messages []api.Message, opts chat.SummaryOptions) (string, error) {
+	model := r.session.GetModelName()
+	maxTokens := r.session.GetMaxTokens()
+	temperature := r.session.GetTemperature()
+
+	chunks := [][]api.Message{messages}
+	if opts.Strategy == config.SummaryStrategyMapReduce {
+		chunks = chat.ChunkMessages(messages, opts.ChunkTokens)
+	}
+
+	summaries := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		if len(chunks) > 1 {
+			r.status.Update(fmt.Sprintf("Compressing history (part %d/%d)...", i+1, len(chunks)))
+		}
+		req := chat.BuildSummarizationRequest(chunk, opts.Prompt, model, maxTokens, temperature)
+		response, err := r.callProvider(ctx, chat.OpSummarization, req)
+		if err != nil {
+			return "", fmt.Errorf("summarization API request failed: %w", err)
+		}
+		summaries = append(summaries, response.Content)
+	}
+
+	if len(summaries) == 1 {
+		return summaries[0], nil
+	}
+
+	r.status.Update("Merging summaries...")
+	req := chat.BuildMergeSummariesRequest(summaries, opts.Prompt, model, maxTokens, temperature)
+	response, err := r.callProvider(ctx, chat.OpSummarization, req)
+	if err != nil {
+		return "", fmt.Errorf("summarization API request failed: %w", err)
+	}
+	return response.Content, nil
+}
+
 func (r *REPL) handleCommand(ctx context.Context, command, args string) error {
 	switch command {
 	case "/help", "/h":
@@ -550,6 +870,21 @@ func (r *REPL) handleCommand(ctx context.Context, command, args string) error {
 		r.displayInfo(fmt.Sprintf("Provider: %s\nModel: %s", r.provider.Name(), r.config.Model.Name))
 		return nil
 
+	case "/profile":
+		profile := r.config.Profile
+		if profile == "" {
+			profile = "(none)"
+		}
+		info := "Profile: " + profile
+		if names := r.config.ProfileNames(); len(names) > 0 {
+			info += fmt.Sprintf("\nAvailable: %s\nSwitch with: chat --profile <name>", strings.Join(names, ", "))
+		}
+		r.displayInfo(info)
+		return nil
+
+	case "/models":
+		return r.handleModelsCommand(ctx, args)
+
 	case "/format", "/f":
 		return r.handleFormatCommand(args)
 
@@ -562,6 +897,15 @@ func (r *REPL) handleCommand(ctx context.Context, command, args string) error {
 	case "/file":
 		return r.handleFileCommand(ctx, args)
 
+	case "/edit", "/e":
+		return r.handleEditCommand(ctx, args)
+
+	case "/image", "/img":
+		return r.handleImageCommand(ctx, args)
+
+	case "/paste":
+		return r.handlePasteCommand(ctx, args)
+
 	case "/context", "/ctx":
 		return r.handleContextCommand(args)
 
@@ -571,6 +915,42 @@ func (r *REPL) handleCommand(ctx context.Context, command, args string) error {
 	case "/askuser", "/ask":
 		return r.handleAskUserCommand(args)
 
+	case "/reasoning":
+		return r.handleReasoningCommand(args)
+
+	case "/confirm":
+		return r.handleConfirmCommand(args)
+
+	case "/plan":
+		return r.handlePlanCommand(args)
+
+	case "/pager":
+		return r.handlePagerCommand(args)
+
+	case "/export":
+		return r.handleExportCommand(args)
+
+	case "/save":
+		return r.handleSaveCommand(args)
+
+	case "/load":
+		return r.handleLoadCommand(args)
+
+	case "/history":
+		return r.handleHistoryCommand(args)
+
+	case "/cost":
+		return r.handleCostCommand()
+
+	case "/budget":
+		return r.handleBudgetCommand(args)
+
+	case "/search":
+		return r.handleSearchCommand(args)
+
+	case "/checkpoint", "/cp":
+		return r.handleCheckpointCommand(args)
+
 	default:
 		return fmt.Errorf("unknown command: %s (type /help for available commands)", command)
 	}
@@ -578,7 +958,7 @@ func (r *REPL) handleCommand(ctx context.Context, command, args string) error {
 
 func (r *REPL) handleFormatCommand(args string) error {
 	if args == "" {
-		return fmt.Errorf("usage: /format <json|show|clear>")
+		return fmt.Errorf("usage: /format <json|yaml|schema <file>|strict [on|off]|show|clear>")
 	}
 
 	parts := strings.Fields(args)
@@ -594,16 +974,96 @@ func (r *REPL) handleFormatCommand(args string) error {
 		if err := r.session.SetFormatPrompt(template.Prompt); err != nil {
 			return err
 		}
+		r.session.SetFormatName("json")
+
+		if r.session.IsJSONMode() {
+			r.displaySystem("JSON format template applied. Native JSON mode enabled (response_format: json_object).")
+			return nil
+		}
 
 		r.displaySystem("JSON format template applied. Responses will be in structured JSON format.")
 		return nil
 
+	case "yaml", "yml":
+		template, err := chat.GetFormatTemplate("yaml")
+		if err != nil {
+			return err
+		}
+
+		if err := r.session.SetFormatPrompt(template.Prompt); err != nil {
+			return err
+		}
+		r.session.SetFormatName("yaml")
+
+		r.displaySystem("YAML format template applied. Responses will be in structured YAML format.")
+		return nil
+
+	case "schema":
+		if len(parts) < 2 {
+			return fmt.Errorf("usage: /format schema <file.json>")
+		}
+		path := strings.TrimSpace(strings.TrimPrefix(args, parts[0]))
+
+		schema, err := chat.LoadResponseSchema(path)
+		if err != nil {
+			return err
+		}
+		if err := r.session.SetResponseSchema(schema); err != nil {
+			return err
+		}
+
+		msg := fmt.Sprintf("Response schema loaded from %s. Responses are validated and re-requested once if they do not match.", path)
+		if r.session.IsJSONMode() {
+			msg += " Native JSON mode enabled."
+		}
+		r.displaySystem(msg)
+		return nil
+
+	case "strict":
+		enabled := true
+		if len(parts) > 1 {
+			switch strings.ToLower(parts[1]) {
+			case "on":
+			case "off":
+				enabled = false
+			default:
+				return fmt.Errorf("usage: /format strict [on|off]")
+			}
+		}
+
+		if enabled && r.session.GetFormatPrompt() == "" {
+			return fmt.Errorf("no format template set, use /format json or /format yaml first")
+		}
+
+		r.session.SetStrictFormat(enabled)
+		if enabled {
+			r.displaySystem("Strict format validation enabled. Invalid responses will be reported with an option to re-request.")
+		} else {
+			r.displaySystem("Strict format validation disabled.")
+		}
+		return nil
+
 	case "show":
 		current := r.session.GetFormatPrompt()
 		if current == "" {
 			r.displayInfo("No format template set (using default behavior).")
 		} else {
-			r.displayInfo("Current format: JSON")
+			var notes []string
+			if r.session.IsJSONMode() {
+				notes = append(notes, "native JSON mode")
+			}
+			if r.session.IsStrictFormat() {
+				notes = append(notes, "strict validation")
+			}
+			name := strings.ToUpper(r.session.GetFormatName())
+			if schema := r.session.GetResponseSchema(); schema != nil {
+				name = "schema from " + schema.Path
+			}
+			if len(notes) > 0 {
+				r.displayInfo("Current format: " + name + " (" + strings.Join(notes, ", ") + ")")
+			} else {
+				r.displayInfo("Current format: " + name)
+			}
 		}
 		return nil
 
@@ -613,7 +1073,7 @@ func (r *REPL) handleFormatCommand(args string) error {
 		return nil
 
 	default:
-		return fmt.Errorf("unknown format: %s (available: json)", subcommand)
+		return fmt.Errorf("unknown format: %s (available: json, yaml, schema <file>)", subcommand)
 	}
 }
 
@@ -668,30 +1128,12 @@ func (r *REPL) handleTempCommand(args string) error {
 	return nil
 }
 
-func (r *REPL) handleFileCommand(ctx context.Context, args string) error {
-	if args == "" {
-		return fmt.Errorf("usage: /file <filename>")
-	}
-
-	filename := strings.TrimSpace(args)
-
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return fmt.Errorf("failed to read file %s: %w", filename, err)
-	}
-
-	fileContent := string(content)
-	if fileContent == "" {
-		return fmt.Errorf("file %s is empty", filename)
-	}
-
-	r.displayInfo(fmt.Sprintf("Loaded %d characters from %s", len(fileContent), filename))
-
-	return r.handleMessage(ctx, fileContent)
-}
-
 func (r *REPL) handleContextCommand(args string) error {
-	subcommand := strings.ToLower(strings.TrimSpace(args))
+	fields := strings.Fields(args)
+	subcommand := ""
+	if len(fields) > 0 {
+		subcommand = strings.ToLower(fields[0])
+	}
 
 	switch subcommand {
 	case "", "show", "status":
@@ -705,7 +1147,10 @@ func (r *REPL) handleContextCommand(args string) error {
 
 		info := fmt.Sprintf("Context window: %d / %d tokens (%.1f%%)\n", used, limit, pct)
 		info += fmt.Sprintf("Summarization threshold: %d tokens (%.0f%%)\n", threshold, r.session.GetContextManager().GetSummarizeAt()*100)
-		info += fmt.Sprintf("Auto-summarization: %s", autoStatus)
+		info += fmt.Sprintf("Auto-summarization: %s\n", autoStatus)
+		opts := r.session.GetSummaryOptions()
+		info += fmt.Sprintf("Summarization: %s, keeps last %d pairs\n", opts.Strategy, opts.KeepLastPairs)
+		info += r.budgetStatus()
 
 		r.displayInfo(info)
 		return nil
@@ -720,8 +1165,22 @@ func (r *REPL) handleContextCommand(args string) error {
 		r.displaySystem("Auto-summarization DISABLED.")
 		return nil
 
+	case "keep":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: /context keep <pairs>")
+		}
+		pairs, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid number of pairs: %s", fields[1])
+		}
+		if err := r.session.SetKeepLastPairs(pairs); err != nil {
+			return err
+		}
+		r.displaySystem(fmt.Sprintf("Summarization will keep the last %d message pairs.", pairs))
+		return nil
+
 	default:
-		return fmt.Errorf("unknown context command: %s (use: show, on, off)", subcommand)
+		return fmt.Errorf("unknown context command: %s (use: show, on, off, keep <n>)", subcommand)
 	}
 }
 
@@ -731,7 +1190,11 @@ func (r *REPL) handleMCPCommand(args string) error {
 		return nil
 	}
 
-	subcommand := strings.ToLower(strings.TrimSpace(args))
+	fields := strings.Fields(args)
+	subcommand := ""
+	if len(fields) > 0 {
+		subcommand = strings.ToLower(fields[0])
+	}
 
 	switch subcommand {
 	case "", "status", "show":
@@ -760,14 +1223,122 @@ func (r *REPL) handleMCPCommand(args string) error {
 		for _, t := range tools {
 			info += fmt.Sprintf("  - %s: %s\n", t.Name, t.Description)
 		}
+		if disabled := r.mcpManager.DisabledTools(); len(disabled) > 0 {
+			info += fmt.Sprintf("Disabled: %s\n", strings.Join(disabled, ", "))
+		}
 		r.displayInfo(info)
 		return nil
 
+	case "disable", "enable":
+		if len(fields) < 2 {
+			return fmt.Errorf("usage: /mcp %s <tool>", subcommand)
+		}
+		name := fields[1]
+		if subcommand == "disable" {
+			if err := r.mcpManager.DisableTool(name); err != nil {
+				return err
+			}
+			r.displaySystem(fmt.Sprintf("Tool %s disabled.", name))
+			return nil
+		}
+		if err := r.mcpManager.EnableTool(name); err != nil {
+			return err
+		}
+		r.displaySystem(fmt.Sprintf("Tool %s enabled.", name))
+		return nil
+
+	case "reload":
+		return r.reloadMCP()
+
+	case "restart":
+		if len(fields) < 2 {
+			return fmt.Errorf("usage: /mcp restart <server>")
+		}
+		name := fields[1]
+		r.displayInfo(fmt.Sprintf("Restarting MCP server %s...", name))
+		if err := r.mcpManager.RestartServer(name); err != nil {
+			return fmt.Errorf("failed to restart %s: %w", name, err)
+		}
+		r.displayInfo(fmt.Sprintf("MCP server %s restarted: %d tools available", name, r.mcpManager.ServerToolCount()[name]))
+		return nil
+
 	default:
-		return fmt.Errorf("unknown mcp command: %s (use: status, tools)", subcommand)
+		return fmt.Errorf("unknown mcp command: %s (use: status, tools, reload, restart <server>, disable <tool>, enable <tool>)", subcommand)
 	}
 }
 
+// mcpReloadTimeout bounds connecting the servers added or changed by
+// /mcp reload.
+const mcpReloadTimeout = 60 * time.Second
+
+// reloadMCP re-reads the MCP config file and connects, restarts or stops
+// servers to match it. Servers that fail to connect or have problems in
+// the file are reported and the others keep working. If the file has no
+// usable server at all, nothing changes.
+func (r *REPL) reloadMCP() error {
+	cfg := *r.config
+	cfg.MCP.Servers = nil
+	issues := cfg.LoadMCPServers()
+	if len(cfg.MCP.Servers) == 0 && len(issues) > 0 {
+		return fmt.Errorf("failed to reload %s, keeping the current servers: %w", cfg.GetMCPConfigPath(), &config.ValidationError{Issues: issues})
+	}
+	for _, issue := range issues {
+		r.displayError(issue)
+	}
+
+	configs := make([]mcp.ServerConfig, 0, len(cfg.MCP.Servers))
+	for _, srv := range cfg.MCP.Servers {
+		configs = append(configs, mcp.ServerConfig{
+			Name:          srv.Name,
+			Command:       srv.Command,
+			Args:          srv.Args,
+			Env:           srv.Env,
+			URL:           srv.URL,
+			Headers:       srv.Headers,
+			DisabledTools: srv.DisabledTools,
+			CallTimeout:   time.Duration(srv.CallTimeout) * time.Second,
+		})
+	}
+
+	r.status.Show("Reloading MCP servers...")
+	ctx, cancel := context.WithTimeout(context.Background(), mcpReloadTimeout)
+	result := r.mcpManager.Reload(ctx, configs)
+	cancel()
+	r.status.Hide()
+
+	r.config.MCP.Servers = cfg.MCP.Servers
+	r.SetMCPManager(r.mcpManager)
+
+	counts := r.mcpManager.ServerToolCount()
+	var lines []string
+	for _, name := range result.Added {
+		lines = append(lines, fmt.Sprintf("  + %s: %d tools", name, counts[name]))
+	}
+	for _, name := range result.Restarted {
+		lines = append(lines, fmt.Sprintf("  ~ %s: restarted, %d tools", name, counts[name]))
+	}
+	for _, name := range result.Removed {
+		lines = append(lines, fmt.Sprintf("  - %s: stopped", name))
+	}
+	if len(lines) == 0 && len(result.Failed) == 0 {
+		r.displayInfo("MCP servers unchanged.")
+		return nil
+	}
+	if len(lines) > 0 {
+		r.displayInfo("MCP servers reloaded:\n" + strings.Join(lines, "\n"))
+	}
+
+	failed := make([]string, 0, len(result.Failed))
+	for name := range result.Failed {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		r.displayError(fmt.Errorf("MCP server %s: %w", name, result.Failed[name]))
+	}
+	return nil
+}
+
 func (r *REPL) handleAskUserCommand(args string) error {
 	subcommand := strings.ToLower(strings.TrimSpace(args))
 
@@ -795,6 +1366,214 @@ func (r *REPL) handleAskUserCommand(args string) error {
 	}
 }
 
+func (r *REPL) handleExportCommand(args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: /export <file.md|file.html>")
+	}
+
+	if r.session.IsEmpty() {
+		return fmt.Errorf("nothing to export: conversation is empty")
+	}
+
+	path := strings.TrimSpace(args)
+	if err := r.session.Export(path); err != nil {
+		return err
+	}
+
+	format := "Markdown"
+	if chat.DetectExportFormat(path) == chat.ExportHTML {
+		format = "HTML"
+	}
+	r.displaySystem(fmt.Sprintf("Exported %d messages as %s to %s", r.session.MessageCount(), format, path))
+	return nil
+}
+
+// handleSaveCommand writes the conversation to a file of the user's
+// choosing, in the history file format, so it can be restored with /load.
+func (r *REPL) handleSaveCommand(args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: /save <file.json>")
+	}
+
+	if r.session.IsEmpty() {
+		return fmt.Errorf("nothing to save: conversation is empty")
+	}
+
+	path := config.ExpandPath(strings.TrimSpace(args))
+	if _, err := os.Stat(path); err == nil && !r.askYesNo(fmt.Sprintf("%s already exists. Overwrite it?", path)) {
+		r.displayInfo("Save cancelled.")
+		return nil
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	if err := r.session.Save(path); err != nil {
+		return err
+	}
+	r.displaySystem(fmt.Sprintf("Saved %d messages to %s", r.session.MessageCount(), path))
+	return nil
+}
+
+// handleLoadCommand replaces the conversation with one saved by /save or
+// found in a history file, after asking if the current one has messages.
+func (r *REPL) handleLoadCommand(args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: /load <file.json>")
+	}
+
+	path := config.ExpandPath(strings.TrimSpace(args))
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot load %s: %w", path, err)
+	}
+
+	if !r.session.IsEmpty() {
+		question := fmt.Sprintf("Replace the current conversation (%d messages) with %s?", r.session.MessageCount(), path)
+		if !r.askYesNo(question) {
+			r.displayInfo("Load cancelled. The current conversation is unchanged.")
+			return nil
+		}
+	}
+
+	if err := r.session.Load(path); err != nil {
+		return err
+	}
+	r.displaySystem(fmt.Sprintf("Loaded %d messages from %s", r.session.MessageCount(), path))
+	return nil
+}
+
+func (r *REPL) handleHistoryCommand(args string) error {
+	parts := strings.Fields(args)
+	subcommand := "messages"
+	if len(parts) > 0 {
+		subcommand = strings.ToLower(parts[0])
+	}
+
+	switch subcommand {
+	case "messages":
+		return r.listMessages()
+
+	case "trim":
+		if len(parts) != 2 {
+			return fmt.Errorf("usage: /history trim <n>")
+		}
+		return r.trimMessages(parts[1])
+
+	case "backups", "list": // "list" listed backups before /history showed messages
+		backups, err := chat.ListHistoryBackups(r.config.Session.BackupDir)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			r.displayInfo("No history backups found.")
+			return nil
+		}
+
+		info := fmt.Sprintf("History backups (%s):\n", r.config.Session.BackupDir)
+		for i, b := range backups {
+			info += fmt.Sprintf("  %d. %s (%d bytes)\n", i+1, b.Timestamp.Format("2006-01-02 15:04:05"), b.Size)
+		}
+		info += "Use /history restore <n> to restore a backup."
+		r.displayInfo(info)
+		return nil
+
+	case "restore":
+		backups, err := chat.ListHistoryBackups(r.config.Session.BackupDir)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return fmt.Errorf("no history backups found in %s", r.config.Session.BackupDir)
+		}
+
+		n := 1
+		if len(parts) > 1 {
+			n, err = strconv.Atoi(parts[1])
+			if err != nil || n < 1 || n > len(backups) {
+				return fmt.Errorf("invalid backup number: %s (use 1-%d)", parts[1], len(backups))
+			}
+		}
+
+		backup := backups[n-1]
+		if err := r.session.Load(backup.Path); err != nil {
+			return err
+		}
+		r.displaySystem(fmt.Sprintf("Restored %d messages from backup %s.",
+			r.session.MessageCount(), backup.Timestamp.Format("2006-01-02 15:04:05")))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown history command: %s (use: messages, trim <n>, backups, restore [n])", subcommand)
+	}
+}
+
+func (r *REPL) handleCheckpointCommand(args string) error {
+	parts := strings.Fields(args)
+	subcommand := "list"
+	if len(parts) > 0 {
+		subcommand = strings.ToLower(parts[0])
+	}
+	name := ""
+	if len(parts) > 1 {
+		name = strings.Join(parts[1:], " ")
+	}
+
+	switch subcommand {
+	case "list":
+		checkpoints := r.session.Checkpoints()
+		if len(checkpoints) == 0 {
+			r.displayInfo("No checkpoints saved. Use /checkpoint save <name>.")
+			return nil
+		}
+
+		info := "Checkpoints:\n"
+		for _, cp := range checkpoints {
+			info += fmt.Sprintf("  %s (%d messages, %s)\n", cp.Name, len(cp.Messages), cp.Timestamp.Format("2006-01-02 15:04:05"))
+		}
+		info += "Use /checkpoint load <name> to continue from a checkpoint."
+		r.displayInfo(info)
+		return nil
+
+	case "save":
+		if name == "" {
+			return fmt.Errorf("usage: /checkpoint save <name>")
+		}
+		if err := r.session.Checkpoint(name); err != nil {
+			return err
+		}
+		r.saveCheckpoints()
+		r.displaySystem(fmt.Sprintf("Saved checkpoint %q (%d messages).", name, r.session.MessageCount()))
+		return nil
+
+	case "load", "restore":
+		if name == "" {
+			return fmt.Errorf("usage: /checkpoint load <name>")
+		}
+		if err := r.session.RestoreCheckpoint(name); err != nil {
+			return err
+		}
+		r.displaySystem(fmt.Sprintf("Restored checkpoint %q (%d messages).", name, r.session.MessageCount()))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown checkpoint command: %s (use: list, save <name>, load <name>)", subcommand)
+	}
+}
+
+// saveCheckpoints persists checkpoints next to the history file. Failures
+// are reported but keep the checkpoint in memory.
+func (r *REPL) saveCheckpoints() {
+	path := chat.CheckpointFile(r.config.Session.HistoryFile)
+	if path == "" {
+		return
+	}
+	if err := r.session.SaveCheckpoints(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save checkpoints: %v\n", err)
+	}
+}
+
 func (r *REPL) SaveHistory() error {
 	if !r.config.Session.SaveHistory {
 		return nil
@@ -804,9 +1583,20 @@ func (r *REPL) SaveHistory() error {
 		return nil
 	}
 
+	r.backupHistoryFile()
+
 	return r.session.Save(r.config.Session.HistoryFile)
 }
 
+// backupHistoryFile keeps a timestamped copy of the history file before it
+// is overwritten or deleted. Failures are reported but never block saving.
+func (r *REPL) backupHistoryFile() {
+	cfg := r.config.Session
+	if err := chat.RotateHistoryFile(cfg.HistoryFile, cfg.BackupDir, cfg.BackupCount); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to back up history: %v\n", err)
+	}
+}
+
 // DeleteHistoryFile removes the history file from disk.
 func (r *REPL) DeleteHistoryFile() error {
 	if !r.config.Session.SaveHistory {
@@ -823,5 +1613,7 @@ func (r *REPL) DeleteHistoryFile() error {
 		return nil
 	}
 
+	r.backupHistoryFile()
+
 	return os.Remove(historyFile)
 }
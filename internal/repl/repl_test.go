@@ -0,0 +1,78 @@
+package repl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/notexe/cli-chat/internal/api"
+)
+
+func TestToolLanes(t *testing.T) {
+	tools := map[string]struct {
+		server   string
+		readOnly bool
+	}{
+		"read_text_file":  {"filesystem", true},
+		"write_file":      {"filesystem", false},
+		"semantic_search": {"codeindex", true},
+		"reload_index":    {"codeindex", false},
+	}
+	toolServer := func(name string) (string, bool, bool) {
+		info, ok := tools[name]
+		return info.server, info.readOnly, ok
+	}
+
+	tests := []struct {
+		name     string
+		calls    []string
+		approved []bool // nil approves all
+		want     [][][]int
+	}{
+		{
+			name:  "reads of one server run together",
+			calls: []string{"semantic_search", "semantic_search", "semantic_search"},
+			want:  [][][]int{{{0, 1, 2}}},
+		},
+		{
+			name:  "a write keeps its place",
+			calls: []string{"write_file", "read_text_file", "read_text_file", "write_file", "read_text_file"},
+			want:  [][][]int{{{0}, {1, 2}, {3}, {4}}},
+		},
+		{
+			name:  "servers run concurrently",
+			calls: []string{"write_file", "semantic_search", "read_text_file", "reload_index"},
+			want:  [][][]int{{{0}, {2}}, {{1}, {3}}},
+		},
+		{
+			name:     "denied calls are skipped",
+			calls:    []string{"read_text_file", "write_file", "read_text_file"},
+			approved: []bool{true, false, true},
+			want:     [][][]int{{{0, 2}}},
+		},
+		{
+			name:  "unknown tools get their own lane",
+			calls: []string{"nope", "read_text_file", "nope"},
+			want:  [][][]int{{{0}}, {{1}}, {{2}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make([]api.ToolCall, len(tt.calls))
+			approved := tt.approved
+			if approved == nil {
+				approved = make([]bool, len(tt.calls))
+			}
+			for i, name := range tt.calls {
+				calls[i] = api.ToolCall{Name: name}
+				if tt.approved == nil {
+					approved[i] = true
+				}
+			}
+
+			if got := toolLanes(calls, approved, toolServer); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toolLanes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notexe/cli-chat/internal/api"
+)
+
+// snippetRadius is how many characters of context are shown around a match.
+const snippetRadius = 60
+
+var (
+	matchStyle      lipgloss.Style
+	searchRoleStyle lipgloss.Style
+)
+
+func (r *REPL) handleSearchCommand(args string) error {
+	term, role, err := parseSearchArgs(args)
+	if err != nil {
+		return err
+	}
+	if term == "" {
+		return fmt.Errorf("usage: /search [--role user|assistant|tool] <term>")
+	}
+
+	var lines []string
+	for _, m := range r.session.FindMessages(term) {
+		if role != "" && m.Message.Role != role {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  #%d %s  %s",
+			m.Index+1, r.renderSearchRole(m.Message.Role), r.searchSnippet(searchableText(m.Message), term)))
+	}
+
+	if len(lines) == 0 {
+		r.displayInfo(fmt.Sprintf("No messages match %q.", term))
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("%d of %d messages match %q:\n", len(lines), r.session.MessageCount(), term)
+	fmt.Println(strings.Join(lines, "\n"))
+	fmt.Println()
+	return nil
+}
+
+// parseSearchArgs splits /search arguments into the term and the optional
+// --role filter.
+func parseSearchArgs(args string) (string, string, error) {
+	var words []string
+	role := ""
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case fields[i] == "--role":
+			if i+1 >= len(fields) {
+				return "", "", fmt.Errorf("--role requires a value")
+			}
+			role = fields[i+1]
+			i++
+		case strings.HasPrefix(fields[i], "--role="):
+			role = strings.TrimPrefix(fields[i], "--role=")
+		default:
+			words = append(words, fields[i])
+		}
+	}
+
+	role = strings.ToLower(role)
+	switch role {
+	case "", "user", "assistant", "tool":
+	default:
+		return "", "", fmt.Errorf("invalid role: %s (use: user, assistant, tool)", role)
+	}
+
+	return strings.Join(words, " "), role, nil
+}
+
+// searchableText returns the text History.Find matched against.
+func searchableText(msg api.Message) string {
+	text := msg.Content
+	for _, tc := range msg.ToolCalls {
+		text += "\n" + tc.Name + " " + tc.Arguments
+	}
+	return text
+}
+
+// searchSnippet returns the text around the first match of term on one
+// line, with the match highlighted.
+func (r *REPL) searchSnippet(text, term string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	i := strings.Index(lower, strings.ToLower(term))
+	if i < 0 || len(lower) != len(text) {
+		return truncateRunes(text, 2*snippetRadius)
+	}
+	end := i + len(term)
+
+	start := max(0, i-snippetRadius)
+	stop := min(len(text), end+snippetRadius)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for stop < len(text) && !utf8.RuneStart(text[stop]) {
+		stop++
+	}
+
+	match := text[i:end]
+	if r.formatter.Colored() {
+		match = matchStyle.Render(match)
+	} else {
+		match = "[" + match + "]"
+	}
+
+	snippet := text[start:i] + match + text[end:stop]
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if stop < len(text) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+func (r *REPL) renderSearchRole(role string) string {
+	label := fmt.Sprintf("%-9s", role)
+	if r.formatter.Colored() {
+		return searchRoleStyle.Render(label)
+	}
+	return label
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
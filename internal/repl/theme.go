@@ -0,0 +1,34 @@
+package repl
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notexe/cli-chat/internal/chat"
+	"github.com/notexe/cli-chat/internal/ui"
+)
+
+func init() {
+	applyStyles(ui.CurrentTheme())
+}
+
+// applyTheme makes t the theme of the UI, the JSON tables and the REPL's
+// own styles.
+func applyTheme(t ui.Theme) {
+	ui.ApplyTheme(t)
+	chat.ApplyTheme(t)
+	applyStyles(t)
+}
+
+// applyStyles rebuilds the REPL's package styles from the colors of t.
+func applyStyles(t ui.Theme) {
+	questionTitleStyle = lipgloss.NewStyle().Foreground(t.Header).Bold(true)
+	counterStyle = lipgloss.NewStyle().Foreground(t.Status)
+	selectedResultStyle = lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+	pastedStyle = lipgloss.NewStyle().Foreground(t.Status).Italic(true)
+	searchRoleStyle = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	// Without a highlight color, matches are shown in reverse video
+	matchStyle = lipgloss.NewStyle().Reverse(true)
+	if t.Match != "" {
+		matchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(t.Match)
+	}
+}
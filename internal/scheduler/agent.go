@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/notexe/cli-chat/internal/agent"
 	"github.com/notexe/cli-chat/internal/api"
 	"github.com/notexe/cli-chat/internal/mcp"
 )
@@ -59,20 +60,9 @@ func RunAgenticPrompt(
 
 		// Execute each tool call and collect results
 		for _, tc := range resp.ToolCalls {
-			result, err := mcpMgr.CallTool(ctx, tc.Name, tc.Arguments)
-			if err != nil {
-				result = fmt.Sprintf("Error: %v", err)
-			}
-
-			// Truncate large results
-			const maxToolResultSize = 32000
-			if len(result) > maxToolResultSize {
-				result = result[:maxToolResultSize] + "\n\n[... truncated]"
-			}
-
 			messages = append(messages, api.Message{
 				Role:       "tool",
-				Content:    result,
+				Content:    agent.CallTool(ctx, mcpMgr, tc),
 				ToolCallID: tc.ID,
 			})
 		}
@@ -7,26 +7,31 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	mcpclient "github.com/notexe/cli-chat/internal/mcp"
 )
 
+// waitCallTimeout is the call timeout declared by the tools that wait for
+// messages: their longest wait, 600 seconds, plus time to send and return.
+const waitCallTimeout = 11 * time.Minute
+
 // Server implements an MCP server for Telegram Bot API operations
 type Server struct {
-	mcpServer    *server.MCPServer
-	client       *http.Client
-	botToken     string
-	chatID       string
-	lastUpdateID int64
-	updateMu     sync.Mutex
+	mcpServer *server.MCPServer
+	client    *http.Client
+	botToken  string
+	chatID    string
+	updates   *updateHub
 }
 
 // NewServer creates a new Telegram MCP server
@@ -37,9 +42,6 @@ func NewServer() *Server {
 	if botToken == "" {
 		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is required")
 	}
-	if chatID == "" {
-		log.Fatal("TELEGRAM_CHAT_ID environment variable is required")
-	}
 
 	s := &Server{
 		client:   &http.Client{},
@@ -47,6 +49,8 @@ func NewServer() *Server {
 		chatID:   chatID,
 	}
 
+	s.updates = newUpdateHub(s.fetchUpdates)
+
 	s.mcpServer = server.NewMCPServer(
 		"telegram",
 		"1.0.0",
@@ -58,6 +62,26 @@ func NewServer() *Server {
 	return s
 }
 
+// chatIDHelp describes the per-call chat_id parameter.
+const chatIDHelp = "Optional. Target chat ID or @channel username. Defaults to TELEGRAM_CHAT_ID"
+
+// errNoChatID is returned when neither a default nor a per-call chat ID is set.
+const errNoChatID = "chat_id parameter required (no default TELEGRAM_CHAT_ID is configured)"
+
+// replyToHelp and threadIDHelp describe the optional threading parameters.
+const (
+	replyToHelp  = "Optional. ID of a message in the chat to reply to"
+	threadIDHelp = "Optional. Forum topic (message thread) ID to send into"
+)
+
+// chatIDFor returns the chat_id argument, falling back to the default chat.
+func (s *Server) chatIDFor(req mcp.CallToolRequest) string {
+	if chatID := strings.TrimSpace(req.GetString("chat_id", "")); chatID != "" {
+		return chatID
+	}
+	return s.chatID
+}
+
 // MCPServer returns the underlying MCP server
 func (s *Server) MCPServer() *server.MCPServer {
 	return s.mcpServer
@@ -69,9 +93,13 @@ func (s *Server) registerTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("send_message",
 			mcp.WithDescription("Send a text message to the configured Telegram chat"),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 			mcp.WithString("text", mcp.Required(), mcp.Description("The message text to send")),
 			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode: 'HTML', 'Markdown', or 'MarkdownV2'. Default is 'HTML'")),
+			mcp.WithBoolean("auto_escape", mcp.Description("Optional. With parse_mode 'MarkdownV2', escape all special characters so the text is sent literally")),
 			mcp.WithBoolean("disable_notification", mcp.Description("Optional. Send message silently without notification")),
+			mcp.WithNumber("reply_to_message_id", mcp.Description(replyToHelp)),
+			mcp.WithNumber("message_thread_id", mcp.Description(threadIDHelp)),
 		),
 		s.handleSendMessage,
 	)
@@ -80,28 +108,83 @@ func (s *Server) registerTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("send_message_with_keyboard",
 			mcp.WithDescription("Send a message with inline keyboard buttons to the Telegram chat"),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 			mcp.WithString("text", mcp.Required(), mcp.Description("The message text to send")),
 			mcp.WithString("buttons", mcp.Required(), mcp.Description("JSON array of button rows, e.g. [[{\"text\":\"Button 1\",\"callback_data\":\"data1\"}]]")),
 			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode: 'HTML', 'Markdown', or 'MarkdownV2'. Default is 'HTML'")),
+			mcp.WithNumber("reply_to_message_id", mcp.Description(replyToHelp)),
+			mcp.WithNumber("message_thread_id", mcp.Description(threadIDHelp)),
 		),
 		s.handleSendMessageWithKeyboard,
 	)
 
+	// Replace or remove the inline keyboard of a sent message
+	s.mcpServer.AddTool(
+		mcp.NewTool("edit_message_reply_markup",
+			mcp.WithDescription("Replace the inline keyboard of a previously sent message, or remove it"),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
+			mcp.WithNumber("message_id", mcp.Required(), mcp.Description("Identifier of the message to edit")),
+			mcp.WithString("buttons", mcp.Description("JSON array of button rows, e.g. [[{\"text\":\"Done\",\"callback_data\":\"done\"}]]. Omit or pass [] to remove the keyboard")),
+		),
+		s.handleEditMessageReplyMarkup,
+	)
+
+	// Answer a button press
+	s.mcpServer.AddTool(
+		mcp.NewTool("answer_callback_query",
+			mcp.WithDescription("Answer an inline keyboard button press (callback query) returned by get_updates. Telegram shows a loading indicator on the button until it is answered."),
+			mcp.WithString("callback_query_id", mcp.Required(), mcp.Description("The callback_query_id from get_updates")),
+			mcp.WithString("text", mcp.Description("Optional. Notification text shown to the user (0-200 characters)")),
+			mcp.WithBoolean("show_alert", mcp.Description("Optional. Show the text as an alert dialog instead of a toast")),
+		),
+		s.handleAnswerCallbackQuery,
+	)
+
 	// Send photo
 	s.mcpServer.AddTool(
 		mcp.NewTool("send_photo",
 			mcp.WithDescription("Send a photo to the configured Telegram chat. Supports local files, HTTP URLs, and file_ids."),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 			mcp.WithString("photo_url", mcp.Required(), mcp.Description("Local file path (e.g., /path/to/image.png or file:///path/to/image.png), HTTP URL, or Telegram file_id")),
 			mcp.WithString("caption", mcp.Description("Optional. Photo caption (max 1024 characters)")),
 			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode for caption: 'HTML', 'Markdown', or 'MarkdownV2'")),
+			mcp.WithNumber("reply_to_message_id", mcp.Description(replyToHelp)),
+			mcp.WithNumber("message_thread_id", mcp.Description(threadIDHelp)),
 		),
 		s.handleSendPhoto,
 	)
 
+	// Send document
+	s.mcpServer.AddTool(
+		mcp.NewTool("send_document",
+			mcp.WithDescription("Send a file as a document to the configured Telegram chat. Supports local files (up to 50 MB), HTTP URLs, and file_ids."),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
+			mcp.WithString("document", mcp.Required(), mcp.Description("Local file path (e.g., /path/to/report.pdf or file:///path/to/report.pdf), HTTP URL, or Telegram file_id")),
+			mcp.WithString("caption", mcp.Description("Optional. Document caption (max 1024 characters)")),
+			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode for caption: 'HTML', 'Markdown', or 'MarkdownV2'")),
+		),
+		s.handleSendDocument,
+	)
+
+	// Send audio
+	s.mcpServer.AddTool(
+		mcp.NewTool("send_audio",
+			mcp.WithDescription("Send an audio file (MP3 or M4A) to the configured Telegram chat for display in the music player. Supports local files (up to 50 MB), HTTP URLs, and file_ids."),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
+			mcp.WithString("audio", mcp.Required(), mcp.Description("Local file path, HTTP URL, or Telegram file_id")),
+			mcp.WithString("caption", mcp.Description("Optional. Audio caption (max 1024 characters)")),
+			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode for caption: 'HTML', 'Markdown', or 'MarkdownV2'")),
+			mcp.WithString("title", mcp.Description("Optional. Track name")),
+			mcp.WithString("performer", mcp.Description("Optional. Performer")),
+		),
+		s.handleSendAudio,
+	)
+
 	// Get chat info
 	s.mcpServer.AddTool(
 		mcp.NewTool("get_chat",
 			mcp.WithDescription("Get information about the configured Telegram chat"),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 		),
 		s.handleGetChat,
 	)
@@ -110,9 +193,11 @@ func (s *Server) registerTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("edit_message",
 			mcp.WithDescription("Edit a previously sent message text"),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 			mcp.WithNumber("message_id", mcp.Required(), mcp.Description("Identifier of the message to edit")),
 			mcp.WithString("text", mcp.Required(), mcp.Description("New text of the message")),
 			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode: 'HTML', 'Markdown', or 'MarkdownV2'")),
+			mcp.WithBoolean("auto_escape", mcp.Description("Optional. With parse_mode 'MarkdownV2', escape all special characters so the text is sent literally")),
 		),
 		s.handleEditMessage,
 	)
@@ -121,6 +206,7 @@ func (s *Server) registerTools() {
 	s.mcpServer.AddTool(
 		mcp.NewTool("delete_message",
 			mcp.WithDescription("Delete a message from the Telegram chat"),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 			mcp.WithNumber("message_id", mcp.Required(), mcp.Description("Identifier of the message to delete")),
 		),
 		s.handleDeleteMessage,
@@ -137,7 +223,8 @@ func (s *Server) registerTools() {
 	// Get updates (incoming messages)
 	s.mcpServer.AddTool(
 		mcp.NewTool("get_updates",
-			mcp.WithDescription("Get new incoming messages from the Telegram chat. Uses long polling to wait for messages."),
+			mcp.WithDescription("Get new incoming messages and inline keyboard button presses (callback_queries) from the Telegram chat. Uses long polling to wait for updates. Answer button presses with answer_callback_query."),
+			mcp.WithString("chat_id", mcp.Description("Optional. Only return updates from this chat. Defaults to TELEGRAM_CHAT_ID; if neither is set, updates from all chats are returned")),
 			mcp.WithNumber("timeout", mcp.Description("Long polling timeout in seconds (1-50). Default is 30. Telegram will hold the connection until a message arrives or timeout expires.")),
 			mcp.WithNumber("limit", mcp.Description("Maximum number of updates to return (1-100). Default is 10.")),
 		),
@@ -147,17 +234,36 @@ func (s *Server) registerTools() {
 	// Wait for reply after sending a message
 	s.mcpServer.AddTool(
 		mcp.NewTool("send_and_wait_reply",
-			mcp.WithDescription("Send a message and wait for a reply from the user. Uses long polling to wait up to the specified timeout."),
+			mcp.WithDescription("Send a message and wait for a reply from the user. Uses long polling to wait up to the specified timeout. If listen_for_messages or get_updates is already waiting on the chat, only messages that reply directly to the sent message are guaranteed to reach this call."),
+			mcp.WithString("chat_id", mcp.Description(chatIDHelp)),
 			mcp.WithString("text", mcp.Required(), mcp.Description("The message text to send")),
 			mcp.WithString("parse_mode", mcp.Description("Optional. Parse mode: 'HTML', 'Markdown', or 'MarkdownV2'. Default is 'HTML'")),
 			mcp.WithNumber("wait_timeout", mcp.Description("How long to wait for a reply in seconds (1-600). Default is 300 (5 minutes). Maximum is 600 (10 minutes).")),
+			mcpclient.WithCallTimeout(waitCallTimeout),
 		),
 		s.handleSendAndWaitReply,
 	)
+
+	// Collect all user messages for a while
+	s.mcpServer.AddTool(
+		mcp.NewTool("listen_for_messages",
+			mcp.WithDescription("Listen for a bounded time and return every user message received, with sender info. Use it to drive a conversation: send a message, then listen for everything the users say. Replies to a message sent by a running send_and_wait_reply go to that call instead."),
+			mcp.WithString("chat_id", mcp.Description("Optional. Only listen to this chat. Defaults to TELEGRAM_CHAT_ID; if neither is set, messages from all chats are returned")),
+			mcp.WithNumber("duration", mcp.Description("How long to listen in seconds (1-600). Default is 60.")),
+			mcp.WithNumber("max_messages", mcp.Description("Optional. Stop early once this many messages arrived (1-100). Default is 100.")),
+			mcpclient.WithCallTimeout(waitCallTimeout),
+		),
+		s.handleListenForMessages,
+	)
 }
 
 // handleSendMessage sends a text message
 func (s *Server) handleSendMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	text := req.GetString("text", "")
 	if text == "" {
 		return mcp.NewToolResultError("text parameter required"), nil
@@ -168,14 +274,26 @@ func (s *Server) handleSendMessage(ctx context.Context, req mcp.CallToolRequest)
 		parseMode = "HTML"
 	}
 
+	if parseMode == "MarkdownV2" && req.GetBool("auto_escape", false) {
+		text = escapeMarkdownV2(text)
+	}
+
+	threading, err := threadingParams(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	disableNotification := req.GetBool("disable_notification", false)
 
 	payload := map[string]interface{}{
-		"chat_id":              s.chatID,
+		"chat_id":              chatID,
 		"text":                 text,
 		"parse_mode":           parseMode,
 		"disable_notification": disableNotification,
 	}
+	for name, id := range threading {
+		payload[name] = id
+	}
 
 	result, err := s.callTelegramAPI("sendMessage", payload)
 	if err != nil {
@@ -183,14 +301,8 @@ func (s *Server) handleSendMessage(ctx context.Context, req mcp.CallToolRequest)
 	}
 
 	// Extract message_id from result
-	var response struct {
-		OK     bool `json:"ok"`
-		Result struct {
-			MessageID int `json:"message_id"`
-		} `json:"result"`
-	}
-	if err := json.Unmarshal(result, &response); err == nil && response.OK {
-		return mcp.NewToolResultText(fmt.Sprintf("Message sent successfully. Message ID: %d", response.Result.MessageID)), nil
+	if id, ok := messageID(result); ok {
+		return mcp.NewToolResultText(fmt.Sprintf("Message sent successfully. Message ID: %d", id)), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Message sent: %s", string(result))), nil
@@ -198,6 +310,11 @@ func (s *Server) handleSendMessage(ctx context.Context, req mcp.CallToolRequest)
 
 // handleSendMessageWithKeyboard sends a message with inline keyboard
 func (s *Server) handleSendMessageWithKeyboard(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	text := req.GetString("text", "")
 	if text == "" {
 		return mcp.NewToolResultError("text parameter required"), nil
@@ -214,19 +331,27 @@ func (s *Server) handleSendMessageWithKeyboard(ctx context.Context, req mcp.Call
 	}
 
 	// Parse buttons JSON
-	var buttons [][]map[string]interface{}
-	if err := json.Unmarshal([]byte(buttonsJSON), &buttons); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid buttons JSON: %v", err)), nil
+	buttons, err := parseButtons(buttonsJSON)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	threading, err := threadingParams(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	payload := map[string]interface{}{
-		"chat_id":    s.chatID,
+		"chat_id":    chatID,
 		"text":       text,
 		"parse_mode": parseMode,
 		"reply_markup": map[string]interface{}{
 			"inline_keyboard": buttons,
 		},
 	}
+	for name, id := range threading {
+		payload[name] = id
+	}
 
 	result, err := s.callTelegramAPI("sendMessage", payload)
 	if err != nil {
@@ -236,8 +361,119 @@ func (s *Server) handleSendMessageWithKeyboard(ctx context.Context, req mcp.Call
 	return mcp.NewToolResultText(fmt.Sprintf("Message with keyboard sent: %s", string(result))), nil
 }
 
+// handleEditMessageReplyMarkup replaces or removes a message's inline keyboard
+func (s *Server) handleEditMessageReplyMarkup(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
+	messageID := req.GetFloat("message_id", 0)
+	if messageID == 0 {
+		return mcp.NewToolResultError("message_id parameter required"), nil
+	}
+
+	buttons := [][]map[string]interface{}{}
+	if buttonsJSON := req.GetString("buttons", ""); buttonsJSON != "" {
+		var err error
+		if buttons, err = parseButtons(buttonsJSON); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	// An empty inline_keyboard removes the keyboard
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": int(messageID),
+		"reply_markup": map[string]interface{}{
+			"inline_keyboard": buttons,
+		},
+	}
+
+	result, err := s.callTelegramAPI("editMessageReplyMarkup", payload)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to edit keyboard: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Keyboard updated: %s", string(result))), nil
+}
+
+// handleAnswerCallbackQuery acknowledges an inline keyboard button press
+func (s *Server) handleAnswerCallbackQuery(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	callbackQueryID := req.GetString("callback_query_id", "")
+	if callbackQueryID == "" {
+		return mcp.NewToolResultError("callback_query_id parameter required"), nil
+	}
+
+	payload := map[string]interface{}{
+		"callback_query_id": callbackQueryID,
+	}
+	if text := req.GetString("text", ""); text != "" {
+		payload["text"] = text
+	}
+	if req.GetBool("show_alert", false) {
+		payload["show_alert"] = true
+	}
+
+	if _, err := s.callTelegramAPI("answerCallbackQuery", payload); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to answer callback query: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText("Callback query answered."), nil
+}
+
+// parseButtons parses a JSON array of inline keyboard button rows.
+func parseButtons(buttonsJSON string) ([][]map[string]interface{}, error) {
+	var buttons [][]map[string]interface{}
+	if err := json.Unmarshal([]byte(buttonsJSON), &buttons); err != nil {
+		return nil, fmt.Errorf("Invalid buttons JSON: %v", err)
+	}
+	return buttons, nil
+}
+
+// threadingParams returns the reply_to_message_id and message_thread_id
+// arguments that were given, rejecting values that are not message IDs
+func threadingParams(req mcp.CallToolRequest) (map[string]int64, error) {
+	params := make(map[string]int64)
+	for _, name := range []string{"reply_to_message_id", "message_thread_id"} {
+		value, ok := req.GetArguments()[name]
+		if !ok || value == nil || value == "" {
+			continue
+		}
+		id, err := req.RequireFloat(name)
+		if err != nil || id < 1 || id != math.Trunc(id) {
+			return nil, fmt.Errorf("%s must be a positive integer, got %v", name, value)
+		}
+		params[name] = int64(id)
+	}
+	return params, nil
+}
+
+// markdownV2Special lists the characters Telegram requires to be escaped
+// with a backslash anywhere in MarkdownV2 text: _ * [ ] ( ) ~ ` > # + - = | { } . !
+// and the backslash itself.
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// escapeMarkdownV2 escapes s so Telegram renders it literally in MarkdownV2
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if strings.ContainsRune(markdownV2Special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // handleSendPhoto sends a photo
 func (s *Server) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	photoURL := req.GetString("photo_url", "")
 	if photoURL == "" {
 		return mcp.NewToolResultError("photo_url parameter required"), nil
@@ -246,6 +482,11 @@ func (s *Server) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (
 	caption := req.GetString("caption", "")
 	parseMode := req.GetString("parse_mode", "")
 
+	threading, err := threadingParams(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Check if it's a local file path
 	filePath := photoURL
 	// Remove file:// prefix if present
@@ -256,7 +497,14 @@ func (s *Server) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (
 	// Check if file exists locally
 	if _, err := os.Stat(filePath); err == nil {
 		// It's a local file, upload it
-		result, err := s.uploadPhotoFile(filePath, caption, parseMode)
+		fields := map[string]string{
+			"caption":    caption,
+			"parse_mode": parseMode,
+		}
+		for name, id := range threading {
+			fields[name] = strconv.FormatInt(id, 10)
+		}
+		result, err := s.uploadFile(chatID, "sendPhoto", "photo", filePath, fields)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to upload photo: %v", err)), nil
 		}
@@ -270,7 +518,7 @@ func (s *Server) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (
 
 	// Send via URL
 	payload := map[string]interface{}{
-		"chat_id": s.chatID,
+		"chat_id": chatID,
 		"photo":   photoURL,
 	}
 
@@ -282,6 +530,10 @@ func (s *Server) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (
 		payload["parse_mode"] = parseMode
 	}
 
+	for name, id := range threading {
+		payload[name] = id
+	}
+
 	result, err := s.callTelegramAPI("sendPhoto", payload)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to send photo: %v", err)), nil
@@ -290,10 +542,69 @@ func (s *Server) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (
 	return mcp.NewToolResultText(fmt.Sprintf("Photo sent: %s", string(result))), nil
 }
 
+// handleSendDocument sends a document
+func (s *Server) handleSendDocument(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
+	document := req.GetString("document", "")
+	if document == "" {
+		return mcp.NewToolResultError("document parameter required"), nil
+	}
+
+	result, err := s.sendFile(chatID, "sendDocument", "document", document, map[string]string{
+		"caption":    req.GetString("caption", ""),
+		"parse_mode": req.GetString("parse_mode", ""),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to send document: %v", err)), nil
+	}
+
+	if id, ok := messageID(result); ok {
+		return mcp.NewToolResultText(fmt.Sprintf("Document sent successfully. Message ID: %d", id)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Document sent: %s", string(result))), nil
+}
+
+// handleSendAudio sends an audio file
+func (s *Server) handleSendAudio(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
+	audio := req.GetString("audio", "")
+	if audio == "" {
+		return mcp.NewToolResultError("audio parameter required"), nil
+	}
+
+	result, err := s.sendFile(chatID, "sendAudio", "audio", audio, map[string]string{
+		"caption":    req.GetString("caption", ""),
+		"parse_mode": req.GetString("parse_mode", ""),
+		"title":      req.GetString("title", ""),
+		"performer":  req.GetString("performer", ""),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to send audio: %v", err)), nil
+	}
+
+	if id, ok := messageID(result); ok {
+		return mcp.NewToolResultText(fmt.Sprintf("Audio sent successfully. Message ID: %d", id)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Audio sent: %s", string(result))), nil
+}
+
 // handleGetChat gets chat information
 func (s *Server) handleGetChat(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	payload := map[string]interface{}{
-		"chat_id": s.chatID,
+		"chat_id": chatID,
 	}
 
 	result, err := s.callTelegramAPI("getChat", payload)
@@ -306,6 +617,11 @@ func (s *Server) handleGetChat(ctx context.Context, req mcp.CallToolRequest) (*m
 
 // handleEditMessage edits a message
 func (s *Server) handleEditMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	messageID := req.GetFloat("message_id", 0)
 	if messageID == 0 {
 		return mcp.NewToolResultError("message_id parameter required"), nil
@@ -317,7 +633,7 @@ func (s *Server) handleEditMessage(ctx context.Context, req mcp.CallToolRequest)
 	}
 
 	payload := map[string]interface{}{
-		"chat_id":    s.chatID,
+		"chat_id":    chatID,
 		"message_id": int(messageID),
 		"text":       text,
 	}
@@ -326,6 +642,9 @@ func (s *Server) handleEditMessage(ctx context.Context, req mcp.CallToolRequest)
 	if parseMode != "" {
 		payload["parse_mode"] = parseMode
 	}
+	if parseMode == "MarkdownV2" && req.GetBool("auto_escape", false) {
+		payload["text"] = escapeMarkdownV2(text)
+	}
 
 	result, err := s.callTelegramAPI("editMessageText", payload)
 	if err != nil {
@@ -337,13 +656,18 @@ func (s *Server) handleEditMessage(ctx context.Context, req mcp.CallToolRequest)
 
 // handleDeleteMessage deletes a message
 func (s *Server) handleDeleteMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	messageID := req.GetFloat("message_id", 0)
 	if messageID == 0 {
 		return mcp.NewToolResultError("message_id parameter required"), nil
 	}
 
 	payload := map[string]interface{}{
-		"chat_id":    s.chatID,
+		"chat_id":    chatID,
 		"message_id": int(messageID),
 	}
 
@@ -405,8 +729,30 @@ func (s *Server) callTelegramAPI(method string, payload map[string]interface{})
 	return responseBody, nil
 }
 
-// uploadPhotoFile uploads a local photo file to Telegram
-func (s *Server) uploadPhotoFile(filePath, caption, parseMode string) ([]byte, error) {
+// Telegram Bot API limits for multipart uploads.
+const (
+	maxPhotoUploadSize = 10 << 20 // 10 MB
+	maxFileUploadSize  = 50 << 20 // 50 MB for documents, audio and other files
+)
+
+// uploadFile uploads a local file to chatID with a multipart request.
+// field is the form field holding the file (e.g. "photo", "document") and
+// extraFields are sent as additional form values; empty values are skipped.
+func (s *Server) uploadFile(chatID, method, field, filePath string, extraFields map[string]string) ([]byte, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	limit := int64(maxFileUploadSize)
+	if method == "sendPhoto" {
+		limit = maxPhotoUploadSize
+	}
+	if info.Size() > limit {
+		return nil, fmt.Errorf("file %s is %.1f MB, Telegram allows at most %d MB for %s",
+			filepath.Base(filePath), float64(info.Size())/(1<<20), limit>>20, field)
+	}
+
 	// Open the file
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -419,27 +765,23 @@ func (s *Server) uploadPhotoFile(filePath, caption, parseMode string) ([]byte, e
 	writer := multipart.NewWriter(body)
 
 	// Add chat_id
-	if err := writer.WriteField("chat_id", s.chatID); err != nil {
+	if err := writer.WriteField("chat_id", chatID); err != nil {
 		return nil, fmt.Errorf("failed to write chat_id field: %w", err)
 	}
 
-	// Add caption if provided
-	if caption != "" {
-		if err := writer.WriteField("caption", caption); err != nil {
-			return nil, fmt.Errorf("failed to write caption field: %w", err)
+	// Add optional fields such as caption and parse_mode
+	for name, value := range extraFields {
+		if value == "" {
+			continue
 		}
-	}
-
-	// Add parse_mode if provided
-	if parseMode != "" {
-		if err := writer.WriteField("parse_mode", parseMode); err != nil {
-			return nil, fmt.Errorf("failed to write parse_mode field: %w", err)
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("failed to write %s field: %w", name, err)
 		}
 	}
 
 	// Add file
 	filename := filepath.Base(filePath)
-	part, err := writer.CreateFormFile("photo", filename)
+	part, err := writer.CreateFormFile(field, filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
@@ -454,7 +796,7 @@ func (s *Server) uploadPhotoFile(filePath, caption, parseMode string) ([]byte, e
 	}
 
 	// Create request
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendPhoto", s.botToken)
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", s.botToken, method)
 	req, err := http.NewRequest("POST", url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -481,10 +823,59 @@ func (s *Server) uploadPhotoFile(filePath, caption, parseMode string) ([]byte, e
 	return responseBody, nil
 }
 
+// sendFile sends a local file, HTTP URL or Telegram file_id to chatID with method,
+// putting the source in field. Local files are uploaded; URLs and file_ids
+// are passed through for Telegram to fetch.
+func (s *Server) sendFile(chatID, method, field, source string, extraFields map[string]string) ([]byte, error) {
+	filePath := strings.TrimPrefix(source, "file://")
+	if _, err := os.Stat(filePath); err == nil {
+		return s.uploadFile(chatID, method, field, filePath, extraFields)
+	}
+
+	if strings.HasPrefix(source, "file://") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") {
+		return nil, fmt.Errorf("file not found: %s", filePath)
+	}
+
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+		field:     source,
+	}
+	for name, value := range extraFields {
+		if value != "" {
+			payload[name] = value
+		}
+	}
+
+	return s.callTelegramAPI(method, payload)
+}
+
+// messageID extracts result.message_id from a Telegram API response.
+func messageID(result []byte) (int, bool) {
+	var response struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			MessageID int `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil || !response.OK {
+		return 0, false
+	}
+	return response.Result.MessageID, true
+}
+
 // TelegramUpdate represents an update from Telegram
 type TelegramUpdate struct {
-	UpdateID int64            `json:"update_id"`
-	Message  *TelegramMessage `json:"message,omitempty"`
+	UpdateID      int64                  `json:"update_id"`
+	Message       *TelegramMessage       `json:"message,omitempty"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+}
+
+// TelegramCallbackQuery represents an inline keyboard button press
+type TelegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    *TelegramUser    `json:"from"`
+	Message *TelegramMessage `json:"message,omitempty"`
+	Data    string           `json:"data,omitempty"`
 }
 
 // TelegramMessage represents a message in Telegram
@@ -526,6 +917,8 @@ type GetUpdatesResponse struct {
 
 // handleGetUpdates gets new messages using long polling
 func (s *Server) handleGetUpdates(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+
 	timeout := int(req.GetFloat("timeout", 30))
 	if timeout < 1 {
 		timeout = 1
@@ -542,98 +935,106 @@ func (s *Server) handleGetUpdates(ctx context.Context, req mcp.CallToolRequest)
 		limit = 100
 	}
 
-	s.updateMu.Lock()
-	offset := s.lastUpdateID
-	s.updateMu.Unlock()
+	updates, err := s.updates.collect(ctx, func(u TelegramUpdate) bool {
+		switch {
+		case u.Message != nil:
+			return matchesChat(u.Message.Chat, chatID)
+		case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+			return matchesChat(u.CallbackQuery.Message.Chat, chatID)
+		}
+		return false
+	}, time.Duration(timeout)*time.Second, limit)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get updates: %v", err)), nil
+	}
 
-	payload := map[string]interface{}{
-		"timeout":         timeout,
-		"limit":           limit,
-		"allowed_updates": []string{"message"},
+	// Messages from the requested chat
+	var messages []map[string]interface{}
+	for _, update := range updates {
+		if update.Message != nil {
+			messages = append(messages, messageInfo(update.Message))
+		}
 	}
-	if offset > 0 {
-		payload["offset"] = offset + 1
+
+	// Button presses on messages in the requested chat
+	var callbacks []map[string]interface{}
+	for _, update := range updates {
+		cq := update.CallbackQuery
+		if cq == nil || cq.Message == nil || cq.Message.Chat == nil {
+			continue
+		}
+		callback := map[string]interface{}{
+			"chat_id":           cq.Message.Chat.ID,
+			"callback_query_id": cq.ID,
+			"data":              cq.Data,
+			"message_id":        cq.Message.MessageID,
+		}
+		if cq.From != nil {
+			callback["from"] = userInfo(cq.From)
+		}
+		callbacks = append(callbacks, callback)
 	}
 
-	// Create a client with extended timeout for long polling
-	client := &http.Client{
-		Timeout: time.Duration(timeout+10) * time.Second,
+	output := map[string]interface{}{
+		"count":    len(messages),
+		"messages": messages,
+	}
+	if len(callbacks) > 0 {
+		output["callback_queries"] = callbacks
 	}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates", s.botToken)
-	jsonData, _ := json.Marshal(payload)
+	result, _ := json.MarshalIndent(output, "", "  ")
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create request: %v", err)), nil
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	return mcp.NewToolResultText(string(result)), nil
+}
 
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Request failed: %v", err)), nil
+// matchesChat reports whether chat is chatID; an empty chatID matches all chats
+func matchesChat(chat *TelegramChat, chatID string) bool {
+	if chatID == "" {
+		return true
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to read response: %v", err)), nil
+	if chat == nil {
+		return false
 	}
+	return fmt.Sprintf("%d", chat.ID) == chatID ||
+		(chat.Username != "" && strings.EqualFold("@"+chat.Username, chatID))
+}
 
-	var updatesResp GetUpdatesResponse
-	if err := json.Unmarshal(body, &updatesResp); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse response: %v", err)), nil
+// messageInfo converts an incoming message to the map used in tool output
+func messageInfo(m *TelegramMessage) map[string]interface{} {
+	msg := map[string]interface{}{
+		"chat_id":    m.Chat.ID,
+		"message_id": m.MessageID,
+		"date":       m.Date,
+		"text":       m.Text,
 	}
-
-	if !updatesResp.OK {
-		return mcp.NewToolResultError(fmt.Sprintf("Telegram API error: %s", string(body))), nil
+	if m.From != nil {
+		msg["from"] = userInfo(m.From)
 	}
-
-	// Update the offset
-	if len(updatesResp.Result) > 0 {
-		s.updateMu.Lock()
-		lastUpdate := updatesResp.Result[len(updatesResp.Result)-1]
-		if lastUpdate.UpdateID > s.lastUpdateID {
-			s.lastUpdateID = lastUpdate.UpdateID
-		}
-		s.updateMu.Unlock()
+	if m.ReplyTo != nil {
+		msg["reply_to_message_id"] = m.ReplyTo.MessageID
 	}
+	return msg
+}
 
-	// Filter messages from the configured chat
-	var messages []map[string]interface{}
-	for _, update := range updatesResp.Result {
-		if update.Message != nil && fmt.Sprintf("%d", update.Message.Chat.ID) == s.chatID {
-			msg := map[string]interface{}{
-				"message_id": update.Message.MessageID,
-				"date":       update.Message.Date,
-				"text":       update.Message.Text,
-			}
-			if update.Message.From != nil {
-				msg["from"] = map[string]interface{}{
-					"id":         update.Message.From.ID,
-					"first_name": update.Message.From.FirstName,
-					"last_name":  update.Message.From.LastName,
-					"username":   update.Message.From.Username,
-					"is_bot":     update.Message.From.IsBot,
-				}
-			}
-			if update.Message.ReplyTo != nil {
-				msg["reply_to_message_id"] = update.Message.ReplyTo.MessageID
-			}
-			messages = append(messages, msg)
-		}
+// userInfo converts a Telegram user to the map used in tool output
+func userInfo(u *TelegramUser) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         u.ID,
+		"first_name": u.FirstName,
+		"last_name":  u.LastName,
+		"username":   u.Username,
+		"is_bot":     u.IsBot,
 	}
-
-	result, _ := json.MarshalIndent(map[string]interface{}{
-		"count":    len(messages),
-		"messages": messages,
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
 }
 
 // handleSendAndWaitReply sends a message and waits for a reply
 func (s *Server) handleSendAndWaitReply(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
+	if chatID == "" {
+		return mcp.NewToolResultError(errNoChatID), nil
+	}
+
 	text := req.GetString("text", "")
 	if text == "" {
 		return mcp.NewToolResultError("text parameter required"), nil
@@ -654,7 +1055,7 @@ func (s *Server) handleSendAndWaitReply(ctx context.Context, req mcp.CallToolReq
 
 	// First, send the message
 	sendPayload := map[string]interface{}{
-		"chat_id":    s.chatID,
+		"chat_id":    chatID,
 		"text":       text,
 		"parse_mode": parseMode,
 	}
@@ -668,6 +1069,7 @@ func (s *Server) handleSendAndWaitReply(ctx context.Context, req mcp.CallToolReq
 		OK     bool `json:"ok"`
 		Result struct {
 			MessageID int64 `json:"message_id"`
+			Date      int64 `json:"date"`
 		} `json:"result"`
 	}
 	if err := json.Unmarshal(sendResult, &sendResp); err != nil || !sendResp.OK {
@@ -675,144 +1077,126 @@ func (s *Server) handleSendAndWaitReply(ctx context.Context, req mcp.CallToolReq
 	}
 
 	sentMessageID := sendResp.Result.MessageID
-
-	// Clear any pending updates first to ensure we only get new messages
-	s.updateMu.Lock()
-	// Fetch current updates to get the latest offset
-	clearPayload := map[string]interface{}{
-		"timeout": 0,
-		"limit":   100,
-	}
-	s.updateMu.Unlock()
-
-	clearResult, _ := s.callTelegramAPI("getUpdates", clearPayload)
-	var clearResp GetUpdatesResponse
-	if err := json.Unmarshal(clearResult, &clearResp); err == nil && clearResp.OK && len(clearResp.Result) > 0 {
-		s.updateMu.Lock()
-		s.lastUpdateID = clearResp.Result[len(clearResp.Result)-1].UpdateID
-		s.updateMu.Unlock()
+	sentDate := sendResp.Result.Date
+
+	// Updates are consumed by the shared hub, so concurrent waits and
+	// get_updates calls can't take each other's replies. Only human messages
+	// sent after our message count; replies to it are routed here first.
+	waiter := &updateWaiter{
+		replyTo: sentMessageID,
+		match: func(u TelegramUpdate) bool {
+			m := u.Message
+			return m != nil && matchesChat(m.Chat, chatID) &&
+				(m.From == nil || !m.From.IsBot) && m.Date >= sentDate
+		},
 	}
+	s.updates.subscribe(waiter, 1)
+	defer s.updates.unsubscribe(waiter)
 
-	// Now wait for a reply using long polling
-	// Telegram max timeout is 50 seconds, so we loop
 	startTime := time.Now()
-	deadline := startTime.Add(time.Duration(waitTimeout) * time.Second)
-
-	for time.Now().Before(deadline) {
-		// Calculate remaining time
-		remaining := time.Until(deadline)
-		pollTimeout := 50 // Max Telegram allows
-		if remaining < time.Duration(pollTimeout)*time.Second {
-			pollTimeout = int(remaining.Seconds())
-			if pollTimeout < 1 {
-				pollTimeout = 1
-			}
+	timer := time.NewTimer(time.Duration(waitTimeout) * time.Second)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return mcp.NewToolResultText(fmt.Sprintf(`{"sent_message_id": %d, "reply": null, "status": "cancelled", "waited_seconds": %.0f}`, sentMessageID, time.Since(startTime).Seconds())), nil
+	case err := <-waiter.errc:
+		return mcp.NewToolResultError(fmt.Sprintf("Message %d was sent, but waiting for a reply failed: %v", sentMessageID, err)), nil
+	case <-timer.C:
+	case update := <-waiter.ch:
+		reply := map[string]interface{}{
+			"message_id": update.Message.MessageID,
+			"text":       update.Message.Text,
+			"date":       update.Message.Date,
 		}
-
-		s.updateMu.Lock()
-		offset := s.lastUpdateID
-		s.updateMu.Unlock()
-
-		payload := map[string]interface{}{
-			"timeout":         pollTimeout,
-			"limit":           10,
-			"allowed_updates": []string{"message"},
+		if update.Message.From != nil {
+			reply["from"] = map[string]interface{}{
+				"id":         update.Message.From.ID,
+				"first_name": update.Message.From.FirstName,
+				"last_name":  update.Message.From.LastName,
+				"username":   update.Message.From.Username,
+			}
 		}
-		if offset > 0 {
-			payload["offset"] = offset + 1
+		if update.Message.ReplyTo != nil {
+			reply["reply_to_message_id"] = update.Message.ReplyTo.MessageID
 		}
 
-		// Create client with extended timeout
-		client := &http.Client{
-			Timeout: time.Duration(pollTimeout+10) * time.Second,
-		}
+		result, _ := json.MarshalIndent(map[string]interface{}{
+			"sent_message_id": sentMessageID,
+			"reply":           reply,
+			"status":          "received",
+			"waited_seconds":  time.Since(startTime).Seconds(),
+		}, "", "  ")
 
-		url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates", s.botToken)
-		jsonData, _ := json.Marshal(payload)
+		return mcp.NewToolResultText(string(result)), nil
+	}
 
-		httpReq, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-		if err != nil {
-			continue
-		}
-		httpReq.Header.Set("Content-Type", "application/json")
+	// Timeout reached, no reply
+	result, _ := json.MarshalIndent(map[string]interface{}{
+		"sent_message_id": sentMessageID,
+		"reply":           nil,
+		"status":          "timeout",
+		"waited_seconds":  waitTimeout,
+	}, "", "  ")
 
-		resp, err := client.Do(httpReq)
-		if err != nil {
-			// Check if context was cancelled
-			if ctx.Err() != nil {
-				return mcp.NewToolResultText(fmt.Sprintf(`{"sent_message_id": %d, "reply": null, "status": "cancelled", "waited_seconds": %.0f}`, sentMessageID, time.Since(startTime).Seconds())), nil
-			}
-			continue
-		}
+	return mcp.NewToolResultText(string(result)), nil
+}
 
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			continue
-		}
+// handleListenForMessages returns all user messages received within a duration
+func (s *Server) handleListenForMessages(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := s.chatIDFor(req)
 
-		var updatesResp GetUpdatesResponse
-		if err := json.Unmarshal(body, &updatesResp); err != nil || !updatesResp.OK {
-			continue
-		}
+	duration := int(req.GetFloat("duration", 60))
+	if duration < 1 {
+		duration = 1
+	}
+	if duration > 600 {
+		duration = 600 // Max 10 minutes
+	}
 
-		// Update offset
-		if len(updatesResp.Result) > 0 {
-			s.updateMu.Lock()
-			lastUpdate := updatesResp.Result[len(updatesResp.Result)-1]
-			if lastUpdate.UpdateID > s.lastUpdateID {
-				s.lastUpdateID = lastUpdate.UpdateID
-			}
-			s.updateMu.Unlock()
-		}
+	maxMessages := int(req.GetFloat("max_messages", 100))
+	if maxMessages < 1 {
+		maxMessages = 1
+	}
+	if maxMessages > 100 {
+		maxMessages = 100
+	}
 
-		// Check for reply from the configured chat
-		for _, update := range updatesResp.Result {
-			if update.Message == nil {
-				continue
-			}
-			// Check if it's from our chat
-			if fmt.Sprintf("%d", update.Message.Chat.ID) != s.chatID {
-				continue
-			}
-			// Skip bot messages
-			if update.Message.From != nil && update.Message.From.IsBot {
-				continue
-			}
+	startTime := time.Now()
+	updates, err := s.updates.listen(ctx, func(u TelegramUpdate) bool {
+		m := u.Message
+		return m != nil && matchesChat(m.Chat, chatID) && (m.From == nil || !m.From.IsBot)
+	}, time.Duration(duration)*time.Second, maxMessages)
+	if err != nil && len(updates) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to listen for messages: %v", err)), nil
+	}
 
-			// Found a human reply!
-			reply := map[string]interface{}{
-				"message_id": update.Message.MessageID,
-				"text":       update.Message.Text,
-				"date":       update.Message.Date,
-			}
-			if update.Message.From != nil {
-				reply["from"] = map[string]interface{}{
-					"id":         update.Message.From.ID,
-					"first_name": update.Message.From.FirstName,
-					"last_name":  update.Message.From.LastName,
-					"username":   update.Message.From.Username,
-				}
-			}
+	messages := make([]map[string]interface{}, 0, len(updates))
+	for _, update := range updates {
+		messages = append(messages, messageInfo(update.Message))
+	}
 
-			result, _ := json.MarshalIndent(map[string]interface{}{
-				"sent_message_id": sentMessageID,
-				"reply":           reply,
-				"status":          "received",
-				"waited_seconds":  time.Since(startTime).Seconds(),
-			}, "", "  ")
+	status := "completed"
+	switch {
+	case err != nil:
+		status = "failed"
+	case ctx.Err() != nil:
+		status = "cancelled"
+	case len(messages) >= maxMessages:
+		status = "max_messages"
+	}
 
-			return mcp.NewToolResultText(string(result)), nil
-		}
+	output := map[string]interface{}{
+		"count":            len(messages),
+		"messages":         messages,
+		"status":           status,
+		"listened_seconds": time.Since(startTime).Seconds(),
+	}
+	if err != nil {
+		output["error"] = err.Error()
 	}
 
-	// Timeout reached, no reply
-	result, _ := json.MarshalIndent(map[string]interface{}{
-		"sent_message_id": sentMessageID,
-		"reply":           nil,
-		"status":          "timeout",
-		"waited_seconds":  waitTimeout,
-	}, "", "  ")
+	result, _ := json.MarshalIndent(output, "", "  ")
 
 	return mcp.NewToolResultText(string(result)), nil
 }
@@ -0,0 +1,36 @@
+package telegram
+
+import "testing"
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	// Every character Telegram reserves in MarkdownV2
+	for _, c := range "_*[]()~`>#+-=|{}.!" {
+		t.Run(string(c), func(t *testing.T) {
+			in := "a" + string(c) + "b"
+			want := "a\\" + string(c) + "b"
+			if got := escapeMarkdownV2(in); got != want {
+				t.Errorf("escapeMarkdownV2(%q) = %q, want %q", in, got, want)
+			}
+		})
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"plain text 123", "plain text 123"},
+		{`back\slash`, `back\\slash`},
+		{"v1.2.3", `v1\.2\.3`},
+		{"*bold* and _italic_", `\*bold\* and \_italic\_`},
+		{"[link](https://example.com)", `\[link\]\(https://example\.com\)`},
+		{"a-b=c!", `a\-b\=c\!`},
+		{"cost: $5 (approx.)", `cost: $5 \(approx\.\)`},
+		{"Привет, мир! 👋", `Привет, мир\! 👋`},
+		{"already \\.", `already \\\.`},
+	}
+	for _, tt := range tests {
+		if got := escapeMarkdownV2(tt.in); got != tt.want {
+			t.Errorf("escapeMarkdownV2(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
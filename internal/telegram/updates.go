@@ -0,0 +1,311 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// hubPollTimeout is the long-poll timeout of the shared consumer. It is
+	// kept short so polling stops soon after the last waiter leaves.
+	hubPollTimeout = 10
+
+	// maxPendingUpdates caps updates buffered for later get_updates calls.
+	maxPendingUpdates = 100
+
+	// hubRetryDelay is the pause after a failed getUpdates call.
+	hubRetryDelay = time.Second
+
+	// maxFetchFailures is how many getUpdates calls in a row may fail
+	// before the waiters are given the error instead of waiting on.
+	maxFetchFailures = 3
+)
+
+// allowedUpdates are the update types requested from Telegram.
+var allowedUpdates = []string{"message", "callback_query"}
+
+// fetchFunc performs one getUpdates call starting at offset.
+type fetchFunc func(ctx context.Context, offset int64, timeout int) ([]TelegramUpdate, error)
+
+// updateWaiter receives updates accepted by match.
+type updateWaiter struct {
+	match   func(TelegramUpdate) bool
+	replyTo int64 // Message ID this waiter expects replies to (0 = none)
+	ch      chan TelegramUpdate
+	errc    chan error // Receives the error when polling keeps failing
+}
+
+// updateHub is the single consumer of getUpdates. Telegram confirms updates
+// by offset, so concurrent pollers steal each other's updates; the hub polls
+// on behalf of all in-flight calls and hands each update to exactly one
+// waiter. Updates nobody is waiting for are kept for the next get_updates.
+type updateHub struct {
+	fetch      fetchFunc
+	retryDelay time.Duration
+
+	mu      sync.Mutex
+	offset  int64 // Highest update ID received
+	pending []TelegramUpdate
+	waiters []*updateWaiter
+	polling bool
+}
+
+func newUpdateHub(fetch fetchFunc) *updateHub {
+	return &updateHub{fetch: fetch, retryDelay: hubRetryDelay}
+}
+
+// subscribe registers a waiter that accepts up to size updates. Buffered
+// updates it matches are delivered immediately. Call unsubscribe when done.
+func (h *updateHub) subscribe(w *updateWaiter, size int) {
+	w.ch = make(chan TelegramUpdate, size)
+	w.errc = make(chan error, 1)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	kept := h.pending[:0]
+	for _, u := range h.pending {
+		if w.match(u) && len(w.ch) < cap(w.ch) {
+			w.ch <- u
+			continue
+		}
+		kept = append(kept, u)
+	}
+	h.pending = kept
+
+	h.waiters = append(h.waiters, w)
+	if !h.polling {
+		h.polling = true
+		go h.poll()
+	}
+}
+
+// unsubscribe removes a waiter. Updates it received but did not read are
+// handed to the remaining waiters or buffered.
+func (h *updateHub) unsubscribe(w *updateWaiter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, other := range h.waiters {
+		if other == w {
+			h.waiters = append(h.waiters[:i], h.waiters[i+1:]...)
+			break
+		}
+	}
+
+	for {
+		select {
+		case u := <-w.ch:
+			h.dispatch(u)
+		default:
+			return
+		}
+	}
+}
+
+// poll fetches updates while anyone is waiting. After maxFetchFailures
+// failed calls in a row, the waiters are dropped and given the last error.
+func (h *updateHub) poll() {
+	failures := 0
+	for {
+		h.mu.Lock()
+		if len(h.waiters) == 0 {
+			h.polling = false
+			h.mu.Unlock()
+			return
+		}
+		offset := h.offset
+		h.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), (hubPollTimeout+10)*time.Second)
+		updates, err := h.fetch(ctx, offset+1, hubPollTimeout)
+		cancel()
+		if err != nil {
+			if failures++; failures >= maxFetchFailures {
+				failures = 0
+				h.fail(fmt.Errorf("polling Telegram failed %d times in a row: %w", maxFetchFailures, err))
+				continue
+			}
+			time.Sleep(h.retryDelay)
+			continue
+		}
+		failures = 0
+
+		h.mu.Lock()
+		for _, u := range updates {
+			if u.UpdateID > h.offset {
+				h.offset = u.UpdateID
+			}
+			h.dispatch(u)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// fail drops all waiters and gives each of them err.
+func (h *updateHub) fail(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, w := range h.waiters {
+		select {
+		case w.errc <- err:
+		default:
+		}
+	}
+	h.waiters = nil
+}
+
+// dispatch hands u to one waiter: one expecting a reply to the replied-to
+// message first, otherwise the first matching waiter in subscription order.
+// Must be called with h.mu held.
+func (h *updateHub) dispatch(u TelegramUpdate) {
+	if u.Message != nil && u.Message.ReplyTo != nil {
+		for _, w := range h.waiters {
+			if w.replyTo != 0 && w.replyTo == u.Message.ReplyTo.MessageID && w.match(u) && h.deliver(w, u) {
+				return
+			}
+		}
+	}
+
+	for _, w := range h.waiters {
+		if w.match(u) && h.deliver(w, u) {
+			return
+		}
+	}
+
+	h.addPending(u)
+}
+
+// collect waits up to timeout for updates accepted by match and returns at
+// most limit of them. It returns early once at least one update arrived, and
+// with an error if polling keeps failing.
+func (h *updateHub) collect(ctx context.Context, match func(TelegramUpdate) bool, timeout time.Duration, limit int) ([]TelegramUpdate, error) {
+	w := &updateWaiter{match: match}
+	h.subscribe(w, limit)
+	defer h.unsubscribe(w)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var updates []TelegramUpdate
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	case <-timer.C:
+		return nil, nil
+	case err := <-w.errc:
+		return nil, err
+	case u := <-w.ch:
+		updates = append(updates, u)
+	}
+
+	for len(updates) < limit {
+		select {
+		case u := <-w.ch:
+			updates = append(updates, u)
+		default:
+			return updates, nil
+		}
+	}
+	return updates, nil
+}
+
+// listen gathers updates accepted by match for the whole duration, or until
+// max updates have arrived. If polling keeps failing, it returns the
+// updates gathered so far with the error.
+func (h *updateHub) listen(ctx context.Context, match func(TelegramUpdate) bool, duration time.Duration, max int) ([]TelegramUpdate, error) {
+	w := &updateWaiter{match: match}
+	h.subscribe(w, max)
+	defer h.unsubscribe(w)
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	var updates []TelegramUpdate
+	for len(updates) < max {
+		select {
+		case <-ctx.Done():
+			return updates, nil
+		case <-timer.C:
+			return updates, nil
+		case err := <-w.errc:
+			return updates, err
+		case u := <-w.ch:
+			updates = append(updates, u)
+		}
+	}
+	return updates, nil
+}
+
+// deliver sends u to w without blocking.
+func (h *updateHub) deliver(w *updateWaiter, u TelegramUpdate) bool {
+	select {
+	case w.ch <- u:
+		return true
+	default:
+		return false
+	}
+}
+
+// addPending buffers u, dropping the oldest update when full.
+// Must be called with h.mu held.
+func (h *updateHub) addPending(u TelegramUpdate) {
+	if len(h.pending) >= maxPendingUpdates {
+		h.pending = h.pending[1:]
+	}
+	h.pending = append(h.pending, u)
+}
+
+// fetchUpdates calls getUpdates with long polling.
+func (s *Server) fetchUpdates(ctx context.Context, offset int64, timeout int) ([]TelegramUpdate, error) {
+	payload := map[string]interface{}{
+		"timeout":         timeout,
+		"limit":           100,
+		"allowed_updates": allowedUpdates,
+	}
+	if offset > 0 {
+		payload["offset"] = offset
+	}
+
+	// Create a client with extended timeout for long polling
+	client := &http.Client{
+		Timeout: time.Duration(timeout+10) * time.Second,
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates", s.botToken)
+	jsonData, _ := json.Marshal(payload)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var updatesResp GetUpdatesResponse
+	if err := json.Unmarshal(body, &updatesResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !updatesResp.OK {
+		return nil, fmt.Errorf("Telegram API error: %s", string(body))
+	}
+
+	return updatesResp.Result, nil
+}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFetch serves the batches sent on its channel, one per getUpdates call.
+func fakeFetch(batches <-chan []TelegramUpdate) fetchFunc {
+	return func(ctx context.Context, offset int64, timeout int) ([]TelegramUpdate, error) {
+		select {
+		case b := <-batches:
+			return b, nil
+		case <-time.After(10 * time.Millisecond):
+			return nil, nil
+		}
+	}
+}
+
+func reply(updateID, messageID, replyTo int64) TelegramUpdate {
+	return TelegramUpdate{
+		UpdateID: updateID,
+		Message: &TelegramMessage{
+			MessageID: messageID,
+			Chat:      &TelegramChat{ID: 1},
+			Text:      "reply",
+			ReplyTo:   &TelegramMessage{MessageID: replyTo},
+		},
+	}
+}
+
+func TestConcurrentWaitsGetTheirOwnReply(t *testing.T) {
+	batches := make(chan []TelegramUpdate, 1)
+	hub := newUpdateHub(fakeFetch(batches))
+
+	anyMessage := func(u TelegramUpdate) bool { return u.Message != nil }
+	first := &updateWaiter{replyTo: 10, match: anyMessage}
+	second := &updateWaiter{replyTo: 20, match: anyMessage}
+	hub.subscribe(first, 1)
+	defer hub.unsubscribe(first)
+	hub.subscribe(second, 1)
+	defer hub.unsubscribe(second)
+
+	// The reply to the second wait arrives first.
+	batches <- []TelegramUpdate{reply(1, 21, 20), reply(2, 11, 10)}
+
+	var wg sync.WaitGroup
+	got := make([]int64, 2)
+	for i, w := range []*updateWaiter{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case u := <-w.ch:
+				got[i] = u.Message.ReplyTo.MessageID
+			case <-time.After(2 * time.Second):
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got[0] != 10 || got[1] != 20 {
+		t.Errorf("waits got replies to %v, want [10 20]", got)
+	}
+}
+
+func TestPollFailuresReachWaiters(t *testing.T) {
+	fetchErr := errors.New("connection refused")
+	calls := 0
+	hub := newUpdateHub(func(ctx context.Context, offset int64, timeout int) ([]TelegramUpdate, error) {
+		calls++
+		return nil, fetchErr
+	})
+	hub.retryDelay = time.Millisecond
+
+	anything := func(TelegramUpdate) bool { return true }
+	updates, err := hub.collect(context.Background(), anything, 5*time.Second, 10)
+	if !errors.Is(err, fetchErr) {
+		t.Fatalf("collect err = %v, want %v", err, fetchErr)
+	}
+	if len(updates) != 0 {
+		t.Errorf("collect returned %d updates, want none", len(updates))
+	}
+	if calls < maxFetchFailures {
+		t.Errorf("gave up after %d calls, want at least %d", calls, maxFetchFailures)
+	}
+
+	if _, err := hub.listen(context.Background(), anything, 5*time.Second, 10); !errors.Is(err, fetchErr) {
+		t.Errorf("listen err = %v, want %v", err, fetchErr)
+	}
+}
+
+func TestPollRecoversFromTransientFailure(t *testing.T) {
+	failures := 0
+	hub := newUpdateHub(func(ctx context.Context, offset int64, timeout int) ([]TelegramUpdate, error) {
+		if failures < maxFetchFailures-1 {
+			failures++
+			return nil, errors.New("timeout")
+		}
+		return []TelegramUpdate{reply(1, 11, 10)}, nil
+	})
+	hub.retryDelay = time.Millisecond
+
+	updates, err := hub.collect(context.Background(), func(TelegramUpdate) bool { return true }, 5*time.Second, 1)
+	if err != nil {
+		t.Fatalf("collect err = %v, want nil", err)
+	}
+	if len(updates) != 1 {
+		t.Errorf("collect returned %d updates, want 1", len(updates))
+	}
+}
@@ -10,67 +10,55 @@ import (
 )
 
 var (
-	// Modern color palette
-	UserStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("81")).  // Bright cyan
-			Bold(true)
-
-	AssistantStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("114"))  // Soft green
-
-	ErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("203")).  // Coral red
-			Bold(true)
-
-	InfoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("222"))  // Warm yellow
-
-	SystemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("183")).  // Soft purple
-			Italic(true)
-
-	StatusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245")).  // Medium gray
-			Italic(true)
-
-	TokenStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))  // Dim gray
-
-	ToolStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("215")).  // Orange
-			Bold(true)
+	UserStyle      lipgloss.Style
+	AssistantStyle lipgloss.Style
+	ErrorStyle     lipgloss.Style
+	InfoStyle      lipgloss.Style
+	SystemStyle    lipgloss.Style
+	StatusStyle    lipgloss.Style
+	TokenStyle     lipgloss.Style
+	ToolStyle      lipgloss.Style
 
 	// Box styles for modern UI
-	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("62")).  // Soft blue border
-			Padding(0, 1)
-
-	HeaderStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("81")).
-			Bold(true)
-
-	DimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
-
-	SuccessStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("114")).  // Green
-			Bold(true)
+	BoxStyle     lipgloss.Style
+	HeaderStyle  lipgloss.Style
+	DimStyle     lipgloss.Style
+	SuccessStyle lipgloss.Style
+	WarningStyle lipgloss.Style
+	AccentStyle  lipgloss.Style
+)
 
-	WarningStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("222")).  // Yellow
-			Bold(true)
+// applyStyles rebuilds the package styles from the colors of t.
+func applyStyles(t Theme) {
+	UserStyle = lipgloss.NewStyle().Foreground(t.User).Bold(true)
+	AssistantStyle = lipgloss.NewStyle().Foreground(t.Assistant)
+	ErrorStyle = lipgloss.NewStyle().Foreground(t.Error).Bold(true)
+	InfoStyle = lipgloss.NewStyle().Foreground(t.Info)
+	SystemStyle = lipgloss.NewStyle().Foreground(t.System).Italic(true)
+	StatusStyle = lipgloss.NewStyle().Foreground(t.Status).Italic(true)
+	TokenStyle = lipgloss.NewStyle().Foreground(t.Dim)
+	ToolStyle = lipgloss.NewStyle().Foreground(t.Tool).Bold(true)
 
-	AccentStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("147"))  // Light purple
-)
+	BoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Padding(0, 1)
+	HeaderStyle = lipgloss.NewStyle().Foreground(t.Header).Bold(true)
+	DimStyle = lipgloss.NewStyle().Foreground(t.Dim)
+	SuccessStyle = lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+	WarningStyle = lipgloss.NewStyle().Foreground(t.Warning).Bold(true)
+	AccentStyle = lipgloss.NewStyle().Foreground(t.Accent)
+}
 
 type Formatter struct {
 	colored         bool
 	provider        string // display name (e.g., "DeepSeek", "Ollama")
 	providerRaw     string // raw name (e.g., "deepseek", "ollama")
+	pricing         map[string]ModelPricing // configured prices, checked before deepSeekPricing
 }
 
+// NewFormatter creates a formatter. Colors are used only if colored is set
+// and ColorSupported, so redirected output and NO_COLOR get plain text.
 func NewFormatter(colored bool, provider ...string) *Formatter {
 	displayName := "AI"
 	rawName := ""
@@ -79,12 +67,17 @@ func NewFormatter(colored bool, provider ...string) *Formatter {
 		displayName = formatProviderName(provider[0])
 	}
 	return &Formatter{
-		colored:     colored,
+		colored:     colored && ColorSupported(),
 		provider:    displayName,
 		providerRaw: rawName,
 	}
 }
 
+// Colored reports whether the formatter emits colors.
+func (f *Formatter) Colored() bool {
+	return f.colored
+}
+
 // formatProviderName returns a display-friendly provider name.
 func formatProviderName(provider string) string {
 	switch provider {
@@ -117,6 +110,16 @@ func (f *Formatter) FormatAssistantMessage(msg string) string {
 	return prefix + msg
 }
 
+// FormatReasoning renders a reasoning model's chain of thought, dimmed so
+// it stands apart from the answer.
+func (f *Formatter) FormatReasoning(reasoning string) string {
+	reasoning = strings.TrimSpace(reasoning)
+	if f.colored {
+		return DimStyle.Render("Reasoning:") + "\n" + DimStyle.Italic(true).Render(reasoning)
+	}
+	return "Reasoning:\n" + reasoning
+}
+
 func (f *Formatter) FormatError(err error) string {
 	prefix := "Error: "
 	if f.colored {
@@ -176,6 +179,11 @@ func (f *Formatter) FormatTokenUsage(usage api.Usage, opts ...TokenUsageOptions)
 		fmt.Sprintf("tokens: input=%d, output=%d", usage.InputTokens, usage.OutputTokens),
 	}
 
+	// Add cached input tokens if the provider reported any
+	if usage.CacheHitTokens > 0 {
+		parts = append(parts, fmt.Sprintf("cached: %d", usage.CacheHitTokens))
+	}
+
 	// Add API call count if more than 1
 	if apiCallCount > 1 {
 		parts = append(parts, fmt.Sprintf("api_calls: %d", apiCallCount))
@@ -187,7 +195,7 @@ func (f *Formatter) FormatTokenUsage(usage api.Usage, opts ...TokenUsageOptions)
 	}
 
 	// Add cost if applicable (DeepSeek models)
-	cost := calculateCost(usage, model, f.providerRaw)
+	cost := f.Cost(usage, model)
 	if cost > 0 {
 		parts = append(parts, fmt.Sprintf("cost: $%.6f", cost))
 	}
@@ -218,37 +226,156 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.2fs", d.Seconds())
 }
 
+// ModelPricing is the price of a model per 1M tokens (USD).
+type ModelPricing struct {
+	InputPer1M         float64 // input tokens (cache miss)
+	InputCacheHitPer1M float64 // input tokens served from the context cache
+	OutputPer1M        float64
+}
+
 // DeepSeek pricing per 1M tokens (USD)
 // https://api-docs.deepseek.com/quick_start/pricing
-var deepSeekPricing = map[string]struct {
-	inputPer1M  float64
-	outputPer1M float64
-}{
+var deepSeekPricing = map[string]ModelPricing{
 	"deepseek-chat": {
-		inputPer1M:  0.14,  // $0.14 per 1M input tokens (cache miss)
-		outputPer1M: 0.28,  // $0.28 per 1M output tokens
+		InputPer1M:         0.14,  // $0.14 per 1M input tokens (cache miss)
+		InputCacheHitPer1M: 0.014, // $0.014 per 1M input tokens (cache hit)
+		OutputPer1M:        0.28,  // $0.28 per 1M output tokens
 	},
 	"deepseek-reasoner": {
-		inputPer1M:  0.55,  // $0.55 per 1M input tokens (cache miss)
-		outputPer1M: 2.19,  // $2.19 per 1M output tokens
+		InputPer1M:         0.55, // $0.55 per 1M input tokens (cache miss)
+		InputCacheHitPer1M: 0.14, // $0.14 per 1M input tokens (cache hit)
+		OutputPer1M:        2.19, // $2.19 per 1M output tokens
 	},
 }
 
-func calculateCost(usage api.Usage, model, provider string) float64 {
+// SetPricing adds or overrides per-model prices, e.g. for models missing
+// from the built-in table.
+func (f *Formatter) SetPricing(pricing map[string]ModelPricing) {
+	f.pricing = pricing
+}
+
+// Cost returns the estimated cost of usage in USD for the formatter's
+// provider. Local providers are free.
+func (f *Formatter) Cost(usage api.Usage, model string) float64 {
+	return calculateCost(usage, model, f.providerRaw, f.pricing)
+}
+
+// CostRow is one line of the session cost table.
+type CostRow struct {
+	Operation string
+	Calls     int
+	Usage     api.Usage
+	Cost      float64
+}
+
+// FormatCostTable renders spend per operation with a total line. Local
+// providers show "free (local)" instead of a price.
+func (f *Formatter) FormatCostTable(rows []CostRow) string {
+	if len(rows) == 0 {
+		return f.FormatInfo("No API calls in this session yet.")
+	}
+
+	formatCost := func(cost float64) string {
+		if f.providerRaw == "ollama" {
+			return "free (local)"
+		}
+		return fmt.Sprintf("$%.6f", cost)
+	}
+
+	header := fmt.Sprintf("%-16s %6s %12s %12s %14s", "Operation", "Calls", "Input", "Output", "Cost")
+	lines := []string{header, strings.Repeat("─", len(header))}
+
+	var total CostRow
+	for _, row := range rows {
+		lines = append(lines, fmt.Sprintf("%-16s %6d %12d %12d %14s",
+			row.Operation, row.Calls, row.Usage.InputTokens, row.Usage.OutputTokens, formatCost(row.Cost)))
+		total.Calls += row.Calls
+		total.Usage.Add(row.Usage)
+		total.Cost += row.Cost
+	}
+
+	lines = append(lines, strings.Repeat("─", len(header)))
+	totalLine := fmt.Sprintf("%-16s %6d %12d %12d %14s",
+		"Total", total.Calls, total.Usage.InputTokens, total.Usage.OutputTokens, formatCost(total.Cost))
+
+	if f.colored {
+		lines[0] = HeaderStyle.Render(lines[0])
+		totalLine = AccentStyle.Render(totalLine)
+	}
+	lines = append(lines, totalLine)
+
+	return f.FormatBox("Session cost", strings.Join(lines, "\n"))
+}
+
+// FormatModelTable renders the provider's models, marking current.
+func (f *Formatter) FormatModelTable(models []api.ModelInfo, current string) string {
+	if len(models) == 0 {
+		return f.FormatInfo("The provider reported no models.")
+	}
+
+	width := len("Model")
+	for _, m := range models {
+		width = max(width, len(m.ID))
+	}
+
+	header := fmt.Sprintf("  %-*s %10s %10s", width, "Model", "Context", "Size")
+	lines := []string{header, strings.Repeat("─", len(header))}
+	for _, m := range models {
+		marker := "  "
+		if m.ID == current {
+			marker = "* "
+		}
+
+		window := "-"
+		if m.ContextWindow > 0 {
+			window = fmt.Sprintf("%dk", m.ContextWindow/1000)
+		}
+		size := "-"
+		if m.Size > 0 {
+			size = fmt.Sprintf("%.1f GB", float64(m.Size)/(1<<30))
+		}
+
+		line := fmt.Sprintf("%s%-*s %10s %10s", marker, width, m.ID, window, size)
+		if f.colored && m.ID == current {
+			line = AccentStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if f.colored {
+		lines[0] = HeaderStyle.Render(lines[0])
+	}
+
+	return f.FormatBox(f.provider+" models", strings.Join(lines, "\n"))
+}
+
+func calculateCost(usage api.Usage, model, provider string, overrides map[string]ModelPricing) float64 {
 	// Ollama is free (local)
 	if provider == "ollama" {
 		return 0
 	}
 
-	// Look up pricing for the model
-	pricing, ok := deepSeekPricing[model]
+	// Look up pricing for the model, configured prices first
+	pricing, ok := overrides[model]
+	if !ok {
+		pricing, ok = deepSeekPricing[model]
+	}
 	if !ok {
 		// Default to deepseek-chat pricing for unknown models
 		pricing = deepSeekPricing["deepseek-chat"]
 	}
 
-	inputCost := float64(usage.InputTokens) * pricing.inputPer1M / 1_000_000
-	outputCost := float64(usage.OutputTokens) * pricing.outputPer1M / 1_000_000
+	// Without a cache-hit price, cached tokens cost the same as a miss
+	hitRate := pricing.InputCacheHitPer1M
+	if hitRate == 0 {
+		hitRate = pricing.InputPer1M
+	}
+
+	hit := min(usage.CacheHitTokens, usage.InputTokens)
+	miss := usage.InputTokens - hit
+
+	inputCost := (float64(hit)*hitRate + float64(miss)*pricing.InputPer1M) / 1_000_000
+	outputCost := float64(usage.OutputTokens) * pricing.OutputPer1M / 1_000_000
 
 	return inputCost + outputCost
 }
@@ -262,20 +389,20 @@ func (f *Formatter) FormatWelcome(model string, provider ...string) string {
 	if f.colored {
 		// Modern styled welcome
 		titleStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("81")).
+			Foreground(palette.Header).
 			Bold(true)
 
 		subtitleStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245"))
+			Foreground(palette.Status)
 
 		labelStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+			Foreground(palette.Dim)
 
 		valueStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("114"))
+			Foreground(palette.Success)
 
 		borderStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("62"))
+			Foreground(palette.Border)
 
 		// Build welcome box
 		topBorder := borderStyle.Render("╭─────────────────────────────────────────╮")
@@ -325,21 +452,21 @@ func (f *Formatter) FormatWelcome(model string, provider ...string) string {
 func (f *Formatter) FormatHelp() string {
 	if f.colored {
 		headerStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("81")).
+			Foreground(palette.Header).
 			Bold(true)
 
 		cmdStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("114"))
+			Foreground(palette.Success)
 
 		descStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+			Foreground(palette.Text)
 
 		sectionStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("147")).
+			Foreground(palette.Accent).
 			Bold(true)
 
 		dimStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245"))
+			Foreground(palette.Status)
 
 		formatCmd := func(cmd, desc string) string {
 			return "  " + cmdStyle.Render(cmd) + " " + descStyle.Render(desc)
@@ -353,23 +480,48 @@ func (f *Formatter) FormatHelp() string {
 			formatCmd("/help", "Show this help"),
 			formatCmd("/help <query>", "Ask about the codebase (uses code index)"),
 			formatCmd("/clear", "Clear conversation"),
+			formatCmd("/history", "List messages with token estimates"),
+			formatCmd("/history trim <n>", "Drop the messages before #n"),
+			formatCmd("/history backups|restore [n]", "List or restore history backups"),
+			formatCmd("/checkpoint save|load <name>", "Save or return to a checkpoint"),
+			formatCmd("/search [--role r] <term>", "Search the conversation"),
+			formatCmd("/budget [extend [usd]]", "Show or extend the session budget"),
+			formatCmd("/cost", "Session spend by operation"),
 			formatCmd("/quit", "Exit chat"),
 			"",
 			sectionStyle.Render("Configuration"),
 			formatCmd("/system <prompt>", "Set system prompt"),
 			formatCmd("/show", "Show system prompt"),
 			formatCmd("/provider", "Show provider info"),
+			formatCmd("/profile", "Show the active config profile"),
+			formatCmd("/models [refresh]", "List the provider's models"),
 			formatCmd("/temp <0-2>", "Set temperature"),
 			"",
 			sectionStyle.Render("Input"),
-			formatCmd("/file <path>", "Send file content"),
+			formatCmd("/file <paths|globs>", "Send files (--head N)"),
+			formatCmd("/edit", "Compose in $EDITOR"),
+			formatCmd("/paste", "Multiline input (end with .)"),
+			formatCmd("/image <path|url>", "Attach an image to the next message"),
+			formatCmd("/export <file>", "Export chat (.md or .html)"),
+			formatCmd("/save <file>", "Save conversation to a file"),
+			formatCmd("/load <file>", "Replace conversation from a file"),
 			"",
 			sectionStyle.Render("Features"),
 			formatCmd("/clarify on|off", "Toggle clarifying questions"),
 			formatCmd("/askuser on|off", "Toggle interactive menus"),
-			formatCmd("/format json|clear", "Response format"),
+			formatCmd("/confirm on|off", "Approve tool calls before they run"),
+			formatCmd("/plan on|off", "Review each batch of tool calls first"),
+			formatCmd("/reasoning on|off", "Show the model's chain of thought"),
+			formatCmd("/pager on|off", "Page long output in $PAGER"),
+			formatCmd("/format json|yaml|clear", "Response format"),
+			formatCmd("/format schema <file>", "Follow a JSON Schema or example"),
+			formatCmd("/format strict on|off", "Validate formatted responses"),
 			formatCmd("/context", "Context window status"),
+			formatCmd("/context keep <n>", "Pairs kept when summarizing"),
 			formatCmd("/mcp tools", "List MCP tools"),
+			formatCmd("/mcp restart <name>", "Restart an MCP server"),
+			formatCmd("/mcp reload", "Apply changes to mcp.json"),
+			formatCmd("/mcp disable|enable <tool>", "Hide or restore an MCP tool"),
 			"",
 			headerStyle.Render("Tips"),
 			dimStyle.Render("  Ctrl+C or Ctrl+D to exit"),
@@ -388,15 +540,39 @@ func (f *Formatter) FormatHelp() string {
 		"  /help                - Show help",
 		"  /help <query>        - Ask about the codebase",
 		"  /clear               - Clear history",
+		"  /history [trim n]    - List/trim messages",
+		"  /history backups|restore [n] - History backups",
+		"  /checkpoint save|load <name> - Checkpoints",
+		"  /search <term>       - Search conversation",
+		"  /budget [extend]     - Session budget",
+		"  /cost                - Session spend",
 		"  /system <prompt>     - Set system prompt",
 		"  /show                - Show system prompt",
 		"  /provider            - Show provider",
+		"  /profile             - Show config profile",
+		"  /models [refresh]    - List models",
 		"  /temp <value>        - Set temperature",
-		"  /file <filename>     - Send file",
+		"  /file <paths|globs>  - Send files (--head N)",
+		"  /edit                - Compose in $EDITOR",
+		"  /paste               - Multiline input",
+		"  /image <path|url>    - Attach image",
+		"  /export <file>       - Export chat (.md/.html)",
+		"  /save <file>         - Save conversation",
+		"  /load <file>         - Load conversation",
 		"  /clarify on|off      - Toggle clarification",
-		"  /format json|clear   - Response format",
+		"  /confirm on|off      - Approve tool calls",
+		"  /plan on|off         - Review tool call batches",
+		"  /reasoning on|off    - Show chain of thought",
+		"  /pager on|off        - Page long output",
+		"  /format json|yaml|clear - Response format",
+		"  /format schema <file> - Follow a JSON Schema",
+		"  /format strict on|off - Validate responses",
 		"  /context             - Context status",
+		"  /context keep <n>    - Pairs kept when summarizing",
 		"  /mcp tools           - MCP tools",
+		"  /mcp restart <name>  - Restart MCP server",
+		"  /mcp reload          - Re-read mcp.json",
+		"  /mcp disable|enable <tool> - Hide/restore MCP tool",
 		"  /quit                - Exit",
 		"",
 	}
@@ -408,9 +584,9 @@ func (f *Formatter) FormatHelp() string {
 func (f *Formatter) FormatPrompt() string {
 	if f.colored {
 		promptStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("62"))
+			Foreground(palette.Border)
 		arrowStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("114")).
+			Foreground(palette.Success).
 			Bold(true)
 		return promptStyle.Render("you") + arrowStyle.Render(" > ")
 	}
@@ -421,7 +597,7 @@ func (f *Formatter) FormatPrompt() string {
 func (f *Formatter) FormatContinuePrompt() string {
 	if f.colored {
 		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
+			Foreground(palette.Dim).
 			Render("... ")
 	}
 	return "... "
@@ -431,10 +607,10 @@ func (f *Formatter) FormatContinuePrompt() string {
 func (f *Formatter) FormatPasteInfo(lineCount int) string {
 	if f.colored {
 		countStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("114")).
+			Foreground(palette.Success).
 			Bold(true)
 		textStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245"))
+			Foreground(palette.Status)
 
 		plural := "lines"
 		if lineCount == 1 {
@@ -453,12 +629,12 @@ func (f *Formatter) FormatPasteInfo(lineCount int) string {
 func (f *Formatter) FormatBox(title, content string) string {
 	if f.colored {
 		titleStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("81")).
+			Foreground(palette.Header).
 			Bold(true)
 
 		borderStyle := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("62")).
+			BorderForeground(palette.Border).
 			Padding(0, 1)
 
 		header := titleStyle.Render(title)
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/notexe/cli-chat/internal/api"
+)
+
+func TestCalculateCost(t *testing.T) {
+	custom := map[string]ModelPricing{
+		"custom":         {InputPer1M: 1, InputCacheHitPer1M: 0.1, OutputPer1M: 2},
+		"no-cache-price": {InputPer1M: 1, OutputPer1M: 2},
+		"deepseek-chat":  {InputPer1M: 10, InputCacheHitPer1M: 1, OutputPer1M: 20},
+	}
+
+	tests := []struct {
+		name      string
+		usage     api.Usage
+		model     string
+		provider  string
+		overrides map[string]ModelPricing
+		want      float64
+	}{
+		{
+			name:     "chat without cache hits",
+			usage:    api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			model:    "deepseek-chat",
+			provider: "deepseek",
+			want:     0.14 + 0.28,
+		},
+		{
+			name:     "chat with cache hits",
+			usage:    api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000, CacheHitTokens: 750_000, CacheMissTokens: 250_000},
+			model:    "deepseek-chat",
+			provider: "deepseek",
+			want:     0.75*0.014 + 0.25*0.14 + 0.28,
+		},
+		{
+			name:     "reasoner all cached",
+			usage:    api.Usage{InputTokens: 2_000_000, OutputTokens: 500_000, CacheHitTokens: 2_000_000},
+			model:    "deepseek-reasoner",
+			provider: "deepseek",
+			want:     2*0.14 + 0.5*2.19,
+		},
+		{
+			name:     "cache hits above input are capped",
+			usage:    api.Usage{InputTokens: 1_000_000, CacheHitTokens: 5_000_000},
+			model:    "deepseek-chat",
+			provider: "deepseek",
+			want:     0.014,
+		},
+		{
+			name:     "unknown model priced as chat",
+			usage:    api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			model:    "deepseek-future",
+			provider: "deepseek",
+			want:     0.14 + 0.28,
+		},
+		{
+			name:      "configured price",
+			usage:     api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000, CacheHitTokens: 500_000},
+			model:     "custom",
+			provider:  "deepseek",
+			overrides: custom,
+			want:      0.5*0.1 + 0.5*1 + 2,
+		},
+		{
+			name:      "configured price overrides built-in",
+			usage:     api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			model:     "deepseek-chat",
+			provider:  "deepseek",
+			overrides: custom,
+			want:      10 + 20,
+		},
+		{
+			name:      "no cache-hit price charges hits as misses",
+			usage:     api.Usage{InputTokens: 1_000_000, CacheHitTokens: 1_000_000},
+			model:     "no-cache-price",
+			provider:  "deepseek",
+			overrides: custom,
+			want:      1,
+		},
+		{
+			name:     "ollama is free",
+			usage:    api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			model:    "llama3.2",
+			provider: "ollama",
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateCost(tt.usage, tt.model, tt.provider, tt.overrides)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("calculateCost = %.6f, want %.6f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatModelTableContextWindow(t *testing.T) {
+	f := NewFormatter(false)
+	table := f.FormatModelTable([]api.ModelInfo{
+		{ID: "deepseek-chat", ContextWindow: 128_000},
+		{ID: "llama3.1", ContextWindow: 131_072},
+		{ID: "unknown"},
+	}, "deepseek-chat")
+
+	for _, want := range []string{"128k", "131k"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table does not show %s:\n%s", want, table)
+		}
+	}
+	if strings.Contains(table, "125k") {
+		t.Errorf("table shows 125k for a 128000-token window:\n%s", table)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -16,14 +17,22 @@ type SelectorOption struct {
 	Description string
 }
 
-// Selector provides an interactive arrow-key navigable menu
+// Selector provides an interactive arrow-key navigable menu. Typing
+// letters filters the options; digits pick an option by its number.
 type Selector struct {
 	question    string
 	options     []SelectorOption
-	selected    int
+	selected    int // Index into options
 	multiSelect bool
 	selections  map[int]bool
 	colored     bool
+	defaultIdx  int  // Option highlighted at the start, -1 for none
+	optional    bool // Esc skips the question
+
+	filter  string // Typed text the visible options contain
+	visible []int  // Indexes of the options matching filter
+	number  int    // Option number typed so far, 0 when none
+	offset  int    // First visible option shown when the list scrolls
 
 	cursorStyle   lipgloss.Style
 	selectedStyle lipgloss.Style
@@ -42,20 +51,59 @@ func NewSelector(question string, options []SelectorOption, multiSelect bool, co
 		multiSelect: multiSelect,
 		selections:  make(map[int]bool),
 		colored:     colored,
+		defaultIdx:  -1,
+
+		cursorStyle:   lipgloss.NewStyle().Foreground(palette.Cursor).Bold(true),
+		selectedStyle: lipgloss.NewStyle().Foreground(palette.Success).Bold(true),
+		optionStyle:   lipgloss.NewStyle().Foreground(palette.Text),
+		dimStyle:      lipgloss.NewStyle().Foreground(palette.Dim),
+		questionStyle: lipgloss.NewStyle().Foreground(palette.Header).Bold(true),
+		hintStyle:     lipgloss.NewStyle().Foreground(palette.Status).Italic(true),
+	}
+}
 
-		cursorStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true),
-		selectedStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("114")).Bold(true),
-		optionStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("252")),
-		dimStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
-		questionStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("81")).Bold(true),
-		hintStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true),
+// SetDefault highlights the option at index i, so Enter alone selects it.
+// An index out of range is ignored.
+func (s *Selector) SetDefault(i int) {
+	if i < 0 || i >= len(s.options) {
+		return
 	}
+	s.defaultIdx = i
+	s.selected = i
+}
+
+// SetOptional lets the user skip the question with Esc, in which case Run
+// returns no options.
+func (s *Selector) SetOptional(optional bool) {
+	s.optional = optional
 }
 
+// customLabel is the option RunWithCustomOption adds for a typed answer.
+const customLabel = "Other (type your own)…"
+
 // Run displays the selector and returns the selected option(s)
 func (s *Selector) Run() ([]string, error) {
+	selected, err := s.run()
+	if err != nil {
+		return nil, err
+	}
+	return s.labels(selected), nil
+}
+
+// RunIndexes displays the selector and returns the indexes of the selected
+// option(s), none when the user skipped.
+func (s *Selector) RunIndexes() ([]int, error) {
+	return s.run()
+}
+
+// run displays the selector and returns the indexes of the selected
+// options, none when the user skipped.
+func (s *Selector) run() ([]int, error) {
 	fd := int(os.Stdin.Fd())
 
+	// Options may have been added since NewSelector
+	s.setFilter("")
+
 	if !term.IsTerminal(fd) {
 		return s.runSimple()
 	}
@@ -76,11 +124,8 @@ func (s *Selector) Run() ([]string, error) {
 	// Hide cursor
 	fmt.Print("\033[?25l")
 
-	// Calculate total lines for clearing
-	totalLines := len(s.options) + 3
-
 	// Initial render
-	s.printMenu()
+	lines := s.printMenu()
 
 	reader := bufio.NewReader(os.Stdin)
 	for {
@@ -90,25 +135,46 @@ func (s *Selector) Run() ([]string, error) {
 		}
 
 		action := ""
+		digit := b >= '0' && b <= '9' && s.filter == ""
+		numbered := s.number > 0
+		if !digit {
+			s.number = 0
+		}
 
-		switch b {
-		case 13, 10: // Enter
-			action = "select"
-		case 3: // Ctrl+C
+		switch {
+		case b == 13 || b == 10: // Enter
+			switch {
+			case numbered && s.multiSelect:
+				s.toggleSelection()
+			case len(s.visible) > 0:
+				action = "select"
+			}
+		case b == 3: // Ctrl+C
 			// Clear and exit
-			s.clearMenu(totalLines)
+			s.clearMenu(lines)
 			return nil, fmt.Errorf("cancelled")
-		case 'j': // vim down
-			s.moveDown()
-		case 'k': // vim up
-			s.moveUp()
-		case ' ': // Space
+		case b == 127 || b == 8: // Backspace
+			if s.filter != "" {
+				runes := []rune(s.filter)
+				s.setFilter(string(runes[:len(runes)-1]))
+			}
+		case b == 21: // Ctrl+U clears the filter
+			s.setFilter("")
+		case b == ' ': // Space
+			if len(s.visible) == 0 {
+				break
+			}
 			if s.multiSelect {
 				s.toggleSelection()
 			} else {
 				action = "select"
 			}
-		case 27: // Escape sequence
+		case b == 27 && reader.Buffered() == 0: // Esc alone, not a key sequence
+			if s.optional {
+				s.clearMenu(lines)
+				return []int{}, nil
+			}
+		case b == 27: // Escape sequence
 			b2, _ := reader.ReadByte()
 			if b2 == '[' {
 				b3, _ := reader.ReadByte()
@@ -117,58 +183,92 @@ func (s *Selector) Run() ([]string, error) {
 					s.moveUp()
 				case 'B': // Down
 					s.moveDown()
+				case '5', '6': // Page Up, Page Down
+					if b4, _ := reader.ReadByte(); b4 == '~' {
+						if b3 == '5' {
+							s.movePage(-1)
+						} else {
+							s.movePage(1)
+						}
+					}
 				}
 			}
-		default:
-			if b >= '1' && b <= '9' {
-				idx := int(b - '1')
-				if idx < len(s.options) {
-					s.selected = idx
-					if !s.multiSelect {
-						action = "select"
-					} else {
-						s.toggleSelection()
-					}
+		case digit:
+			if s.pickNumber(int(b - '0')) {
+				if s.multiSelect {
+					s.toggleSelection()
+				} else {
+					action = "select"
 				}
 			}
+		case s.filter == "" && b == 'j': // vim down
+			s.moveDown()
+		case s.filter == "" && b == 'k': // vim up
+			s.moveUp()
+		case b >= 32 && b < 127: // Printable characters filter the options
+			s.setFilter(s.filter + string(rune(b)))
+		case b >= 0xC0: // First byte of a multi-byte UTF-8 character
+			_ = reader.UnreadByte()
+			if r, _, err := reader.ReadRune(); err == nil {
+				s.setFilter(s.filter + string(r))
+			}
 		}
 
 		if action == "select" {
-			s.clearMenu(totalLines)
+			s.clearMenu(lines)
 			return s.getSelected(), nil
 		}
 
 		// Redraw
-		s.clearMenu(totalLines)
-		s.printMenu()
+		s.clearMenu(lines)
+		lines = s.printMenu()
 	}
 }
 
-func (s *Selector) printMenu() {
+// printMenu draws the menu and returns the number of lines it used.
+func (s *Selector) printMenu() int {
 	var sb strings.Builder
+	lines := 0
+	writeLine := func(text string, style lipgloss.Style) {
+		if s.colored {
+			text = style.Render(text)
+		}
+		sb.WriteString(text + "\r\n")
+		lines++
+	}
 
 	// Question
-	if s.colored {
-		sb.WriteString(s.questionStyle.Render(s.question))
-	} else {
-		sb.WriteString(s.question)
-	}
-	sb.WriteString("\r\n")
+	writeLine(s.question, s.questionStyle)
 
 	// Hint
-	hint := "[j/k or arrows] move  [enter] select"
+	hint := "[j/k or arrows] move  [1-n] pick  [type] filter  [enter] select"
 	if s.multiSelect {
-		hint = "[j/k or arrows] move  [space] toggle  [enter] confirm"
+		hint = "[j/k or arrows] move  [1-n] toggle  [type] filter  [space] toggle  [enter] confirm"
+	}
+	if s.optional {
+		hint += "  [esc] skip"
 	}
-	if s.colored {
-		sb.WriteString(s.hintStyle.Render(hint))
-	} else {
-		sb.WriteString(hint)
+	writeLine(hint, s.hintStyle)
+
+	switch {
+	case s.filter != "" && len(s.visible) == 0:
+		writeLine(fmt.Sprintf("Filter: %s (no matches, backspace to edit)", s.filter), s.hintStyle)
+	case s.filter != "":
+		writeLine(fmt.Sprintf("Filter: %s (%d of %d)", s.filter, len(s.visible), len(s.options)), s.hintStyle)
+	case s.number > 0:
+		writeLine(fmt.Sprintf("Number: %d", s.number), s.hintStyle)
+	default:
+		writeLine("", s.hintStyle)
 	}
-	sb.WriteString("\r\n\r\n")
 
-	// Options
-	for i, opt := range s.options {
+	// Options, scrolled to keep the cursor on screen
+	start, end := s.window()
+	if start > 0 {
+		writeLine(fmt.Sprintf("  ↑ %d more", start), s.dimStyle)
+	}
+	width := len(strconv.Itoa(len(s.options)))
+	for _, i := range s.visible[start:end] {
+		opt := s.options[i]
 		cursor := "  "
 		if i == s.selected {
 			cursor = "> "
@@ -183,29 +283,40 @@ func (s *Selector) printMenu() {
 			}
 		}
 
+		number := fmt.Sprintf("%*d. ", width, i+1)
+
 		label := opt.Label
 		if opt.Description != "" {
 			label += " - " + opt.Description
 		}
+		if i == s.defaultIdx {
+			label += " (default)"
+		}
 
 		if s.colored {
 			if i == s.selected {
 				sb.WriteString(s.cursorStyle.Render(cursor))
+				sb.WriteString(s.dimStyle.Render(number))
 				sb.WriteString(checkbox)
 				sb.WriteString(s.selectedStyle.Render(label))
 			} else {
-				sb.WriteString(s.dimStyle.Render(cursor))
+				sb.WriteString(s.dimStyle.Render(cursor + number))
 				sb.WriteString(checkbox)
 				sb.WriteString(s.optionStyle.Render(label))
 			}
 		} else {
-			sb.WriteString(cursor + checkbox + label)
+			sb.WriteString(cursor + number + checkbox + label)
 		}
 		sb.WriteString("\r\n")
+		lines++
+	}
+	if end < len(s.visible) {
+		writeLine(fmt.Sprintf("  ↓ %d more", len(s.visible)-end), s.dimStyle)
 	}
 
 	fmt.Print(sb.String())
 	os.Stdout.Sync()
+	return lines
 }
 
 func (s *Selector) clearMenu(lines int) {
@@ -216,7 +327,7 @@ func (s *Selector) clearMenu(lines int) {
 	os.Stdout.Sync()
 }
 
-func (s *Selector) runSimple() ([]string, error) {
+func (s *Selector) runSimple() ([]int, error) {
 	fmt.Println(s.question)
 	for i, opt := range s.options {
 		label := opt.Label
@@ -225,75 +336,209 @@ func (s *Selector) runSimple() ([]string, error) {
 		}
 		fmt.Printf("  [%d] %s\n", i+1, label)
 	}
-	fmt.Print("Enter number: ")
+	prompt := "Enter number"
+	if s.multiSelect {
+		prompt = "Enter numbers separated by commas"
+	}
+	switch {
+	case s.defaultIdx >= 0:
+		fmt.Printf("%s (default %d): ", prompt, s.defaultIdx+1)
+	case s.optional:
+		fmt.Printf("%s (empty to skip): ", prompt)
+	default:
+		fmt.Printf("%s: ", prompt)
+	}
 
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 
-	if len(input) >= 1 && input[0] >= '1' && input[0] <= '9' {
-		idx := int(input[0] - '1')
-		if idx < len(s.options) {
-			return []string{s.options[idx].Label}, nil
+	var selected []int
+	for _, field := range strings.Split(input, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err == nil && n >= 1 && n <= len(s.options) {
+			selected = append(selected, n-1)
+		}
+		if !s.multiSelect {
+			break
 		}
 	}
+	if len(selected) > 0 {
+		return selected, nil
+	}
 
-	return []string{s.options[0].Label}, nil
+	// Input naming no option never picks one the caller didn't choose as
+	// the default: an optional question is skipped instead
+	switch {
+	case s.defaultIdx >= 0:
+		return []int{s.defaultIdx}, nil
+	case s.optional:
+		return []int{}, nil
+	}
+	return []int{0}, nil
 }
 
-func (s *Selector) moveUp() {
-	if s.selected > 0 {
-		s.selected--
-	} else {
-		s.selected = len(s.options) - 1
+// setFilter shows only the options whose label or description contains
+// filter, ignoring case, and keeps the cursor on a shown option.
+func (s *Selector) setFilter(filter string) {
+	s.filter = filter
+	s.offset = 0
+	s.visible = s.visible[:0]
+	needle := strings.ToLower(filter)
+	for i, opt := range s.options {
+		text := strings.ToLower(opt.Label + " " + opt.Description)
+		if strings.Contains(text, needle) {
+			s.visible = append(s.visible, i)
+		}
+	}
+
+	if len(s.visible) > 0 && s.cursor() < 0 {
+		s.selected = s.visible[0]
+	}
+}
+
+// pickNumber adds a typed digit to the option number and moves the cursor
+// to that option. It reports true once no further digit could name another
+// option, so the pick is final; until then Enter confirms it.
+func (s *Selector) pickNumber(digit int) bool {
+	n := s.number*10 + digit
+	if n < 1 || n > len(s.options) {
+		// Start over with this digit alone
+		n = digit
+		if n < 1 || n > len(s.options) {
+			s.number = 0
+			return false
+		}
+	}
+
+	s.selected = n - 1
+	if n*10 > len(s.options) {
+		s.number = 0
+		return true
+	}
+	s.number = n
+	return false
+}
+
+// cursor returns the position of the selected option among the visible
+// ones, or -1 if it is filtered out.
+func (s *Selector) cursor() int {
+	for pos, i := range s.visible {
+		if i == s.selected {
+			return pos
+		}
 	}
+	return -1
+}
+
+// pageSize is how many options fit on the terminal below the question and
+// hints, leaving room for the scroll markers.
+func (s *Selector) pageSize() int {
+	_, height := TerminalSize()
+	if height <= 0 {
+		return len(s.options)
+	}
+	return max(3, height-6)
+}
+
+// window returns the range of s.visible to show, scrolling s.offset so the
+// cursor stays inside it.
+func (s *Selector) window() (int, int) {
+	size := s.pageSize()
+	if len(s.visible) <= size {
+		return 0, len(s.visible)
+	}
+	// Leave a row for each scroll marker
+	size = max(1, size-2)
+
+	pos := max(0, s.cursor())
+	if pos < s.offset {
+		s.offset = pos
+	}
+	if pos >= s.offset+size {
+		s.offset = pos - size + 1
+	}
+	s.offset = min(s.offset, len(s.visible)-size)
+	return s.offset, s.offset + size
+}
+
+func (s *Selector) moveUp() {
+	s.moveTo(s.cursor() - 1)
 }
 
 func (s *Selector) moveDown() {
-	if s.selected < len(s.options)-1 {
-		s.selected++
-	} else {
-		s.selected = 0
+	s.moveTo(s.cursor() + 1)
+}
+
+// movePage moves the cursor a page up (dir < 0) or down, stopping at the
+// first and last option.
+func (s *Selector) movePage(dir int) {
+	pos := s.cursor() + dir*max(1, s.pageSize()-2)
+	s.moveTo(min(max(pos, 0), len(s.visible)-1))
+}
+
+// moveTo puts the cursor on the visible option at pos, wrapping around at
+// either end.
+func (s *Selector) moveTo(pos int) {
+	if len(s.visible) == 0 {
+		return
 	}
+	pos = (pos + len(s.visible)) % len(s.visible)
+	s.selected = s.visible[pos]
 }
 
 func (s *Selector) toggleSelection() {
 	s.selections[s.selected] = !s.selections[s.selected]
 }
 
-func (s *Selector) getSelected() []string {
+// getSelected returns the indexes of the toggled options, or the option
+// under the cursor when none are toggled.
+func (s *Selector) getSelected() []int {
 	if s.multiSelect {
-		var result []string
-		for i, opt := range s.options {
+		var result []int
+		for i := range s.options {
 			if s.selections[i] {
-				result = append(result, opt.Label)
+				result = append(result, i)
 			}
 		}
 		if len(result) == 0 {
-			return []string{s.options[s.selected].Label}
+			return []int{s.selected}
 		}
 		return result
 	}
-	return []string{s.options[s.selected].Label}
+	return []int{s.selected}
 }
 
-// RunWithCustomOption adds an "Other" option for custom input
+// labels returns the labels of the options at indexes.
+func (s *Selector) labels(indexes []int) []string {
+	result := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		result = append(result, s.options[i].Label)
+	}
+	return result
+}
+
+// RunWithCustomOption adds an option for typing a custom answer. It
+// returns the selected options other than that one, and whether it was
+// chosen too; in a multi-select menu it can be chosen alongside others.
 func (s *Selector) RunWithCustomOption() ([]string, bool, error) {
-	s.options = append(s.options, SelectorOption{
-		Label:       "Other",
-		Description: "Type custom answer",
-	})
+	custom := len(s.options)
+	s.options = append(s.options, SelectorOption{Label: customLabel})
 
-	result, err := s.Run()
+	selected, err := s.run()
 	if err != nil {
 		return nil, false, err
 	}
 
-	for _, r := range result {
-		if r == "Other" {
-			return nil, true, nil
+	wantsCustom := false
+	chosen := selected[:0]
+	for _, i := range selected {
+		if i == custom {
+			wantsCustom = true
+		} else {
+			chosen = append(chosen, i)
 		}
 	}
 
-	return result, false, nil
+	return s.labels(chosen), wantsCustom, nil
 }
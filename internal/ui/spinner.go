@@ -57,8 +57,8 @@ func NewSpinner(colored bool, style ...SpinnerStyle) *Spinner {
 		}
 	}
 
-	spinStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86"))
-	msgStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+	spinStyle := lipgloss.NewStyle().Foreground(palette.Cursor)
+	msgStyle := lipgloss.NewStyle().Foreground(palette.Status).Italic(true)
 
 	return &Spinner{
 		frames:   frames,
@@ -113,7 +113,7 @@ func (s *Spinner) Update(message string) {
 func (s *Spinner) StopWithMessage(message string) {
 	s.Stop()
 	if s.colored {
-		successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+		successStyle := lipgloss.NewStyle().Foreground(palette.Success)
 		fmt.Println(successStyle.Render("✓") + " " + message)
 	} else {
 		fmt.Println("✓ " + message)
@@ -124,7 +124,7 @@ func (s *Spinner) StopWithMessage(message string) {
 func (s *Spinner) StopWithError(message string) {
 	s.Stop()
 	if s.colored {
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		errorStyle := lipgloss.NewStyle().Foreground(palette.Error)
 		fmt.Println(errorStyle.Render("✗") + " " + message)
 	} else {
 		fmt.Println("✗ " + message)
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -12,11 +13,13 @@ type StatusDisplay struct {
 	useSpinner bool
 }
 
-// NewStatusDisplay creates a new status display
+// NewStatusDisplay creates a new status display. It is disabled when stdout
+// is not a terminal, where the spinner and line clearing would end up as
+// escape codes in the output.
 func NewStatusDisplay(formatter *Formatter, enabled bool) *StatusDisplay {
 	return &StatusDisplay{
 		formatter:  formatter,
-		enabled:    enabled,
+		enabled:    enabled && stdoutIsTerminal(),
 		spinner:    NewSpinner(formatter.colored),
 		useSpinner: true, // Enable spinner by default
 	}
@@ -101,6 +104,6 @@ func (s *StatusDisplay) Error(message string) {
 		s.spinner.StopWithError(message)
 	} else {
 		fmt.Print("\r\033[K")
-		fmt.Println(s.formatter.FormatError(fmt.Errorf(message)))
+		fmt.Println(s.formatter.FormatError(errors.New(message)))
 	}
 }
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// isTerminal reports whether fd is a terminal. Tests replace it to
+// simulate one.
+var isTerminal = term.IsTerminal
+
+// stdoutIsTerminal reports whether stdout is a terminal rather than a pipe
+// or file.
+func stdoutIsTerminal() bool {
+	return isTerminal(int(os.Stdout.Fd()))
+}
+
+// ColorSupported reports whether stdout can show colors: it is a terminal
+// and NO_COLOR (https://no-color.org) is not set to a non-empty value.
+func ColorSupported() bool {
+	return os.Getenv("NO_COLOR") == "" && stdoutIsTerminal()
+}
+
+// TerminalSize returns the width and height of the terminal on stdout, or
+// zeros when stdout is not a terminal.
+func TerminalSize() (width, height int) {
+	if !stdoutIsTerminal() {
+		return 0, 0
+	}
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0, 0
+	}
+	return width, height
+}
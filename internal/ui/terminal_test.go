@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"os"
+	"testing"
+)
+
+// pipeStdout points os.Stdout at a pipe for the rest of the test.
+func pipeStdout(t *testing.T) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		w.Close()
+		r.Close()
+	})
+}
+
+// fakeTerminal makes every file descriptor look like a terminal for the
+// rest of the test.
+func fakeTerminal(t *testing.T) {
+	t.Helper()
+
+	orig := isTerminal
+	isTerminal = func(int) bool { return true }
+	t.Cleanup(func() { isTerminal = orig })
+}
+
+func TestPipeGetsPlainOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	pipeStdout(t)
+
+	if ColorSupported() {
+		t.Error("ColorSupported() = true for a pipe")
+	}
+	f := NewFormatter(true)
+	if f.Colored() {
+		t.Error("formatter is colored on a pipe")
+	}
+	if s := NewStatusDisplay(f, true); s.enabled {
+		t.Error("status display is enabled on a pipe")
+	}
+	if w, h := TerminalSize(); w != 0 || h != 0 {
+		t.Errorf("TerminalSize() = %d, %d on a pipe, want zeros", w, h)
+	}
+}
+
+func TestTerminalOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		noColor     string
+		colored     bool
+		wantColored bool
+	}{
+		{"colors", "", true, true},
+		{"colors off in config", "", false, false},
+		{"NO_COLOR set", "1", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			pipeStdout(t)
+			fakeTerminal(t)
+
+			f := NewFormatter(tt.colored)
+			if f.Colored() != tt.wantColored {
+				t.Errorf("Colored() = %v, want %v", f.Colored(), tt.wantColored)
+			}
+			if s := NewStatusDisplay(f, true); !s.enabled {
+				t.Error("status display is disabled on a terminal")
+			}
+		})
+	}
+}
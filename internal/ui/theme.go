@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the color palette used by the formatter, the selector, the
+// spinner and the JSON table rendering. Colors are ANSI 256 numbers or
+// hex codes; an empty color leaves the terminal's default.
+type Theme struct {
+	Name string
+
+	User      lipgloss.Color
+	Assistant lipgloss.Color
+	Error     lipgloss.Color
+	Info      lipgloss.Color
+	System    lipgloss.Color
+	Status    lipgloss.Color // hints and secondary text
+	Dim       lipgloss.Color // labels and token counts
+	Tool      lipgloss.Color
+	Border    lipgloss.Color
+	Header    lipgloss.Color
+	Success   lipgloss.Color
+	Warning   lipgloss.Color
+	Accent    lipgloss.Color
+	Text      lipgloss.Color // descriptions and options
+	Cursor    lipgloss.Color // selector cursor and spinner
+	Match     lipgloss.Color // background of /search matches
+
+	// JSON table rendering
+	Field         lipgloss.Color
+	Tag           lipgloss.Color
+	Summary       lipgloss.Color
+	Step          lipgloss.Color
+	URL           lipgloss.Color
+	Code          lipgloss.Color
+	Reference     lipgloss.Color
+	StatusSuccess lipgloss.Color
+	StatusInfo    lipgloss.Color
+	StatusWarning lipgloss.Color
+	StatusError   lipgloss.Color
+}
+
+// DarkTheme is the default palette, tuned for dark backgrounds.
+func DarkTheme() Theme {
+	return Theme{
+		Name:      "dark",
+		User:      "81",  // Bright cyan
+		Assistant: "114", // Soft green
+		Error:     "203", // Coral red
+		Info:      "222", // Warm yellow
+		System:    "183", // Soft purple
+		Status:    "245", // Medium gray
+		Dim:       "240", // Dim gray
+		Tool:      "215", // Orange
+		Border:    "62",  // Soft blue
+		Header:    "81",
+		Success:   "114",
+		Warning:   "222",
+		Accent:    "147", // Light purple
+		Text:      "252",
+		Cursor:    "86",
+		Match:     "220",
+
+		Field:         "75",
+		Tag:           "141",
+		Summary:       "117",
+		Step:          "228",
+		URL:           "87",
+		Code:          "120",
+		Reference:     "213",
+		StatusSuccess: "46",
+		StatusInfo:    "39",
+		StatusWarning: "226",
+		StatusError:   "196",
+	}
+}
+
+// LightTheme uses darker colors that stay readable on light backgrounds.
+func LightTheme() Theme {
+	return Theme{
+		Name:      "light",
+		User:      "25",
+		Assistant: "28",
+		Error:     "160",
+		Info:      "130",
+		System:    "91",
+		Status:    "242",
+		Dim:       "245",
+		Tool:      "166",
+		Border:    "61",
+		Header:    "25",
+		Success:   "28",
+		Warning:   "130",
+		Accent:    "97",
+		Text:      "236",
+		Cursor:    "30",
+		Match:     "228",
+
+		Field:         "25",
+		Tag:           "91",
+		Summary:       "31",
+		Step:          "94",
+		URL:           "27",
+		Code:          "22",
+		Reference:     "127",
+		StatusSuccess: "28",
+		StatusInfo:    "25",
+		StatusWarning: "130",
+		StatusError:   "160",
+	}
+}
+
+// MonoTheme sets no colors; only bold, italic and similar attributes
+// remain, and /search matches are shown in reverse video.
+func MonoTheme() Theme {
+	return Theme{Name: "mono"}
+}
+
+// themes lists the built-in themes by name.
+var themes = map[string]func() Theme{
+	"dark":  DarkTheme,
+	"light": LightTheme,
+	"mono":  MonoTheme,
+}
+
+// ThemeNames returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// themeRoles maps the role names accepted in ui.colors to Theme fields.
+var themeRoles = map[string]func(*Theme) *lipgloss.Color{
+	"user":           func(t *Theme) *lipgloss.Color { return &t.User },
+	"assistant":      func(t *Theme) *lipgloss.Color { return &t.Assistant },
+	"error":          func(t *Theme) *lipgloss.Color { return &t.Error },
+	"info":           func(t *Theme) *lipgloss.Color { return &t.Info },
+	"system":         func(t *Theme) *lipgloss.Color { return &t.System },
+	"status":         func(t *Theme) *lipgloss.Color { return &t.Status },
+	"dim":            func(t *Theme) *lipgloss.Color { return &t.Dim },
+	"tool":           func(t *Theme) *lipgloss.Color { return &t.Tool },
+	"border":         func(t *Theme) *lipgloss.Color { return &t.Border },
+	"header":         func(t *Theme) *lipgloss.Color { return &t.Header },
+	"success":        func(t *Theme) *lipgloss.Color { return &t.Success },
+	"warning":        func(t *Theme) *lipgloss.Color { return &t.Warning },
+	"accent":         func(t *Theme) *lipgloss.Color { return &t.Accent },
+	"text":           func(t *Theme) *lipgloss.Color { return &t.Text },
+	"cursor":         func(t *Theme) *lipgloss.Color { return &t.Cursor },
+	"match":          func(t *Theme) *lipgloss.Color { return &t.Match },
+	"field":          func(t *Theme) *lipgloss.Color { return &t.Field },
+	"tag":            func(t *Theme) *lipgloss.Color { return &t.Tag },
+	"summary":        func(t *Theme) *lipgloss.Color { return &t.Summary },
+	"step":           func(t *Theme) *lipgloss.Color { return &t.Step },
+	"url":            func(t *Theme) *lipgloss.Color { return &t.URL },
+	"code":           func(t *Theme) *lipgloss.Color { return &t.Code },
+	"reference":      func(t *Theme) *lipgloss.Color { return &t.Reference },
+	"status_success": func(t *Theme) *lipgloss.Color { return &t.StatusSuccess },
+	"status_info":    func(t *Theme) *lipgloss.Color { return &t.StatusInfo },
+	"status_warning": func(t *Theme) *lipgloss.Color { return &t.StatusWarning },
+	"status_error":   func(t *Theme) *lipgloss.Color { return &t.StatusError },
+}
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// LoadTheme returns the built-in theme name with the colors in overrides
+// (role → color) applied on top. An empty name selects the dark theme.
+func LoadTheme(name string, overrides map[string]string) (Theme, error) {
+	if name == "" {
+		name = "dark"
+	}
+	build, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	theme := build()
+
+	for role, color := range overrides {
+		field, ok := themeRoles[strings.ToLower(role)]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown color role %q", role)
+		}
+		color = strings.TrimSpace(color)
+		if !validColor(color) {
+			return Theme{}, fmt.Errorf("invalid color %q for role %q (use 0-255, #rgb or #rrggbb)", color, role)
+		}
+		*field(&theme) = lipgloss.Color(color)
+	}
+	return theme, nil
+}
+
+// validColor reports whether color is empty, an ANSI 256 number or a hex code.
+func validColor(color string) bool {
+	if color == "" || hexColorPattern.MatchString(color) {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// palette is the theme in use.
+var palette = DarkTheme()
+
+// CurrentTheme returns the theme in use.
+func CurrentTheme() Theme {
+	return palette
+}
+
+// ApplyTheme makes t the theme in use and rebuilds the package styles.
+func ApplyTheme(t Theme) {
+	palette = t
+	applyStyles(t)
+}
+
+func init() {
+	applyStyles(palette)
+}